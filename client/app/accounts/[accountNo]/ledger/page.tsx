@@ -177,7 +177,7 @@ export default function AccountLedgerPage() {
                         href={`/vouchers/${entry.voucher_id}`}
                         className="hover:underline"
                       >
-                        #{entry.voucher_number}
+                        {entry.series}{entry.voucher_number}
                       </Link>
                     </td>
                     <td className="py-3 px-6 text-sm text-gray-900">{entry.description}</td>
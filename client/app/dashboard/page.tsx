@@ -130,7 +130,7 @@ export default function DashboardPage() {
               <tbody>
                 {vouchers.map((voucher) => (
                   <tr key={voucher.voucher_id} className="border-b border-gray-100 hover:bg-gray-50">
-                    <td className="py-3 px-4 text-sm font-semibold text-blue-600">#{voucher.voucher_number}</td>
+                    <td className="py-3 px-4 text-sm font-semibold text-blue-600">{voucher.series}{voucher.voucher_number}</td>
                     <td className="py-3 px-4 text-sm text-gray-600">
                       {new Date(voucher.date).toLocaleDateString("sv-SE")}
                     </td>
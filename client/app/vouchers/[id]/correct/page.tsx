@@ -16,6 +16,8 @@ interface LineItemForm {
   debit_amount: string;
   credit_amount: string;
   tax_code: number;
+  project_id: number | null;
+  cost_center_id: number | null;
 }
 
 export default function CorrectVoucherPage() {
@@ -64,6 +66,8 @@ export default function CorrectVoucherPage() {
           debit_amount: item.debit_amount.toString(),
           credit_amount: item.credit_amount.toString(),
           tax_code: item.tax_code,
+          project_id: item.project_id ?? null,
+          cost_center_id: item.cost_center_id ?? null,
         }));
 
         setLineItems(formLineItems);
@@ -95,6 +99,8 @@ export default function CorrectVoucherPage() {
         debit_amount: "",
         credit_amount: "",
         tax_code: 0,
+        project_id: null,
+        cost_center_id: null,
       },
     ]);
   };
@@ -156,20 +162,20 @@ export default function CorrectVoucherPage() {
           "Content-Type": "application/json",
         },
         body: JSON.stringify({
-          user_id: user.user_id,
           new_voucher: {
             date: formData.date,
             description: formData.description,
             reference: formData.reference,
             total_amount: totalDebit,
             period,
-            created_by: user.user_id,
           },
           new_line_items: filledLineItems.map(item => ({
             account_no: parseInt(item.account_no),
             debit_amount: parseFloat(item.debit_amount) || 0,
             credit_amount: parseFloat(item.credit_amount) || 0,
             tax_code: item.tax_code,
+            project_id: item.project_id,
+            cost_center_id: item.cost_center_id,
           })),
         }),
       });
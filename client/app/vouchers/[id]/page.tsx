@@ -77,7 +77,7 @@ export default function VoucherDetailPage() {
       const url = window.URL.createObjectURL(blob);
       const a = document.createElement("a");
       a.href = url;
-      a.download = `verifikat_${voucher.voucher_number}.pdf`;
+      a.download = `verifikat_${voucher.series}${voucher.voucher_number}.pdf`;
       document.body.appendChild(a);
       a.click();
       window.URL.revokeObjectURL(url);
@@ -96,7 +96,7 @@ export default function VoucherDetailPage() {
     }
     setActionLoading(true);
     try {
-      const correctionVoucher = await vouchersApi.createCorrection(voucher.voucher_id, user.user_id);
+      const correctionVoucher = await vouchersApi.createCorrection(voucher.voucher_id);
       router.push(`/vouchers/${correctionVoucher.voucher_id}`);
     } catch (err) {
       setError(err instanceof Error ? err.message : "Kunde inte skapa rättelseverifikat");
@@ -149,7 +149,7 @@ export default function VoucherDetailPage() {
           <div className="flex items-center justify-between">
             <div>
               <h1 className="text-3xl font-bold text-gray-900">
-                Verifikat #{voucher.voucher_number}
+                Verifikat {voucher.series}{voucher.voucher_number}
               </h1>
               <p className="text-gray-600 mt-2">{voucher.description}</p>
             </div>
@@ -190,7 +190,7 @@ export default function VoucherDetailPage() {
                 href={`/vouchers/${correctedByVoucher.voucher_id}`}
                 className="underline hover:text-red-800"
               >
-                verifikat #{correctedByVoucher.voucher_number}
+                verifikat {correctedByVoucher.series}{correctedByVoucher.voucher_number}
               </Link>
             </p>
           </div>
@@ -204,7 +204,7 @@ export default function VoucherDetailPage() {
                 href={`/vouchers/${correctsVoucher.voucher_id}`}
                 className="underline hover:text-orange-800"
               >
-                verifikat #{correctsVoucher.voucher_number}
+                verifikat {correctsVoucher.series}{correctsVoucher.voucher_number}
               </Link>
             </p>
           </div>
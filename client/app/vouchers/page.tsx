@@ -174,7 +174,7 @@ export default function VouchersPage() {
                     className={`hover:bg-gray-50 ${voucher.corrected_by_voucher_id ? 'bg-red-50 opacity-60' : ''}`}
                   >
                     <td className="py-4 px-6 text-sm font-semibold text-blue-600">
-                      #{voucher.voucher_number}
+                      {voucher.series}{voucher.voucher_number}
                       {voucher.corrected_by_voucher_id && (
                         <span className="ml-2 text-xs text-red-600 font-normal">(rättad)</span>
                       )}
@@ -0,0 +1,84 @@
+import {
+  BankImportResult,
+  BankMatchSuggestion,
+  BankReconciliation,
+  BankRule,
+  BankRuleApplication,
+  BankRuleResult,
+  BankTransaction,
+} from "@/types";
+import { apiClient } from "./client";
+
+export const bankApi = {
+  importStatement: async (accountNo: number, file: File, dryRun = false): Promise<BankImportResult> => {
+    const form = new FormData();
+    form.append("file", file);
+    const params = new URLSearchParams({ account_no: String(accountNo) });
+    if (dryRun) {
+      params.append("dry_run", "true");
+    }
+    const response = await fetch(`${process.env.NEXT_PUBLIC_API_URL || "http://localhost:8080/api/v1"}/bank/import?${params.toString()}`, {
+      method: "POST",
+      body: form,
+      credentials: "include",
+    });
+    const data = await response.json().catch(() => ({}));
+    if (!response.ok && response.status !== 422) {
+      throw new Error(data.error || "Failed to import bank statement");
+    }
+    return data;
+  },
+
+  getTransactions: async (accountNo: number, matched?: boolean): Promise<BankTransaction[]> => {
+    const params = new URLSearchParams({ account_no: String(accountNo) });
+    if (matched !== undefined) {
+      params.append("matched", String(matched));
+    }
+    return apiClient.get<BankTransaction[]>(`/bank/transactions?${params.toString()}`);
+  },
+
+  getSuggestions: async (transactionId: number): Promise<BankMatchSuggestion[]> => {
+    return apiClient.get<BankMatchSuggestion[]>(`/bank/transactions/${transactionId}/suggestions`);
+  },
+
+  match: async (transactionId: number, lineId: number): Promise<BankTransaction> => {
+    return apiClient.post<BankTransaction>(`/bank/transactions/${transactionId}/match`, { line_id: lineId });
+  },
+
+  unmatch: async (transactionId: number): Promise<BankTransaction> => {
+    return apiClient.delete<BankTransaction>(`/bank/transactions/${transactionId}/match`);
+  },
+
+  getReconciliation: async (accountNo: number, fromDate: string, toDate: string): Promise<BankReconciliation> => {
+    const params = new URLSearchParams({ account_no: String(accountNo), from_date: fromDate, to_date: toDate });
+    return apiClient.get<BankReconciliation>(`/bank/reconciliation?${params.toString()}`);
+  },
+
+  getRules: async (): Promise<BankRule[]> => {
+    return apiClient.get<BankRule[]>("/bank/rules");
+  },
+
+  createRule: async (data: Partial<BankRule>): Promise<{ message: string; rule: BankRule }> => {
+    return apiClient.post<{ message: string; rule: BankRule }>("/bank/rules", data);
+  },
+
+  updateRule: async (id: number, data: Partial<BankRule>): Promise<{ message: string; rule: BankRule }> => {
+    return apiClient.put<{ message: string; rule: BankRule }>(`/bank/rules/${id}`, data);
+  },
+
+  deleteRule: async (id: number): Promise<{ message: string }> => {
+    return apiClient.delete<{ message: string }>(`/bank/rules/${id}`);
+  },
+
+  getRuleApplications: async (id: number): Promise<BankRuleApplication[]> => {
+    return apiClient.get<BankRuleApplication[]>(`/bank/rules/${id}/applications`);
+  },
+
+  applyRules: async (accountNo: number, dryRun = false): Promise<BankRuleResult> => {
+    const params = new URLSearchParams({ account_no: String(accountNo) });
+    if (dryRun) {
+      params.append("dry_run", "true");
+    }
+    return apiClient.post<BankRuleResult>(`/bank/rules/apply?${params.toString()}`, {});
+  },
+};
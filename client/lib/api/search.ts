@@ -0,0 +1,12 @@
+import { SearchResult } from "@/types";
+import { apiClient } from "./client";
+
+export const searchApi = {
+  search: async (q: string, limit?: number): Promise<SearchResult> => {
+    const params = new URLSearchParams({ q });
+    if (limit) {
+      params.append("limit", String(limit));
+    }
+    return apiClient.get<SearchResult>(`/search?${params.toString()}`);
+  },
+};
@@ -0,0 +1,28 @@
+import { TemplateVoucherRequest, Voucher, VoucherTemplate } from "@/types";
+import { apiClient } from "./client";
+
+export const templatesApi = {
+  getAll: async (): Promise<VoucherTemplate[]> => {
+    return apiClient.get<VoucherTemplate[]>("/voucher-templates");
+  },
+
+  getById: async (id: number): Promise<VoucherTemplate> => {
+    return apiClient.get<VoucherTemplate>(`/voucher-templates/${id}`);
+  },
+
+  create: async (data: Partial<VoucherTemplate>): Promise<{ message: string; template: VoucherTemplate }> => {
+    return apiClient.post<{ message: string; template: VoucherTemplate }>("/voucher-templates", data);
+  },
+
+  update: async (id: number, data: Partial<VoucherTemplate>): Promise<{ message: string; template: VoucherTemplate }> => {
+    return apiClient.put<{ message: string; template: VoucherTemplate }>(`/voucher-templates/${id}`, data);
+  },
+
+  delete: async (id: number): Promise<{ message: string }> => {
+    return apiClient.delete<{ message: string }>(`/voucher-templates/${id}`);
+  },
+
+  createVoucher: async (id: number, data: TemplateVoucherRequest): Promise<Voucher> => {
+    return apiClient.post<Voucher>(`/vouchers/from-template/${id}`, data);
+  },
+};
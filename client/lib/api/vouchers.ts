@@ -1,9 +1,21 @@
-import { Voucher, CreateVoucherRequest, UpdateVoucherRequest, ValidationResponse } from "@/types";
+import { Attachment, Voucher, VoucherPage, VoucherListParams, CreateVoucherRequest, UpdateVoucherRequest, ValidationResponse } from "@/types";
 import { apiClient } from "./client";
 
 export const vouchersApi = {
+  list: async (params: VoucherListParams = {}): Promise<VoucherPage> => {
+    const query = new URLSearchParams();
+    Object.entries(params).forEach(([key, value]) => {
+      if (value !== undefined && value !== "") {
+        query.append(key, String(value));
+      }
+    });
+    const qs = query.toString();
+    return apiClient.get<VoucherPage>(qs ? `/vouchers?${qs}` : "/vouchers");
+  },
+
   getAll: async (): Promise<Voucher[]> => {
-    return apiClient.get<Voucher[]>("/vouchers");
+    const page = await vouchersApi.list({ limit: 500 });
+    return page.vouchers;
   },
 
   getById: async (id: number): Promise<Voucher> => {
@@ -38,8 +50,32 @@ export const vouchersApi = {
     return apiClient.delete<void>(`/vouchers/${id}`);
   },
 
-  createCorrection: async (id: number, userId: number): Promise<Voucher> => {
-    return apiClient.post<Voucher>(`/vouchers/${id}/correct`, { user_id: userId });
+  book: async (id: number): Promise<Voucher> => {
+    return apiClient.post<Voucher>(`/vouchers/${id}/book`, {});
+  },
+
+  // The reversal is dated date (YYYY-MM-DD) or today and booked in that period
+  createCorrection: async (id: number, date?: string): Promise<Voucher> => {
+    return apiClient.post<Voucher>(`/vouchers/${id}/correct`, { date });
+  },
+
+  getAttachments: async (id: number): Promise<Attachment[]> => {
+    return apiClient.get<Attachment[]>(`/vouchers/${id}/attachments`);
+  },
+
+  uploadAttachment: async (id: number, file: File): Promise<Attachment> => {
+    const form = new FormData();
+    form.append("file", file);
+    const response = await fetch(`${process.env.NEXT_PUBLIC_API_URL || "http://localhost:8080/api/v1"}/vouchers/${id}/attachments`, {
+      method: "POST",
+      body: form,
+      credentials: "include",
+    });
+    if (!response.ok) {
+      const data = await response.json().catch(() => ({}));
+      throw new Error(data.error || "Failed to upload attachment");
+    }
+    return response.json();
   },
 
   getPdf: async (id: number): Promise<Blob> => {
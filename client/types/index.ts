@@ -88,9 +88,13 @@ export interface UpdateLineItemRequest {
 }
 
 // Voucher types
+export type VoucherStatus = "draft" | "booked";
+
 export interface Voucher {
   voucher_id: number;
-  voucher_number: number;
+  series: string;
+  voucher_number: number; // 0 för utkast
+  status: VoucherStatus;
   date: string;
   description: string;
   reference: string;
@@ -99,18 +103,205 @@ export interface Voucher {
   created_by: number;
   corrects_voucher_id?: number | null;     // ID för verifikat som detta rättar
   corrected_by_voucher_id?: number | null; // ID för verifikat som rättat detta
+  chain_position?: number | null;          // Plats i hashkedjan, null om bokförd före kedjan
+  hash?: string | null;                    // SHA-256 över verifikatet och föregående hash
+  booked_at?: string | null;               // Tidpunkt för bokföringen, null för utkast
   created_at: string;
   updated_at: string;
   lines?: LineItem[]; // Populated in detail responses
 }
 
+export interface VoucherPage {
+  vouchers: Voucher[];
+  total: number;  // Antal verifikat som matchar filtret
+  limit: number;
+  offset: number;
+}
+
+export interface VoucherListParams {
+  from_date?: string;
+  to_date?: string;
+  period?: string;
+  min_amount?: number;
+  max_amount?: number;
+  account_no?: number;
+  created_by?: number;
+  corrected?: boolean;
+  status?: VoucherStatus;
+  q?: string;
+  sort?: "number" | "date" | "amount" | "created_at";
+  order?: "asc" | "desc";
+  limit?: number;
+  offset?: number;
+}
+
+export interface SearchHit {
+  voucher: Voucher;
+  rank: number;
+  // Träffar markerade med <mark></mark>, per fält
+  snippets: Partial<Record<"description" | "reference" | "accounts" | "amount", string>>;
+}
+
+export interface SearchResult {
+  query: string;
+  hits: SearchHit[];
+}
+
+export interface Attachment {
+  attachment_id: number;
+  voucher_id: number;
+  file_name: string;
+  content_type: string; // "application/pdf", "image/jpeg" ...
+  size: number;         // Storlek i byte
+  checksum: string;     // SHA-256, hex
+  uploaded_by: number;
+  created_at: string;
+}
+
+export interface BankTransaction {
+  transaction_id: number;
+  account_no: number;
+  booking_date: string;
+  value_date: string | null;
+  amount: number;          // Insättningar positiva, uttag negativa
+  currency: string;
+  text: string;
+  reference: string;
+  counterparty: string;
+  bank_reference: string;
+  matched_line_id: number | null;
+  match_method: "" | "auto" | "manual";
+  matched_by: number | null;
+  matched_at: string | null;
+  imported_by: number;
+  created_at: string;
+}
+
+export interface BankLedgerLine {
+  line_id: number;
+  voucher_id: number;
+  series: string;
+  voucher_number: number;
+  date: string;
+  description: string;
+  reference: string;
+  amount: number; // Debet minus kredit
+}
+
+export interface BankMatchSuggestion {
+  line: BankLedgerLine;
+  score: number;
+  reasons: ("amount" | "reference" | "date" | "text")[];
+}
+
+export interface BankImportResult {
+  dry_run: boolean;
+  format: "camt.053" | "csv";
+  account_no: number;
+  imported: number;
+  duplicates: number;
+  matched: number;
+  transactions: BankTransaction[];
+  errors: { line: number; message: string }[];
+}
+
+export interface BankReconciliation {
+  account_no: number;
+  from: string;
+  to: string;
+  bank_total: number;
+  ledger_total: number;
+  difference: number;
+  matched: number;
+  unmatched_bank: BankTransaction[];
+  unmatched_ledger: BankLedgerLine[];
+}
+
+export interface BankRule {
+  rule_id: number;
+  name: string;
+  priority: number;               // Lägre prövas först
+  account_no: number | null;      // Bankkonto, null för alla
+  counterparty_pattern: string;   // Reguljärt uttryck, skiftlägesokänsligt
+  text_pattern: string;
+  direction: "" | "in" | "out";
+  min_amount: number | null;      // Utan tecken
+  max_amount: number | null;
+  description: string;            // Verifikattext, banktexten om tom
+  counter_account_no: number;
+  tax_code: 0 | 6 | 12 | 25 | null; // null för kontots momssats
+  project_id: number | null;
+  cost_center_id: number | null;
+  series: string;
+  active: boolean;
+  created_by: number;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface BankRuleApplication {
+  application_id: number;
+  rule_id: number;
+  transaction_id: number;
+  voucher_id: number;
+  created_by: number;
+  created_at: string;
+}
+
+export interface BankRuleProposal {
+  transaction: BankTransaction;
+  rule_id: number;
+  rule_name: string;
+  voucher: Voucher | null;
+  error?: string;
+}
+
+export interface BankRuleResult {
+  dry_run: boolean;
+  proposals: BankRuleProposal[];
+  created: number;
+  unmatched: number;
+}
+
+export interface VoucherTemplateLine {
+  line_id?: number;
+  account_no: number;
+  side: "debit" | "credit";
+  amount: number | null;          // Fast belopp
+  percentage: number | null;      // Andel av totalbeloppet
+  tax_code: 0 | 6 | 12 | 25;
+  project_id: number | null;
+  cost_center_id: number | null;
+}
+
+export interface VoucherTemplate {
+  template_id: number;
+  name: string;
+  description: string;
+  series: string;
+  active: boolean;
+  created_by: number;
+  created_at: string;
+  updated_at: string;
+  lines: VoucherTemplateLine[];
+}
+
+export interface TemplateVoucherRequest {
+  date: string;
+  total_amount?: number;          // Krävs om mallen har procentrader
+  description?: string;
+  reference?: string;
+  status?: "draft" | "booked";
+}
+
 export interface CreateVoucherRequest {
   date: string;
   description: string;
   reference: string;
   total_amount: number;
-  period: string;
+  period?: string;        // Datumets månad (YYYY-MM), härleds om den saknas
   created_by: number;
+  status?: VoucherStatus; // "booked" bokför direkt, annars sparas ett utkast
   lines?: CreateLineItemRequest[];
 }
 
@@ -180,6 +371,7 @@ export interface VoucherFilters extends PaginationParams {
 export interface LedgerEntry {
   date: string;
   voucher_id: number;
+  series: string;
   voucher_number: number;
   description: string;
   reference: string;
@@ -1,11 +1,13 @@
 -- BAS 2025 Kontoplan - Comprehensive Account List
--- This will populate the accounts table with standard Swedish BAS accounts
+-- This will populate the chart of accounts of every company with the
+-- standard Swedish BAS accounts. Accounts a company already has are kept.
 
 -- Clear existing accounts (optional - comment out if you want to keep existing)
--- DELETE FROM accounts;
+-- DELETE FROM accounts WHERE company_id = <company_id>;
 
 -- Group 1: Tillgångar (Assets) - Debit side, Balance Sheet
-INSERT INTO accounts (account_no, account_name, account_group, tax_standard, type, standard_side) VALUES
+INSERT INTO accounts (company_id, account_no, account_name, account_group, tax_standard, type, standard_side)
+SELECT c.company_id, bas.* FROM companies c CROSS JOIN (VALUES
 -- Immateriella anläggningstillgångar
 (1010, 'Utvecklingsutgifter', 1, '0%', 'BS', 'Debit'),
 (1020, 'Koncessioner m.m.', 1, '0%', 'BS', 'Debit'),
@@ -84,10 +86,12 @@ INSERT INTO accounts (account_no, account_name, account_group, tax_standard, typ
 (1940, 'Övriga bankkonton', 1, '0%', 'BS', 'Debit'),
 (1950, 'Bankcertifikat', 1, '0%', 'BS', 'Debit')
 
-ON CONFLICT (account_no) DO NOTHING;
+) AS bas(account_no, account_name, account_group, tax_standard, type, standard_side)
+ON CONFLICT (company_id, account_no) DO NOTHING;
 
 -- Group 2: Eget kapital och skulder (Equity and Liabilities) - Credit side, Balance Sheet
-INSERT INTO accounts (account_no, account_name, account_group, tax_standard, type, standard_side) VALUES
+INSERT INTO accounts (company_id, account_no, account_name, account_group, tax_standard, type, standard_side)
+SELECT c.company_id, bas.* FROM companies c CROSS JOIN (VALUES
 -- Eget kapital
 (2010, 'Eget kapital', 2, '0%', 'BS', 'Credit'),
 (2081, 'Aktiekapital', 2, '0%', 'BS', 'Credit'),
@@ -155,10 +159,12 @@ INSERT INTO accounts (account_no, account_name, account_group, tax_standard, typ
 (2950, 'Förutbetalda hyresintäkter', 2, '0%', 'BS', 'Credit'),
 (2990, 'Övriga upplupna kostnader och förutbetalda intäkter', 2, '0%', 'BS', 'Credit')
 
-ON CONFLICT (account_no) DO NOTHING;
+) AS bas(account_no, account_name, account_group, tax_standard, type, standard_side)
+ON CONFLICT (company_id, account_no) DO NOTHING;
 
 -- Group 3: Intäkter (Revenue) - Credit side, Income Statement
-INSERT INTO accounts (account_no, account_name, account_group, tax_standard, type, standard_side) VALUES
+INSERT INTO accounts (company_id, account_no, account_name, account_group, tax_standard, type, standard_side)
+SELECT c.company_id, bas.* FROM companies c CROSS JOIN (VALUES
 (3000, 'Försäljning varor och tjänster', 3, '25%', 'P&L', 'Credit'),
 (3001, 'Försäljning varor, Sverige', 3, '25%', 'P&L', 'Credit'),
 (3002, 'Försäljning varor, EU', 3, '0%', 'P&L', 'Credit'),
@@ -198,10 +204,12 @@ INSERT INTO accounts (account_no, account_name, account_group, tax_standard, typ
 (3970, 'Valutakursförluster på fordringar och skulder av rörelsekaraktär', 3, '0%', 'P&L', 'Debit'),
 (3990, 'Övriga rörelseintäkter', 3, '0%', 'P&L', 'Credit')
 
-ON CONFLICT (account_no) DO NOTHING;
+) AS bas(account_no, account_name, account_group, tax_standard, type, standard_side)
+ON CONFLICT (company_id, account_no) DO NOTHING;
 
 -- Group 4: Kostnader för varor, material och underentreprenörer (Cost of Goods) - Debit side, Income Statement
-INSERT INTO accounts (account_no, account_name, account_group, tax_standard, type, standard_side) VALUES
+INSERT INTO accounts (company_id, account_no, account_name, account_group, tax_standard, type, standard_side)
+SELECT c.company_id, bas.* FROM companies c CROSS JOIN (VALUES
 (4000, 'Inköp varor och material', 4, '25%', 'P&L', 'Debit'),
 (4010, 'Inköp varor inom Sverige', 4, '25%', 'P&L', 'Debit'),
 (4011, 'Inköp varor inom EU', 4, '0%', 'P&L', 'Debit'),
@@ -216,10 +224,12 @@ INSERT INTO accounts (account_no, account_name, account_group, tax_standard, typ
 (4500, 'Förändring av pågående arbeten', 4, '0%', 'P&L', 'Debit'),
 (4900, 'Övriga kostnader för varor', 4, '25%', 'P&L', 'Debit')
 
-ON CONFLICT (account_no) DO NOTHING;
+) AS bas(account_no, account_name, account_group, tax_standard, type, standard_side)
+ON CONFLICT (company_id, account_no) DO NOTHING;
 
 -- Group 5: Övriga externa kostnader (Other External Costs) - Debit side, Income Statement
-INSERT INTO accounts (account_no, account_name, account_group, tax_standard, type, standard_side) VALUES
+INSERT INTO accounts (company_id, account_no, account_name, account_group, tax_standard, type, standard_side)
+SELECT c.company_id, bas.* FROM companies c CROSS JOIN (VALUES
 (5000, 'Lokalkostnader', 5, '25%', 'P&L', 'Debit'),
 (5010, 'Lokalhyra', 5, '25%', 'P&L', 'Debit'),
 (5020, 'El för belysning', 5, '25%', 'P&L', 'Debit'),
@@ -270,10 +280,12 @@ INSERT INTO accounts (account_no, account_name, account_group, tax_standard, typ
 (5930, 'Representation', 5, '25%', 'P&L', 'Debit'),
 (5940, 'Utbildning', 5, '25%', 'P&L', 'Debit')
 
-ON CONFLICT (account_no) DO NOTHING;
+) AS bas(account_no, account_name, account_group, tax_standard, type, standard_side)
+ON CONFLICT (company_id, account_no) DO NOTHING;
 
 -- Group 6: Övriga externa kostnader (fortsättning) + Personalkostnader
-INSERT INTO accounts (account_no, account_name, account_group, tax_standard, type, standard_side) VALUES
+INSERT INTO accounts (company_id, account_no, account_name, account_group, tax_standard, type, standard_side)
+SELECT c.company_id, bas.* FROM companies c CROSS JOIN (VALUES
 (6000, 'Lokalkostnader', 6, '25%', 'P&L', 'Debit'),
 (6010, 'Licenser och överlåtelser', 6, '25%', 'P&L', 'Debit'),
 (6020, 'Royalties', 6, '25%', 'P&L', 'Debit'),
@@ -306,10 +318,12 @@ INSERT INTO accounts (account_no, account_name, account_group, tax_standard, typ
 (6980, 'Föreningsavgifter', 6, '25%', 'P&L', 'Debit'),
 (6990, 'Övriga externa kostnader', 6, '25%', 'P&L', 'Debit')
 
-ON CONFLICT (account_no) DO NOTHING;
+) AS bas(account_no, account_name, account_group, tax_standard, type, standard_side)
+ON CONFLICT (company_id, account_no) DO NOTHING;
 
 -- Group 7: Personalkostnader och avskrivningar
-INSERT INTO accounts (account_no, account_name, account_group, tax_standard, type, standard_side) VALUES
+INSERT INTO accounts (company_id, account_no, account_name, account_group, tax_standard, type, standard_side)
+SELECT c.company_id, bas.* FROM companies c CROSS JOIN (VALUES
 (7000, 'Löner till arbetare', 7, '0%', 'P&L', 'Debit'),
 (7010, 'Löner till arbetsledare', 7, '0%', 'P&L', 'Debit'),
 (7020, 'Löner till kontorspersonal', 7, '0%', 'P&L', 'Debit'),
@@ -350,10 +364,12 @@ INSERT INTO accounts (account_no, account_name, account_group, tax_standard, typ
 (7831, 'Avskrivning inventarier och verktyg', 7, '0%', 'P&L', 'Debit'),
 (7832, 'Avskrivning datorer', 7, '0%', 'P&L', 'Debit')
 
-ON CONFLICT (account_no) DO NOTHING;
+) AS bas(account_no, account_name, account_group, tax_standard, type, standard_side)
+ON CONFLICT (company_id, account_no) DO NOTHING;
 
 -- Group 8: Finansiella poster och extraordinära poster
-INSERT INTO accounts (account_no, account_name, account_group, tax_standard, type, standard_side) VALUES
+INSERT INTO accounts (company_id, account_no, account_name, account_group, tax_standard, type, standard_side)
+SELECT c.company_id, bas.* FROM companies c CROSS JOIN (VALUES
 (8000, 'Finansiella intäkter', 8, '0%', 'P&L', 'Credit'),
 (8010, 'Ränteintäkter från koncernföretag', 8, '0%', 'P&L', 'Credit'),
 (8020, 'Ränteintäkter från övriga företag', 8, '0%', 'P&L', 'Credit'),
@@ -386,8 +402,9 @@ INSERT INTO accounts (account_no, account_name, account_group, tax_standard, typ
 (8900, 'Periodens resultat', 8, '0%', 'P&L', 'Credit'),
 (8999, 'Årets resultat', 8, '0%', 'P&L', 'Credit')
 
-ON CONFLICT (account_no) DO NOTHING;
+) AS bas(account_no, account_name, account_group, tax_standard, type, standard_side)
+ON CONFLICT (company_id, account_no) DO NOTHING;
 
 -- Verify insertion
-SELECT 'Total accounts inserted:' as message, COUNT(*) as count FROM accounts;
-SELECT 'Accounts by group:' as message, account_group, COUNT(*) as count FROM accounts GROUP BY account_group ORDER BY account_group;
+SELECT 'Total accounts inserted:' as message, company_id, COUNT(*) as count FROM accounts GROUP BY company_id ORDER BY company_id;
+SELECT 'Accounts by group:' as message, company_id, account_group, COUNT(*) as count FROM accounts GROUP BY company_id, account_group ORDER BY company_id, account_group;
@@ -8,11 +8,14 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
-// Claims defines the JWT claims structure
+// Claims defines the JWT claims structure. CompanyID is the active company
+// and Role the user's role in it; CompanyID is 0 for users that are not a
+// member of any company.
 type Claims struct {
-	UserID int    `json:"user_id"`
-	Email  string `json:"email"`
-	Role   string `json:"role"`
+	UserID    int    `json:"user_id"`
+	Email     string `json:"email"`
+	Role      string `json:"role"`
+	CompanyID int    `json:"company_id"`
 	jwt.RegisteredClaims
 }
 
@@ -30,12 +33,14 @@ func NewJWTManager(secretKey string, expiration time.Duration) *JWTManager {
 	}
 }
 
-// GenerateToken generates a new JWT token for a user
-func (m *JWTManager) GenerateToken(userID int, email, role string) (string, error) {
+// GenerateToken generates a new JWT token for a user working in companyID
+// with the given role there
+func (m *JWTManager) GenerateToken(userID int, email, role string, companyID int) (string, error) {
 	claims := &Claims{
-		UserID: userID,
-		Email:  email,
-		Role:   role,
+		UserID:    userID,
+		Email:     email,
+		Role:      role,
+		CompanyID: companyID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(m.expiration)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -73,14 +78,3 @@ func (m *JWTManager) ValidateToken(tokenString string) (*Claims, error) {
 
 	return claims, nil
 }
-
-// RefreshToken generates a new token with extended expiration
-func (m *JWTManager) RefreshToken(tokenString string) (string, error) {
-	claims, err := m.ValidateToken(tokenString)
-	if err != nil {
-		return "", err
-	}
-
-	// Generate new token with same user info
-	return m.GenerateToken(claims.UserID, claims.Email, claims.Role)
-}
@@ -0,0 +1,77 @@
+// Package bank reads bank statements: ISO 20022 camt.053 XML and the CSV
+// exports of the Swedish banks.
+package bank
+
+import (
+	"bytes"
+	"cmd/api/internal/domain"
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// Statement formats
+const (
+	FormatCamt053 = "camt.053"
+	FormatCSV     = "csv"
+)
+
+// Statement is the booked transactions of one bank account
+type Statement struct {
+	Format         string
+	Account        string // IBAN or account number, if the file has it
+	Currency       string
+	OpeningBalance *domain.Amount // Ingående saldo, if the file has it
+	ClosingBalance *domain.Amount // Utgående saldo, if the file has it
+	Transactions   []Transaction
+}
+
+// Transaction is one booked row of a statement. Money into the account is
+// positive and money out negative.
+type Transaction struct {
+	BookingDate   time.Time
+	ValueDate     *time.Time
+	Amount        domain.Amount
+	Currency      string
+	Text          string
+	Reference     string // OCR, invoice number or end-to-end reference
+	Counterparty  string
+	BankReference string // The bank's own ID of the row, if the file has it
+	Line          int    // Row in a CSV file, entry number in camt.053
+}
+
+// ParseError is a problem on a given line of the file (1-based)
+type ParseError struct {
+	Line    int
+	Message string
+}
+
+func (e ParseError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Message)
+}
+
+// Parse reads a statement in either format; XML is read as camt.053 and
+// anything else as CSV. Like the SIE reader it keeps going after bad rows,
+// so every problem is reported.
+func Parse(data []byte) (*Statement, []ParseError) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("<")) {
+		return ParseCamt053(data)
+	}
+	return ParseCSV(data)
+}
+
+// decode converts the file to UTF-8. The banks' CSV exports are often
+// Windows-1252; a file that is valid UTF-8 is kept as is.
+func decode(data []byte) []byte {
+	if utf8.Valid(data) {
+		return data
+	}
+	out, err := charmap.Windows1252.NewDecoder().Bytes(data)
+	if err != nil {
+		return data
+	}
+	return out
+}
@@ -0,0 +1,227 @@
+package bank
+
+import (
+	"cmd/api/internal/domain"
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// camtDocument is the part of a camt.053 document that is read. The tags
+// have no namespace, so every version of the schema (001.02 to 001.11) is
+// accepted.
+type camtDocument struct {
+	Statements []camtStatement `xml:"BkToCstmrStmt>Stmt"`
+}
+
+type camtStatement struct {
+	Account struct {
+		IBAN     string `xml:"Id>IBAN"`
+		Other    string `xml:"Id>Othr>Id"`
+		Currency string `xml:"Ccy"`
+	} `xml:"Acct"`
+	Balances []struct {
+		Code   string     `xml:"Tp>CdOrPrtry>Cd"`
+		Amount camtAmount `xml:"Amt"`
+		Sign   string     `xml:"CdtDbtInd"`
+	} `xml:"Bal"`
+	Entries []camtEntry `xml:"Ntry"`
+}
+
+type camtAmount struct {
+	Value    string `xml:",chardata"`
+	Currency string `xml:"Ccy,attr"`
+}
+
+type camtEntry struct {
+	Amount camtAmount `xml:"Amt"`
+	Sign   string     `xml:"CdtDbtInd"`
+	Status struct {
+		Value string `xml:",chardata"` // 001.02 to 001.06
+		Code  string `xml:"Cd"`        // 001.07 and later
+	} `xml:"Sts"`
+	BookingDate   camtDate `xml:"BookgDt"`
+	ValueDate     camtDate `xml:"ValDt"`
+	BankReference string   `xml:"AcctSvcrRef"`
+	Details       []struct {
+		EndToEndID   string    `xml:"Refs>EndToEndId"`
+		Creditor     camtParty `xml:"RltdPties>Cdtr"`
+		Debtor       camtParty `xml:"RltdPties>Dbtr"`
+		Unstructured []string  `xml:"RmtInf>Ustrd"`
+		CreditorRef  string    `xml:"RmtInf>Strd>CdtrRefInf>Ref"`
+	} `xml:"NtryDtls>TxDtls"`
+	AdditionalInfo string `xml:"AddtlNtryInf"`
+}
+
+type camtDate struct {
+	Date     string `xml:"Dt"`
+	DateTime string `xml:"DtTm"`
+}
+
+type camtParty struct {
+	Name      string `xml:"Nm"`
+	PartyName string `xml:"Pty>Nm"` // 001.08 and later
+}
+
+func (p camtParty) name() string {
+	if p.Name != "" {
+		return p.Name
+	}
+	return p.PartyName
+}
+
+func (d camtDate) parse() (*time.Time, error) {
+	switch {
+	case d.Date != "":
+		t, err := time.Parse("2006-01-02", d.Date)
+		if err != nil {
+			return nil, fmt.Errorf("invalid date %q", d.Date)
+		}
+		return &t, nil
+	case d.DateTime != "":
+		t, err := time.Parse("2006-01-02T15:04:05", d.DateTime[:min(len(d.DateTime), 19)])
+		if err != nil {
+			return nil, fmt.Errorf("invalid date %q", d.DateTime)
+		}
+		t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		return &t, nil
+	}
+	return nil, nil
+}
+
+// signed returns the amount with a debit (DBIT) as negative
+func signed(value, sign string) (domain.Amount, error) {
+	amount, err := domain.ParseAmount(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q", value)
+	}
+	switch sign {
+	case "CRDT":
+		return amount, nil
+	case "DBIT":
+		return amount.Neg(), nil
+	}
+	return 0, fmt.Errorf("invalid credit/debit indicator %q", sign)
+}
+
+// ParseCamt053 reads an ISO 20022 bank-to-customer statement. Only booked
+// entries are read; pending and information entries are skipped. A file
+// with several statements must be for one account.
+func ParseCamt053(data []byte) (*Statement, []ParseError) {
+	var doc camtDocument
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return &Statement{Format: FormatCamt053}, []ParseError{{Line: 0, Message: fmt.Sprintf("invalid camt.053 XML: %v", err)}}
+	}
+	if len(doc.Statements) == 0 {
+		return &Statement{Format: FormatCamt053}, []ParseError{{Line: 0, Message: "the file has no BkToCstmrStmt/Stmt, is it a camt.053 statement?"}}
+	}
+
+	statement := &Statement{Format: FormatCamt053}
+	var errs []ParseError
+	entryNo := 0
+	for _, stmt := range doc.Statements {
+		account := stmt.Account.IBAN
+		if account == "" {
+			account = stmt.Account.Other
+		}
+		if statement.Account != "" && account != statement.Account {
+			errs = append(errs, ParseError{Message: fmt.Sprintf("the file has statements for both %s and %s", statement.Account, account)})
+			continue
+		}
+		statement.Account = account
+		statement.Currency = stmt.Account.Currency
+
+		for _, balance := range stmt.Balances {
+			amount, err := signed(balance.Amount.Value, balance.Sign)
+			if err != nil {
+				errs = append(errs, ParseError{Message: "balance: " + err.Error()})
+				continue
+			}
+			switch balance.Code {
+			case "OPBD":
+				if statement.OpeningBalance == nil {
+					statement.OpeningBalance = &amount
+				}
+			case "CLBD":
+				statement.ClosingBalance = &amount
+			}
+		}
+
+		for _, entry := range stmt.Entries {
+			entryNo++
+			status := strings.TrimSpace(entry.Status.Code)
+			if status == "" {
+				status = strings.TrimSpace(entry.Status.Value)
+			}
+			if status != "" && status != "BOOK" {
+				continue
+			}
+
+			fail := func(format string, args ...interface{}) {
+				errs = append(errs, ParseError{Line: entryNo, Message: fmt.Sprintf(format, args...)})
+			}
+
+			amount, err := signed(entry.Amount.Value, entry.Sign)
+			if err != nil {
+				fail("%v", err)
+				continue
+			}
+			bookingDate, err := entry.BookingDate.parse()
+			if err != nil || bookingDate == nil {
+				fail("entry has no valid booking date")
+				continue
+			}
+			valueDate, err := entry.ValueDate.parse()
+			if err != nil {
+				fail("%v", err)
+				continue
+			}
+
+			transaction := Transaction{
+				BookingDate:   *bookingDate,
+				ValueDate:     valueDate,
+				Amount:        amount,
+				Currency:      entry.Amount.Currency,
+				Text:          strings.TrimSpace(entry.AdditionalInfo),
+				BankReference: strings.TrimSpace(entry.BankReference),
+				Line:          entryNo,
+			}
+			if len(entry.Details) > 0 {
+				details := entry.Details[0]
+				texts := make([]string, 0, len(details.Unstructured))
+				for _, text := range details.Unstructured {
+					if text = strings.TrimSpace(text); text != "" {
+						texts = append(texts, text)
+					}
+				}
+				if len(texts) > 0 {
+					transaction.Text = strings.Join(texts, " ")
+				}
+
+				transaction.Reference = strings.TrimSpace(details.CreditorRef)
+				if transaction.Reference == "" && details.EndToEndID != "NOTPROVIDED" {
+					transaction.Reference = strings.TrimSpace(details.EndToEndID)
+				}
+
+				// The counterparty is the creditor of money going out and
+				// the debtor of money coming in
+				if amount.IsNegative() {
+					transaction.Counterparty = strings.TrimSpace(details.Creditor.name())
+				} else {
+					transaction.Counterparty = strings.TrimSpace(details.Debtor.name())
+				}
+			}
+			if transaction.Text == "" {
+				transaction.Text = transaction.Counterparty
+			}
+			if transaction.Currency == "" {
+				transaction.Currency = statement.Currency
+			}
+
+			statement.Transactions = append(statement.Transactions, transaction)
+		}
+	}
+
+	return statement, errs
+}
@@ -0,0 +1,171 @@
+package bank
+
+import (
+	"cmd/api/internal/domain"
+	"reflect"
+	"testing"
+	"time"
+)
+
+const sampleCamt053 = `<?xml version="1.0" encoding="UTF-8"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:camt.053.001.08">
+  <BkToCstmrStmt>
+    <Stmt>
+      <Acct>
+        <Id><IBAN>SE4550000000058398257466</IBAN></Id>
+        <Ccy>SEK</Ccy>
+      </Acct>
+      <Bal>
+        <Tp><CdOrPrtry><Cd>OPBD</Cd></CdOrPrtry></Tp>
+        <Amt Ccy="SEK">1000.00</Amt>
+        <CdtDbtInd>CRDT</CdtDbtInd>
+      </Bal>
+      <Bal>
+        <Tp><CdOrPrtry><Cd>CLBD</Cd></CdOrPrtry></Tp>
+        <Amt Ccy="SEK">50.00</Amt>
+        <CdtDbtInd>DBIT</CdtDbtInd>
+      </Bal>
+      <Ntry>
+        <Amt Ccy="SEK">1250.00</Amt>
+        <CdtDbtInd>CRDT</CdtDbtInd>
+        <Sts>BOOK</Sts>
+        <BookgDt><Dt>2025-01-03</Dt></BookgDt>
+        <ValDt><Dt>2025-01-04</Dt></ValDt>
+        <AcctSvcrRef>REF-1</AcctSvcrRef>
+        <NtryDtls><TxDtls>
+          <Refs><EndToEndId>E2E-1</EndToEndId></Refs>
+          <RltdPties>
+            <Dbtr><Nm>Kund AB</Nm></Dbtr>
+            <Cdtr><Nm>Exempel AB</Nm></Cdtr>
+          </RltdPties>
+          <RmtInf><Ustrd>Faktura 17</Ustrd><Ustrd> januari </Ustrd></RmtInf>
+        </TxDtls></NtryDtls>
+      </Ntry>
+      <Ntry>
+        <Amt Ccy="SEK">300.00</Amt>
+        <CdtDbtInd>CRDT</CdtDbtInd>
+        <Sts><Cd>PDNG</Cd></Sts>
+        <BookgDt><Dt>2025-01-05</Dt></BookgDt>
+      </Ntry>
+      <Ntry>
+        <Amt>99.50</Amt>
+        <CdtDbtInd>DBIT</CdtDbtInd>
+        <Sts><Cd>BOOK</Cd></Sts>
+        <BookgDt><DtTm>2025-01-06T14:30:00+01:00</DtTm></BookgDt>
+        <NtryDtls><TxDtls>
+          <Refs><EndToEndId>NOTPROVIDED</EndToEndId></Refs>
+          <RltdPties>
+            <Dbtr><Pty><Nm>Exempel AB</Nm></Pty></Dbtr>
+            <Cdtr><Pty><Nm>Leverantör AB</Nm></Pty></Cdtr>
+          </RltdPties>
+          <RmtInf><Strd><CdtrRefInf><Ref>4711</Ref></CdtrRefInf></Strd></RmtInf>
+        </TxDtls></NtryDtls>
+        <AddtlNtryInf>Bankgiro</AddtlNtryInf>
+      </Ntry>
+      <Ntry>
+        <Amt Ccy="SEK">10.00</Amt>
+        <CdtDbtInd>X</CdtDbtInd>
+        <Sts>BOOK</Sts>
+        <BookgDt><Dt>2025-01-07</Dt></BookgDt>
+      </Ntry>
+    </Stmt>
+  </BkToCstmrStmt>
+</Document>
+`
+
+func TestParseCamt053(t *testing.T) {
+	statement, errs := Parse([]byte("\xef\xbb\xbf" + sampleCamt053))
+	if len(errs) != 1 || errs[0].Line != 4 {
+		t.Errorf("errors = %v, want one on entry 4", errs)
+	}
+
+	if statement.Format != FormatCamt053 || statement.Account != "SE4550000000058398257466" || statement.Currency != "SEK" {
+		t.Errorf("statement = %s %s %s", statement.Format, statement.Account, statement.Currency)
+	}
+	if statement.OpeningBalance == nil || *statement.OpeningBalance != 100000 {
+		t.Errorf("OpeningBalance = %v, want 1000.00", statement.OpeningBalance)
+	}
+	if statement.ClosingBalance == nil || *statement.ClosingBalance != -5000 {
+		t.Errorf("ClosingBalance = %v, want -50.00", statement.ClosingBalance)
+	}
+
+	valueDate := date(2025, time.January, 4)
+	want := []Transaction{
+		{
+			BookingDate:   date(2025, time.January, 3),
+			ValueDate:     &valueDate,
+			Amount:        125000,
+			Currency:      "SEK",
+			Text:          "Faktura 17 januari",
+			Reference:     "E2E-1",
+			Counterparty:  "Kund AB",
+			BankReference: "REF-1",
+			Line:          1,
+		},
+		{
+			BookingDate:  date(2025, time.January, 6),
+			Amount:       -9950,
+			Currency:     "SEK",
+			Text:         "Bankgiro",
+			Reference:    "4711",
+			Counterparty: "Leverantör AB",
+			Line:         3,
+		},
+	}
+	if !reflect.DeepEqual(statement.Transactions, want) {
+		t.Errorf("Transactions = %+v, want %+v", statement.Transactions, want)
+	}
+}
+
+func TestParseCamt053Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+	}{
+		{name: "not XML", file: "<Document><BkToCstmrStmt>"},
+		{name: "no statement", file: "<Document><BkToCstmrDbtCdtNtfctn/></Document>"},
+		{
+			name: "two accounts",
+			file: `<Document><BkToCstmrStmt>
+				<Stmt><Acct><Id><IBAN>SE01</IBAN></Id></Acct></Stmt>
+				<Stmt><Acct><Id><Othr><Id>5555-1234</Id></Othr></Id></Acct></Stmt>
+			</BkToCstmrStmt></Document>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, errs := ParseCamt053([]byte(tt.file)); len(errs) != 1 {
+				t.Errorf("errors = %v, want one", errs)
+			}
+		})
+	}
+}
+
+func TestSigned(t *testing.T) {
+	tests := []struct {
+		value   string
+		sign    string
+		want    domain.Amount
+		wantErr bool
+	}{
+		{value: "12.50", sign: "CRDT", want: 1250},
+		{value: "12.50", sign: "DBIT", want: -1250},
+		{value: "0", sign: "DBIT", want: 0},
+		{value: "12.50", sign: "", wantErr: true},
+		{value: "12,50 kr", sign: "CRDT", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := signed(tt.value, tt.sign)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("signed(%q, %q) = %v, want an error", tt.value, tt.sign, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("signed(%q, %q) = %v, %v, want %v", tt.value, tt.sign, got, err, tt.want)
+		}
+	}
+}
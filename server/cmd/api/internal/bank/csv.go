@@ -0,0 +1,226 @@
+package bank
+
+import (
+	"bytes"
+	"cmd/api/internal/domain"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// csvColumns maps the column names used by the Swedish banks' exports
+// (Swedbank, SEB, Handelsbanken, Nordea, Länsförsäkringar and others) to
+// the field they hold. The first name found in a header wins.
+var csvColumns = map[string][]string{
+	"booking_date": {"bokföringsdag", "bokföringsdatum", "bokförd", "reskontradatum", "datum"},
+	"value_date":   {"valutadag", "valutadatum"},
+	"amount":       {"belopp", "belopp sek", "transaktionsbelopp"},
+	"text":         {"beskrivning", "text", "text/mottagare", "rubrik", "transaktion", "meddelande", "transaktionstyp"},
+	"reference":    {"referens", "verifikationsnummer", "ocr", "ocr-nummer"},
+	"counterparty": {"namn", "motpart"},
+	"receiver":     {"mottagare"},
+	"sender":       {"avsändare"},
+	"currency":     {"valuta"},
+	"bank_ref":     {"radnummer", "transaktionsid", "transaktions-id"},
+}
+
+// csvDelimiters are tried in this order when the header uses several
+var csvDelimiters = []rune{';', ',', '\t'}
+
+// headerSearchLines is how far down the file the header row is looked for;
+// some banks put the account and period above it
+const headerSearchLines = 20
+
+// ParseCSV reads a bank's CSV export. The header row is found by its
+// column names, and the delimiter, the encoding and the amount format
+// (decimal comma, space as thousands separator) are detected.
+func ParseCSV(data []byte) (*Statement, []ParseError) {
+	statement := &Statement{Format: FormatCSV}
+	data = decode(data)
+
+	lines := bytes.Split(data, []byte("\n"))
+	headerLine := -1
+	for i := 0; i < len(lines) && i < headerSearchLines; i++ {
+		lower := strings.ToLower(string(lines[i]))
+		if strings.Contains(lower, "belopp") && containsAny(lower, csvColumns["booking_date"]) {
+			headerLine = i
+			break
+		}
+	}
+	if headerLine < 0 {
+		return statement, []ParseError{{Line: 0, Message: "no header row with a date and a Belopp column was found, is it a bank statement?"}}
+	}
+
+	reader := csv.NewReader(bytes.NewReader(bytes.Join(lines[headerLine:], []byte("\n"))))
+	reader.Comma = detectDelimiter(string(lines[headerLine]))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return statement, []ParseError{{Line: headerLine + 1, Message: fmt.Sprintf("invalid header row: %v", err)}}
+	}
+	columns := mapColumns(header)
+	if _, ok := columns["booking_date"]; !ok {
+		return statement, []ParseError{{Line: headerLine + 1, Message: "the header row has no booking date column"}}
+	}
+	if _, ok := columns["amount"]; !ok {
+		return statement, []ParseError{{Line: headerLine + 1, Message: "the header row has no Belopp column"}}
+	}
+
+	var errs []ParseError
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				errs = append(errs, ParseError{Line: parseErr.Line + headerLine, Message: parseErr.Err.Error()})
+				continue
+			}
+			errs = append(errs, ParseError{Message: err.Error()})
+			break
+		}
+		line, _ := reader.FieldPos(0)
+		line += headerLine
+
+		field := func(name string) string {
+			i, ok := columns[name]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+
+		if strings.Join(record, "") == "" {
+			continue
+		}
+
+		bookingDate, err := parseCSVDate(field("booking_date"))
+		if err != nil {
+			errs = append(errs, ParseError{Line: line, Message: err.Error()})
+			continue
+		}
+		amount, err := parseCSVAmount(field("amount"))
+		if err != nil {
+			errs = append(errs, ParseError{Line: line, Message: err.Error()})
+			continue
+		}
+
+		transaction := Transaction{
+			BookingDate:   bookingDate,
+			Amount:        amount,
+			Currency:      strings.ToUpper(field("currency")),
+			Text:          field("text"),
+			Reference:     field("reference"),
+			Counterparty:  field("counterparty"),
+			BankReference: field("bank_ref"),
+			Line:          line,
+		}
+		if value := field("value_date"); value != "" {
+			valueDate, err := parseCSVDate(value)
+			if err != nil {
+				errs = append(errs, ParseError{Line: line, Message: err.Error()})
+				continue
+			}
+			transaction.ValueDate = &valueDate
+		}
+
+		// Nordea has both a sender and a receiver column; the counterparty
+		// is the one that is not the account itself
+		if transaction.Counterparty == "" {
+			if amount.IsNegative() {
+				transaction.Counterparty = field("receiver")
+			} else {
+				transaction.Counterparty = field("sender")
+			}
+		}
+		if transaction.Text == "" {
+			transaction.Text = transaction.Counterparty
+		}
+
+		statement.Transactions = append(statement.Transactions, transaction)
+	}
+
+	return statement, errs
+}
+
+func containsAny(s string, substrings []string) bool {
+	for _, sub := range substrings {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
+
+// detectDelimiter returns the delimiter that splits the header row into the
+// most columns
+func detectDelimiter(header string) rune {
+	best, bestCount := csvDelimiters[0], 0
+	for _, delimiter := range csvDelimiters {
+		if count := strings.Count(header, string(delimiter)); count > bestCount {
+			best, bestCount = delimiter, count
+		}
+	}
+	return best
+}
+
+// mapColumns returns the index of each known field in header
+func mapColumns(header []string) map[string]int {
+	columns := make(map[string]int)
+	for field, names := range csvColumns {
+		for _, name := range names {
+			for i, column := range header {
+				column = strings.ToLower(strings.Trim(strings.TrimSpace(column), `"`))
+				if column == name {
+					columns[field] = i
+					break
+				}
+			}
+			if _, ok := columns[field]; ok {
+				break
+			}
+		}
+	}
+	return columns
+}
+
+// csvDateLayouts are the date formats seen in the banks' exports
+var csvDateLayouts = []string{"2006-01-02", "20060102", "2006/01/02", "02.01.2006", "02/01/2006"}
+
+func parseCSVDate(s string) (time.Time, error) {
+	for _, layout := range csvDateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", s)
+}
+
+// parseCSVAmount parses amounts such as "-1 234,50", "1.234,50" and
+// "-1234.50 kr"
+func parseCSVAmount(s string) (domain.Amount, error) {
+	cleaned := strings.NewReplacer(" ", "", " ", "", " ", "", "kr", "", "SEK", "", "−", "-").Replace(s)
+
+	// With both separators the first one groups thousands
+	comma, dot := strings.LastIndex(cleaned, ","), strings.LastIndex(cleaned, ".")
+	switch {
+	case comma >= 0 && dot >= 0 && comma > dot:
+		cleaned = strings.ReplaceAll(cleaned, ".", "")
+	case comma >= 0 && dot >= 0:
+		cleaned = strings.ReplaceAll(cleaned, ",", "")
+	}
+
+	amount, err := domain.ParseAmount(cleaned)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	return amount, nil
+}
@@ -0,0 +1,201 @@
+package bank
+
+import (
+	"cmd/api/internal/domain"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestParseCSV(t *testing.T) {
+	// A Swedbank export: Windows-1252, semicolons and the account above the header
+	file := "* Transaktionsrapport 2025-01-01 – 2025-01-31\r\n" +
+		"Radnummer;Clearingnummer;Kontonummer;Produkt;Valuta;Bokföringsdag;Transaktionsdag;Valutadag;Referens;Beskrivning;Belopp;Bokfört saldo\r\n" +
+		"1;8327-9;1234567;Företagskonto;SEK;2025-01-03;2025-01-02;2025-01-03;5555;Kortköp Clas Ohlson;-1 234,50;8 765,50\r\n" +
+		"\r\n" +
+		"2;8327-9;1234567;Företagskonto;SEK;2025-01-05;2025-01-05;2025-01-06;OCR 4711;Insättning Kund AB;12 500,00;21 265,50\r\n"
+	data, err := charmap.Windows1252.NewEncoder().String(file)
+	if err != nil {
+		t.Fatalf("failed to encode test file: %v", err)
+	}
+
+	statement, errs := Parse([]byte(data))
+	if len(errs) > 0 {
+		t.Fatalf("Parse returned errors: %v", errs)
+	}
+	if statement.Format != FormatCSV {
+		t.Errorf("Format = %q, want %q", statement.Format, FormatCSV)
+	}
+
+	valueDate1, valueDate2 := date(2025, time.January, 3), date(2025, time.January, 6)
+	want := []Transaction{
+		{
+			BookingDate:   date(2025, time.January, 3),
+			ValueDate:     &valueDate1,
+			Amount:        -123450,
+			Currency:      "SEK",
+			Text:          "Kortköp Clas Ohlson",
+			Reference:     "5555",
+			BankReference: "1",
+			Line:          3,
+		},
+		{
+			BookingDate:   date(2025, time.January, 5),
+			ValueDate:     &valueDate2,
+			Amount:        1250000,
+			Currency:      "SEK",
+			Text:          "Insättning Kund AB",
+			Reference:     "OCR 4711",
+			BankReference: "2",
+			Line:          5,
+		},
+	}
+	if !reflect.DeepEqual(statement.Transactions, want) {
+		t.Errorf("Transactions = %+v, want %+v", statement.Transactions, want)
+	}
+}
+
+func TestParseCSVSenderAndReceiver(t *testing.T) {
+	// A Nordea export: commas, quoted amounts and no counterparty column
+	file := "Bokföringsdag,Belopp,Avsändare,Mottagare,Rubrik,Saldo,Valuta\n" +
+		"2025/01/10,\"-500,00\",5555 1234,Leverantör AB,Betalning,\"9 500,00\",SEK\n" +
+		"2025/01/11,\"1 000,00\",Kund AB,5555 1234,,\"10 500,00\",SEK\n"
+
+	statement, errs := ParseCSV([]byte(file))
+	if len(errs) > 0 {
+		t.Fatalf("ParseCSV returned errors: %v", errs)
+	}
+	if len(statement.Transactions) != 2 {
+		t.Fatalf("got %d transactions, want 2", len(statement.Transactions))
+	}
+
+	out, in := statement.Transactions[0], statement.Transactions[1]
+	if out.Counterparty != "Leverantör AB" || out.Text != "Betalning" || out.Amount != -50000 {
+		t.Errorf("outgoing = %+v, want Leverantör AB, Betalning, -500.00", out)
+	}
+	if in.Counterparty != "Kund AB" || in.Text != "Kund AB" || in.Amount != 100000 {
+		t.Errorf("incoming = %+v, want Kund AB as counterparty and text, 1000.00", in)
+	}
+}
+
+func TestParseCSVErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		file  string
+		lines []int
+	}{
+		{name: "no header", file: "Datum;Summa\n2025-01-01;100\n", lines: []int{0}},
+		{name: "no amount column", file: "Bokföringsdag;Beloppet\n2025-01-01;100\n", lines: []int{1}},
+		{
+			name:  "bad rows are reported and skipped",
+			file:  "Datum;Text;Belopp\n2025-01-01;Ok;100\n2025-13-01;Datum;100\n2025-01-02;Belopp;12x\n2025-01-03;Ok;200\n",
+			lines: []int{3, 4},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, errs := ParseCSV([]byte(tt.file))
+			lines := make([]int, len(errs))
+			for i, err := range errs {
+				lines[i] = err.Line
+			}
+			if !reflect.DeepEqual(lines, tt.lines) {
+				t.Errorf("errors on lines %v (%v), want %v", lines, errs, tt.lines)
+			}
+		})
+	}
+}
+
+func TestParseCSVAmount(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    domain.Amount
+		wantErr bool
+	}{
+		{in: "100", want: 10000},
+		{in: "12,5", want: 1250},
+		{in: "-1 234,50", want: -123450},
+		{in: "1 234,50", want: 123450},
+		{in: "1.234,50", want: 123450},
+		{in: "1,234.50", want: 123450},
+		{in: "-1234.50 kr", want: -123450},
+		{in: "1 234,50 SEK", want: 123450},
+		{in: "−99,00", want: -9900},
+		{in: "", wantErr: true},
+		{in: "abc", wantErr: true},
+		{in: "1,2,3", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseCSVAmount(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseCSVAmount(%q) = %v, want an error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseCSVAmount(%q) returned error: %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseCSVAmount(%q) = %d öre, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseCSVDate(t *testing.T) {
+	for _, in := range []string{"2025-03-07", "20250307", "2025/03/07", "07.03.2025", "07/03/2025"} {
+		got, err := parseCSVDate(in)
+		if err != nil || !got.Equal(date(2025, time.March, 7)) {
+			t.Errorf("parseCSVDate(%q) = %v, %v, want 2025-03-07", in, got, err)
+		}
+	}
+	if _, err := parseCSVDate("7 mars"); err == nil {
+		t.Error(`parseCSVDate("7 mars") did not return an error`)
+	}
+}
+
+func TestDetectDelimiter(t *testing.T) {
+	tests := []struct {
+		header string
+		want   rune
+	}{
+		{header: "Datum;Text;Belopp", want: ';'},
+		{header: "Datum,Text,Belopp", want: ','},
+		{header: "Datum\tText\tBelopp", want: '\t'},
+		{header: "Datum;Text, kort;Belopp", want: ';'},
+		{header: "Datum,Text;Belopp,Saldo", want: ','},
+		{header: "Belopp", want: ';'},
+	}
+
+	for _, tt := range tests {
+		if got := detectDelimiter(tt.header); got != tt.want {
+			t.Errorf("detectDelimiter(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestMapColumns(t *testing.T) {
+	header := strings.Split(`"Bokföringsdatum";Valutadatum;Text;Belopp SEK;Namn;Mottagare;ocr`, ";")
+	want := map[string]int{
+		"booking_date": 0,
+		"value_date":   1,
+		"text":         2,
+		"amount":       3,
+		"counterparty": 4,
+		"receiver":     5,
+		"reference":    6,
+	}
+	if got := mapColumns(header); !reflect.DeepEqual(got, want) {
+		t.Errorf("mapColumns = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,210 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Amount is a money amount stored as an integer number of öre (1/100 krona).
+// It is exact, so sums and comparisons never suffer from float rounding. In
+// JSON it is a plain number with two decimals (e.g. 1234.50) and in the
+// database it maps to the DECIMAL(15, 2) columns.
+type Amount int64
+
+// NewAmount creates an amount from whole kronor
+func NewAmount(kronor int64) Amount {
+	return Amount(kronor * 100)
+}
+
+// ParseAmount parses a decimal string such as "1234.5", "-12,30" or "100".
+// Digits beyond two decimals are rounded half away from zero.
+func ParseAmount(s string) (Amount, error) {
+	s = strings.TrimSpace(s)
+	s = strings.ReplaceAll(s, " ", "")
+	s = strings.Replace(s, ",", ".", 1)
+	if s == "" {
+		return 0, errors.New("empty amount")
+	}
+
+	negative := false
+	switch s[0] {
+	case '-':
+		negative = true
+		s = s[1:]
+	case '+':
+		s = s[1:]
+	}
+
+	intPart, fracPart, _ := strings.Cut(s, ".")
+	if intPart == "" && fracPart == "" {
+		return 0, fmt.Errorf("invalid amount: %q", s)
+	}
+	if intPart == "" {
+		intPart = "0"
+	}
+	if !isDigits(intPart) || !isDigits(fracPart) {
+		return 0, fmt.Errorf("invalid amount: %q", s)
+	}
+
+	kronor, err := strconv.ParseInt(intPart, 10, 64)
+	if err != nil || kronor > math.MaxInt64/100-1 {
+		return 0, fmt.Errorf("amount out of range: %q", s)
+	}
+
+	// Pad or cut the fraction to öre, rounding on the third decimal
+	roundUp := len(fracPart) > 2 && fracPart[2] >= '5'
+	fracPart = (fracPart + "00")[:2]
+	ore, _ := strconv.ParseInt(fracPart, 10, 64)
+
+	amount := kronor*100 + ore
+	if roundUp {
+		amount++
+	}
+	if negative {
+		amount = -amount
+	}
+
+	return Amount(amount), nil
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Ore returns the amount in öre
+func (a Amount) Ore() int64 {
+	return int64(a)
+}
+
+// Kronor returns the whole kronor of the amount, dropping the öre
+func (a Amount) Kronor() int64 {
+	return int64(a) / 100
+}
+
+func (a Amount) Add(b Amount) Amount {
+	return a + b
+}
+
+func (a Amount) Sub(b Amount) Amount {
+	return a - b
+}
+
+func (a Amount) Neg() Amount {
+	return -a
+}
+
+func (a Amount) Abs() Amount {
+	if a < 0 {
+		return -a
+	}
+	return a
+}
+
+func (a Amount) IsZero() bool {
+	return a == 0
+}
+
+func (a Amount) IsPositive() bool {
+	return a > 0
+}
+
+func (a Amount) IsNegative() bool {
+	return a < 0
+}
+
+// String formats the amount with two decimals, e.g. "-1234.50"
+func (a Amount) String() string {
+	sign := ""
+	ore := int64(a)
+	if ore < 0 {
+		sign = "-"
+		ore = -ore
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, ore/100, ore%100)
+}
+
+// Format formats the amount the Swedish way with thousand separators and
+// decimal comma, e.g. "1 234,50"
+func (a Amount) Format() string {
+	s := a.String()
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign = "-"
+		s = s[1:]
+	}
+	intPart, fracPart, _ := strings.Cut(s, ".")
+
+	var b strings.Builder
+	for i, r := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			b.WriteRune(' ')
+		}
+		b.WriteRune(r)
+	}
+
+	return sign + b.String() + "," + fracPart
+}
+
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+func (a *Amount) UnmarshalJSON(b []byte) error {
+	s := strings.Trim(string(b), "\"")
+	if s == "null" || s == "" {
+		*a = 0
+		return nil
+	}
+
+	parsed, err := ParseAmount(s)
+	if err != nil {
+		return err
+	}
+	*a = parsed
+	return nil
+}
+
+// Scan implements sql.Scanner for DECIMAL and integer columns
+func (a *Amount) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*a = 0
+		return nil
+	case []byte:
+		parsed, err := ParseAmount(string(v))
+		if err != nil {
+			return err
+		}
+		*a = parsed
+		return nil
+	case string:
+		parsed, err := ParseAmount(v)
+		if err != nil {
+			return err
+		}
+		*a = parsed
+		return nil
+	case int64:
+		*a = NewAmount(v)
+		return nil
+	case float64:
+		*a = Amount(math.Round(v * 100))
+		return nil
+	default:
+		return fmt.Errorf("cannot scan %T into Amount", src)
+	}
+}
+
+// Value implements driver.Valuer, sending the amount as an exact decimal
+func (a Amount) Value() (driver.Value, error) {
+	return a.String(), nil
+}
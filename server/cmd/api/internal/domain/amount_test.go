@@ -0,0 +1,154 @@
+package domain
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Amount
+		wantErr bool
+	}{
+		{in: "100", want: 10000},
+		{in: "1234.5", want: 123450},
+		{in: "1234.50", want: 123450},
+		{in: "-12,30", want: -1230},
+		{in: "+7", want: 700},
+		{in: ".5", want: 50},
+		{in: "5.", want: 500},
+		{in: " 1 234,56 ", want: 123456},
+		{in: "0.004", want: 0},
+		{in: "0.005", want: 1},
+		{in: "0.995", want: 100},
+		{in: "-0.125", want: -13},
+		{in: "1.999", want: 200},
+		{in: "", wantErr: true},
+		{in: "-", wantErr: true},
+		{in: ".", wantErr: true},
+		{in: "12a", wantErr: true},
+		{in: "1.2.3", wantErr: true},
+		{in: "1e5", wantErr: true},
+		{in: "99999999999999999999", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseAmount(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseAmount(%q) = %v, want an error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseAmount(%q) returned error: %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseAmount(%q) = %d öre, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestAmountString(t *testing.T) {
+	tests := []struct {
+		amount Amount
+		want   string
+		format string
+	}{
+		{amount: 0, want: "0.00", format: "0,00"},
+		{amount: 5, want: "0.05", format: "0,05"},
+		{amount: -5, want: "-0.05", format: "-0,05"},
+		{amount: 123450, want: "1234.50", format: "1 234,50"},
+		{amount: -123456789, want: "-1234567.89", format: "-1 234 567,89"},
+		{amount: 10000000, want: "100000.00", format: "100 000,00"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.amount.String(); got != tt.want {
+			t.Errorf("Amount(%d).String() = %q, want %q", tt.amount, got, tt.want)
+		}
+		if got := tt.amount.Format(); got != tt.format {
+			t.Errorf("Amount(%d).Format() = %q, want %q", tt.amount, got, tt.format)
+		}
+
+		parsed, err := ParseAmount(tt.amount.String())
+		if err != nil || parsed != tt.amount {
+			t.Errorf("ParseAmount(%q) = %d, %v, want %d", tt.amount.String(), parsed, err, tt.amount)
+		}
+	}
+}
+
+func TestAmountKronor(t *testing.T) {
+	tests := []struct {
+		amount Amount
+		want   int64
+	}{
+		{amount: 0, want: 0},
+		{amount: 199, want: 1},
+		{amount: 12345, want: 123},
+		{amount: -199, want: -1},
+	}
+
+	for _, tt := range tests {
+		if got := tt.amount.Kronor(); got != tt.want {
+			t.Errorf("Amount(%d).Kronor() = %d, want %d", tt.amount, got, tt.want)
+		}
+	}
+}
+
+func TestAmountJSON(t *testing.T) {
+	var v struct {
+		A Amount `json:"a"`
+		B Amount `json:"b"`
+		C Amount `json:"c"`
+		D Amount `json:"d"`
+	}
+	if err := json.Unmarshal([]byte(`{"a": 0.1, "b": "12,50", "c": null, "d": 1e2}`), &v); err == nil {
+		t.Fatalf("exponent notation was accepted: %+v", v)
+	}
+	if err := json.Unmarshal([]byte(`{"a": 0.1, "b": "12,50", "c": null, "d": 100}`), &v); err != nil {
+		t.Fatalf("Unmarshal returned error: %v", err)
+	}
+	if v.A != 10 || v.B != 1250 || v.C != 0 || v.D != 10000 {
+		t.Errorf("Unmarshal = %+v, want {A:10 B:1250 C:0 D:10000}", v)
+	}
+
+	out, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("Marshal returned error: %v", err)
+	}
+	if want := `{"a":0.10,"b":12.50,"c":0.00,"d":100.00}`; string(out) != want {
+		t.Errorf("Marshal = %s, want %s", out, want)
+	}
+}
+
+func TestAmountScan(t *testing.T) {
+	tests := []struct {
+		src  interface{}
+		want Amount
+	}{
+		{src: nil, want: 0},
+		{src: []byte("1234.56"), want: 123456},
+		{src: "-0.10", want: -10},
+		{src: int64(12), want: 1200},
+		{src: 0.1 + 0.2, want: 30},
+	}
+
+	for _, tt := range tests {
+		var a Amount
+		if err := a.Scan(tt.src); err != nil {
+			t.Errorf("Scan(%v) returned error: %v", tt.src, err)
+			continue
+		}
+		if a != tt.want {
+			t.Errorf("Scan(%v) = %d, want %d", tt.src, a, tt.want)
+		}
+	}
+
+	var a Amount
+	if err := a.Scan(true); err == nil {
+		t.Error("Scan(true) did not return an error")
+	}
+}
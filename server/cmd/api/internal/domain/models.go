@@ -45,6 +45,31 @@ type User struct {
     Role        string `json:"role" validate:"omitempty,oneof=Admin Bookkeeper Manager"`        // T.ex. "Admin", "Bookkeeper"
 }
 
+// Company is one set of books. Accounts, vouchers, periods and everything
+// booked on them belong to exactly one company; users can be members of
+// several.
+type Company struct {
+    CompanyID int    `json:"company_id"`                         // Unikt ID
+    Name      string `json:"name" validate:"required,max=255"`   // Företagets namn
+    OrgNr     string `json:"org_nr" validate:"omitempty,max=20"` // Organisationsnummer
+}
+
+// CompanyMember is a user's membership in a company. Role is the user's
+// role in that company only.
+type CompanyMember struct {
+    CompanyID int    `json:"company_id"`
+    UserID    int    `json:"user_id" validate:"required,gt=0"`
+    Name      string `json:"name"`
+    Email     string `json:"email"`
+    Role      string `json:"role" validate:"required,oneof=Admin Bookkeeper Manager"`
+}
+
+// CompanyMembership is a company together with the current user's role in it
+type CompanyMembership struct {
+    Company
+    Role string `json:"role"`
+}
+
 type Account struct {
     AccountNo   int    `json:"account_no"`    // Kontonummer (Primary Key)
     AccountName string `json:"account_name"`  // Kontots namn
@@ -58,42 +83,235 @@ type LineItem struct {
     LineID       int     `json:"line_id"`         // Unikt ID för raden
     VoucherID    int     `json:"voucher_id"`      // Foreign Key till VoucherID
     AccountNo    int     `json:"account_no"`      // Foreign Key till Account
-    DebitAmount  float64 `json:"debit_amount"`    // Belopp i Debet
-    CreditAmount float64 `json:"credit_amount"`   // Belopp i Kredit
+    DebitAmount  Amount  `json:"debit_amount"`    // Belopp i Debet
+    CreditAmount Amount  `json:"credit_amount"`   // Belopp i Kredit
     TaxCode      int     `json:"tax_code"`        // Momskod (t.ex. 25, 12, 6, 0)
-    ProjectID    int     `json:"project_id"`      // Valfri: För projektspårning
-    CostCenterID int     `json:"cost_center_id"`  // Valfri: För kostnadsställe
+    ProjectID    *int    `json:"project_id"`      // Valfri: För projektspårning
+    CostCenterID *int    `json:"cost_center_id"`  // Valfri: För kostnadsställe
 }
 
 type Voucher struct {
     VoucherID            int          `json:"voucher_id"`              // Unikt ID
-    VoucherNumber        int          `json:"voucher_number"`          // Löpnummer för verifikat (1, 2, 3...)
+    Series               string       `json:"series"`                  // Verifikationsserie (t.ex. "A"), A om inget anges
+    VoucherNumber        int          `json:"voucher_number"`          // Löpnummer inom serie och räkenskapsår (1, 2, 3...), 0 för utkast
+    Status               string       `json:"status"`                  // "draft" (utkast) eller "booked" (bokfört)
     Date                 FlexibleDate `json:"date"`                    // Datum då händelsen inträffade
     Description          string       `json:"description"`             // Beskrivning av transaktionen
     Reference            string       `json:"reference"`               // Fakturanummer, kvitto-ID, etc.
-    TotalAmount          float64      `json:"total_amount"`            // Totalbelopp
+    TotalAmount          Amount       `json:"total_amount"`            // Totalbelopp
     Period               string       `json:"period"`                  // Period (t.ex. "2025-01")
     CreatedBy            int          `json:"created_by"`              // Foreign Key till UserID
     CorrectsVoucherID    *int         `json:"corrects_voucher_id"`     // ID för verifikat som detta rättar
     CorrectedByVoucherID *int         `json:"corrected_by_voucher_id"` // ID för verifikat som rättat detta
+    ChainPosition        *int         `json:"chain_position"`          // Plats i hashkedjan, null om bokförd före kedjan
+    Hash                 *string      `json:"hash"`                    // SHA-256 över verifikatet och föregående hash
+    BookedAt             *time.Time   `json:"booked_at"`               // Tidpunkt för bokföringen, null för utkast
     Lines                []LineItem   `json:"lines"`                   // Lista över Verifikatraderna
 }
 
+// Voucher statuses. A draft can be changed and deleted by its creator; a
+// booked voucher has its final number and can only be changed with a
+// correction voucher.
+const (
+    VoucherDraft  = "draft"
+    VoucherBooked = "booked"
+)
+
+// Sort orders of voucher listings
+const (
+    VoucherSortNumber    = "number"     // Räkenskapsår, serie och löpnummer
+    VoucherSortDate      = "date"
+    VoucherSortAmount    = "amount"
+    VoucherSortCreatedAt = "created_at"
+)
+
+// VoucherFilter selects, sorts and pages a voucher listing. Empty fields do
+// not filter.
+type VoucherFilter struct {
+    From      *time.Time // Första dag, inklusive
+    To        *time.Time // Sista dag, inklusive
+    Period    string     // YYYY-MM
+    MinAmount *Amount
+    MaxAmount *Amount
+    AccountNo *int       // Verifikat med minst en rad på kontot
+    CreatedBy *int
+    Corrected *bool      // true: bara rättade, false: bara ej rättade
+    Status    string     // "draft" eller "booked"
+    Text      string     // Fritext i beskrivning eller referens
+    Sort      string     // VoucherSort*, VoucherSortNumber om inget anges
+    Ascending bool
+    Limit     int        // 0 för alla
+    Offset    int
+}
+
+// VoucherPage is one page of a voucher listing
+type VoucherPage struct {
+    Vouchers []*Voucher `json:"vouchers"`
+    Total    int        `json:"total"`  // Antal verifikat som matchar filtret
+    Limit    int        `json:"limit"`
+    Offset   int        `json:"offset"`
+}
+
+// SearchHit is a voucher found by a search, with the parts that matched
+// marked with <mark></mark>
+type SearchHit struct {
+    Voucher  Voucher           `json:"voucher"`
+    Rank     float64           `json:"rank"`     // Högre är bättre
+    Snippets map[string]string `json:"snippets"` // Per fält: "description", "reference", "accounts", "amount"
+}
+
+// SearchResult is the ranked result of a search
+type SearchResult struct {
+    Query string       `json:"query"`
+    Hits  []*SearchHit `json:"hits"`
+}
+
+// Attachment is a receipt, invoice or other document attached to a voucher
+// (underlag). The file is kept in the attachment storage.
+type Attachment struct {
+    AttachmentID int       `json:"attachment_id"`
+    VoucherID    int       `json:"voucher_id"`
+    FileName     string    `json:"file_name"`    // Filnamn som det laddades upp
+    ContentType  string    `json:"content_type"` // MIME-typ, t.ex. "application/pdf"
+    Size         int64     `json:"size"`         // Storlek i byte
+    Checksum     string    `json:"checksum"`     // SHA-256 över filen, hex
+    StorageKey   string    `json:"-"`            // Nyckel i lagringen
+    UploadedBy   int       `json:"uploaded_by"`
+    CreatedAt    time.Time `json:"created_at"`
+}
+
+// Sides of a voucher template line
+const (
+    TemplateSideDebit  = "debit"
+    TemplateSideCredit = "credit"
+)
+
+// VoucherTemplate is a konteringsmall: the account split of a recurring
+// entry such as payroll, rent or the VAT settlement
+type VoucherTemplate struct {
+    TemplateID  int                   `json:"template_id"`
+    Name        string                `json:"name" validate:"required,max=100"`
+    Description string                `json:"description" validate:"max=255"` // Verifikattext
+    Series      string                `json:"series" validate:"max=10"`       // Verifikationsserie, A om tom
+    Active      bool                  `json:"active"`
+    CreatedBy   int                   `json:"created_by"`
+    CreatedAt   time.Time             `json:"created_at"`
+    UpdatedAt   time.Time             `json:"updated_at"`
+    Lines       []VoucherTemplateLine `json:"lines" validate:"required,min=2,dive"`
+}
+
+// VoucherTemplateLine is one line of a template. It has either a fixed
+// Amount or a Percentage of the total the voucher is created with.
+type VoucherTemplateLine struct {
+    LineID       int      `json:"line_id"`
+    AccountNo    int      `json:"account_no" validate:"required,gt=0"`
+    Side         string   `json:"side" validate:"required,oneof=debit credit"`
+    Amount       *Amount  `json:"amount"`                                       // Fast belopp
+    Percentage   *float64 `json:"percentage" validate:"omitempty,gt=0,lte=100"` // Andel av totalbeloppet, två decimaler
+    TaxCode      int      `json:"tax_code" validate:"oneof=0 6 12 25"`          // Momskod
+    ProjectID    *int     `json:"project_id"`
+    CostCenterID *int     `json:"cost_center_id"`
+}
+
+// TemplateVoucherRequest is what a voucher is created from a template with
+type TemplateVoucherRequest struct {
+    Date        FlexibleDate `json:"date"`
+    TotalAmount Amount       `json:"total_amount"` // Krävs om mallen har procentrader
+    Description string       `json:"description"`  // Mallens text om tom
+    Reference   string       `json:"reference"`
+    Status      string       `json:"status"`       // "draft" (standard) eller "booked"
+}
+
+// ManualVoucherSeries is the series vouchers are booked in when none is given
+const ManualVoucherSeries = "A"
+
+// VoucherSeries is a verifikationsserie, e.g. A for manual vouchers and B for
+// customer invoices. Vouchers are numbered 1, 2, 3... within their series and
+// fiscal year.
+type VoucherSeries struct {
+    Series string `json:"series" validate:"required,max=10,alphanum"` // Seriens kod, t.ex. "A"
+    Name   string `json:"name" validate:"required,max=255"`           // Beskrivning, t.ex. "Kundfakturor"
+}
+
+// DefaultVoucherSeries are the series a new company starts with
+var DefaultVoucherSeries = []VoucherSeries{
+    {Series: "A", Name: "Manuella verifikationer"},
+    {Series: "B", Name: "Kundfakturor"},
+    {Series: "C", Name: "Leverantörsfakturor"},
+}
+
+// Period statuses. Open periods accept bookings, locked periods can be
+// reopened by an Admin and closed periods are final.
+const (
+    PeriodOpen   = "open"
+    PeriodLocked = "locked"
+    PeriodClosed = "closed"
+)
+
+type Period struct {
+    Period    string     `json:"period"`     // Period (t.ex. "2025-01")
+    Status    string     `json:"status"`     // "open", "locked" eller "closed"
+    ChangedBy *int       `json:"changed_by"` // Användare som senast ändrade status
+    ChangedAt *time.Time `json:"changed_at"` // När status senast ändrades
+}
+
+// Fiscal year statuses
+const (
+    FiscalYearOpen   = "open"
+    FiscalYearClosed = "closed"
+)
+
+// FiscalYear is a räkenskapsår. It usually follows the calendar year but
+// broken years (e.g. 2024-07-01 - 2025-06-30) are allowed.
+type FiscalYear struct {
+    FiscalYearID     int          `json:"fiscal_year_id"`     // Unikt ID
+    StartDate        FlexibleDate `json:"start_date"`         // Första dagen i räkenskapsåret
+    EndDate          FlexibleDate `json:"end_date"`           // Sista dagen i räkenskapsåret
+    Status           string       `json:"status"`             // "open" eller "closed"
+    ClosingVoucherID *int         `json:"closing_voucher_id"` // Bokslutsverifikat som för resultatet till 2099
+    OpeningVoucherID *int         `json:"opening_voucher_id"` // Ingående balans i nästa räkenskapsår
+    ClosedBy         *int         `json:"closed_by"`          // Användare som gjorde bokslutet
+    ClosedAt         *time.Time   `json:"closed_at"`          // När bokslutet gjordes
+}
+
+// AccountBalance is the net balance (debit - credit) of one account
+type AccountBalance struct {
+    AccountNo   int    `json:"account_no"`   // Account number
+    AccountName string `json:"account_name"` // Account name
+    Type        string `json:"type"`         // "P&L" or "BS"
+    Balance     Amount `json:"balance"`      // Debit - credit
+}
+
 type LedgerEntry struct {
     Date          FlexibleDate `json:"date"`           // Transaction date
     VoucherID     int          `json:"voucher_id"`     // Voucher ID
+    Series        string       `json:"series"`         // Voucher series (A, B, etc.)
     VoucherNumber int          `json:"voucher_number"` // Voucher number (#1, #2, etc.)
     Description   string       `json:"description"`    // Transaction description
     Reference     string       `json:"reference"`      // Invoice/reference number
-    DebitAmount   float64      `json:"debit_amount"`   // Debit amount
-    CreditAmount  float64      `json:"credit_amount"`  // Credit amount
-    Balance       float64      `json:"balance"`        // Running balance (calculated)
+    DebitAmount   Amount       `json:"debit_amount"`   // Debit amount
+    CreditAmount  Amount       `json:"credit_amount"`  // Credit amount
+    Balance       Amount       `json:"balance"`        // Running balance (calculated)
+    ProjectID     *int         `json:"project_id"`     // Project of the line, if any
+    CostCenterID  *int         `json:"cost_center_id"` // Cost centre of the line, if any
+}
+
+// LedgerGroup is the part of a ledger that belongs to one project or cost
+// centre. ID is nil for lines without one.
+type LedgerGroup struct {
+    ID          *int           `json:"id"`
+    Code        string         `json:"code"`
+    Name        string         `json:"name"`
+    Entries     []*LedgerEntry `json:"entries"`      // Running balance restarts in each group
+    TotalDebit  Amount         `json:"total_debit"`
+    TotalCredit Amount         `json:"total_credit"`
+    Balance     Amount         `json:"balance"`
 }
 
 type IncomeStatementEntry struct {
-    AccountNo   int     `json:"account_no"`   // Account number
-    AccountName string  `json:"account_name"` // Account name
-    Balance     float64 `json:"balance"`      // Account balance for period
+    AccountNo   int    `json:"account_no"`   // Account number
+    AccountName string `json:"account_name"` // Account name
+    Balance     Amount `json:"balance"`      // Account balance for period
 }
 
 type IncomeStatement struct {
@@ -103,7 +321,397 @@ type IncomeStatement struct {
     } `json:"period"`
     Income        []IncomeStatementEntry `json:"income"`         // Revenue accounts (3000-3999)
     Expenses      []IncomeStatementEntry `json:"expenses"`       // Expense accounts (4000-8999)
-    TotalIncome   float64                `json:"total_income"`   // Sum of all income
-    TotalExpenses float64                `json:"total_expenses"` // Sum of all expenses
-    NetResult     float64                `json:"net_result"`     // Total income - Total expenses
-}
\ No newline at end of file
+    TotalIncome   Amount                 `json:"total_income"`   // Sum of all income
+    TotalExpenses Amount                 `json:"total_expenses"` // Sum of all expenses
+    NetResult     Amount                 `json:"net_result"`     // Total income - Total expenses
+}
+
+// IncomeStatementGroup is the income statement of one project or cost
+// centre. ID is nil for lines without one.
+type IncomeStatementGroup struct {
+    ID        *int             `json:"id"`
+    Code      string           `json:"code"`
+    Name      string           `json:"name"`
+    Statement *IncomeStatement `json:"statement"`
+}
+
+type BalanceSheetEntry struct {
+    AccountNo   int    `json:"account_no"`   // Account number
+    AccountName string `json:"account_name"` // Account name
+    Balance     Amount `json:"balance"`      // Assets as debit - credit, equity and liabilities as credit - debit
+}
+
+// BalanceSheetGroup is a BAS account group, e.g. 19 Kassa och bank
+type BalanceSheetGroup struct {
+    Group    int                 `json:"group"`    // Two-digit BAS group (10-29)
+    Name     string              `json:"name"`     // Group name
+    Accounts []BalanceSheetEntry `json:"accounts"` // Accounts with a balance
+    Total    Amount              `json:"total"`    // Sum of the accounts
+}
+
+type BalanceSheet struct {
+    Date                      string              `json:"date"`                         // Balance date (YYYY-MM-DD)
+    FiscalYearStart           string              `json:"fiscal_year_start"`            // Start of the fiscal year the result is counted from
+    Assets                    []BalanceSheetGroup `json:"assets"`                       // Tillgångar (1xxx)
+    EquityAndLiabilities      []BalanceSheetGroup `json:"equity_and_liabilities"`       // Eget kapital och skulder (2xxx)
+    RetainedResult            Amount              `json:"retained_result"`              // Balanserat resultat: result of earlier years never closed to 2099 (profit is positive)
+    CurrentYearResult         Amount              `json:"current_year_result"`          // Årets resultat not yet moved to 2099 (profit is positive)
+    TotalAssets               Amount              `json:"total_assets"`                 // Sum of assets
+    TotalEquityAndLiabilities Amount              `json:"total_equity_and_liabilities"` // Sum of equity, liabilities and the retained and current year results
+    Difference                Amount              `json:"difference"`                   // Total assets - total equity and liabilities
+    Balanced                  bool                `json:"balanced"`                     // True when the difference is zero
+}
+
+type TrialBalanceEntry struct {
+    AccountNo      int    `json:"account_no"`      // Account number
+    AccountName    string `json:"account_name"`    // Account name
+    Type           string `json:"type"`            // "P&L" or "BS"
+    OpeningBalance Amount `json:"opening_balance"` // Ingående saldo (debit - credit)
+    PeriodDebit    Amount `json:"period_debit"`    // Debit during the period
+    PeriodCredit   Amount `json:"period_credit"`   // Credit during the period
+    ClosingBalance Amount `json:"closing_balance"` // Utgående saldo (debit - credit)
+}
+
+// TrialBalance is the råbalans / saldobalans for a period. BS accounts open
+// with their balance from the first booking, P&L accounts from the start of
+// the fiscal year; the result of earlier years not closed to 2099 is carried
+// as RetainedResult, so the opening balances still net to zero.
+type TrialBalance struct {
+    Period struct {
+        FromDate string `json:"from_date"` // Start date (YYYY-MM-DD)
+        ToDate   string `json:"to_date"`   // End date (YYYY-MM-DD)
+    } `json:"period"`
+    FiscalYearStart     string              `json:"fiscal_year_start"`     // Start of the fiscal year P&L accounts are counted from
+    Accounts            []TrialBalanceEntry `json:"accounts"`              // Accounts with a balance or activity
+    RetainedResult      Amount              `json:"retained_result"`       // Balanserat resultat: P&L balance (debit - credit) of earlier years never closed to 2099
+    TotalOpeningBalance Amount              `json:"total_opening_balance"` // Sum of opening balances and the retained result
+    TotalDebit          Amount              `json:"total_debit"`           // Sum of period debit
+    TotalCredit         Amount              `json:"total_credit"`          // Sum of period credit
+    TotalClosingBalance Amount              `json:"total_closing_balance"` // Sum of closing balances and the retained result
+    Balanced            bool                `json:"balanced"`              // Debit equals credit and the closing balances net to zero
+}
+// Dimensions that reports can be grouped by
+const (
+    DimensionProject    = "project"
+    DimensionCostCenter = "cost_center"
+)
+
+// Project is a projekt that lines can be booked on. Bookings are only
+// accepted while it is active and within its dates (if set).
+type Project struct {
+    ProjectID int           `json:"project_id"`                           // Unikt ID
+    Code      string        `json:"code" validate:"required,max=20"`      // Kort kod, t.ex. "P100"
+    Name      string        `json:"name" validate:"required,max=100"`     // Projektets namn
+    Active    bool          `json:"active"`                               // Inaktiva projekt tar inte emot bokningar
+    StartDate *FlexibleDate `json:"start_date"`                           // Första dag för bokningar (valfri)
+    EndDate   *FlexibleDate `json:"end_date"`                             // Sista dag för bokningar (valfri)
+}
+
+// CostCenter is a kostnadsställe, with the same rules as a Project
+type CostCenter struct {
+    CostCenterID int           `json:"cost_center_id"`                    // Unikt ID
+    Code         string        `json:"code" validate:"required,max=20"`   // Kort kod, t.ex. "100"
+    Name         string        `json:"name" validate:"required,max=100"`  // Kostnadsställets namn
+    Active       bool          `json:"active"`                            // Inaktiva kostnadsställen tar inte emot bokningar
+    StartDate    *FlexibleDate `json:"start_date"`                        // Första dag för bokningar (valfri)
+    EndDate      *FlexibleDate `json:"end_date"`                          // Sista dag för bokningar (valfri)
+}
+
+// DimensionFilter limits a report to one project and/or cost centre
+type DimensionFilter struct {
+    ProjectID    *int
+    CostCenterID *int
+}
+
+// VATAccountSum is the turnover of one account and tax code in a period
+type VATAccountSum struct {
+    AccountNo int    `json:"account_no"`
+    TaxCode   int    `json:"tax_code"`
+    Debit     Amount `json:"debit"`
+    Credit    Amount `json:"credit"`
+}
+
+// VATBox is one box (ruta) of the momsdeklaration
+type VATBox struct {
+    Box    string `json:"box"`    // Rutans nummer, t.ex. "05"
+    Label  string `json:"label"`  // Rutans text hos Skatteverket
+    Amount Amount `json:"amount"` // Underlag eller moms för perioden
+}
+
+// VATReturn is the momsdeklaration for a month, quarter or year
+type VATReturn struct {
+    Period              string   `json:"period"`                // "2025-01", "2025-Q1" eller "2025"
+    FromDate            string   `json:"from_date"`             // Första dagen (YYYY-MM-DD)
+    ToDate              string   `json:"to_date"`               // Sista dagen (YYYY-MM-DD)
+    Boxes               []VATBox `json:"boxes"`                 // Ruta 05-62 i blankettens ordning
+    VATToPay            Amount   `json:"vat_to_pay"`            // Ruta 49 i hela kronor, positiv = att betala, negativ = att få tillbaka
+    SettlementVoucherID *int     `json:"settlement_voucher_id"` // Momsavräkningen om den är bokförd
+}
+
+// VATSettlement records the voucher that moved a period's VAT to 2650
+type VATSettlement struct {
+    VATSettlementID int          `json:"vat_settlement_id"`
+    Period          string       `json:"period"`
+    FromDate        FlexibleDate `json:"from_date"`
+    ToDate          FlexibleDate `json:"to_date"`
+    VoucherID       int          `json:"voucher_id"`
+    BookedBy        *int         `json:"booked_by"`
+    BookedAt        *time.Time   `json:"booked_at"`
+}
+
+// SIEImportError is a problem in an imported SIE file
+type SIEImportError struct {
+    Line    int    `json:"line"`    // Row in the file (1-based)
+    Message string `json:"message"` // What is wrong
+}
+
+// SIEImportResult describes what an import created, or with dry run what
+// it would create. Nothing is imported when Errors is not empty.
+type SIEImportResult struct {
+    DryRun             bool             `json:"dry_run"`
+    AccountsCreated    []Account        `json:"accounts_created"`     // Accounts missing from the chart of accounts
+    ProjectsCreated    []Project        `json:"projects_created"`     // #OBJEKT in dimension 6 that did not exist
+    CostCentersCreated []CostCenter     `json:"cost_centers_created"` // #OBJEKT in dimension 1 that did not exist
+    Vouchers           []Voucher        `json:"vouchers"`             // Imported vouchers with their lines
+    Errors             []SIEImportError `json:"errors"`
+    Warnings           []SIEImportError `json:"warnings"`             // Data that was left out, e.g. unknown dimensions
+}
+
+// Bank transaction match methods
+const (
+    BankMatchAuto   = "auto"   // Matchad av den automatiska matchningen
+    BankMatchManual = "manual" // Matchad för hand
+)
+
+// BankTransaction is a booked row of an imported bank statement
+// (bankhändelse). It is reconciled by matching it to the ledger line on the
+// bank account that books the same payment.
+type BankTransaction struct {
+    TransactionID int        `json:"transaction_id"`
+    AccountNo     int        `json:"account_no"`     // Bankkontot i bokföringen, t.ex. 1930
+    BookingDate   time.Time  `json:"booking_date"`   // Bokföringsdag hos banken
+    ValueDate     *time.Time `json:"value_date"`     // Valutadag, om filen har den
+    Amount        Amount     `json:"amount"`         // Insättningar positiva, uttag negativa
+    Currency      string     `json:"currency"`
+    Text          string     `json:"text"`
+    Reference     string     `json:"reference"`      // OCR, fakturanummer eller end-to-end-referens
+    Counterparty  string     `json:"counterparty"`
+    BankReference string     `json:"bank_reference"` // Bankens ID för raden
+    Fingerprint   string     `json:"-"`              // Känner igen raden vid ny import
+    MatchedLineID *int       `json:"matched_line_id"`
+    MatchMethod   string     `json:"match_method"`   // "auto", "manual" eller tom
+    MatchedBy     *int       `json:"matched_by"`
+    MatchedAt     *time.Time `json:"matched_at"`
+    ImportedBy    int        `json:"imported_by"`
+    CreatedAt     time.Time  `json:"created_at"`
+}
+
+// BankTransactionFilter limits bank transactions to an account, a date
+// range and whether they are matched. Empty fields do not filter.
+type BankTransactionFilter struct {
+    AccountNo *int
+    From      *time.Time // Första bokföringsdag, inklusive
+    To        *time.Time // Sista bokföringsdag, inklusive
+    Matched   *bool
+}
+
+// BankLedgerLine is a line on a bank account in the books, as seen from the
+// bank: Amount is debit minus credit, so money in is positive
+type BankLedgerLine struct {
+    LineID        int       `json:"line_id"`
+    VoucherID     int       `json:"voucher_id"`
+    Series        string    `json:"series"`
+    VoucherNumber int       `json:"voucher_number"`
+    Date          time.Time `json:"date"`
+    Description   string    `json:"description"`
+    Reference     string    `json:"reference"`
+    Amount        Amount    `json:"amount"`
+}
+
+// BankMatchSuggestion is a ledger line that may book a bank transaction.
+// Higher scores are better; Reasons says what agreed.
+type BankMatchSuggestion struct {
+    Line    BankLedgerLine `json:"line"`
+    Score   int            `json:"score"`
+    Reasons []string       `json:"reasons"` // T.ex. "amount", "date", "reference", "text"
+}
+
+// BankImportError is a problem in an imported bank statement
+type BankImportError struct {
+    Line    int    `json:"line"`    // Rad i CSV-filen eller post i camt.053 (1-baserad)
+    Message string `json:"message"`
+}
+
+// BankImportResult describes what a bank statement import added, or with
+// dry run what it would add. Nothing is imported when Errors is not empty.
+type BankImportResult struct {
+    DryRun       bool               `json:"dry_run"`
+    Format       string             `json:"format"`       // "camt.053" eller "csv"
+    AccountNo    int                `json:"account_no"`
+    Imported     int                `json:"imported"`     // Nya transaktioner
+    Duplicates   int                `json:"duplicates"`   // Redan importerade, hoppades över
+    Matched      int                `json:"matched"`      // Automatiskt matchade mot bokföringen
+    Transactions []*BankTransaction `json:"transactions"` // De nya transaktionerna
+    Errors       []BankImportError  `json:"errors"`
+}
+
+// BankReconciliation compares a bank account with the bank's statement
+// over a date range and lists what is only on one side
+type BankReconciliation struct {
+    AccountNo       int                `json:"account_no"`
+    From            time.Time          `json:"from"`
+    To              time.Time          `json:"to"`
+    BankTotal       Amount             `json:"bank_total"`   // Summa av bankens transaktioner
+    LedgerTotal     Amount             `json:"ledger_total"` // Summa av kontots rader i bokföringen
+    Difference      Amount             `json:"difference"`   // BankTotal - LedgerTotal
+    Matched         int                `json:"matched"`      // Antal matchade par
+    UnmatchedBank   []*BankTransaction `json:"unmatched_bank"`   // Finns hos banken men inte i bokföringen
+    UnmatchedLedger []*BankLedgerLine  `json:"unmatched_ledger"` // Finns i bokföringen men inte hos banken
+}
+
+// Directions of a bank rule
+const (
+    BankDirectionIn  = "in"  // Insättningar
+    BankDirectionOut = "out" // Uttag
+)
+
+// BankRule books recurring bank transactions (hyra, telefon, bankavgifter).
+// A transaction matches when every condition that is set holds; it is then
+// booked as a draft between the bank account and CounterAccountNo.
+type BankRule struct {
+    RuleID              int       `json:"rule_id"`
+    Name                string    `json:"name" validate:"required,max=100"`
+    Priority            int       `json:"priority"`                                // Lägre prövas först
+    AccountNo           *int      `json:"account_no"`                              // Bankkonto regeln gäller, null för alla
+    CounterpartyPattern string    `json:"counterparty_pattern" validate:"max=255"` // Reguljärt uttryck mot motparten
+    TextPattern         string    `json:"text_pattern" validate:"max=255"`         // Reguljärt uttryck mot banktexten
+    Direction           string    `json:"direction" validate:"omitempty,oneof=in out"`
+    MinAmount           *Amount   `json:"min_amount"`                                    // Lägsta belopp utan tecken, inklusive
+    MaxAmount           *Amount   `json:"max_amount"`                                    // Högsta belopp utan tecken, inklusive
+    Description         string    `json:"description" validate:"max=255"`                // Verifikattext, banktexten om tom
+    CounterAccountNo    int       `json:"counter_account_no" validate:"required,gt=0"`   // Motkonto, t.ex. 5010 Lokalhyra
+    TaxCode             *int      `json:"tax_code" validate:"omitempty,oneof=0 6 12 25"` // Momssats, null för kontots
+    ProjectID           *int      `json:"project_id"`
+    CostCenterID        *int      `json:"cost_center_id"`
+    Series              string    `json:"series" validate:"max=10"` // Verifikationsserie, A om tom
+    Active              bool      `json:"active"`
+    CreatedBy           int       `json:"created_by"`
+    CreatedAt           time.Time `json:"created_at"`
+    UpdatedAt           time.Time `json:"updated_at"`
+}
+
+// BankRuleApplication records the draft voucher a rule produced from a bank
+// transaction
+type BankRuleApplication struct {
+    ApplicationID int       `json:"application_id"`
+    RuleID        int       `json:"rule_id"`
+    TransactionID int       `json:"transaction_id"`
+    VoucherID     int       `json:"voucher_id"`
+    CreatedBy     int       `json:"created_by"`
+    CreatedAt     time.Time `json:"created_at"`
+}
+
+// BankRuleProposal is the draft a rule books, or with a preview would book,
+// for a bank transaction. Error says why it could not be booked.
+type BankRuleProposal struct {
+    Transaction *BankTransaction `json:"transaction"`
+    RuleID      int              `json:"rule_id"`
+    RuleName    string           `json:"rule_name"`
+    Voucher     *Voucher         `json:"voucher"`
+    Error       string           `json:"error,omitempty"`
+}
+
+// BankRuleResult is the outcome of running the rules over the unbooked
+// transactions of a bank account
+type BankRuleResult struct {
+    DryRun    bool                `json:"dry_run"`
+    Proposals []*BankRuleProposal `json:"proposals"`
+    Created   int                 `json:"created"`   // Skapade utkast
+    Unmatched int                 `json:"unmatched"` // Transaktioner utan matchande regel
+}
+
+// Actions in the audit trail
+const (
+    AuditCreate = "create"
+    AuditUpdate = "update"
+    AuditDelete = "delete"
+)
+
+// Entities in the audit trail
+const (
+    AuditEntityAccount         = "account"
+    AuditEntityAttachment      = "attachment"
+    AuditEntityBankRule        = "bank_rule"
+    AuditEntityBankTransaction = "bank_transaction"
+    AuditEntityCompany         = "company"
+    AuditEntityCompanyMember   = "company_member"
+    AuditEntityCostCenter      = "cost_center"
+    AuditEntityFiscalYear      = "fiscal_year"
+    AuditEntityLineItem        = "line_item"
+    AuditEntityPeriod          = "period"
+    AuditEntityProject         = "project"
+    AuditEntityUser            = "user"
+    AuditEntityVATSettlement   = "vat_settlement"
+    AuditEntityVoucher         = "voucher"
+    AuditEntityVoucherSeries   = "voucher_series"
+    AuditEntityVoucherTemplate = "voucher_template"
+)
+
+// AuditEntry is one change in the audit trail (behandlingshistorik). Entries
+// are written in the same transaction as the change and never altered.
+type AuditEntry struct {
+    AuditID   int64           `json:"audit_id"`
+    UserID    int             `json:"user_id"`    // Användare som gjorde ändringen
+    Entity    string          `json:"entity"`     // T.ex. "voucher", "account"
+    EntityID  string          `json:"entity_id"`  // Verifikat-ID, kontonummer, period etc.
+    Action    string          `json:"action"`     // "create", "update" eller "delete"
+    Before    json.RawMessage `json:"before"`     // Tillstånd före ändringen, null vid create
+    After     json.RawMessage `json:"after"`      // Tillstånd efter ändringen, null vid delete
+    CreatedAt time.Time       `json:"created_at"`
+}
+
+// AuditFilter limits the audit trail to an entity, a user and a date range.
+// Empty fields do not filter.
+type AuditFilter struct {
+    Entity   string
+    EntityID string
+    UserID   *int
+    From     *time.Time // Första dag, inklusive
+    To       *time.Time // Sista dag, inklusive
+}
+
+// ChainHead is the last voucher in a company's hash chain. The first voucher
+// booked links to position 0 with an empty hash.
+type ChainHead struct {
+    Position int    `json:"position"`
+    Hash     string `json:"hash"`
+}
+
+// ChainLink is a voucher as stored in the hash chain, with the lines the
+// hash is computed over
+type ChainLink struct {
+    Voucher  Voucher `json:"voucher"`
+    PrevHash string  `json:"prev_hash"`
+}
+
+// ChainBreak is the first link where the stored chain does not match the
+// recomputed one
+type ChainBreak struct {
+    Position      int    `json:"position"`
+    VoucherID     int    `json:"voucher_id"`
+    Series        string `json:"series"`
+    VoucherNumber int    `json:"voucher_number"`
+    Reason        string `json:"reason"`
+    ExpectedHash  string `json:"expected_hash"`
+    StoredHash    string `json:"stored_hash"`
+}
+
+// ChainVerification is the result of recomputing a company's hash chain
+type ChainVerification struct {
+    Valid           bool        `json:"valid"`
+    VouchersChecked int         `json:"vouchers_checked"`
+    Unchained       int         `json:"unchained"`   // Verifikat bokförda innan kedjan infördes
+    HeadPosition    int         `json:"head_position"`
+    HeadHash        string      `json:"head_hash"`
+    BrokenLink      *ChainBreak `json:"broken_link"` // Första brutna länken, null om kedjan är hel
+}
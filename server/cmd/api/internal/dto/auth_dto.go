@@ -9,8 +9,13 @@ type RegisterRequest struct {
 }
 
 type LoginRequest struct {
-	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required"`
+	Email     string `json:"email" binding:"required,email"`
+	Password  string `json:"password" binding:"required"`
+	CompanyID int    `json:"company_id"` // Optional; defaults to the user's first company
+}
+
+type SwitchCompanyRequest struct {
+	CompanyID int `json:"company_id" binding:"required,gt=0"`
 }
 
 type AuthResponse struct {
@@ -0,0 +1,216 @@
+// Package eskd writes the momsdeklaration as an eSKD file, the XML format
+// Skatteverket accepts for upload in its e-service (eSKDUpload version 6.0).
+package eskd
+
+import (
+	"bytes"
+	"cmd/api/internal/domain"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+const (
+	version = "6.0"
+	doctype = `<!DOCTYPE eSKDUpload PUBLIC "-//Skatteverket, Sweden//DTD Skatteverket eSKDUpload-DTD Version 6.0//SV" "https://www1.skatteverket.se/demoeskd/eSKDUpload_6p0.dtd">`
+)
+
+// Upload is the eSKDUpload root element
+type Upload struct {
+	XMLName xml.Name `xml:"eSKDUpload"`
+	Version string   `xml:"Version,attr"`
+	OrgNr   string   `xml:"OrgNr"`
+	Moms    Moms     `xml:"Moms"`
+}
+
+// Moms holds the boxes in whole kronor. The fields are in the order the DTD
+// requires; boxes that are zero are left out.
+type Moms struct {
+	Period                string `xml:"Period"`
+	ForsMomsEjAnnan       *int64 `xml:"ForsMomsEjAnnan,omitempty"`       // 05
+	UttagMoms             *int64 `xml:"UttagMoms,omitempty"`             // 06
+	UlagMargbesk          *int64 `xml:"UlagMargbesk,omitempty"`          // 07
+	HyrinkomstFriv        *int64 `xml:"HyrinkomstFriv,omitempty"`        // 08
+	InkopVaruAnnatEg      *int64 `xml:"InkopVaruAnnatEg,omitempty"`      // 20
+	InkopTjanstAnnatEg    *int64 `xml:"InkopTjanstAnnatEg,omitempty"`    // 21
+	InkopTjanstUtomEg     *int64 `xml:"InkopTjanstUtomEg,omitempty"`     // 22
+	InkopVaruSverige      *int64 `xml:"InkopVaruSverige,omitempty"`      // 23
+	InkopTjanstSverige    *int64 `xml:"InkopTjanstSverige,omitempty"`    // 24
+	MomsUlagImport        *int64 `xml:"MomsUlagImport,omitempty"`        // 50
+	ForsVaruAnnatEg       *int64 `xml:"ForsVaruAnnatEg,omitempty"`       // 35
+	ForsVaruUtomEg        *int64 `xml:"ForsVaruUtomEg,omitempty"`        // 36
+	InkopVaruMellan3p     *int64 `xml:"InkopVaruMellan3p,omitempty"`     // 37
+	ForsVaruMellan3p      *int64 `xml:"ForsVaruMellan3p,omitempty"`      // 38
+	ForsTjSkskAnnatEg     *int64 `xml:"ForsTjSkskAnnatEg,omitempty"`     // 39
+	ForsTjOvrUtomEg       *int64 `xml:"ForsTjOvrUtomEg,omitempty"`       // 40
+	ForsKopareSkskSverige *int64 `xml:"ForsKopareSkskSverige,omitempty"` // 41
+	ForsOvrigt            *int64 `xml:"ForsOvrigt,omitempty"`            // 42
+	MomsUtgHog            *int64 `xml:"MomsUtgHog,omitempty"`            // 10
+	MomsUtgMedel          *int64 `xml:"MomsUtgMedel,omitempty"`          // 11
+	MomsUtgLag            *int64 `xml:"MomsUtgLag,omitempty"`            // 12
+	MomsInkopUtgHog       *int64 `xml:"MomsInkopUtgHog,omitempty"`       // 30
+	MomsInkopUtgMedel     *int64 `xml:"MomsInkopUtgMedel,omitempty"`     // 31
+	MomsInkopUtgLag       *int64 `xml:"MomsInkopUtgLag,omitempty"`       // 32
+	MomsImportUtgHog      *int64 `xml:"MomsImportUtgHog,omitempty"`      // 60
+	MomsImportUtgMedel    *int64 `xml:"MomsImportUtgMedel,omitempty"`    // 61
+	MomsImportUtgLag      *int64 `xml:"MomsImportUtgLag,omitempty"`      // 62
+	MomsIngAvdr           *int64 `xml:"MomsIngAvdr,omitempty"`           // 48
+	MomsBetala            int64  `xml:"MomsBetala"`                      // 49
+}
+
+// boxFields maps a box number to its element
+func (m *Moms) boxFields() map[string]**int64 {
+	return map[string]**int64{
+		"05": &m.ForsMomsEjAnnan,
+		"06": &m.UttagMoms,
+		"07": &m.UlagMargbesk,
+		"08": &m.HyrinkomstFriv,
+		"20": &m.InkopVaruAnnatEg,
+		"21": &m.InkopTjanstAnnatEg,
+		"22": &m.InkopTjanstUtomEg,
+		"23": &m.InkopVaruSverige,
+		"24": &m.InkopTjanstSverige,
+		"50": &m.MomsUlagImport,
+		"35": &m.ForsVaruAnnatEg,
+		"36": &m.ForsVaruUtomEg,
+		"37": &m.InkopVaruMellan3p,
+		"38": &m.ForsVaruMellan3p,
+		"39": &m.ForsTjSkskAnnatEg,
+		"40": &m.ForsTjOvrUtomEg,
+		"41": &m.ForsKopareSkskSverige,
+		"42": &m.ForsOvrigt,
+		"10": &m.MomsUtgHog,
+		"11": &m.MomsUtgMedel,
+		"12": &m.MomsUtgLag,
+		"30": &m.MomsInkopUtgHog,
+		"31": &m.MomsInkopUtgMedel,
+		"32": &m.MomsInkopUtgLag,
+		"60": &m.MomsImportUtgHog,
+		"61": &m.MomsImportUtgMedel,
+		"62": &m.MomsImportUtgLag,
+		"48": &m.MomsIngAvdr,
+	}
+}
+
+// NewUpload builds the eSKD document for a VAT return. The period in the
+// file is the last month of the return, as Skatteverket expects for
+// quarterly and yearly returns.
+func NewUpload(orgNr string, vatReturn *domain.VATReturn) (*Upload, error) {
+	to, err := time.Parse("2006-01-02", vatReturn.ToDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid VAT return end date: %w", err)
+	}
+
+	upload := &Upload{
+		Version: version,
+		OrgNr:   normalizeOrgNr(orgNr),
+		Moms:    Moms{Period: to.Format("200601")},
+	}
+
+	fields := upload.Moms.boxFields()
+	for _, box := range vatReturn.Boxes {
+		kronor := box.Amount.Kronor()
+		if box.Box == "49" {
+			upload.Moms.MomsBetala = kronor
+			continue
+		}
+		field, ok := fields[box.Box]
+		if !ok {
+			return nil, fmt.Errorf("unknown VAT box %s", box.Box)
+		}
+		if kronor != 0 {
+			*field = &kronor
+		}
+	}
+
+	if err := upload.Validate(); err != nil {
+		return nil, err
+	}
+
+	return upload, nil
+}
+
+// Validate checks the rules of the eSKD format that Skatteverket rejects
+// files for: the organisation number, the period and that box 49 matches
+// the VAT boxes less box 48.
+func (u *Upload) Validate() error {
+	if u.Version != version {
+		return fmt.Errorf("eSKD version must be %s", version)
+	}
+
+	if len(u.OrgNr) != 12 || !isDigits(u.OrgNr) {
+		return errors.New("organisation number must be 10 or 12 digits")
+	}
+
+	if _, err := time.Parse("200601", u.Moms.Period); err != nil || len(u.Moms.Period) != 6 {
+		return errors.New("period must be in format YYYYMM")
+	}
+
+	m := &u.Moms
+	var toPay int64
+	for _, v := range []*int64{
+		m.MomsUtgHog, m.MomsUtgMedel, m.MomsUtgLag,
+		m.MomsInkopUtgHog, m.MomsInkopUtgMedel, m.MomsInkopUtgLag,
+		m.MomsImportUtgHog, m.MomsImportUtgMedel, m.MomsImportUtgLag,
+	} {
+		toPay += value(v)
+	}
+	toPay -= value(m.MomsIngAvdr)
+	if toPay != m.MomsBetala {
+		return fmt.Errorf("MomsBetala is %d but the VAT boxes less box 48 give %d", m.MomsBetala, toPay)
+	}
+
+	return nil
+}
+
+// Marshal returns the document as ISO-8859-1 encoded XML with the DTD
+// declaration
+func (u *Upload) Marshal() ([]byte, error) {
+	body, err := xml.MarshalIndent(u, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal eSKD: %w", err)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(`<?xml version="1.0" encoding="ISO-8859-1"?>` + "\n")
+	buf.WriteString(doctype + "\n")
+	buf.Write(body)
+	buf.WriteString("\n")
+
+	out, err := charmap.ISO8859_1.NewEncoder().Bytes(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode eSKD: %w", err)
+	}
+
+	return out, nil
+}
+
+// normalizeOrgNr turns "556000-0000" into the 12 digit form "165560000000"
+// used in eSKD files. Organisations get the prefix 16.
+func normalizeOrgNr(orgNr string) string {
+	orgNr = strings.NewReplacer("-", "", " ", "").Replace(orgNr)
+	if len(orgNr) == 10 {
+		orgNr = "16" + orgNr
+	}
+	return orgNr
+}
+
+func value(v *int64) int64 {
+	if v == nil {
+		return 0
+	}
+	return *v
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,288 @@
+package eskd
+
+import (
+	"bytes"
+	"cmd/api/internal/domain"
+	"encoding/xml"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// momsOrder is the content model of Moms in eSKDUpload_6p0.dtd. Every
+// element but Period and MomsBetala is optional, but the order is fixed.
+var momsOrder = []string{
+	"Period",
+	"ForsMomsEjAnnan", "UttagMoms", "UlagMargbesk", "HyrinkomstFriv",
+	"InkopVaruAnnatEg", "InkopTjanstAnnatEg", "InkopTjanstUtomEg", "InkopVaruSverige", "InkopTjanstSverige",
+	"MomsUlagImport",
+	"ForsVaruAnnatEg", "ForsVaruUtomEg", "InkopVaruMellan3p", "ForsVaruMellan3p",
+	"ForsTjSkskAnnatEg", "ForsTjOvrUtomEg", "ForsKopareSkskSverige", "ForsOvrigt",
+	"MomsUtgHog", "MomsUtgMedel", "MomsUtgLag",
+	"MomsInkopUtgHog", "MomsInkopUtgMedel", "MomsInkopUtgLag",
+	"MomsImportUtgHog", "MomsImportUtgMedel", "MomsImportUtgLag",
+	"MomsIngAvdr",
+	"MomsBetala",
+}
+
+// boxElements maps the boxes of the momsdeklaration to their elements
+var boxElements = []struct {
+	box     string
+	element string
+}{
+	{"05", "ForsMomsEjAnnan"}, {"06", "UttagMoms"}, {"07", "UlagMargbesk"}, {"08", "HyrinkomstFriv"},
+	{"20", "InkopVaruAnnatEg"}, {"21", "InkopTjanstAnnatEg"}, {"22", "InkopTjanstUtomEg"},
+	{"23", "InkopVaruSverige"}, {"24", "InkopTjanstSverige"}, {"50", "MomsUlagImport"},
+	{"35", "ForsVaruAnnatEg"}, {"36", "ForsVaruUtomEg"}, {"37", "InkopVaruMellan3p"}, {"38", "ForsVaruMellan3p"},
+	{"39", "ForsTjSkskAnnatEg"}, {"40", "ForsTjOvrUtomEg"}, {"41", "ForsKopareSkskSverige"}, {"42", "ForsOvrigt"},
+	{"10", "MomsUtgHog"}, {"11", "MomsUtgMedel"}, {"12", "MomsUtgLag"},
+	{"30", "MomsInkopUtgHog"}, {"31", "MomsInkopUtgMedel"}, {"32", "MomsInkopUtgLag"},
+	{"60", "MomsImportUtgHog"}, {"61", "MomsImportUtgMedel"}, {"62", "MomsImportUtgLag"},
+	{"48", "MomsIngAvdr"}, {"49", "MomsBetala"},
+}
+
+// element is a decoded element with its children, enough to check the
+// structure the DTD requires
+type element struct {
+	Name     string
+	Attrs    map[string]string
+	Text     string
+	Children []*element
+}
+
+func parseDocument(t *testing.T, data []byte) (directives []string, root *element) {
+	t.Helper()
+
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) {
+		if !strings.EqualFold(charset, "ISO-8859-1") {
+			t.Fatalf("unexpected charset %q", charset)
+		}
+		return charmap.ISO8859_1.NewDecoder().Reader(input), nil
+	}
+
+	var stack []*element
+	for {
+		tok, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("invalid XML: %v", err)
+		}
+		switch tok := tok.(type) {
+		case xml.Directive:
+			directives = append(directives, string(tok))
+		case xml.StartElement:
+			el := &element{Name: tok.Name.Local, Attrs: map[string]string{}}
+			for _, attr := range tok.Attr {
+				el.Attrs[attr.Name.Local] = attr.Value
+			}
+			if len(stack) == 0 {
+				root = el
+			} else {
+				parent := stack[len(stack)-1]
+				parent.Children = append(parent.Children, el)
+			}
+			stack = append(stack, el)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].Text += strings.TrimSpace(string(tok))
+			}
+		}
+	}
+	if root == nil {
+		t.Fatal("document has no root element")
+	}
+	return directives, root
+}
+
+func TestMarshalStructure(t *testing.T) {
+	// Every box filled in, so every optional element is written
+	vatReturn := &domain.VATReturn{Period: "2025-Q1", FromDate: "2025-01-01", ToDate: "2025-03-31"}
+	var toPay int64
+	for i, b := range boxElements {
+		if b.box == "49" {
+			continue
+		}
+		kronor := int64(i+1) * 100
+		switch b.box {
+		case "10", "11", "12", "30", "31", "32", "60", "61", "62":
+			toPay += kronor
+		case "48":
+			toPay -= kronor
+		}
+		vatReturn.Boxes = append(vatReturn.Boxes, domain.VATBox{Box: b.box, Amount: domain.NewAmount(kronor)})
+	}
+	vatReturn.Boxes = append(vatReturn.Boxes, domain.VATBox{Box: "49", Amount: domain.NewAmount(toPay)})
+
+	upload, err := NewUpload("556000-0000", vatReturn)
+	if err != nil {
+		t.Fatalf("NewUpload returned error: %v", err)
+	}
+	data, err := upload.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal returned error: %v", err)
+	}
+
+	if prolog := `<?xml version="1.0" encoding="ISO-8859-1"?>` + "\n"; !bytes.HasPrefix(data, []byte(prolog)) {
+		t.Errorf("document does not start with %q", prolog)
+	}
+
+	directives, root := parseDocument(t, data)
+	wantDoctype := `DOCTYPE eSKDUpload PUBLIC "-//Skatteverket, Sweden//DTD Skatteverket eSKDUpload-DTD Version 6.0//SV" "https://www1.skatteverket.se/demoeskd/eSKDUpload_6p0.dtd"`
+	if len(directives) != 1 || directives[0] != wantDoctype {
+		t.Errorf("directives = %q, want [%q]", directives, wantDoctype)
+	}
+
+	if root.Name != "eSKDUpload" || root.Attrs["Version"] != "6.0" {
+		t.Errorf("root = %s Version=%q, want eSKDUpload Version=6.0", root.Name, root.Attrs["Version"])
+	}
+	if len(root.Children) != 2 || root.Children[0].Name != "OrgNr" || root.Children[1].Name != "Moms" {
+		t.Fatalf("eSKDUpload must contain OrgNr and Moms in that order")
+	}
+	if orgNr := root.Children[0].Text; orgNr != "165560000000" {
+		t.Errorf("OrgNr = %q, want 165560000000", orgNr)
+	}
+
+	moms := root.Children[1]
+	var names []string
+	values := make(map[string]string)
+	for _, child := range moms.Children {
+		names = append(names, child.Name)
+		values[child.Name] = child.Text
+	}
+	if !reflect.DeepEqual(names, momsOrder) {
+		t.Errorf("Moms children = %v, want %v", names, momsOrder)
+	}
+	if values["Period"] != "202503" {
+		t.Errorf("Period = %q, want 202503", values["Period"])
+	}
+	for _, box := range vatReturn.Boxes {
+		for _, b := range boxElements {
+			if b.box != box.Box {
+				continue
+			}
+			if want := strconv.FormatInt(box.Amount.Kronor(), 10); values[b.element] != want {
+				t.Errorf("box %s: %s = %q, want %s", b.box, b.element, values[b.element], want)
+			}
+		}
+	}
+}
+
+func TestMarshalOmitsZeroBoxes(t *testing.T) {
+	vatReturn := &domain.VATReturn{
+		ToDate: "2025-02-28",
+		Boxes: []domain.VATBox{
+			{Box: "05", Amount: domain.NewAmount(1000)},
+			{Box: "06", Amount: 0},
+			{Box: "10", Amount: domain.NewAmount(250)},
+			{Box: "48", Amount: 0},
+			{Box: "49", Amount: domain.NewAmount(250)},
+		},
+	}
+
+	upload, err := NewUpload("5560000000", vatReturn)
+	if err != nil {
+		t.Fatalf("NewUpload returned error: %v", err)
+	}
+	data, err := upload.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal returned error: %v", err)
+	}
+
+	_, root := parseDocument(t, data)
+	var names []string
+	for _, child := range root.Children[1].Children {
+		names = append(names, child.Name)
+	}
+	if want := []string{"Period", "ForsMomsEjAnnan", "MomsUtgHog", "MomsBetala"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("Moms children = %v, want %v", names, want)
+	}
+
+	// MomsBetala is required even when nothing is to be paid
+	upload, err = NewUpload("5560000000", &domain.VATReturn{ToDate: "2025-02-28"})
+	if err != nil {
+		t.Fatalf("NewUpload returned error: %v", err)
+	}
+	data, err = upload.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal returned error: %v", err)
+	}
+	if !bytes.Contains(data, []byte("<MomsBetala>0</MomsBetala>")) {
+		t.Errorf("empty return has no MomsBetala:\n%s", data)
+	}
+}
+
+func TestNewUploadErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		orgNr     string
+		vatReturn domain.VATReturn
+	}{
+		{
+			name:      "bad end date",
+			orgNr:     "556000-0000",
+			vatReturn: domain.VATReturn{ToDate: "2025-13-01"},
+		},
+		{
+			name:      "bad organisation number",
+			orgNr:     "556000-000",
+			vatReturn: domain.VATReturn{ToDate: "2025-01-31"},
+		},
+		{
+			name:      "letters in organisation number",
+			orgNr:     "55600A-0000",
+			vatReturn: domain.VATReturn{ToDate: "2025-01-31"},
+		},
+		{
+			name:  "unknown box",
+			orgNr: "556000-0000",
+			vatReturn: domain.VATReturn{ToDate: "2025-01-31", Boxes: []domain.VATBox{
+				{Box: "99", Amount: domain.NewAmount(1)},
+			}},
+		},
+		{
+			name:  "box 49 does not add up",
+			orgNr: "556000-0000",
+			vatReturn: domain.VATReturn{ToDate: "2025-01-31", Boxes: []domain.VATBox{
+				{Box: "10", Amount: domain.NewAmount(250)},
+				{Box: "48", Amount: domain.NewAmount(100)},
+				{Box: "49", Amount: domain.NewAmount(250)},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewUpload(tt.orgNr, &tt.vatReturn); err == nil {
+				t.Error("NewUpload did not return an error")
+			}
+		})
+	}
+}
+
+func TestNormalizeOrgNr(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "556000-0000", want: "165560000000"},
+		{in: "5560000000", want: "165560000000"},
+		{in: "16556000-0000", want: "165560000000"},
+		{in: "19800101-1234", want: "198001011234"},
+		{in: "556 000 0000", want: "165560000000"},
+	}
+
+	for _, tt := range tests {
+		if got := normalizeOrgNr(tt.in); got != tt.want {
+			t.Errorf("normalizeOrgNr(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
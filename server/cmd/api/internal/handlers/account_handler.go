@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"cmd/api/internal/domain"
+	"cmd/api/internal/middleware"
 	"cmd/api/internal/service"
 	"net/http"
 	"strconv"
@@ -19,6 +20,14 @@ func NewAccountHandler(accountService *service.AccountService) *AccountHandler {
 	}
 }
 
+// accounts returns the AccountService for the company and user of the
+// request
+func (h *AccountHandler) accounts(c *gin.Context) *service.AccountService {
+	companyID, _ := middleware.GetCompanyIDFromContext(c)
+	userID, _ := middleware.GetUserIDFromContext(c)
+	return h.accountService.ForCompany(companyID).AsUser(userID)
+}
+
 // CreateAccount handles POST /accounts
 func (h *AccountHandler) CreateAccount(c *gin.Context) {
 	var account domain.Account
@@ -28,7 +37,7 @@ func (h *AccountHandler) CreateAccount(c *gin.Context) {
 		return
 	}
 	
-	if err := h.accountService.CreateAccount(&account); err != nil {
+	if err := h.accounts(c).CreateAccount(&account); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -48,7 +57,7 @@ func (h *AccountHandler) GetAccountByNo(c *gin.Context) {
 		return
 	}
 
-	account, err := h.accountService.GetAccountByNo(accountNo)
+	account, err := h.accounts(c).GetAccountByNo(accountNo)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -59,7 +68,7 @@ func (h *AccountHandler) GetAccountByNo(c *gin.Context) {
 
 // GetAllAccounts handles GET /accounts
 func (h *AccountHandler) GetAllAccounts(c *gin.Context) {
-	accounts, err := h.accountService.GetAllAccounts()
+	accounts, err := h.accounts(c).GetAllAccounts()
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -77,7 +86,7 @@ func (h *AccountHandler) GetAccountsByGroup(c *gin.Context) {
 		return
 	}
 
-	accounts, err := h.accountService.GetAccountsByGroup(accountGroup)
+	accounts, err := h.accounts(c).GetAccountsByGroup(accountGroup)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -107,7 +116,7 @@ func (h *AccountHandler) UpdateAccount(c *gin.Context) {
 
 	account.AccountNo = accountNo
 
-	if err := h.accountService.UpdateAccount(&account); err != nil {
+	if err := h.accounts(c).UpdateAccount(&account); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -127,7 +136,7 @@ func (h *AccountHandler) DeleteAccount(c *gin.Context) {
 		return
 	}
 
-	if err := h.accountService.DeleteAccount(accountNo); err != nil {
+	if err := h.accounts(c).DeleteAccount(accountNo); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -136,6 +145,8 @@ func (h *AccountHandler) DeleteAccount(c *gin.Context) {
 }
 
 // GetAccountLedger handles GET /accounts/:accountNo/ledger
+// project_id and cost_center_id limit the ledger; group_by=project|cost_center
+// splits it per project or cost centre.
 func (h *AccountHandler) GetAccountLedger(c *gin.Context) {
 	accountNoParam := c.Param("accountNo")
 	accountNo, err := strconv.Atoi(accountNoParam)
@@ -147,7 +158,27 @@ func (h *AccountHandler) GetAccountLedger(c *gin.Context) {
 	// Get period from query parameter (optional)
 	period := c.Query("period")
 
-	entries, err := h.accountService.GetLedger(accountNo, period)
+	filter, err := parseDimensionFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if groupBy := c.Query("group_by"); groupBy != "" {
+		groups, err := h.accounts(c).GetLedgerByDimension(accountNo, period, filter, groupBy)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"group_by": groupBy,
+			"groups":   groups,
+		})
+		return
+	}
+
+	entries, err := h.accounts(c).GetLedger(accountNo, period, filter)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
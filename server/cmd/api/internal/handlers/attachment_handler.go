@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"cmd/api/internal/middleware"
+	"cmd/api/internal/service"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AttachmentHandler struct {
+	attachmentService *service.AttachmentService
+}
+
+func NewAttachmentHandler(attachmentService *service.AttachmentService) *AttachmentHandler {
+	return &AttachmentHandler{
+		attachmentService: attachmentService,
+	}
+}
+
+// attachments returns the AttachmentService for the company and user of the
+// request
+func (h *AttachmentHandler) attachments(c *gin.Context) *service.AttachmentService {
+	companyID, _ := middleware.GetCompanyIDFromContext(c)
+	userID, _ := middleware.GetUserIDFromContext(c)
+	return h.attachmentService.ForCompany(companyID).AsUser(userID)
+}
+
+// UploadAttachment handles POST /vouchers/:id/attachments with the file in
+// the multipart form field "file" and optionally its SHA-256 in "checksum"
+func (h *AttachmentHandler) UploadAttachment(c *gin.Context) {
+	voucherID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid voucher ID"})
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "a file is required in the form field 'file'"})
+		return
+	}
+	if fileHeader.Size > service.MaxAttachmentSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "file is too large"})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	attachment, err := h.attachments(c).UploadAttachment(voucherID, fileHeader.Filename, data, c.PostForm("checksum"))
+	if err != nil {
+		respondServiceError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	c.JSON(http.StatusCreated, attachment)
+}
+
+// GetAttachments handles GET /vouchers/:id/attachments
+func (h *AttachmentHandler) GetAttachments(c *gin.Context) {
+	voucherID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid voucher ID"})
+		return
+	}
+
+	attachments, err := h.attachments(c).GetAttachments(voucherID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, attachments)
+}
+
+// DownloadAttachment handles GET /vouchers/:id/attachments/:attachmentId
+func (h *AttachmentHandler) DownloadAttachment(c *gin.Context) {
+	voucherID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid voucher ID"})
+		return
+	}
+	attachmentID, err := strconv.Atoi(c.Param("attachmentId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid attachment ID"})
+		return
+	}
+
+	attachment, data, err := h.attachments(c).GetAttachmentFile(voucherID, attachmentID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", attachment.FileName))
+	c.Header("X-Checksum-SHA256", attachment.Checksum)
+	c.Data(http.StatusOK, attachment.ContentType, data)
+}
+
+// DeleteAttachment handles DELETE /vouchers/:id/attachments/:attachmentId
+func (h *AttachmentHandler) DeleteAttachment(c *gin.Context) {
+	voucherID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid voucher ID"})
+		return
+	}
+	attachmentID, err := strconv.Atoi(c.Param("attachmentId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid attachment ID"})
+		return
+	}
+
+	if err := h.attachments(c).DeleteAttachment(voucherID, attachmentID); err != nil {
+		respondServiceError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "attachment deleted successfully"})
+}
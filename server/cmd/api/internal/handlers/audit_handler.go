@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"cmd/api/internal/domain"
+	"cmd/api/internal/middleware"
+	"cmd/api/internal/service"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuditHandler struct {
+	auditService *service.AuditService
+}
+
+func NewAuditHandler(auditService *service.AuditService) *AuditHandler {
+	return &AuditHandler{
+		auditService: auditService,
+	}
+}
+
+// GetAuditTrail handles GET /audit
+// entity, entity_id and user_id narrow the trail down; from_date and to_date
+// (YYYY-MM-DD, both inclusive) limit it to a date range.
+func (h *AuditHandler) GetAuditTrail(c *gin.Context) {
+	filter := domain.AuditFilter{
+		Entity:   c.Query("entity"),
+		EntityID: c.Query("entity_id"),
+	}
+
+	if param := c.Query("user_id"); param != "" {
+		userID, err := strconv.Atoi(param)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+			return
+		}
+		filter.UserID = &userID
+	}
+
+	var err error
+	if filter.From, err = parseOptionalDate(c, "from_date"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if filter.To, err = parseOptionalDate(c, "to_date"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	companyID, _ := middleware.GetCompanyIDFromContext(c)
+	entries, err := h.auditService.ForCompany(companyID).GetEntries(filter)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
+
+// parseOptionalDate reads a YYYY-MM-DD query parameter; a missing one gives nil
+func parseOptionalDate(c *gin.Context, name string) (*time.Time, error) {
+	param := c.Query(name)
+	if param == "" {
+		return nil, nil
+	}
+
+	date, err := time.Parse("2006-01-02", param)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s, expected YYYY-MM-DD", name)
+	}
+
+	return &date, nil
+}
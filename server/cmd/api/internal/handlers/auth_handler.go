@@ -4,6 +4,7 @@ import (
 	"cmd/api/internal/auth"
 	"cmd/api/internal/domain"
 	"cmd/api/internal/dto"
+	"cmd/api/internal/middleware"
 	"cmd/api/internal/service"
 	"net/http"
 
@@ -12,16 +13,19 @@ import (
 )
 
 type AuthHandler struct {
-	userService *service.UserService
-	jwtManager  *auth.JWTManager
+	userService    *service.UserService
+	companyService *service.CompanyService
+	jwtManager     *auth.JWTManager
 }
 
 func NewAuthHandler(
-	userService *service.UserService, 
+	userService *service.UserService,
+	companyService *service.CompanyService,
 	jwtManager *auth.JWTManager) *AuthHandler {
 	return &AuthHandler{
-		userService: userService,
-		jwtManager:  jwtManager,
+		userService:    userService,
+		companyService: companyService,
+		jwtManager:     jwtManager,
 	}
 }
 
@@ -46,23 +50,13 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		return
 	}
 
-	token, err := h.jwtManager.GenerateToken(user.UserID, user.Email, user.Role)
-	if err != nil {
+	// A new user is not a member of any company until they create one or
+	// an Admin adds them
+	if err := h.issueToken(c, user, nil); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate token"})
 		return
 	}
 
-	// Set JWT as httpOnly cookie
-	c.SetCookie(
-		"token",           // name
-		token,             // value
-		604800,            // maxAge (7 days in seconds)
-		"/",               // path
-		"",                // domain (empty for current domain)
-		false,             // secure (set true in production with HTTPS!)
-		true,              // httpOnly
-	)
-
 	user.PasswordHash = ""
 
 	c.JSON(http.StatusCreated, gin.H{
@@ -92,13 +86,85 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	token, err := h.jwtManager.GenerateToken(user.UserID, user.Email, user.Role)
+	// Log in to the requested company, or the first one the user is in
+	var membership *domain.CompanyMember
+	if req.CompanyID > 0 {
+		membership, err = h.companyService.GetMembership(req.CompanyID, user.UserID)
+	} else {
+		membership, err = h.companyService.GetDefaultMembership(user.UserID)
+	}
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.issueToken(c, user, membership); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate token"})
+		return
+	}
+
+	user.PasswordHash = ""
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "login successful",
+		"user":       user,
+		"company_id": companyIDOf(membership),
+	})
+}
+
+// SwitchCompany handles POST /auth/switch-company and issues a new token
+// for another company the user is a member of
+func (h *AuthHandler) SwitchCompany(c *gin.Context) {
+	userID, ok := middleware.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	var req dto.SwitchCompanyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	membership, err := h.companyService.GetMembership(req.CompanyID, userID)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := h.userService.GetUserByID(userID)
 	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return
+	}
+
+	if err := h.issueToken(c, user, membership); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate token"})
 		return
 	}
 
-	// Set JWT as httpOnly cookie
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "company switched successfully",
+		"company_id": membership.CompanyID,
+		"role":       membership.Role,
+	})
+}
+
+// issueToken sets a JWT for the user as httpOnly cookie. The token carries
+// the company of membership and the user's role there; without a
+// membership it has no company.
+func (h *AuthHandler) issueToken(c *gin.Context, user *domain.User, membership *domain.CompanyMember) error {
+	role := ""
+	if membership != nil {
+		role = membership.Role
+	}
+
+	token, err := h.jwtManager.GenerateToken(user.UserID, user.Email, role, companyIDOf(membership))
+	if err != nil {
+		return err
+	}
+
 	c.SetCookie(
 		"token",           // name
 		token,             // value
@@ -109,15 +175,20 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		true,              // httpOnly
 	)
 
-	user.PasswordHash = ""
+	return nil
+}
 
-	c.JSON(http.StatusOK, gin.H{
-		"message": "login successful",
-		"user":    user,
-	})
+func companyIDOf(membership *domain.CompanyMember) int {
+	if membership == nil {
+		return 0
+	}
+	return membership.CompanyID
 }
 
-// RefreshToken handles POST /auth/refresh
+// RefreshToken handles POST /auth/refresh. The user and their membership
+// of the token's company are loaded again, so a user removed from the
+// company, or given another role there, cannot keep the old access by
+// refreshing.
 func (h *AuthHandler) RefreshToken(c *gin.Context) {
 	// Get token from cookie
 	tokenString, err := c.Cookie("token")
@@ -126,22 +197,32 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 		return
 	}
 
-	newToken, err := h.jwtManager.RefreshToken(tokenString)
+	claims, err := h.jwtManager.ValidateToken(tokenString)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid or expired token"})
 		return
 	}
 
+	user, err := h.userService.GetUserByID(claims.UserID)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user no longer exists"})
+		return
+	}
+
+	var membership *domain.CompanyMember
+	if claims.CompanyID > 0 {
+		membership, err = h.companyService.GetMembership(claims.CompanyID, user.UserID)
+		if err != nil || membership.Role != claims.Role {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "company membership has changed, please log in again"})
+			return
+		}
+	}
+
 	// Set new token as httpOnly cookie
-	c.SetCookie(
-		"token",
-		newToken,
-		604800,
-		"/",
-		"",
-		false,
-		true,
-	)
+	if err := h.issueToken(c, user, membership); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate token"})
+		return
+	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"message": "token refreshed successfully",
@@ -0,0 +1,236 @@
+package handlers
+
+import (
+	"cmd/api/internal/domain"
+	"cmd/api/internal/middleware"
+	"cmd/api/internal/service"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type BankHandler struct {
+	bankService *service.BankService
+}
+
+func NewBankHandler(bankService *service.BankService) *BankHandler {
+	return &BankHandler{
+		bankService: bankService,
+	}
+}
+
+// bank returns the BankService for the company and user of the request
+func (h *BankHandler) bank(c *gin.Context) *service.BankService {
+	companyID, _ := middleware.GetCompanyIDFromContext(c)
+	userID, _ := middleware.GetUserIDFromContext(c)
+	return h.bankService.ForCompany(companyID).AsUser(userID)
+}
+
+// parseAccountNo reads the required account_no query parameter
+func parseAccountNo(c *gin.Context) (int, error) {
+	accountNo, err := parseOptionalInt(c, "account_no")
+	if err != nil {
+		return 0, err
+	}
+	if accountNo == nil {
+		return 0, errors.New("account_no is required")
+	}
+	return *accountNo, nil
+}
+
+// ImportStatement handles POST /bank/import?account_no=1930&dry_run=true
+// with a camt.053 or CSV bank statement in the multipart form field "file"
+func (h *BankHandler) ImportStatement(c *gin.Context) {
+	accountNo, err := parseAccountNo(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "a bank statement is required in the form field 'file'"})
+		return
+	}
+	if fileHeader.Size > maxImportSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "file is too large"})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	dryRun := c.Query("dry_run") == "true"
+
+	result, err := h.bank(c).ImportStatement(accountNo, data, dryRun)
+	if err != nil {
+		respondServiceError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	switch {
+	case dryRun:
+		c.JSON(http.StatusOK, result)
+	case len(result.Errors) > 0:
+		c.JSON(http.StatusUnprocessableEntity, result)
+	default:
+		c.JSON(http.StatusCreated, result)
+	}
+}
+
+// GetTransactions handles GET /bank/transactions?account_no=&from_date=&to_date=&matched=
+func (h *BankHandler) GetTransactions(c *gin.Context) {
+	var filter domain.BankTransactionFilter
+	var err error
+
+	if filter.AccountNo, err = parseOptionalInt(c, "account_no"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if filter.From, err = parseOptionalDate(c, "from_date"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if filter.To, err = parseOptionalDate(c, "to_date"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if param := c.Query("matched"); param != "" {
+		matched, err := strconv.ParseBool(param)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid matched, expected true or false"})
+			return
+		}
+		filter.Matched = &matched
+	}
+
+	transactions, err := h.bank(c).GetTransactions(filter)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, transactions)
+}
+
+// GetSuggestions handles GET /bank/transactions/:id/suggestions
+func (h *BankHandler) GetSuggestions(c *gin.Context) {
+	transactionID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid bank transaction ID"})
+		return
+	}
+
+	suggestions, err := h.bank(c).GetSuggestions(transactionID)
+	if err != nil {
+		respondServiceError(c, err, http.StatusNotFound)
+		return
+	}
+
+	c.JSON(http.StatusOK, suggestions)
+}
+
+// MatchTransaction handles POST /bank/transactions/:id/match with the body
+// {"line_id": 123}
+func (h *BankHandler) MatchTransaction(c *gin.Context) {
+	transactionID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid bank transaction ID"})
+		return
+	}
+
+	var req struct {
+		LineID int `json:"line_id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	transaction, err := h.bank(c).Match(transactionID, req.LineID)
+	if err != nil {
+		respondServiceError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	c.JSON(http.StatusOK, transaction)
+}
+
+// UnmatchTransaction handles DELETE /bank/transactions/:id/match
+func (h *BankHandler) UnmatchTransaction(c *gin.Context) {
+	transactionID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid bank transaction ID"})
+		return
+	}
+
+	transaction, err := h.bank(c).Unmatch(transactionID)
+	if err != nil {
+		respondServiceError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	c.JSON(http.StatusOK, transaction)
+}
+
+// MatchAccount handles POST /bank/match?account_no=1930
+// It runs the automatic matching again on the unmatched transactions.
+func (h *BankHandler) MatchAccount(c *gin.Context) {
+	accountNo, err := parseAccountNo(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	matched, err := h.bank(c).MatchAccount(accountNo)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"account_no": accountNo, "matched": matched})
+}
+
+// GetReconciliation handles GET /bank/reconciliation?account_no=1930&from_date=YYYY-MM-DD&to_date=YYYY-MM-DD
+func (h *BankHandler) GetReconciliation(c *gin.Context) {
+	accountNo, err := parseAccountNo(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	from, err := parseOptionalDate(c, "from_date")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	to, err := parseOptionalDate(c, "to_date")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if from == nil || to == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from_date and to_date are required"})
+		return
+	}
+
+	reconciliation, err := h.bank(c).GetReconciliation(accountNo, *from, *to)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, reconciliation)
+}
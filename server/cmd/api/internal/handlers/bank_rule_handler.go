@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"cmd/api/internal/domain"
+	"cmd/api/internal/middleware"
+	"cmd/api/internal/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type BankRuleHandler struct {
+	bankRuleService *service.BankRuleService
+}
+
+func NewBankRuleHandler(bankRuleService *service.BankRuleService) *BankRuleHandler {
+	return &BankRuleHandler{
+		bankRuleService: bankRuleService,
+	}
+}
+
+// rules returns the BankRuleService for the company and user of the request
+func (h *BankRuleHandler) rules(c *gin.Context) *service.BankRuleService {
+	companyID, _ := middleware.GetCompanyIDFromContext(c)
+	userID, _ := middleware.GetUserIDFromContext(c)
+	return h.bankRuleService.ForCompany(companyID).AsUser(userID)
+}
+
+// CreateRule handles POST /bank/rules
+func (h *BankRuleHandler) CreateRule(c *gin.Context) {
+	var rule domain.BankRule
+	if err := c.ShouldBindJSON(&rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.rules(c).CreateRule(&rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "bank rule created successfully",
+		"rule":    rule,
+	})
+}
+
+// GetRuleByID handles GET /bank/rules/:id
+func (h *BankRuleHandler) GetRuleByID(c *gin.Context) {
+	ruleID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid bank rule ID"})
+		return
+	}
+
+	rule, err := h.rules(c).GetRuleByID(ruleID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+// GetAllRules handles GET /bank/rules
+func (h *BankRuleHandler) GetAllRules(c *gin.Context) {
+	rules, err := h.rules(c).GetAllRules()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, rules)
+}
+
+// UpdateRule handles PUT /bank/rules/:id
+func (h *BankRuleHandler) UpdateRule(c *gin.Context) {
+	ruleID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid bank rule ID"})
+		return
+	}
+
+	var rule domain.BankRule
+	if err := c.ShouldBindJSON(&rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rule.RuleID = ruleID
+
+	if err := h.rules(c).UpdateRule(&rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "bank rule updated successfully",
+		"rule":    rule,
+	})
+}
+
+// DeleteRule handles DELETE /bank/rules/:id
+func (h *BankRuleHandler) DeleteRule(c *gin.Context) {
+	ruleID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid bank rule ID"})
+		return
+	}
+
+	if err := h.rules(c).DeleteRule(ruleID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "bank rule deleted successfully"})
+}
+
+// GetApplications handles GET /bank/rules/:id/applications
+// It lists the draft vouchers the rule produced and their bank transactions.
+func (h *BankRuleHandler) GetApplications(c *gin.Context) {
+	ruleID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid bank rule ID"})
+		return
+	}
+
+	applications, err := h.rules(c).GetApplications(ruleID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, applications)
+}
+
+// ApplyRules handles POST /bank/rules/apply?account_no=1930&dry_run=true
+// With dry_run it previews the drafts the rules would create.
+func (h *BankRuleHandler) ApplyRules(c *gin.Context) {
+	accountNo, err := parseAccountNo(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	dryRun := c.Query("dry_run") == "true"
+
+	result, err := h.rules(c).ApplyRules(accountNo, dryRun)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if dryRun {
+		c.JSON(http.StatusOK, result)
+		return
+	}
+	c.JSON(http.StatusCreated, result)
+}
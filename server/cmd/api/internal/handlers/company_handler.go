@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"cmd/api/internal/domain"
+	"cmd/api/internal/middleware"
+	"cmd/api/internal/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CompanyHandler struct {
+	companyService *service.CompanyService
+}
+
+func NewCompanyHandler(companyService *service.CompanyService) *CompanyHandler {
+	return &CompanyHandler{
+		companyService: companyService,
+	}
+}
+
+// companies returns the CompanyService for the user of the request
+func (h *CompanyHandler) companies(c *gin.Context) *service.CompanyService {
+	userID, _ := middleware.GetUserIDFromContext(c)
+	return h.companyService.AsUser(userID)
+}
+
+// CreateCompanyRequest is the body of POST /companies
+type CreateCompanyRequest struct {
+	Name             string `json:"name" binding:"required"`
+	OrgNr            string `json:"org_nr"`
+	CopyAccountsFrom int    `json:"copy_accounts_from"` // Company to copy the chart of accounts from (optional)
+}
+
+// CreateCompany handles POST /companies. The user becomes Admin of the new
+// company and can switch to it with POST /auth/switch-company.
+func (h *CompanyHandler) CreateCompany(c *gin.Context) {
+	userID, ok := middleware.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	var req CreateCompanyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	company := &domain.Company{Name: req.Name, OrgNr: req.OrgNr}
+	if err := h.companyService.CreateCompany(company, userID, req.CopyAccountsFrom); err != nil {
+		respondServiceError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "company created successfully",
+		"company": company,
+	})
+}
+
+// GetMyCompanies handles GET /companies with the companies the user is a
+// member of and their role in each
+func (h *CompanyHandler) GetMyCompanies(c *gin.Context) {
+	userID, ok := middleware.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	companies, err := h.companyService.GetCompaniesForUser(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, companies)
+}
+
+// GetCurrentCompany handles GET /companies/current
+func (h *CompanyHandler) GetCurrentCompany(c *gin.Context) {
+	companyID, _ := middleware.GetCompanyIDFromContext(c)
+
+	company, err := h.companyService.GetCompany(companyID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, company)
+}
+
+// UpdateCurrentCompany handles PUT /companies/current
+func (h *CompanyHandler) UpdateCurrentCompany(c *gin.Context) {
+	var company domain.Company
+	if err := c.ShouldBindJSON(&company); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	company.CompanyID, _ = middleware.GetCompanyIDFromContext(c)
+
+	if err := h.companies(c).UpdateCompany(&company); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "company updated successfully",
+		"company": company,
+	})
+}
+
+// GetMembers handles GET /companies/current/members
+func (h *CompanyHandler) GetMembers(c *gin.Context) {
+	companyID, _ := middleware.GetCompanyIDFromContext(c)
+
+	members, err := h.companyService.GetMembers(companyID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, members)
+}
+
+// SaveMember handles POST /companies/current/members with {user_id, role}.
+// It adds the user or changes their role if they already are a member.
+func (h *CompanyHandler) SaveMember(c *gin.Context) {
+	var member domain.CompanyMember
+	if err := c.ShouldBindJSON(&member); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	member.CompanyID, _ = middleware.GetCompanyIDFromContext(c)
+
+	if err := h.companies(c).SaveMember(&member); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "member saved successfully",
+		"member":  member,
+	})
+}
+
+// RemoveMember handles DELETE /companies/current/members/:userId
+func (h *CompanyHandler) RemoveMember(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	companyID, _ := middleware.GetCompanyIDFromContext(c)
+
+	if err := h.companies(c).RemoveMember(companyID, userID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "member removed successfully"})
+}
@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"cmd/api/internal/domain"
+	"cmd/api/internal/middleware"
+	"cmd/api/internal/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CostCenterHandler struct {
+	costCenterService *service.CostCenterService
+}
+
+func NewCostCenterHandler(costCenterService *service.CostCenterService) *CostCenterHandler {
+	return &CostCenterHandler{
+		costCenterService: costCenterService,
+	}
+}
+
+// costCenters returns the CostCenterService for the company and user of the
+// request
+func (h *CostCenterHandler) costCenters(c *gin.Context) *service.CostCenterService {
+	companyID, _ := middleware.GetCompanyIDFromContext(c)
+	userID, _ := middleware.GetUserIDFromContext(c)
+	return h.costCenterService.ForCompany(companyID).AsUser(userID)
+}
+
+// CreateCostCenter handles POST /cost-centers
+func (h *CostCenterHandler) CreateCostCenter(c *gin.Context) {
+	var costCenter domain.CostCenter
+	if err := c.ShouldBindJSON(&costCenter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.costCenters(c).CreateCostCenter(&costCenter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":     "cost centre created successfully",
+		"cost_center": costCenter,
+	})
+}
+
+// GetCostCenterByID handles GET /cost-centers/:id
+func (h *CostCenterHandler) GetCostCenterByID(c *gin.Context) {
+	costCenterID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid cost centre ID"})
+		return
+	}
+
+	costCenter, err := h.costCenters(c).GetCostCenterByID(costCenterID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, costCenter)
+}
+
+// GetAllCostCenters handles GET /cost-centers
+func (h *CostCenterHandler) GetAllCostCenters(c *gin.Context) {
+	costCenters, err := h.costCenters(c).GetAllCostCenters()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, costCenters)
+}
+
+// UpdateCostCenter handles PUT /cost-centers/:id
+func (h *CostCenterHandler) UpdateCostCenter(c *gin.Context) {
+	costCenterID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid cost centre ID"})
+		return
+	}
+
+	var costCenter domain.CostCenter
+	if err := c.ShouldBindJSON(&costCenter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	costCenter.CostCenterID = costCenterID
+
+	if err := h.costCenters(c).UpdateCostCenter(&costCenter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "cost centre updated successfully",
+		"cost_center": costCenter,
+	})
+}
+
+// DeleteCostCenter handles DELETE /cost-centers/:id
+func (h *CostCenterHandler) DeleteCostCenter(c *gin.Context) {
+	costCenterID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid cost centre ID"})
+		return
+	}
+
+	if err := h.costCenters(c).DeleteCostCenter(costCenterID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "cost centre deleted successfully"})
+}
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"cmd/api/internal/service"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// respondServiceError writes err as JSON, using a specific status for the
+// service errors that have one and defaultStatus for everything else
+func respondServiceError(c *gin.Context, err error, defaultStatus int) {
+	var balanceErr *service.BalanceError
+	if errors.As(err, &balanceErr) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":        balanceErr.Error(),
+			"total_debit":  balanceErr.TotalDebit,
+			"total_credit": balanceErr.TotalCredit,
+			"difference":   balanceErr.Difference,
+		})
+		return
+	}
+
+	switch {
+	case errors.Is(err, service.ErrVoucherAlreadyCorrected),
+		errors.Is(err, service.ErrVoucherBooked),
+		errors.Is(err, service.ErrVoucherNotBooked),
+		errors.Is(err, service.ErrPeriodNotOpen),
+		errors.Is(err, service.ErrInvalidPeriodTransition),
+		errors.Is(err, service.ErrFiscalYearClosed),
+		errors.Is(err, service.ErrVATAlreadySettled),
+		errors.Is(err, service.ErrAttachmentExists),
+		errors.Is(err, service.ErrBankTransactionMatched):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+
+	if errors.Is(err, service.ErrUnsupportedAttachmentType) {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": err.Error()})
+		return
+	}
+
+	if errors.Is(err, service.ErrAttachmentChecksum) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+
+	if errors.Is(err, service.ErrNotCompanyMember) ||
+		errors.Is(err, service.ErrNotVoucherCreator) ||
+		errors.Is(err, service.ErrNotSelfOrAdmin) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(defaultStatus, gin.H{"error": err.Error()})
+}
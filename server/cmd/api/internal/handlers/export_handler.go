@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"cmd/api/internal/middleware"
+	"cmd/api/internal/service"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ExportHandler struct {
+	sieService *service.SIEService
+}
+
+func NewExportHandler(sieService *service.SIEService) *ExportHandler {
+	return &ExportHandler{
+		sieService: sieService,
+	}
+}
+
+// sie returns the SIEService for the company of the request
+func (h *ExportHandler) sie(c *gin.Context) *service.SIEService {
+	companyID, _ := middleware.GetCompanyIDFromContext(c)
+	return h.sieService.ForCompany(companyID)
+}
+
+// ExportSIE handles GET /export/sie?fiscal_year=<fiscal year ID>
+func (h *ExportHandler) ExportSIE(c *gin.Context) {
+	fiscalYearID, err := strconv.Atoi(c.Query("fiscal_year"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "fiscal_year query parameter must be a fiscal year ID"})
+		return
+	}
+
+	filename, data, err := h.sie(c).ExportFiscalYear(fiscalYearID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
+	c.Data(http.StatusOK, "text/plain; charset=IBM437", data)
+}
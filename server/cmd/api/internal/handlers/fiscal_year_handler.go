@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"cmd/api/internal/domain"
+	"cmd/api/internal/middleware"
+	"cmd/api/internal/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type FiscalYearHandler struct {
+	fiscalYearService *service.FiscalYearService
+}
+
+func NewFiscalYearHandler(fiscalYearService *service.FiscalYearService) *FiscalYearHandler {
+	return &FiscalYearHandler{
+		fiscalYearService: fiscalYearService,
+	}
+}
+
+// fiscalYears returns the FiscalYearService for the company and user of the
+// request
+func (h *FiscalYearHandler) fiscalYears(c *gin.Context) *service.FiscalYearService {
+	companyID, _ := middleware.GetCompanyIDFromContext(c)
+	userID, _ := middleware.GetUserIDFromContext(c)
+	return h.fiscalYearService.ForCompany(companyID).AsUser(userID)
+}
+
+// CreateFiscalYear handles POST /fiscal-years
+func (h *FiscalYearHandler) CreateFiscalYear(c *gin.Context) {
+	var fiscalYear domain.FiscalYear
+
+	if err := c.ShouldBindJSON(&fiscalYear); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.fiscalYears(c).CreateFiscalYear(&fiscalYear); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, fiscalYear)
+}
+
+// GetAllFiscalYears handles GET /fiscal-years
+func (h *FiscalYearHandler) GetAllFiscalYears(c *gin.Context) {
+	fiscalYears, err := h.fiscalYears(c).GetAllFiscalYears()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, fiscalYears)
+}
+
+// GetFiscalYearByID handles GET /fiscal-years/:id
+func (h *FiscalYearHandler) GetFiscalYearByID(c *gin.Context) {
+	idParam := c.Param("id")
+	fiscalYearID, err := strconv.Atoi(idParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid fiscal year ID"})
+		return
+	}
+
+	fiscalYear, err := h.fiscalYears(c).GetFiscalYearByID(fiscalYearID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, fiscalYear)
+}
+
+// CloseFiscalYear handles POST /fiscal-years/:id/close
+func (h *FiscalYearHandler) CloseFiscalYear(c *gin.Context) {
+	idParam := c.Param("id")
+	fiscalYearID, err := strconv.Atoi(idParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid fiscal year ID"})
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	closed, next, err := h.fiscalYears(c).CloseFiscalYear(fiscalYearID, userID)
+	if err != nil {
+		respondServiceError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":          "fiscal year closed successfully",
+		"fiscal_year":      closed,
+		"next_fiscal_year": next,
+	})
+}
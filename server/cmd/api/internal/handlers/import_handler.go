@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"cmd/api/internal/middleware"
+	"cmd/api/internal/service"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxImportSize limits the size of an uploaded import file
+const maxImportSize = 50 << 20
+
+type ImportHandler struct {
+	sieService *service.SIEService
+}
+
+func NewImportHandler(sieService *service.SIEService) *ImportHandler {
+	return &ImportHandler{
+		sieService: sieService,
+	}
+}
+
+// sie returns the SIEService for the company of the request
+func (h *ImportHandler) sie(c *gin.Context) *service.SIEService {
+	companyID, _ := middleware.GetCompanyIDFromContext(c)
+	return h.sieService.ForCompany(companyID)
+}
+
+// ImportSIE handles POST /import/sie?dry_run=true with the SIE file in the
+// multipart form field "file"
+func (h *ImportHandler) ImportSIE(c *gin.Context) {
+	userID, ok := middleware.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "a SIE file is required in the form field 'file'"})
+		return
+	}
+	if fileHeader.Size > maxImportSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "file is too large"})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	dryRun := c.Query("dry_run") == "true"
+
+	result, err := h.sie(c).ImportFile(data, userID, dryRun)
+	if err != nil {
+		respondServiceError(c, err, http.StatusInternalServerError)
+		return
+	}
+
+	switch {
+	case dryRun:
+		c.JSON(http.StatusOK, result)
+	case len(result.Errors) > 0:
+		c.JSON(http.StatusUnprocessableEntity, result)
+	default:
+		c.JSON(http.StatusCreated, result)
+	}
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"cmd/api/internal/domain"
+	"cmd/api/internal/middleware"
 	"cmd/api/internal/service"
 	"net/http"
 	"strconv"
@@ -19,6 +20,14 @@ func NewLineItemHandler(lineItemService *service.LineItemService) *LineItemHandl
 	}
 }
 
+// lineItems returns the LineItemService for the company and user of the
+// request
+func (h *LineItemHandler) lineItems(c *gin.Context) *service.LineItemService {
+	companyID, _ := middleware.GetCompanyIDFromContext(c)
+	userID, _ := middleware.GetUserIDFromContext(c)
+	return h.lineItemService.ForCompany(companyID).AsUser(userID)
+}
+
 // CreateLineItem handles POST /lineitems
 func (h *LineItemHandler) CreateLineItem(c *gin.Context) {
 	var lineItem domain.LineItem
@@ -28,8 +37,8 @@ func (h *LineItemHandler) CreateLineItem(c *gin.Context) {
 		return
 	}
 
-	if err := h.lineItemService.CreateLineItem(&lineItem); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := h.lineItems(c).CreateLineItem(&lineItem); err != nil {
+		respondServiceError(c, err, http.StatusInternalServerError)
 		return
 	}
 
@@ -45,7 +54,7 @@ func (h *LineItemHandler) GetLineItemByID(c *gin.Context) {
 		return
 	}
 
-	lineItem, err := h.lineItemService.GetLineItemByID(lineID)
+	lineItem, err := h.lineItems(c).GetLineItemByID(lineID)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -63,7 +72,7 @@ func (h *LineItemHandler) GetLineItemsByVoucherID(c *gin.Context) {
 		return
 	}
 
-	lineItems, err := h.lineItemService.GetLineItemsByVoucherID(voucherID)
+	lineItems, err := h.lineItems(c).GetLineItemsByVoucherID(voucherID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -81,7 +90,7 @@ func (h *LineItemHandler) GetLineItemsByAccountNo(c *gin.Context) {
 		return
 	}
 
-	lineItems, err := h.lineItemService.GetLineItemsByAccountNo(accountNo)
+	lineItems, err := h.lineItems(c).GetLineItemsByAccountNo(accountNo)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -107,8 +116,8 @@ func (h *LineItemHandler) UpdateLineItem(c *gin.Context) {
 
 	lineItem.LineID = lineID
 
-	if err := h.lineItemService.UpdateLineItem(&lineItem); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := h.lineItems(c).UpdateLineItem(&lineItem); err != nil {
+		respondServiceError(c, err, http.StatusInternalServerError)
 		return
 	}
 
@@ -127,8 +136,8 @@ func (h *LineItemHandler) DeleteLineItem(c *gin.Context) {
 		return
 	}
 
-	if err := h.lineItemService.DeleteLineItem(lineID); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := h.lineItems(c).DeleteLineItem(lineID); err != nil {
+		respondServiceError(c, err, http.StatusInternalServerError)
 		return
 	}
 
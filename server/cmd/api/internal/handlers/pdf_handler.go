@@ -2,6 +2,8 @@ package handlers
 
 import (
 	"bytes"
+	"cmd/api/internal/domain"
+	"cmd/api/internal/middleware"
 	"cmd/api/internal/service"
 	"fmt"
 	"net/http"
@@ -12,17 +14,37 @@ import (
 )
 
 type PDFHandler struct {
-	voucherService *service.VoucherService
-	accountService *service.AccountService
+	voucherService    *service.VoucherService
+	accountService    *service.AccountService
+	attachmentService *service.AttachmentService
 }
 
-func NewPDFHandler(voucherService *service.VoucherService, accountService *service.AccountService) *PDFHandler {
+func NewPDFHandler(voucherService *service.VoucherService, accountService *service.AccountService, attachmentService *service.AttachmentService) *PDFHandler {
 	return &PDFHandler{
-		voucherService: voucherService,
-		accountService: accountService,
+		voucherService:    voucherService,
+		accountService:    accountService,
+		attachmentService: attachmentService,
 	}
 }
 
+// vouchers returns the VoucherService for the company of the request
+func (h *PDFHandler) vouchers(c *gin.Context) *service.VoucherService {
+	companyID, _ := middleware.GetCompanyIDFromContext(c)
+	return h.voucherService.ForCompany(companyID)
+}
+
+// accounts returns the AccountService for the company of the request
+func (h *PDFHandler) accounts(c *gin.Context) *service.AccountService {
+	companyID, _ := middleware.GetCompanyIDFromContext(c)
+	return h.accountService.ForCompany(companyID)
+}
+
+// attachments returns the AttachmentService for the company of the request
+func (h *PDFHandler) attachments(c *gin.Context) *service.AttachmentService {
+	companyID, _ := middleware.GetCompanyIDFromContext(c)
+	return h.attachmentService.ForCompany(companyID)
+}
+
 // GenerateVoucherPDF handles GET /vouchers/:id/pdf
 func (h *PDFHandler) GenerateVoucherPDF(c *gin.Context) {
 	idParam := c.Param("id")
@@ -33,7 +55,7 @@ func (h *PDFHandler) GenerateVoucherPDF(c *gin.Context) {
 	}
 
 	// Get voucher with line items
-	voucher, err := h.voucherService.GetVoucherByID(voucherID)
+	voucher, err := h.vouchers(c).GetVoucherByID(voucherID)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -53,7 +75,7 @@ func (h *PDFHandler) GenerateVoucherPDF(c *gin.Context) {
 
 	// Voucher number and date
 	pdf.SetFont("Arial", "B", 12)
-	pdf.Cell(40, 8, tr(fmt.Sprintf("Verifikat #%d", voucher.VoucherNumber)))
+	pdf.Cell(40, 8, tr(fmt.Sprintf("Verifikat %s%d", voucher.Series, voucher.VoucherNumber)))
 	pdf.Ln(8)
 
 	pdf.SetFont("Arial", "", 10)
@@ -105,11 +127,11 @@ func (h *PDFHandler) GenerateVoucherPDF(c *gin.Context) {
 
 	// Line items
 	pdf.SetFont("Arial", "", 10)
-	var totalDebit, totalCredit float64
+	var totalDebit, totalCredit domain.Amount
 	for _, line := range voucher.Lines {
 		// Get account name
 		accountName := ""
-		if account, err := h.accountService.GetAccountByNo(line.AccountNo); err == nil {
+		if account, err := h.accounts(c).GetAccountByNo(line.AccountNo); err == nil {
 			accountName = account.AccountName
 		}
 
@@ -131,8 +153,8 @@ func (h *PDFHandler) GenerateVoucherPDF(c *gin.Context) {
 		pdf.CellFormat(20, 7, fmt.Sprintf("%d%%", line.TaxCode), "1", 0, "C", false, 0, "")
 		pdf.Ln(7)
 
-		totalDebit += line.DebitAmount
-		totalCredit += line.CreditAmount
+		totalDebit = totalDebit.Add(line.DebitAmount)
+		totalCredit = totalCredit.Add(line.CreditAmount)
 	}
 
 	// Totals row
@@ -154,6 +176,24 @@ func (h *PDFHandler) GenerateVoucherPDF(c *gin.Context) {
 	}
 	pdf.SetTextColor(0, 0, 0)
 
+	// Attachments (underlag)
+	attachments, err := h.attachments(c).GetAttachments(voucherID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if len(attachments) > 0 {
+		pdf.Ln(10)
+		pdf.SetFont("Arial", "B", 10)
+		pdf.Cell(0, 6, tr("Underlag:"))
+		pdf.Ln(6)
+		pdf.SetFont("Arial", "", 9)
+		for _, attachment := range attachments {
+			pdf.Cell(0, 5, tr(fmt.Sprintf("%s (%s)", attachment.FileName, attachment.CreatedAt.Format("2006-01-02"))))
+			pdf.Ln(5)
+		}
+	}
+
 	// Footer
 	pdf.Ln(20)
 	pdf.SetFont("Arial", "I", 8)
@@ -169,15 +209,15 @@ func (h *PDFHandler) GenerateVoucherPDF(c *gin.Context) {
 	}
 
 	// Set headers and send PDF
-	filename := fmt.Sprintf("verifikat_%d.pdf", voucher.VoucherNumber)
+	filename := fmt.Sprintf("verifikat_%s%d.pdf", voucher.Series, voucher.VoucherNumber)
 	c.Header("Content-Type", "application/pdf")
 	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
 	c.Header("Content-Length", strconv.Itoa(buf.Len()))
 	c.Data(http.StatusOK, "application/pdf", buf.Bytes())
 }
 
-func formatCurrency(amount float64) string {
-	return fmt.Sprintf("%.2f kr", amount)
+func formatCurrency(amount domain.Amount) string {
+	return amount.Format() + " kr"
 }
 
 func truncateString(s string, maxLen int) string {
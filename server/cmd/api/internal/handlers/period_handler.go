@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"cmd/api/internal/domain"
+	"cmd/api/internal/middleware"
+	"cmd/api/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PeriodHandler struct {
+	periodService *service.PeriodService
+}
+
+func NewPeriodHandler(periodService *service.PeriodService) *PeriodHandler {
+	return &PeriodHandler{
+		periodService: periodService,
+	}
+}
+
+// periods returns the PeriodService for the company of the request
+func (h *PeriodHandler) periods(c *gin.Context) *service.PeriodService {
+	companyID, _ := middleware.GetCompanyIDFromContext(c)
+	return h.periodService.ForCompany(companyID)
+}
+
+// GetAllPeriods handles GET /periods
+func (h *PeriodHandler) GetAllPeriods(c *gin.Context) {
+	periods, err := h.periods(c).GetAllPeriods()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, periods)
+}
+
+// GetPeriod handles GET /periods/:period
+func (h *PeriodHandler) GetPeriod(c *gin.Context) {
+	period, err := h.periods(c).GetPeriod(c.Param("period"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, period)
+}
+
+// LockPeriod handles POST /periods/:period/lock
+func (h *PeriodHandler) LockPeriod(c *gin.Context) {
+	h.changeStatus(c, h.periods(c).LockPeriod, "period locked successfully")
+}
+
+// ReopenPeriod handles POST /periods/:period/reopen
+func (h *PeriodHandler) ReopenPeriod(c *gin.Context) {
+	h.changeStatus(c, h.periods(c).ReopenPeriod, "period reopened successfully")
+}
+
+// ClosePeriod handles POST /periods/:period/close
+func (h *PeriodHandler) ClosePeriod(c *gin.Context) {
+	h.changeStatus(c, h.periods(c).ClosePeriod, "period closed successfully")
+}
+
+func (h *PeriodHandler) changeStatus(c *gin.Context, change func(period string, userID int) (*domain.Period, error), message string) {
+	userID, ok := middleware.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	period, err := change(c.Param("period"), userID)
+	if err != nil {
+		respondServiceError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
+		"period":  period,
+	})
+}
@@ -1,8 +1,12 @@
 package handlers
 
 import (
+	"cmd/api/internal/domain"
+	"cmd/api/internal/middleware"
 	"cmd/api/internal/service"
+	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
@@ -17,17 +21,60 @@ func NewReportHandler(reportService *service.ReportService) *ReportHandler {
 	}
 }
 
+// reports returns the ReportService for the company of the request
+func (h *ReportHandler) reports(c *gin.Context) *service.ReportService {
+	companyID, _ := middleware.GetCompanyIDFromContext(c)
+	return h.reportService.ForCompany(companyID)
+}
+
 // GetIncomeStatement handles GET /reports/income-statement
+// Use either from_date and to_date or fiscal_year_id. project_id and
+// cost_center_id limit the statement; group_by=project|cost_center returns
+// one statement per project or cost centre.
 func (h *ReportHandler) GetIncomeStatement(c *gin.Context) {
 	fromDate := c.Query("from_date")
 	toDate := c.Query("to_date")
 
+	if fiscalYearParam := c.Query("fiscal_year_id"); fiscalYearParam != "" {
+		fiscalYearID, err := strconv.Atoi(fiscalYearParam)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid fiscal year ID"})
+			return
+		}
+
+		fromDate, toDate, err = h.reports(c).FiscalYearDates(fiscalYearID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
 	if fromDate == "" || toDate == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "from_date and to_date query parameters are required"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from_date and to_date (or fiscal_year_id) query parameters are required"})
+		return
+	}
+
+	filter, err := parseDimensionFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if groupBy := c.Query("group_by"); groupBy != "" {
+		groups, err := h.reports(c).GetIncomeStatementByDimension(fromDate, toDate, filter, groupBy)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"group_by": groupBy,
+			"groups":   groups,
+		})
 		return
 	}
 
-	statement, err := h.reportService.GetIncomeStatement(fromDate, toDate)
+	statement, err := h.reports(c).GetIncomeStatement(fromDate, toDate, filter)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -35,3 +82,63 @@ func (h *ReportHandler) GetIncomeStatement(c *gin.Context) {
 
 	c.JSON(http.StatusOK, statement)
 }
+
+// parseDimensionFilter reads the optional project_id and cost_center_id
+// query parameters
+func parseDimensionFilter(c *gin.Context) (domain.DimensionFilter, error) {
+	var filter domain.DimensionFilter
+
+	if param := c.Query("project_id"); param != "" {
+		projectID, err := strconv.Atoi(param)
+		if err != nil {
+			return filter, errors.New("invalid project ID")
+		}
+		filter.ProjectID = &projectID
+	}
+
+	if param := c.Query("cost_center_id"); param != "" {
+		costCenterID, err := strconv.Atoi(param)
+		if err != nil {
+			return filter, errors.New("invalid cost centre ID")
+		}
+		filter.CostCenterID = &costCenterID
+	}
+
+	return filter, nil
+}
+
+// GetBalanceSheet handles GET /reports/balance-sheet?date=YYYY-MM-DD
+func (h *ReportHandler) GetBalanceSheet(c *gin.Context) {
+	date := c.Query("date")
+	if date == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "date query parameter is required"})
+		return
+	}
+
+	sheet, err := h.reports(c).GetBalanceSheet(date)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, sheet)
+}
+
+// GetTrialBalance handles GET /reports/trial-balance?from_date&to_date
+func (h *ReportHandler) GetTrialBalance(c *gin.Context) {
+	fromDate := c.Query("from_date")
+	toDate := c.Query("to_date")
+
+	if fromDate == "" || toDate == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from_date and to_date query parameters are required"})
+		return
+	}
+
+	trialBalance, err := h.reports(c).GetTrialBalance(fromDate, toDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, trialBalance)
+}
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"cmd/api/internal/middleware"
+	"cmd/api/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SearchHandler struct {
+	searchService *service.SearchService
+}
+
+func NewSearchHandler(searchService *service.SearchService) *SearchHandler {
+	return &SearchHandler{
+		searchService: searchService,
+	}
+}
+
+// Search handles GET /search?q=...&limit=20
+// It returns the vouchers matching q, best match first, with the matching
+// words marked in the snippets.
+func (h *SearchHandler) Search(c *gin.Context) {
+	limit, err := parseOptionalInt(c, "limit")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if limit == nil {
+		limit = new(int)
+	}
+
+	companyID, _ := middleware.GetCompanyIDFromContext(c)
+	result, err := h.searchService.ForCompany(companyID).Search(c.Query("q"), *limit)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
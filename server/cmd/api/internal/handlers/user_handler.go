@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"cmd/api/internal/domain"
+	"cmd/api/internal/middleware"
 	"cmd/api/internal/service"
 	"net/http"
 	"strconv"
@@ -19,6 +20,13 @@ func NewUserHandler(userService *service.UserService) *UserHandler {
 	}
 }
 
+// users returns the UserService for the company and user of the request
+func (h *UserHandler) users(c *gin.Context) *service.UserService {
+	companyID, _ := middleware.GetCompanyIDFromContext(c)
+	userID, _ := middleware.GetUserIDFromContext(c)
+	return h.userService.ForCompany(companyID).AsUser(userID)
+}
+
 // CreateUser handles POST /users
 func (h *UserHandler) CreateUser(c *gin.Context) {
 	var user domain.User
@@ -28,8 +36,8 @@ func (h *UserHandler) CreateUser(c *gin.Context) {
 		return
 	}
 
-	if err := h.userService.CreateUser(&user); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := h.users(c).CreateUser(&user); err != nil {
+		respondServiceError(c, err, http.StatusInternalServerError)
 		return
 	}
 
@@ -48,7 +56,7 @@ func (h *UserHandler) GetUserByID(c *gin.Context) {
 		return
 	}
 
-	user, err := h.userService.GetUserByID(userID)
+	user, err := h.users(c).GetVisibleUserByID(userID)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -61,7 +69,7 @@ func (h *UserHandler) GetUserByID(c *gin.Context) {
 func (h *UserHandler) GetUserByEmail(c *gin.Context) {
 	email := c.Param("email")
 
-	user, err := h.userService.GetUserByEmail(email)
+	user, err := h.users(c).GetVisibleUserByEmail(email)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -87,8 +95,8 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 
 	user.UserID = userID
 
-	if err := h.userService.UpdateUser(&user); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := h.users(c).UpdateUser(&user); err != nil {
+		respondServiceError(c, err, http.StatusInternalServerError)
 		return
 	}
 
@@ -107,8 +115,8 @@ func (h *UserHandler) DeleteUser(c *gin.Context) {
 		return
 	}
 
-	if err := h.userService.DeleteUser(userID); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := h.users(c).DeleteUser(userID); err != nil {
+		respondServiceError(c, err, http.StatusInternalServerError)
 		return
 	}
 
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"cmd/api/internal/middleware"
+	"cmd/api/internal/service"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type VATHandler struct {
+	vatService *service.VATService
+}
+
+func NewVATHandler(vatService *service.VATService) *VATHandler {
+	return &VATHandler{
+		vatService: vatService,
+	}
+}
+
+// vat returns the VATService for the company of the request
+func (h *VATHandler) vat(c *gin.Context) *service.VATService {
+	companyID, _ := middleware.GetCompanyIDFromContext(c)
+	return h.vatService.ForCompany(companyID)
+}
+
+// GetVATReturn handles GET /reports/vat-return?period=2025-01
+// The period can also be a quarter (2025-Q1) or a year (2025).
+func (h *VATHandler) GetVATReturn(c *gin.Context) {
+	period := c.Query("period")
+	if period == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "period query parameter is required"})
+		return
+	}
+
+	vatReturn, err := h.vat(c).GetVATReturn(period)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, vatReturn)
+}
+
+// ExportESKD handles GET /reports/vat-return/eskd?period=2025-01
+func (h *VATHandler) ExportESKD(c *gin.Context) {
+	period := c.Query("period")
+	if period == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "period query parameter is required"})
+		return
+	}
+
+	filename, data, err := h.vat(c).ExportESKD(period)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
+	c.Data(http.StatusOK, "application/xml; charset=ISO-8859-1", data)
+}
+
+// BookVATSettlement handles POST /reports/vat-return/settlement?period=2025-01
+func (h *VATHandler) BookVATSettlement(c *gin.Context) {
+	period := c.Query("period")
+	if period == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "period query parameter is required"})
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	vatReturn, voucher, err := h.vat(c).BookVATSettlement(period, userID)
+	if err != nil {
+		respondServiceError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":    "VAT settlement booked successfully",
+		"vat_return": vatReturn,
+		"voucher":    voucher,
+	})
+}
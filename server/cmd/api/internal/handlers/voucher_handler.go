@@ -2,9 +2,14 @@ package handlers
 
 import (
 	"cmd/api/internal/domain"
+	"cmd/api/internal/middleware"
 	"cmd/api/internal/service"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
@@ -19,7 +24,16 @@ func NewVoucherHandler(voucherService *service.VoucherService) *VoucherHandler {
 	}
 }
 
+// vouchers returns the VoucherService for the company and user of the
+// request
+func (h *VoucherHandler) vouchers(c *gin.Context) *service.VoucherService {
+	companyID, _ := middleware.GetCompanyIDFromContext(c)
+	userID, _ := middleware.GetUserIDFromContext(c)
+	return h.voucherService.ForCompany(companyID).AsUser(userID)
+}
+
 // CreateVoucher handles POST /vouchers
+// With ?vat=gross or ?vat=net the VAT lines are generated from the tax codes.
 func (h *VoucherHandler) CreateVoucher(c *gin.Context) {
 	var voucher domain.Voucher
 
@@ -28,14 +42,53 @@ func (h *VoucherHandler) CreateVoucher(c *gin.Context) {
 		return
 	}
 
-	if err := h.voucherService.CreateVoucher(&voucher); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if mode := c.Query("vat"); mode != "" {
+		lines, err := h.vouchers(c).ExpandVATLines(voucher.Lines, mode)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		voucher.Lines = lines
+	}
+
+	if err := h.vouchers(c).CreateVoucher(&voucher); err != nil {
+		respondServiceError(c, err, http.StatusInternalServerError)
 		return
 	}
 
 	c.JSON(http.StatusCreated, voucher)
 }
 
+// PreviewVATLines handles POST /vouchers/vat-preview?vat=gross|net
+// It returns the lines the voucher would get without saving anything.
+func (h *VoucherHandler) PreviewVATLines(c *gin.Context) {
+	var voucher domain.Voucher
+
+	if err := c.ShouldBindJSON(&voucher); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	lines, err := h.vouchers(c).ExpandVATLines(voucher.Lines, c.DefaultQuery("vat", service.VATModeGross))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var totalDebit, totalCredit domain.Amount
+	for _, line := range lines {
+		totalDebit = totalDebit.Add(line.DebitAmount)
+		totalCredit = totalCredit.Add(line.CreditAmount)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"lines":        lines,
+		"total_debit":  totalDebit,
+		"total_credit": totalCredit,
+		"balanced":     totalDebit == totalCredit,
+	})
+}
+
 // GetVoucherByID handles GET /vouchers/:id
 func (h *VoucherHandler) GetVoucherByID(c *gin.Context) {
 	idParam := c.Param("id")
@@ -45,7 +98,7 @@ func (h *VoucherHandler) GetVoucherByID(c *gin.Context) {
 		return
 	}
 
-	voucher, err := h.voucherService.GetVoucherByID(voucherID)
+	voucher, err := h.vouchers(c).GetVoucherByID(voucherID)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -55,21 +108,124 @@ func (h *VoucherHandler) GetVoucherByID(c *gin.Context) {
 }
 
 // GetAllVouchers handles GET /vouchers
+// It returns one page of vouchers. from_date and to_date (YYYY-MM-DD), period,
+// min_amount and max_amount, account_no, created_by, corrected (true/false),
+// status and q (text in description or reference) filter; sort
+// (number, date, amount or created_at) with order (asc/desc) sorts; limit and
+// offset page.
 func (h *VoucherHandler) GetAllVouchers(c *gin.Context) {
-	vouchers, err := h.voucherService.GetAllVouchers()
+	filter, err := parseVoucherFilter(c)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, vouchers)
+	page, err := h.vouchers(c).ListVouchers(filter)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// parseVoucherFilter reads the filter, sort and page parameters of a voucher
+// listing
+func parseVoucherFilter(c *gin.Context) (domain.VoucherFilter, error) {
+	filter := domain.VoucherFilter{
+		Period: c.Query("period"),
+		Status: c.Query("status"),
+		Text:   strings.TrimSpace(c.Query("q")),
+		Sort:   c.Query("sort"),
+	}
+
+	var err error
+	if filter.From, err = parseOptionalDate(c, "from_date"); err != nil {
+		return filter, err
+	}
+	if filter.To, err = parseOptionalDate(c, "to_date"); err != nil {
+		return filter, err
+	}
+
+	if filter.MinAmount, err = parseOptionalAmount(c, "min_amount"); err != nil {
+		return filter, err
+	}
+	if filter.MaxAmount, err = parseOptionalAmount(c, "max_amount"); err != nil {
+		return filter, err
+	}
+	if filter.AccountNo, err = parseOptionalInt(c, "account_no"); err != nil {
+		return filter, err
+	}
+	if filter.CreatedBy, err = parseOptionalInt(c, "created_by"); err != nil {
+		return filter, err
+	}
+
+	if param := c.Query("corrected"); param != "" {
+		corrected, err := strconv.ParseBool(param)
+		if err != nil {
+			return filter, errors.New("corrected must be true or false")
+		}
+		filter.Corrected = &corrected
+	}
+
+	switch c.DefaultQuery("order", "desc") {
+	case "asc":
+		filter.Ascending = true
+	case "desc":
+	default:
+		return filter, errors.New("order must be 'asc' or 'desc'")
+	}
+
+	if limit, err := parseOptionalInt(c, "limit"); err != nil {
+		return filter, err
+	} else if limit != nil {
+		filter.Limit = *limit
+	}
+	if offset, err := parseOptionalInt(c, "offset"); err != nil {
+		return filter, err
+	} else if offset != nil {
+		filter.Offset = *offset
+	}
+
+	return filter, nil
+}
+
+// parseOptionalInt reads an integer query parameter; a missing one gives nil
+func parseOptionalInt(c *gin.Context, name string) (*int, error) {
+	param := c.Query(name)
+	if param == "" {
+		return nil, nil
+	}
+
+	value, err := strconv.Atoi(param)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s", name)
+	}
+
+	return &value, nil
+}
+
+// parseOptionalAmount reads an amount query parameter such as "1250.50"; a
+// missing one gives nil
+func parseOptionalAmount(c *gin.Context, name string) (*domain.Amount, error) {
+	param := c.Query(name)
+	if param == "" {
+		return nil, nil
+	}
+
+	amount, err := domain.ParseAmount(param)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s", name)
+	}
+
+	return &amount, nil
 }
 
 // GetVouchersByPeriod handles GET /vouchers/period/:period
 func (h *VoucherHandler) GetVouchersByPeriod(c *gin.Context) {
 	period := c.Param("period")
 
-	vouchers, err := h.voucherService.GetVouchersByPeriod(period)
+	vouchers, err := h.vouchers(c).GetVouchersByPeriod(period)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -87,7 +243,7 @@ func (h *VoucherHandler) GetVouchersByCreatedBy(c *gin.Context) {
 		return
 	}
 
-	vouchers, err := h.voucherService.GetVouchersByCreatedBy(userID)
+	vouchers, err := h.vouchers(c).GetVouchersByCreatedBy(userID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -98,7 +254,7 @@ func (h *VoucherHandler) GetVouchersByCreatedBy(c *gin.Context) {
 
 // GetAllPeriods handles GET /vouchers/periods
 func (h *VoucherHandler) GetAllPeriods(c *gin.Context) {
-	periods, err := h.voucherService.GetAllPeriods()
+	periods, err := h.vouchers(c).GetAllPeriods()
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -124,8 +280,8 @@ func (h *VoucherHandler) UpdateVoucher(c *gin.Context) {
 
 	voucher.VoucherID = voucherID
 
-	if err := h.voucherService.UpdateVoucher(&voucher); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := h.vouchers(c).UpdateVoucher(&voucher); err != nil {
+		respondServiceError(c, err, http.StatusInternalServerError)
 		return
 	}
 
@@ -144,14 +300,33 @@ func (h *VoucherHandler) DeleteVoucher(c *gin.Context) {
 		return
 	}
 
-	if err := h.voucherService.DeleteVoucher(voucherID); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := h.vouchers(c).DeleteVoucher(voucherID); err != nil {
+		respondServiceError(c, err, http.StatusInternalServerError)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "voucher and associated line items deleted successfully"})
 }
 
+// BookVoucher handles POST /vouchers/:id/book
+// It books a draft, giving it its final number.
+func (h *VoucherHandler) BookVoucher(c *gin.Context) {
+	idParam := c.Param("id")
+	voucherID, err := strconv.Atoi(idParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid voucher ID"})
+		return
+	}
+
+	voucher, err := h.vouchers(c).BookVoucher(voucherID)
+	if err != nil {
+		respondServiceError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	c.JSON(http.StatusOK, voucher)
+}
+
 // ValidateVoucherBalance handles GET /vouchers/:id/validate
 func (h *VoucherHandler) ValidateVoucherBalance(c *gin.Context) {
 	idParam := c.Param("id")
@@ -161,7 +336,7 @@ func (h *VoucherHandler) ValidateVoucherBalance(c *gin.Context) {
 		return
 	}
 
-	balanced, err := h.voucherService.ValidateVoucherBalance(voucherID)
+	balanced, err := h.vouchers(c).ValidateVoucherBalance(voucherID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -174,7 +349,22 @@ func (h *VoucherHandler) ValidateVoucherBalance(c *gin.Context) {
 	})
 }
 
-// CreateCorrectionVoucher handles POST /vouchers/:id/correct
+// VerifyChain handles GET /vouchers/verify-chain
+// It recomputes the hash chain of the company's booked vouchers and reports
+// the first broken link, if any.
+func (h *VoucherHandler) VerifyChain(c *gin.Context) {
+	verification, err := h.vouchers(c).VerifyChain()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, verification)
+}
+
+// CreateCorrectionVoucher handles POST /vouchers/:id/correct. The reversal
+// is dated "date" (YYYY-MM-DD) from the optional body, or today, and made
+// by the user of the request.
 func (h *VoucherHandler) CreateCorrectionVoucher(c *gin.Context) {
 	idParam := c.Param("id")
 	voucherID, err := strconv.Atoi(idParam)
@@ -183,25 +373,25 @@ func (h *VoucherHandler) CreateCorrectionVoucher(c *gin.Context) {
 		return
 	}
 
-	// Get user ID from request body
 	var req struct {
-		UserID int `json:"user_id" binding:"required"`
+		Date string `json:"date"`
 	}
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "user_id is required"})
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	correctionVoucher, err := h.voucherService.CreateCorrectionVoucher(voucherID, req.UserID)
+	correctionVoucher, err := h.vouchers(c).CreateCorrectionVoucher(voucherID, req.Date)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondServiceError(c, err, http.StatusBadRequest)
 		return
 	}
 
 	c.JSON(http.StatusCreated, correctionVoucher)
 }
 
-// CreateCorrectionWithChanges handles POST /vouchers/:id/correct-with-changes
+// CreateCorrectionWithChanges handles POST /vouchers/:id/correct-with-changes.
+// The correction is made by the user of the request.
 func (h *VoucherHandler) CreateCorrectionWithChanges(c *gin.Context) {
 	idParam := c.Param("id")
 	voucherID, err := strconv.Atoi(idParam)
@@ -212,20 +402,20 @@ func (h *VoucherHandler) CreateCorrectionWithChanges(c *gin.Context) {
 
 	// Get request body with new voucher data
 	var req struct {
-		UserID int `json:"user_id" binding:"required"`
 		NewVoucher struct {
-			Date        string  `json:"date"`
-			Description string  `json:"description"`
-			Reference   string  `json:"reference"`
-			TotalAmount float64 `json:"total_amount"`
-			Period      string  `json:"period"`
-			CreatedBy   int     `json:"created_by"`
+			Date        string        `json:"date"`
+			Description string        `json:"description"`
+			Reference   string        `json:"reference"`
+			TotalAmount domain.Amount `json:"total_amount"`
+			Period      string        `json:"period"`
 		} `json:"new_voucher" binding:"required"`
 		NewLineItems []struct {
-			AccountNo    int     `json:"account_no"`
-			DebitAmount  float64 `json:"debit_amount"`
-			CreditAmount float64 `json:"credit_amount"`
-			TaxCode      int     `json:"tax_code"`
+			AccountNo    int           `json:"account_no"`
+			DebitAmount  domain.Amount `json:"debit_amount"`
+			CreditAmount domain.Amount `json:"credit_amount"`
+			TaxCode      int           `json:"tax_code"`
+			ProjectID    *int          `json:"project_id"`
+			CostCenterID *int          `json:"cost_center_id"`
 		} `json:"new_line_items" binding:"required"`
 	}
 
@@ -242,12 +432,13 @@ func (h *VoucherHandler) CreateCorrectionWithChanges(c *gin.Context) {
 			DebitAmount:  item.DebitAmount,
 			CreditAmount: item.CreditAmount,
 			TaxCode:      item.TaxCode,
+			ProjectID:    item.ProjectID,
+			CostCenterID: item.CostCenterID,
 		}
 	}
 
-	correctionVoucher, err := h.voucherService.CreateCorrectionWithChanges(
+	correctionVoucher, err := h.vouchers(c).CreateCorrectionWithChanges(
 		voucherID,
-		req.UserID,
 		req.NewVoucher.Date,
 		req.NewVoucher.Description,
 		req.NewVoucher.Reference,
@@ -255,7 +446,7 @@ func (h *VoucherHandler) CreateCorrectionWithChanges(c *gin.Context) {
 		newLineItems,
 	)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondServiceError(c, err, http.StatusBadRequest)
 		return
 	}
 
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"cmd/api/internal/domain"
+	"cmd/api/internal/middleware"
+	"cmd/api/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type VoucherSeriesHandler struct {
+	seriesService *service.VoucherSeriesService
+}
+
+func NewVoucherSeriesHandler(seriesService *service.VoucherSeriesService) *VoucherSeriesHandler {
+	return &VoucherSeriesHandler{
+		seriesService: seriesService,
+	}
+}
+
+// series returns the VoucherSeriesService for the company and user of the
+// request
+func (h *VoucherSeriesHandler) series(c *gin.Context) *service.VoucherSeriesService {
+	companyID, _ := middleware.GetCompanyIDFromContext(c)
+	userID, _ := middleware.GetUserIDFromContext(c)
+	return h.seriesService.ForCompany(companyID).AsUser(userID)
+}
+
+// CreateSeries handles POST /voucher-series
+func (h *VoucherSeriesHandler) CreateSeries(c *gin.Context) {
+	var series domain.VoucherSeries
+	if err := c.ShouldBindJSON(&series); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.series(c).CreateSeries(&series); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "voucher series created successfully",
+		"series":  series,
+	})
+}
+
+// GetAllSeries handles GET /voucher-series
+func (h *VoucherSeriesHandler) GetAllSeries(c *gin.Context) {
+	allSeries, err := h.series(c).GetAllSeries()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, allSeries)
+}
+
+// UpdateSeries handles PUT /voucher-series/:series
+func (h *VoucherSeriesHandler) UpdateSeries(c *gin.Context) {
+	var series domain.VoucherSeries
+	if err := c.ShouldBindJSON(&series); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	series.Series = c.Param("series")
+
+	if err := h.series(c).UpdateSeries(&series); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "voucher series updated successfully",
+		"series":  series,
+	})
+}
+
+// DeleteSeries handles DELETE /voucher-series/:series
+func (h *VoucherSeriesHandler) DeleteSeries(c *gin.Context) {
+	if err := h.series(c).DeleteSeries(c.Param("series")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "voucher series deleted successfully"})
+}
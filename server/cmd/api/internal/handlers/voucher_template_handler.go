@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"cmd/api/internal/domain"
+	"cmd/api/internal/middleware"
+	"cmd/api/internal/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type VoucherTemplateHandler struct {
+	voucherTemplateService *service.VoucherTemplateService
+}
+
+func NewVoucherTemplateHandler(voucherTemplateService *service.VoucherTemplateService) *VoucherTemplateHandler {
+	return &VoucherTemplateHandler{
+		voucherTemplateService: voucherTemplateService,
+	}
+}
+
+// templates returns the VoucherTemplateService for the company and user of
+// the request
+func (h *VoucherTemplateHandler) templates(c *gin.Context) *service.VoucherTemplateService {
+	companyID, _ := middleware.GetCompanyIDFromContext(c)
+	userID, _ := middleware.GetUserIDFromContext(c)
+	return h.voucherTemplateService.ForCompany(companyID).AsUser(userID)
+}
+
+// CreateTemplate handles POST /voucher-templates
+func (h *VoucherTemplateHandler) CreateTemplate(c *gin.Context) {
+	var template domain.VoucherTemplate
+	if err := c.ShouldBindJSON(&template); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.templates(c).CreateTemplate(&template); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":  "voucher template created successfully",
+		"template": template,
+	})
+}
+
+// GetTemplateByID handles GET /voucher-templates/:id
+func (h *VoucherTemplateHandler) GetTemplateByID(c *gin.Context) {
+	templateID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid voucher template ID"})
+		return
+	}
+
+	template, err := h.templates(c).GetTemplateByID(templateID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, template)
+}
+
+// GetAllTemplates handles GET /voucher-templates
+func (h *VoucherTemplateHandler) GetAllTemplates(c *gin.Context) {
+	templates, err := h.templates(c).GetAllTemplates()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, templates)
+}
+
+// UpdateTemplate handles PUT /voucher-templates/:id
+func (h *VoucherTemplateHandler) UpdateTemplate(c *gin.Context) {
+	templateID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid voucher template ID"})
+		return
+	}
+
+	var template domain.VoucherTemplate
+	if err := c.ShouldBindJSON(&template); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	template.TemplateID = templateID
+
+	if err := h.templates(c).UpdateTemplate(&template); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "voucher template updated successfully",
+		"template": template,
+	})
+}
+
+// DeleteTemplate handles DELETE /voucher-templates/:id
+func (h *VoucherTemplateHandler) DeleteTemplate(c *gin.Context) {
+	templateID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid voucher template ID"})
+		return
+	}
+
+	if err := h.templates(c).DeleteTemplate(templateID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "voucher template deleted successfully"})
+}
+
+// CreateVoucherFromTemplate handles POST /vouchers/from-template/:id
+func (h *VoucherTemplateHandler) CreateVoucherFromTemplate(c *gin.Context) {
+	templateID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid voucher template ID"})
+		return
+	}
+
+	var req domain.TemplateVoucherRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	voucher, err := h.templates(c).CreateVoucherFromTemplate(templateID, &req)
+	if err != nil {
+		respondServiceError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	c.JSON(http.StatusCreated, voucher)
+}
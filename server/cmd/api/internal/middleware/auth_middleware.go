@@ -30,6 +30,7 @@ func AuthMiddleware(jwtManager *auth.JWTManager) gin.HandlerFunc {
 		c.Set("userID", claims.UserID)
 		c.Set("email", claims.Email)
 		c.Set("role", claims.Role)
+		c.Set("companyID", claims.CompanyID)
 
 		c.Next()
 	}
@@ -64,6 +65,21 @@ func RequireRole(requiredRoles ...string) gin.HandlerFunc {
 	}
 }
 
+// RequireCompany creates a middleware that refuses requests without an
+// active company, e.g. from users that are not a member of any company yet
+func RequireCompany() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		companyID, ok := GetCompanyIDFromContext(c)
+		if !ok || companyID <= 0 {
+			c.JSON(http.StatusForbidden, gin.H{"error": "no active company"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
 // GetUserIDFromContext retrieves the user ID from the Gin context
 func GetUserIDFromContext(c *gin.Context) (int, bool) {
 	userID, exists := c.Get("userID")
@@ -81,3 +97,12 @@ func GetUserRoleFromContext(c *gin.Context) (string, bool) {
 	}
 	return role.(string), true
 }
+
+// GetCompanyIDFromContext retrieves the active company ID from the Gin context
+func GetCompanyIDFromContext(c *gin.Context) (int, bool) {
+	companyID, exists := c.Get("companyID")
+	if !exists {
+		return 0, false
+	}
+	return companyID.(int), true
+}
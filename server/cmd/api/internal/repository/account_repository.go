@@ -13,21 +13,35 @@ type AccountRepository interface {
 	GetAccountsByGroup(accountGroup int) ([]*domain.Account, error)
 	UpdateAccount(account *domain.Account) error
 	DeleteAccount(accountNo int) error
-	GetLedger(accountNo int, period string) ([]*domain.LedgerEntry, error)
+	GetLedger(accountNo int, period string, filter domain.DimensionFilter) ([]*domain.LedgerEntry, error)
+	WithTx(tx *sql.Tx) AccountRepository
+	ForCompany(companyID int) AccountRepository
 }
 
 type accountRepository struct {
-	db *sql.DB
+	db        DBTX
+	companyID int
 }
 
 func NewAccountRepository(db *sql.DB) AccountRepository {
 	return &accountRepository{db: db}
 }
 
+// WithTx returns a copy of the repository that runs its queries in tx
+func (r *accountRepository) WithTx(tx *sql.Tx) AccountRepository {
+	return &accountRepository{db: tx, companyID: r.companyID}
+}
+
+// ForCompany returns a copy of the repository that only sees the chart of
+// accounts of companyID
+func (r *accountRepository) ForCompany(companyID int) AccountRepository {
+	return &accountRepository{db: r.db, companyID: companyID}
+}
+
 func (r *accountRepository) CreateAccount(account *domain.Account) error {
 	query := `
-		INSERT INTO accounts (account_no, account_name, account_group, tax_standard, type, standard_side)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO accounts (account_no, account_name, account_group, tax_standard, type, standard_side, company_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
 	`
 	_, err := r.db.Exec(query,
 		account.AccountNo,
@@ -36,6 +50,7 @@ func (r *accountRepository) CreateAccount(account *domain.Account) error {
 		account.TaxStandard,
 		account.Type,
 		account.StandardSide,
+		r.companyID,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to create account: %w", err)
@@ -48,10 +63,10 @@ func (r *accountRepository) GetAccountByNo(accountNo int) (*domain.Account, erro
 	query := `
 		SELECT account_no, account_name, account_group, tax_standard, type, standard_side
 		FROM accounts
-		WHERE account_no = $1
+		WHERE account_no = $1 AND company_id = $2
 	`
 	account := &domain.Account{}
-	err := r.db.QueryRow(query, accountNo).Scan(
+	err := r.db.QueryRow(query, accountNo, r.companyID).Scan(
 		&account.AccountNo,
 		&account.AccountName,
 		&account.AccountGroup,
@@ -73,9 +88,10 @@ func (r *accountRepository) GetAllAccounts() ([]*domain.Account, error) {
 	query := `
 		SELECT account_no, account_name, account_group, tax_standard, type, standard_side
 		FROM accounts
+		WHERE company_id = $1
 		ORDER BY account_no
 	`
-	rows, err := r.db.Query(query)
+	rows, err := r.db.Query(query, r.companyID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get accounts: %w", err)
 	}
@@ -105,10 +121,10 @@ func (r *accountRepository) GetAccountsByGroup(accountGroup int) ([]*domain.Acco
 	query := `
 		SELECT account_no, account_name, account_group, tax_standard, type, standard_side
 		FROM accounts
-		WHERE account_group = $1
+		WHERE account_group = $1 AND company_id = $2
 		ORDER BY account_no
 	`
-	rows, err := r.db.Query(query, accountGroup)
+	rows, err := r.db.Query(query, accountGroup, r.companyID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get accounts by group: %w", err)
 	}
@@ -138,7 +154,7 @@ func (r *accountRepository) UpdateAccount(account *domain.Account) error {
 	query := `
 		UPDATE accounts
 		SET account_name = $1, account_group = $2, tax_standard = $3, type = $4, standard_side = $5
-		WHERE account_no = $1
+		WHERE account_no = $6 AND company_id = $7
 	`
 	_, err := r.db.Exec(query,
 		account.AccountName,
@@ -147,6 +163,7 @@ func (r *accountRepository) UpdateAccount(account *domain.Account) error {
 		account.Type,
 		account.StandardSide,
 		account.AccountNo,
+		r.companyID,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to update account: %w", err)
@@ -156,8 +173,8 @@ func (r *accountRepository) UpdateAccount(account *domain.Account) error {
 }
 
 func (r *accountRepository) DeleteAccount(accountNo int) error {
-	query := `DELETE FROM accounts WHERE account_no = $1`
-	_, err := r.db.Exec(query, accountNo)
+	query := `DELETE FROM accounts WHERE account_no = $1 AND company_id = $2`
+	_, err := r.db.Exec(query, accountNo, r.companyID)
 	if err != nil {
 		return fmt.Errorf("failed to delete account: %w", err)
 	}
@@ -165,50 +182,60 @@ func (r *accountRepository) DeleteAccount(accountNo int) error {
 	return nil
 }
 
-func (r *accountRepository) GetLedger(accountNo int, period string) ([]*domain.LedgerEntry, error) {
+func (r *accountRepository) GetLedger(accountNo int, period string, filter domain.DimensionFilter) ([]*domain.LedgerEntry, error) {
 	query := `
 		SELECT
 			v.date,
 			v.voucher_id,
+			v.series,
 			v.voucher_number,
 			v.description,
 			v.reference,
 			l.debit_amount,
-			l.credit_amount
+			l.credit_amount,
+			l.project_id,
+			l.cost_center_id
 		FROM line_items l
 		INNER JOIN vouchers v ON l.voucher_id = v.voucher_id
 		WHERE l.account_no = $1
 			AND ($2 = '' OR v.period = $2)
+			AND ($3::int IS NULL OR l.project_id = $3)
+			AND ($4::int IS NULL OR l.cost_center_id = $4)
+			AND l.company_id = $5
 			AND v.corrected_by_voucher_id IS NULL
-		ORDER BY v.date ASC, v.voucher_number ASC
+			AND v.status = 'booked'
+		ORDER BY v.date ASC, v.series ASC, v.voucher_number ASC
 	`
 
-	rows, err := r.db.Query(query, accountNo, period)
+	rows, err := r.db.Query(query, accountNo, period, filter.ProjectID, filter.CostCenterID, r.companyID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get ledger entries: %w", err)
 	}
 	defer rows.Close()
 
 	entries := make([]*domain.LedgerEntry, 0)
-	var runningBalance float64 = 0
+	var runningBalance domain.Amount
 
 	for rows.Next() {
 		entry := &domain.LedgerEntry{}
 		err := rows.Scan(
 			&entry.Date.Time,
 			&entry.VoucherID,
+			&entry.Series,
 			&entry.VoucherNumber,
 			&entry.Description,
 			&entry.Reference,
 			&entry.DebitAmount,
 			&entry.CreditAmount,
+			&entry.ProjectID,
+			&entry.CostCenterID,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan ledger entry: %w", err)
 		}
 
 		// Calculate running balance (Debit increases, Credit decreases)
-		runningBalance += entry.DebitAmount - entry.CreditAmount
+		runningBalance = runningBalance.Add(entry.DebitAmount).Sub(entry.CreditAmount)
 		entry.Balance = runningBalance
 
 		entries = append(entries, entry)
@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"cmd/api/internal/domain"
+	"database/sql"
+	"fmt"
+)
+
+type AttachmentRepository interface {
+	CreateAttachment(attachment *domain.Attachment) (bool, error)
+	GetAttachmentByID(voucherID, attachmentID int) (*domain.Attachment, error)
+	GetAttachmentsByVoucherID(voucherID int) ([]*domain.Attachment, error)
+	DeleteAttachment(voucherID, attachmentID int) error
+	WithTx(tx *sql.Tx) AttachmentRepository
+	ForCompany(companyID int) AttachmentRepository
+}
+
+type attachmentRepository struct {
+	db        DBTX
+	companyID int
+}
+
+func NewAttachmentRepository(db *sql.DB) AttachmentRepository {
+	return &attachmentRepository{db: db}
+}
+
+// WithTx returns a copy of the repository that runs its queries in tx
+func (r *attachmentRepository) WithTx(tx *sql.Tx) AttachmentRepository {
+	return &attachmentRepository{db: tx, companyID: r.companyID}
+}
+
+// ForCompany returns a copy of the repository that only sees the
+// attachments of companyID
+func (r *attachmentRepository) ForCompany(companyID int) AttachmentRepository {
+	return &attachmentRepository{db: r.db, companyID: companyID}
+}
+
+// CreateAttachment inserts an attachment. It returns false, and leaves the
+// attachment without an ID, when the voucher already has a file with the
+// same checksum.
+func (r *attachmentRepository) CreateAttachment(attachment *domain.Attachment) (bool, error) {
+	query := `
+		INSERT INTO voucher_attachments (company_id, voucher_id, file_name, content_type, size_bytes, checksum, storage_key, uploaded_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (voucher_id, checksum) DO NOTHING
+		RETURNING attachment_id, created_at
+	`
+	err := r.db.QueryRow(query,
+		r.companyID,
+		attachment.VoucherID,
+		attachment.FileName,
+		attachment.ContentType,
+		attachment.Size,
+		attachment.Checksum,
+		attachment.StorageKey,
+		attachment.UploadedBy,
+	).Scan(&attachment.AttachmentID, &attachment.CreatedAt)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to create attachment: %w", err)
+	}
+
+	return true, nil
+}
+
+func (r *attachmentRepository) GetAttachmentByID(voucherID, attachmentID int) (*domain.Attachment, error) {
+	query := `
+		SELECT attachment_id, voucher_id, file_name, content_type, size_bytes, checksum, storage_key, uploaded_by, created_at
+		FROM voucher_attachments
+		WHERE attachment_id = $1 AND voucher_id = $2 AND company_id = $3
+	`
+	attachment := &domain.Attachment{}
+	err := r.db.QueryRow(query, attachmentID, voucherID, r.companyID).Scan(
+		&attachment.AttachmentID,
+		&attachment.VoucherID,
+		&attachment.FileName,
+		&attachment.ContentType,
+		&attachment.Size,
+		&attachment.Checksum,
+		&attachment.StorageKey,
+		&attachment.UploadedBy,
+		&attachment.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("attachment not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attachment: %w", err)
+	}
+
+	return attachment, nil
+}
+
+// GetAttachmentsByVoucherID returns the attachments of a voucher in the
+// order they were uploaded
+func (r *attachmentRepository) GetAttachmentsByVoucherID(voucherID int) ([]*domain.Attachment, error) {
+	query := `
+		SELECT attachment_id, voucher_id, file_name, content_type, size_bytes, checksum, storage_key, uploaded_by, created_at
+		FROM voucher_attachments
+		WHERE voucher_id = $1 AND company_id = $2
+		ORDER BY attachment_id
+	`
+	rows, err := r.db.Query(query, voucherID, r.companyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attachments: %w", err)
+	}
+	defer rows.Close()
+
+	attachments := make([]*domain.Attachment, 0)
+	for rows.Next() {
+		attachment := &domain.Attachment{}
+		err := rows.Scan(
+			&attachment.AttachmentID,
+			&attachment.VoucherID,
+			&attachment.FileName,
+			&attachment.ContentType,
+			&attachment.Size,
+			&attachment.Checksum,
+			&attachment.StorageKey,
+			&attachment.UploadedBy,
+			&attachment.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan attachment: %w", err)
+		}
+		attachments = append(attachments, attachment)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating attachments: %w", err)
+	}
+
+	return attachments, nil
+}
+
+func (r *attachmentRepository) DeleteAttachment(voucherID, attachmentID int) error {
+	result, err := r.db.Exec(
+		`DELETE FROM voucher_attachments WHERE attachment_id = $1 AND voucher_id = $2 AND company_id = $3`,
+		attachmentID, voucherID, r.companyID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to delete attachment: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("attachment not found")
+	}
+
+	return nil
+}
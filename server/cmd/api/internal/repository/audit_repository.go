@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"cmd/api/internal/domain"
+	"database/sql"
+	"fmt"
+)
+
+// AuditRepository appends to and reads the audit trail. There is no way to
+// change or remove an entry.
+type AuditRepository interface {
+	CreateEntry(entry *domain.AuditEntry) error
+	GetEntries(filter domain.AuditFilter) ([]*domain.AuditEntry, error)
+	WithTx(tx *sql.Tx) AuditRepository
+	ForCompany(companyID int) AuditRepository
+}
+
+type auditRepository struct {
+	db        DBTX
+	companyID int
+}
+
+func NewAuditRepository(db *sql.DB) AuditRepository {
+	return &auditRepository{db: db}
+}
+
+// WithTx returns a copy of the repository that runs its queries in tx
+func (r *auditRepository) WithTx(tx *sql.Tx) AuditRepository {
+	return &auditRepository{db: tx, companyID: r.companyID}
+}
+
+// ForCompany returns a copy of the repository that writes and reads the
+// audit trail of companyID. Without a company, entries are written for
+// changes that belong to no company, such as a user registering; they are
+// in no company's audit trail.
+func (r *auditRepository) ForCompany(companyID int) AuditRepository {
+	return &auditRepository{db: r.db, companyID: companyID}
+}
+
+func (r *auditRepository) CreateEntry(entry *domain.AuditEntry) error {
+	query := `
+		INSERT INTO audit_log (company_id, user_id, entity, entity_id, action, before_data, after_data)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING audit_id, created_at
+	`
+	err := r.db.QueryRow(query,
+		r.nullableCompanyID(),
+		entry.UserID,
+		entry.Entity,
+		entry.EntityID,
+		entry.Action,
+		nullableJSON(entry.Before),
+		nullableJSON(entry.After),
+	).Scan(&entry.AuditID, &entry.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to write audit entry: %w", err)
+	}
+
+	return nil
+}
+
+// GetEntries returns the audit trail of the company, including the changes
+// to users made by its members while working in it, newest first
+func (r *auditRepository) GetEntries(filter domain.AuditFilter) ([]*domain.AuditEntry, error) {
+	query := `
+		SELECT audit_id, user_id, entity, entity_id, action, before_data, after_data, created_at
+		FROM audit_log
+		WHERE company_id = $1
+			AND ($2 = '' OR entity = $2)
+			AND ($3 = '' OR entity_id = $3)
+			AND ($4::int IS NULL OR user_id = $4)
+			AND ($5::date IS NULL OR created_at >= $5::date)
+			AND ($6::date IS NULL OR created_at < $6::date + 1)
+		ORDER BY created_at DESC, audit_id DESC
+	`
+	rows, err := r.db.Query(query, r.companyID, filter.Entity, filter.EntityID, filter.UserID, filter.From, filter.To)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get audit entries: %w", err)
+	}
+	defer rows.Close()
+
+	entries := make([]*domain.AuditEntry, 0)
+	for rows.Next() {
+		entry := &domain.AuditEntry{}
+		var before, after []byte
+		err := rows.Scan(
+			&entry.AuditID,
+			&entry.UserID,
+			&entry.Entity,
+			&entry.EntityID,
+			&entry.Action,
+			&before,
+			&after,
+			&entry.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan audit entry: %w", err)
+		}
+		entry.Before = before
+		entry.After = after
+		entries = append(entries, entry)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating audit entries: %w", err)
+	}
+
+	return entries, nil
+}
+
+func (r *auditRepository) nullableCompanyID() interface{} {
+	if r.companyID <= 0 {
+		return nil
+	}
+	return r.companyID
+}
+
+// nullableJSON passes an empty JSON document as NULL
+func nullableJSON(data []byte) interface{} {
+	if len(data) == 0 {
+		return nil
+	}
+	return string(data)
+}
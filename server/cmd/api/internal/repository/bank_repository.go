@@ -0,0 +1,293 @@
+package repository
+
+import (
+	"cmd/api/internal/domain"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+type BankRepository interface {
+	CreateTransaction(transaction *domain.BankTransaction) (bool, error)
+	GetTransactionByID(transactionID int) (*domain.BankTransaction, error)
+	GetTransactionByIDForUpdate(transactionID int) (*domain.BankTransaction, error)
+	GetTransactions(filter domain.BankTransactionFilter) ([]*domain.BankTransaction, error)
+	SetMatch(transactionID int, lineID *int, method string, userID int) error
+	GetLedgerLineByID(accountNo, lineID int) (*domain.BankLedgerLine, error)
+	IsLineMatched(lineID int) (bool, error)
+	GetUnmatchedLedgerLines(accountNo int, from, to time.Time) ([]*domain.BankLedgerLine, error)
+	GetLedgerTotal(accountNo int, from, to time.Time) (domain.Amount, error)
+	WithTx(tx *sql.Tx) BankRepository
+	ForCompany(companyID int) BankRepository
+}
+
+type bankRepository struct {
+	db        DBTX
+	companyID int
+}
+
+func NewBankRepository(db *sql.DB) BankRepository {
+	return &bankRepository{db: db}
+}
+
+// WithTx returns a copy of the repository that runs its queries in tx
+func (r *bankRepository) WithTx(tx *sql.Tx) BankRepository {
+	return &bankRepository{db: tx, companyID: r.companyID}
+}
+
+// ForCompany returns a copy of the repository that only sees the bank
+// transactions and books of companyID
+func (r *bankRepository) ForCompany(companyID int) BankRepository {
+	return &bankRepository{db: r.db, companyID: companyID}
+}
+
+const bankTransactionColumns = `transaction_id, account_no, booking_date, value_date, amount, currency, text, reference,
+	counterparty, bank_reference, fingerprint, matched_line_id, COALESCE(match_method, ''), matched_by, matched_at,
+	imported_by, created_at`
+
+func scanBankTransaction(row rowScanner) (*domain.BankTransaction, error) {
+	transaction := &domain.BankTransaction{}
+	err := row.Scan(
+		&transaction.TransactionID,
+		&transaction.AccountNo,
+		&transaction.BookingDate,
+		&transaction.ValueDate,
+		&transaction.Amount,
+		&transaction.Currency,
+		&transaction.Text,
+		&transaction.Reference,
+		&transaction.Counterparty,
+		&transaction.BankReference,
+		&transaction.Fingerprint,
+		&transaction.MatchedLineID,
+		&transaction.MatchMethod,
+		&transaction.MatchedBy,
+		&transaction.MatchedAt,
+		&transaction.ImportedBy,
+		&transaction.CreatedAt,
+	)
+	return transaction, err
+}
+
+// CreateTransaction inserts a bank transaction. It returns false, and
+// leaves the transaction without an ID, when one with the same fingerprint
+// has already been imported to the account.
+func (r *bankRepository) CreateTransaction(transaction *domain.BankTransaction) (bool, error) {
+	query := `
+		INSERT INTO bank_transactions (company_id, account_no, booking_date, value_date, amount, currency, text,
+			reference, counterparty, bank_reference, fingerprint, imported_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		ON CONFLICT (company_id, account_no, fingerprint) DO NOTHING
+		RETURNING transaction_id, created_at
+	`
+	err := r.db.QueryRow(query,
+		r.companyID,
+		transaction.AccountNo,
+		transaction.BookingDate,
+		transaction.ValueDate,
+		transaction.Amount,
+		transaction.Currency,
+		transaction.Text,
+		transaction.Reference,
+		transaction.Counterparty,
+		transaction.BankReference,
+		transaction.Fingerprint,
+		transaction.ImportedBy,
+	).Scan(&transaction.TransactionID, &transaction.CreatedAt)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to create bank transaction: %w", err)
+	}
+
+	return true, nil
+}
+
+func (r *bankRepository) GetTransactionByID(transactionID int) (*domain.BankTransaction, error) {
+	return r.getTransaction(transactionID, "")
+}
+
+// GetTransactionByIDForUpdate is GetTransactionByID with the row locked
+// until the transaction ends, so two users cannot match it at once
+func (r *bankRepository) GetTransactionByIDForUpdate(transactionID int) (*domain.BankTransaction, error) {
+	return r.getTransaction(transactionID, "FOR UPDATE")
+}
+
+func (r *bankRepository) getTransaction(transactionID int, lock string) (*domain.BankTransaction, error) {
+	query := `SELECT ` + bankTransactionColumns + `
+		FROM bank_transactions
+		WHERE transaction_id = $1 AND company_id = $2
+		` + lock
+	transaction, err := scanBankTransaction(r.db.QueryRow(query, transactionID, r.companyID))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("bank transaction not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bank transaction: %w", err)
+	}
+
+	return transaction, nil
+}
+
+// GetTransactions returns the bank transactions that match filter in
+// booking order
+func (r *bankRepository) GetTransactions(filter domain.BankTransactionFilter) ([]*domain.BankTransaction, error) {
+	query := `SELECT ` + bankTransactionColumns + `
+		FROM bank_transactions
+		WHERE company_id = $1
+			AND ($2::int IS NULL OR account_no = $2)
+			AND ($3::date IS NULL OR booking_date >= $3)
+			AND ($4::date IS NULL OR booking_date <= $4)
+			AND ($5::boolean IS NULL OR (matched_line_id IS NOT NULL) = $5)
+		ORDER BY booking_date, transaction_id
+	`
+	rows, err := r.db.Query(query, r.companyID, filter.AccountNo, filter.From, filter.To, filter.Matched)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bank transactions: %w", err)
+	}
+	defer rows.Close()
+
+	transactions := make([]*domain.BankTransaction, 0)
+	for rows.Next() {
+		transaction, err := scanBankTransaction(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan bank transaction: %w", err)
+		}
+		transactions = append(transactions, transaction)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating bank transactions: %w", err)
+	}
+
+	return transactions, nil
+}
+
+// SetMatch matches a bank transaction to a ledger line, or with lineID nil
+// removes its match
+func (r *bankRepository) SetMatch(transactionID int, lineID *int, method string, userID int) error {
+	query := `
+		UPDATE bank_transactions
+		SET matched_line_id = $1,
+			match_method = NULLIF($2, ''),
+			matched_by = CASE WHEN $1::int IS NULL THEN NULL ELSE $3::int END,
+			matched_at = CASE WHEN $1::int IS NULL THEN NULL ELSE CURRENT_TIMESTAMP END
+		WHERE transaction_id = $4 AND company_id = $5
+	`
+	result, err := r.db.Exec(query, lineID, method, userID, transactionID, r.companyID)
+	if err != nil {
+		return fmt.Errorf("failed to match bank transaction: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("bank transaction not found")
+	}
+
+	return nil
+}
+
+// bankLedgerFrom limits a query to the lines on a bank account that count
+// in the reconciliation: lines of booked vouchers that have not been
+// corrected, the same lines the ledger shows
+const bankLedgerFrom = `
+	FROM line_items l
+	INNER JOIN vouchers v ON l.voucher_id = v.voucher_id
+	WHERE l.company_id = $1
+		AND l.account_no = $2
+		AND v.status = 'booked'
+		AND v.corrected_by_voucher_id IS NULL
+`
+
+const bankLedgerLines = `
+	SELECT l.line_id, v.voucher_id, v.series, v.voucher_number, v.date, v.description, COALESCE(v.reference, ''),
+		l.debit_amount - l.credit_amount` + bankLedgerFrom
+
+func scanBankLedgerLine(row rowScanner) (*domain.BankLedgerLine, error) {
+	line := &domain.BankLedgerLine{}
+	err := row.Scan(
+		&line.LineID,
+		&line.VoucherID,
+		&line.Series,
+		&line.VoucherNumber,
+		&line.Date,
+		&line.Description,
+		&line.Reference,
+		&line.Amount,
+	)
+	return line, err
+}
+
+// GetLedgerLineByID returns a line on the bank account accountNo
+func (r *bankRepository) GetLedgerLineByID(accountNo, lineID int) (*domain.BankLedgerLine, error) {
+	line, err := scanBankLedgerLine(r.db.QueryRow(bankLedgerLines+` AND l.line_id = $3`, r.companyID, accountNo, lineID))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("line %d is not a booked line on account %d", lineID, accountNo)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ledger line: %w", err)
+	}
+
+	return line, nil
+}
+
+// IsLineMatched reports whether a bank transaction is matched to the line
+func (r *bankRepository) IsLineMatched(lineID int) (bool, error) {
+	var matched bool
+	err := r.db.QueryRow(
+		`SELECT EXISTS (SELECT 1 FROM bank_transactions WHERE matched_line_id = $1 AND company_id = $2)`,
+		lineID, r.companyID,
+	).Scan(&matched)
+	if err != nil {
+		return false, fmt.Errorf("failed to check ledger line: %w", err)
+	}
+
+	return matched, nil
+}
+
+// GetUnmatchedLedgerLines returns the lines on the bank account dated from
+// to to that no bank transaction is matched to
+func (r *bankRepository) GetUnmatchedLedgerLines(accountNo int, from, to time.Time) ([]*domain.BankLedgerLine, error) {
+	query := bankLedgerLines + `
+		AND v.date BETWEEN $3 AND $4
+		AND NOT EXISTS (SELECT 1 FROM bank_transactions b WHERE b.matched_line_id = l.line_id)
+		ORDER BY v.date, v.series, v.voucher_number, l.line_id
+	`
+	rows, err := r.db.Query(query, r.companyID, accountNo, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ledger lines: %w", err)
+	}
+	defer rows.Close()
+
+	lines := make([]*domain.BankLedgerLine, 0)
+	for rows.Next() {
+		line, err := scanBankLedgerLine(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan ledger line: %w", err)
+		}
+		lines = append(lines, line)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating ledger lines: %w", err)
+	}
+
+	return lines, nil
+}
+
+// GetLedgerTotal returns the net of the lines on the bank account dated
+// from to to
+func (r *bankRepository) GetLedgerTotal(accountNo int, from, to time.Time) (domain.Amount, error) {
+	query := `SELECT COALESCE(SUM(l.debit_amount - l.credit_amount), 0)` + bankLedgerFrom + ` AND v.date BETWEEN $3 AND $4`
+
+	var total domain.Amount
+	if err := r.db.QueryRow(query, r.companyID, accountNo, from, to).Scan(&total); err != nil {
+		return 0, fmt.Errorf("failed to get ledger total: %w", err)
+	}
+
+	return total, nil
+}
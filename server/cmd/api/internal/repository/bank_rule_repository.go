@@ -0,0 +1,289 @@
+package repository
+
+import (
+	"cmd/api/internal/domain"
+	"database/sql"
+	"fmt"
+)
+
+type BankRuleRepository interface {
+	CreateRule(rule *domain.BankRule) error
+	GetRuleByID(ruleID int) (*domain.BankRule, error)
+	GetAllRules() ([]*domain.BankRule, error)
+	UpdateRule(rule *domain.BankRule) error
+	DeleteRule(ruleID int) error
+	GetUnbookedTransactions(accountNo int) ([]*domain.BankTransaction, error)
+	CreateApplication(application *domain.BankRuleApplication) error
+	GetApplications(ruleID *int) ([]*domain.BankRuleApplication, error)
+	WithTx(tx *sql.Tx) BankRuleRepository
+	ForCompany(companyID int) BankRuleRepository
+}
+
+type bankRuleRepository struct {
+	db        DBTX
+	companyID int
+}
+
+func NewBankRuleRepository(db *sql.DB) BankRuleRepository {
+	return &bankRuleRepository{db: db}
+}
+
+// WithTx returns a copy of the repository that runs its queries in tx
+func (r *bankRuleRepository) WithTx(tx *sql.Tx) BankRuleRepository {
+	return &bankRuleRepository{db: tx, companyID: r.companyID}
+}
+
+// ForCompany returns a copy of the repository that only sees the bank rules
+// of companyID
+func (r *bankRuleRepository) ForCompany(companyID int) BankRuleRepository {
+	return &bankRuleRepository{db: r.db, companyID: companyID}
+}
+
+const bankRuleColumns = `rule_id, name, priority, account_no, counterparty_pattern, text_pattern, direction, min_amount,
+	max_amount, description, counter_account_no, tax_code, project_id, cost_center_id, series, active, created_by,
+	created_at, updated_at`
+
+func scanBankRule(row rowScanner) (*domain.BankRule, error) {
+	rule := &domain.BankRule{}
+	err := row.Scan(
+		&rule.RuleID,
+		&rule.Name,
+		&rule.Priority,
+		&rule.AccountNo,
+		&rule.CounterpartyPattern,
+		&rule.TextPattern,
+		&rule.Direction,
+		&rule.MinAmount,
+		&rule.MaxAmount,
+		&rule.Description,
+		&rule.CounterAccountNo,
+		&rule.TaxCode,
+		&rule.ProjectID,
+		&rule.CostCenterID,
+		&rule.Series,
+		&rule.Active,
+		&rule.CreatedBy,
+		&rule.CreatedAt,
+		&rule.UpdatedAt,
+	)
+	return rule, err
+}
+
+func (r *bankRuleRepository) CreateRule(rule *domain.BankRule) error {
+	query := `
+		INSERT INTO bank_rules (company_id, name, priority, account_no, counterparty_pattern, text_pattern, direction,
+			min_amount, max_amount, description, counter_account_no, tax_code, project_id, cost_center_id, series,
+			active, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+		RETURNING rule_id, created_at, updated_at
+	`
+	err := r.db.QueryRow(query,
+		r.companyID,
+		rule.Name,
+		rule.Priority,
+		rule.AccountNo,
+		rule.CounterpartyPattern,
+		rule.TextPattern,
+		rule.Direction,
+		rule.MinAmount,
+		rule.MaxAmount,
+		rule.Description,
+		rule.CounterAccountNo,
+		rule.TaxCode,
+		rule.ProjectID,
+		rule.CostCenterID,
+		rule.Series,
+		rule.Active,
+		rule.CreatedBy,
+	).Scan(&rule.RuleID, &rule.CreatedAt, &rule.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create bank rule: %w", err)
+	}
+
+	return nil
+}
+
+func (r *bankRuleRepository) GetRuleByID(ruleID int) (*domain.BankRule, error) {
+	query := `SELECT ` + bankRuleColumns + ` FROM bank_rules WHERE rule_id = $1 AND company_id = $2`
+	rule, err := scanBankRule(r.db.QueryRow(query, ruleID, r.companyID))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("bank rule not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bank rule: %w", err)
+	}
+
+	return rule, nil
+}
+
+// GetAllRules returns the rules in the order they are tried
+func (r *bankRuleRepository) GetAllRules() ([]*domain.BankRule, error) {
+	query := `SELECT ` + bankRuleColumns + ` FROM bank_rules WHERE company_id = $1 ORDER BY priority, rule_id`
+	rows, err := r.db.Query(query, r.companyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bank rules: %w", err)
+	}
+	defer rows.Close()
+
+	rules := make([]*domain.BankRule, 0)
+	for rows.Next() {
+		rule, err := scanBankRule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan bank rule: %w", err)
+		}
+		rules = append(rules, rule)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating bank rules: %w", err)
+	}
+
+	return rules, nil
+}
+
+func (r *bankRuleRepository) UpdateRule(rule *domain.BankRule) error {
+	query := `
+		UPDATE bank_rules
+		SET name = $1, priority = $2, account_no = $3, counterparty_pattern = $4, text_pattern = $5, direction = $6,
+			min_amount = $7, max_amount = $8, description = $9, counter_account_no = $10, tax_code = $11,
+			project_id = $12, cost_center_id = $13, series = $14, active = $15, updated_at = CURRENT_TIMESTAMP
+		WHERE rule_id = $16 AND company_id = $17
+		RETURNING created_by, created_at, updated_at
+	`
+	err := r.db.QueryRow(query,
+		rule.Name,
+		rule.Priority,
+		rule.AccountNo,
+		rule.CounterpartyPattern,
+		rule.TextPattern,
+		rule.Direction,
+		rule.MinAmount,
+		rule.MaxAmount,
+		rule.Description,
+		rule.CounterAccountNo,
+		rule.TaxCode,
+		rule.ProjectID,
+		rule.CostCenterID,
+		rule.Series,
+		rule.Active,
+		rule.RuleID,
+		r.companyID,
+	).Scan(&rule.CreatedBy, &rule.CreatedAt, &rule.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("bank rule not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update bank rule: %w", err)
+	}
+
+	return nil
+}
+
+func (r *bankRuleRepository) DeleteRule(ruleID int) error {
+	result, err := r.db.Exec(`DELETE FROM bank_rules WHERE rule_id = $1 AND company_id = $2`, ruleID, r.companyID)
+	if err != nil {
+		return fmt.Errorf("failed to delete bank rule: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("bank rule not found")
+	}
+
+	return nil
+}
+
+// GetUnbookedTransactions returns the transactions of the bank account that
+// are neither matched to the books nor booked as a draft by a rule
+func (r *bankRuleRepository) GetUnbookedTransactions(accountNo int) ([]*domain.BankTransaction, error) {
+	query := `SELECT ` + bankTransactionColumns + `
+		FROM bank_transactions b
+		WHERE b.company_id = $1
+			AND b.account_no = $2
+			AND b.matched_line_id IS NULL
+			AND NOT EXISTS (SELECT 1 FROM bank_rule_applications a WHERE a.transaction_id = b.transaction_id)
+		ORDER BY b.booking_date, b.transaction_id
+	`
+	rows, err := r.db.Query(query, r.companyID, accountNo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bank transactions: %w", err)
+	}
+	defer rows.Close()
+
+	transactions := make([]*domain.BankTransaction, 0)
+	for rows.Next() {
+		transaction, err := scanBankTransaction(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan bank transaction: %w", err)
+		}
+		transactions = append(transactions, transaction)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating bank transactions: %w", err)
+	}
+
+	return transactions, nil
+}
+
+func (r *bankRuleRepository) CreateApplication(application *domain.BankRuleApplication) error {
+	query := `
+		INSERT INTO bank_rule_applications (company_id, rule_id, transaction_id, voucher_id, created_by)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING application_id, created_at
+	`
+	err := r.db.QueryRow(query,
+		r.companyID,
+		application.RuleID,
+		application.TransactionID,
+		application.VoucherID,
+		application.CreatedBy,
+	).Scan(&application.ApplicationID, &application.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record bank rule application: %w", err)
+	}
+
+	return nil
+}
+
+// GetApplications returns the vouchers produced by a rule, or with ruleID
+// nil by every rule, newest first
+func (r *bankRuleRepository) GetApplications(ruleID *int) ([]*domain.BankRuleApplication, error) {
+	query := `
+		SELECT application_id, rule_id, transaction_id, voucher_id, created_by, created_at
+		FROM bank_rule_applications
+		WHERE company_id = $1 AND ($2::int IS NULL OR rule_id = $2)
+		ORDER BY application_id DESC
+	`
+	rows, err := r.db.Query(query, r.companyID, ruleID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bank rule applications: %w", err)
+	}
+	defer rows.Close()
+
+	applications := make([]*domain.BankRuleApplication, 0)
+	for rows.Next() {
+		application := &domain.BankRuleApplication{}
+		err := rows.Scan(
+			&application.ApplicationID,
+			&application.RuleID,
+			&application.TransactionID,
+			&application.VoucherID,
+			&application.CreatedBy,
+			&application.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan bank rule application: %w", err)
+		}
+		applications = append(applications, application)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating bank rule applications: %w", err)
+	}
+
+	return applications, nil
+}
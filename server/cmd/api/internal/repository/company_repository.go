@@ -0,0 +1,271 @@
+package repository
+
+import (
+	"cmd/api/internal/domain"
+	"database/sql"
+	"fmt"
+)
+
+// CompanyRepository manages companies and their members. Unlike the
+// repositories of the books it is not scoped to one company.
+type CompanyRepository interface {
+	CreateCompany(company *domain.Company) error
+	GetCompanyByID(companyID int) (*domain.Company, error)
+	GetAllCompanies() ([]*domain.Company, error)
+	UpdateCompany(company *domain.Company) error
+	GetCompaniesByUser(userID int) ([]*domain.CompanyMembership, error)
+	GetMember(companyID, userID int) (*domain.CompanyMember, error)
+	GetMembers(companyID int) ([]*domain.CompanyMember, error)
+	SaveMember(member *domain.CompanyMember) error
+	RemoveMember(companyID, userID int) error
+	CopyAccounts(fromCompanyID, toCompanyID int) error
+	WithTx(tx *sql.Tx) CompanyRepository
+}
+
+type companyRepository struct {
+	db DBTX
+}
+
+func NewCompanyRepository(db *sql.DB) CompanyRepository {
+	return &companyRepository{db: db}
+}
+
+// WithTx returns a copy of the repository that runs its queries in tx
+func (r *companyRepository) WithTx(tx *sql.Tx) CompanyRepository {
+	return &companyRepository{db: tx}
+}
+
+func (r *companyRepository) CreateCompany(company *domain.Company) error {
+	query := `
+		INSERT INTO companies (name, org_nr)
+		VALUES ($1, $2)
+		RETURNING company_id
+	`
+	err := r.db.QueryRow(query, company.Name, company.OrgNr).Scan(&company.CompanyID)
+	if err != nil {
+		return fmt.Errorf("failed to create company: %w", err)
+	}
+
+	return nil
+}
+
+func (r *companyRepository) GetCompanyByID(companyID int) (*domain.Company, error) {
+	query := `
+		SELECT company_id, name, org_nr
+		FROM companies
+		WHERE company_id = $1
+	`
+	company := &domain.Company{}
+	err := r.db.QueryRow(query, companyID).Scan(&company.CompanyID, &company.Name, &company.OrgNr)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("company not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get company: %w", err)
+	}
+
+	return company, nil
+}
+
+func (r *companyRepository) UpdateCompany(company *domain.Company) error {
+	query := `
+		UPDATE companies
+		SET name = $1, org_nr = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE company_id = $3
+	`
+	result, err := r.db.Exec(query, company.Name, company.OrgNr, company.CompanyID)
+	if err != nil {
+		return fmt.Errorf("failed to update company: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("company not found")
+	}
+
+	return nil
+}
+
+// GetAllCompanies returns every company, for maintenance commands that work
+// across all books
+func (r *companyRepository) GetAllCompanies() ([]*domain.Company, error) {
+	query := `
+		SELECT company_id, name, org_nr
+		FROM companies
+		ORDER BY company_id
+	`
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get companies: %w", err)
+	}
+	defer rows.Close()
+
+	companies := make([]*domain.Company, 0)
+	for rows.Next() {
+		company := &domain.Company{}
+		if err := rows.Scan(&company.CompanyID, &company.Name, &company.OrgNr); err != nil {
+			return nil, fmt.Errorf("failed to scan company: %w", err)
+		}
+		companies = append(companies, company)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating companies: %w", err)
+	}
+
+	return companies, nil
+}
+
+// GetCompaniesByUser returns the companies a user is a member of, with the
+// user's role in each
+func (r *companyRepository) GetCompaniesByUser(userID int) ([]*domain.CompanyMembership, error) {
+	query := `
+		SELECT c.company_id, c.name, c.org_nr, uc.role
+		FROM user_companies uc
+		INNER JOIN companies c ON c.company_id = uc.company_id
+		WHERE uc.user_id = $1
+		ORDER BY c.company_id
+	`
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get companies: %w", err)
+	}
+	defer rows.Close()
+
+	memberships := make([]*domain.CompanyMembership, 0)
+	for rows.Next() {
+		membership := &domain.CompanyMembership{}
+		err := rows.Scan(
+			&membership.CompanyID,
+			&membership.Name,
+			&membership.OrgNr,
+			&membership.Role,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan company: %w", err)
+		}
+		memberships = append(memberships, membership)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating companies: %w", err)
+	}
+
+	return memberships, nil
+}
+
+func (r *companyRepository) GetMember(companyID, userID int) (*domain.CompanyMember, error) {
+	query := `
+		SELECT uc.company_id, uc.user_id, u.name, u.email, uc.role
+		FROM user_companies uc
+		INNER JOIN users u ON u.user_id = uc.user_id
+		WHERE uc.company_id = $1 AND uc.user_id = $2
+	`
+	member := &domain.CompanyMember{}
+	err := r.db.QueryRow(query, companyID, userID).Scan(
+		&member.CompanyID,
+		&member.UserID,
+		&member.Name,
+		&member.Email,
+		&member.Role,
+	)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("user is not a member of the company")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get company member: %w", err)
+	}
+
+	return member, nil
+}
+
+func (r *companyRepository) GetMembers(companyID int) ([]*domain.CompanyMember, error) {
+	query := `
+		SELECT uc.company_id, uc.user_id, u.name, u.email, uc.role
+		FROM user_companies uc
+		INNER JOIN users u ON u.user_id = uc.user_id
+		WHERE uc.company_id = $1
+		ORDER BY u.name
+	`
+	rows, err := r.db.Query(query, companyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get company members: %w", err)
+	}
+	defer rows.Close()
+
+	members := make([]*domain.CompanyMember, 0)
+	for rows.Next() {
+		member := &domain.CompanyMember{}
+		err := rows.Scan(
+			&member.CompanyID,
+			&member.UserID,
+			&member.Name,
+			&member.Email,
+			&member.Role,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan company member: %w", err)
+		}
+		members = append(members, member)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating company members: %w", err)
+	}
+
+	return members, nil
+}
+
+// SaveMember adds a user to a company, or changes the role of a user who
+// already is a member
+func (r *companyRepository) SaveMember(member *domain.CompanyMember) error {
+	query := `
+		INSERT INTO user_companies (company_id, user_id, role)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, company_id) DO UPDATE SET role = EXCLUDED.role
+	`
+	_, err := r.db.Exec(query, member.CompanyID, member.UserID, member.Role)
+	if err != nil {
+		return fmt.Errorf("failed to save company member: %w", err)
+	}
+
+	return nil
+}
+
+func (r *companyRepository) RemoveMember(companyID, userID int) error {
+	query := `DELETE FROM user_companies WHERE company_id = $1 AND user_id = $2`
+	result, err := r.db.Exec(query, companyID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to remove company member: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("user is not a member of the company")
+	}
+
+	return nil
+}
+
+// CopyAccounts copies the chart of accounts of one company to another
+func (r *companyRepository) CopyAccounts(fromCompanyID, toCompanyID int) error {
+	query := `
+		INSERT INTO accounts (company_id, account_no, account_name, account_group, tax_standard, type, standard_side)
+		SELECT $2, account_no, account_name, account_group, tax_standard, type, standard_side
+		FROM accounts
+		WHERE company_id = $1
+		ON CONFLICT (company_id, account_no) DO NOTHING
+	`
+	_, err := r.db.Exec(query, fromCompanyID, toCompanyID)
+	if err != nil {
+		return fmt.Errorf("failed to copy accounts: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,189 @@
+package repository
+
+import (
+	"cmd/api/internal/domain"
+	"database/sql"
+	"fmt"
+)
+
+type CostCenterRepository interface {
+	CreateCostCenter(costCenter *domain.CostCenter) error
+	GetCostCenterByID(costCenterID int) (*domain.CostCenter, error)
+	GetCostCenterByCode(code string) (*domain.CostCenter, error)
+	GetAllCostCenters() ([]*domain.CostCenter, error)
+	UpdateCostCenter(costCenter *domain.CostCenter) error
+	DeleteCostCenter(costCenterID int) error
+	WithTx(tx *sql.Tx) CostCenterRepository
+	ForCompany(companyID int) CostCenterRepository
+}
+
+type costCenterRepository struct {
+	db        DBTX
+	companyID int
+}
+
+func NewCostCenterRepository(db *sql.DB) CostCenterRepository {
+	return &costCenterRepository{db: db}
+}
+
+// WithTx returns a copy of the repository that runs its queries in tx
+func (r *costCenterRepository) WithTx(tx *sql.Tx) CostCenterRepository {
+	return &costCenterRepository{db: tx, companyID: r.companyID}
+}
+
+// ForCompany returns a copy of the repository that only sees the cost centres
+// of companyID
+func (r *costCenterRepository) ForCompany(companyID int) CostCenterRepository {
+	return &costCenterRepository{db: r.db, companyID: companyID}
+}
+
+func (r *costCenterRepository) CreateCostCenter(costCenter *domain.CostCenter) error {
+	query := `
+		INSERT INTO cost_centers (code, name, active, start_date, end_date, company_id)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING cost_center_id
+	`
+	err := r.db.QueryRow(query,
+		costCenter.Code,
+		costCenter.Name,
+		costCenter.Active,
+		nullableDate(costCenter.StartDate),
+		nullableDate(costCenter.EndDate),
+		r.companyID,
+	).Scan(&costCenter.CostCenterID)
+	if err != nil {
+		return fmt.Errorf("failed to create cost centre: %w", err)
+	}
+
+	return nil
+}
+
+func (r *costCenterRepository) GetCostCenterByID(costCenterID int) (*domain.CostCenter, error) {
+	query := `
+		SELECT cost_center_id, code, name, active, start_date, end_date
+		FROM cost_centers
+		WHERE cost_center_id = $1 AND company_id = $2
+	`
+	return r.getCostCenter(query, costCenterID)
+}
+
+func (r *costCenterRepository) GetCostCenterByCode(code string) (*domain.CostCenter, error) {
+	query := `
+		SELECT cost_center_id, code, name, active, start_date, end_date
+		FROM cost_centers
+		WHERE code = $1 AND company_id = $2
+	`
+	return r.getCostCenter(query, code)
+}
+
+func (r *costCenterRepository) getCostCenter(query string, arg interface{}) (*domain.CostCenter, error) {
+	costCenter := &domain.CostCenter{}
+	var startDate, endDate sql.NullTime
+	err := r.db.QueryRow(query, arg, r.companyID).Scan(
+		&costCenter.CostCenterID,
+		&costCenter.Code,
+		&costCenter.Name,
+		&costCenter.Active,
+		&startDate,
+		&endDate,
+	)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("cost centre not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cost centre: %w", err)
+	}
+	costCenter.StartDate = dateFromNull(startDate)
+	costCenter.EndDate = dateFromNull(endDate)
+
+	return costCenter, nil
+}
+
+func (r *costCenterRepository) GetAllCostCenters() ([]*domain.CostCenter, error) {
+	query := `
+		SELECT cost_center_id, code, name, active, start_date, end_date
+		FROM cost_centers
+		WHERE company_id = $1
+		ORDER BY code
+	`
+	rows, err := r.db.Query(query, r.companyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cost centres: %w", err)
+	}
+	defer rows.Close()
+
+	costCenters := make([]*domain.CostCenter, 0)
+	for rows.Next() {
+		costCenter := &domain.CostCenter{}
+		var startDate, endDate sql.NullTime
+		err := rows.Scan(
+			&costCenter.CostCenterID,
+			&costCenter.Code,
+			&costCenter.Name,
+			&costCenter.Active,
+			&startDate,
+			&endDate,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan cost centre: %w", err)
+		}
+		costCenter.StartDate = dateFromNull(startDate)
+		costCenter.EndDate = dateFromNull(endDate)
+		costCenters = append(costCenters, costCenter)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating cost centres: %w", err)
+	}
+
+	return costCenters, nil
+}
+
+func (r *costCenterRepository) UpdateCostCenter(costCenter *domain.CostCenter) error {
+	query := `
+		UPDATE cost_centers
+		SET code = $1, name = $2, active = $3, start_date = $4, end_date = $5, updated_at = CURRENT_TIMESTAMP
+		WHERE cost_center_id = $6 AND company_id = $7
+	`
+	result, err := r.db.Exec(query,
+		costCenter.Code,
+		costCenter.Name,
+		costCenter.Active,
+		nullableDate(costCenter.StartDate),
+		nullableDate(costCenter.EndDate),
+		costCenter.CostCenterID,
+		r.companyID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update cost centre: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("cost centre not found")
+	}
+
+	return nil
+}
+
+// DeleteCostCenter deletes a cost centre. Cost centres with booked lines
+// cannot be deleted (the foreign key refuses); deactivate them instead.
+func (r *costCenterRepository) DeleteCostCenter(costCenterID int) error {
+	result, err := r.db.Exec(`DELETE FROM cost_centers WHERE cost_center_id = $1 AND company_id = $2`, costCenterID, r.companyID)
+	if err != nil {
+		return fmt.Errorf("failed to delete cost centre: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("cost centre not found")
+	}
+
+	return nil
+}
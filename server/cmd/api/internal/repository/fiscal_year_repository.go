@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"cmd/api/internal/domain"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+type FiscalYearRepository interface {
+	CreateFiscalYear(fiscalYear *domain.FiscalYear) error
+	GetFiscalYearByID(fiscalYearID int) (*domain.FiscalYear, error)
+	GetFiscalYearByIDForUpdate(fiscalYearID int) (*domain.FiscalYear, error)
+	GetFiscalYearByDate(date time.Time) (*domain.FiscalYear, error)
+	GetAllFiscalYears() ([]*domain.FiscalYear, error)
+	MarkFiscalYearAsClosed(fiscalYearID int, closingVoucherID, openingVoucherID *int, userID int) error
+	WithTx(tx *sql.Tx) FiscalYearRepository
+	ForCompany(companyID int) FiscalYearRepository
+}
+
+type fiscalYearRepository struct {
+	db        DBTX
+	companyID int
+}
+
+func NewFiscalYearRepository(db *sql.DB) FiscalYearRepository {
+	return &fiscalYearRepository{db: db}
+}
+
+// WithTx returns a copy of the repository that runs its queries in tx
+func (r *fiscalYearRepository) WithTx(tx *sql.Tx) FiscalYearRepository {
+	return &fiscalYearRepository{db: tx, companyID: r.companyID}
+}
+
+// ForCompany returns a copy of the repository that only sees the fiscal
+// years of companyID
+func (r *fiscalYearRepository) ForCompany(companyID int) FiscalYearRepository {
+	return &fiscalYearRepository{db: r.db, companyID: companyID}
+}
+
+func (r *fiscalYearRepository) CreateFiscalYear(fiscalYear *domain.FiscalYear) error {
+	query := `
+		INSERT INTO fiscal_years (start_date, end_date, status, company_id)
+		VALUES ($1, $2, $3, $4)
+		RETURNING fiscal_year_id
+	`
+	err := r.db.QueryRow(query,
+		fiscalYear.StartDate.Time,
+		fiscalYear.EndDate.Time,
+		fiscalYear.Status,
+		r.companyID,
+	).Scan(&fiscalYear.FiscalYearID)
+	if err != nil {
+		return fmt.Errorf("failed to create fiscal year: %w", err)
+	}
+
+	return nil
+}
+
+func (r *fiscalYearRepository) GetFiscalYearByID(fiscalYearID int) (*domain.FiscalYear, error) {
+	query := `
+		SELECT fiscal_year_id, start_date, end_date, status, closing_voucher_id, opening_voucher_id, closed_by, closed_at
+		FROM fiscal_years
+		WHERE fiscal_year_id = $1 AND company_id = $2
+	`
+	return r.getFiscalYear(query, fiscalYearID)
+}
+
+// GetFiscalYearByIDForUpdate reads a fiscal year and locks its row until the
+// surrounding transaction ends. Only meaningful on a repository from WithTx.
+func (r *fiscalYearRepository) GetFiscalYearByIDForUpdate(fiscalYearID int) (*domain.FiscalYear, error) {
+	query := `
+		SELECT fiscal_year_id, start_date, end_date, status, closing_voucher_id, opening_voucher_id, closed_by, closed_at
+		FROM fiscal_years
+		WHERE fiscal_year_id = $1 AND company_id = $2
+		FOR UPDATE
+	`
+	return r.getFiscalYear(query, fiscalYearID)
+}
+
+// GetFiscalYearByDate returns the fiscal year that contains date
+func (r *fiscalYearRepository) GetFiscalYearByDate(date time.Time) (*domain.FiscalYear, error) {
+	query := `
+		SELECT fiscal_year_id, start_date, end_date, status, closing_voucher_id, opening_voucher_id, closed_by, closed_at
+		FROM fiscal_years
+		WHERE start_date <= $1 AND end_date >= $1 AND company_id = $2
+	`
+	return r.getFiscalYear(query, date)
+}
+
+func (r *fiscalYearRepository) getFiscalYear(query string, arg interface{}) (*domain.FiscalYear, error) {
+	fiscalYear := &domain.FiscalYear{}
+	err := r.db.QueryRow(query, arg, r.companyID).Scan(
+		&fiscalYear.FiscalYearID,
+		&fiscalYear.StartDate.Time,
+		&fiscalYear.EndDate.Time,
+		&fiscalYear.Status,
+		&fiscalYear.ClosingVoucherID,
+		&fiscalYear.OpeningVoucherID,
+		&fiscalYear.ClosedBy,
+		&fiscalYear.ClosedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("fiscal year not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fiscal year: %w", err)
+	}
+
+	return fiscalYear, nil
+}
+
+func (r *fiscalYearRepository) GetAllFiscalYears() ([]*domain.FiscalYear, error) {
+	query := `
+		SELECT fiscal_year_id, start_date, end_date, status, closing_voucher_id, opening_voucher_id, closed_by, closed_at
+		FROM fiscal_years
+		WHERE company_id = $1
+		ORDER BY start_date DESC
+	`
+	rows, err := r.db.Query(query, r.companyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fiscal years: %w", err)
+	}
+	defer rows.Close()
+
+	fiscalYears := make([]*domain.FiscalYear, 0)
+	for rows.Next() {
+		fiscalYear := &domain.FiscalYear{}
+		err := rows.Scan(
+			&fiscalYear.FiscalYearID,
+			&fiscalYear.StartDate.Time,
+			&fiscalYear.EndDate.Time,
+			&fiscalYear.Status,
+			&fiscalYear.ClosingVoucherID,
+			&fiscalYear.OpeningVoucherID,
+			&fiscalYear.ClosedBy,
+			&fiscalYear.ClosedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan fiscal year: %w", err)
+		}
+		fiscalYears = append(fiscalYears, fiscalYear)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating fiscal years: %w", err)
+	}
+
+	return fiscalYears, nil
+}
+
+func (r *fiscalYearRepository) MarkFiscalYearAsClosed(fiscalYearID int, closingVoucherID, openingVoucherID *int, userID int) error {
+	query := `
+		UPDATE fiscal_years
+		SET status = 'closed', closing_voucher_id = $1, opening_voucher_id = $2, closed_by = $3, closed_at = CURRENT_TIMESTAMP
+		WHERE fiscal_year_id = $4 AND company_id = $5
+	`
+	_, err := r.db.Exec(query, closingVoucherID, openingVoucherID, userID, fiscalYearID, r.companyID)
+	if err != nil {
+		return fmt.Errorf("failed to close fiscal year: %w", err)
+	}
+
+	return nil
+}
@@ -11,26 +11,43 @@ type LineItemRepository interface {
 	GetLineItemByID(lineID int) (*domain.LineItem, error)
 	GetLineItemsByVoucherID(voucherID int) ([]*domain.LineItem, error)
 	GetLineItemsByAccountNo(accountNo int) ([]*domain.LineItem, error)
+	GetLineItemsByDateRange(fromDate, toDate string) ([]*domain.LineItem, error)
+	GetChainedLineItems() ([]*domain.LineItem, error)
 	UpdateLineItem(lineItem *domain.LineItem) error
 	DeleteLineItem(lineID int) error
 	DeleteLineItemsByVoucherID(voucherID int) error
+	WithTx(tx *sql.Tx) LineItemRepository
+	ForCompany(companyID int) LineItemRepository
 }
 
 type lineItemRepository struct {
-	db *sql.DB
+	db        DBTX
+	companyID int
 }
 
 func NewLineItemRepository(db *sql.DB) LineItemRepository {
 	return &lineItemRepository{db: db}
 }
 
+// WithTx returns a copy of the repository that runs its queries in tx
+func (r *lineItemRepository) WithTx(tx *sql.Tx) LineItemRepository {
+	return &lineItemRepository{db: tx, companyID: r.companyID}
+}
+
+// ForCompany returns a copy of the repository that only sees the line
+// items of companyID
+func (r *lineItemRepository) ForCompany(companyID int) LineItemRepository {
+	return &lineItemRepository{db: r.db, companyID: companyID}
+}
+
 func (r *lineItemRepository) CreateLineItem(lineItem *domain.LineItem) error {
 	query := `
-		INSERT INTO line_items (voucher_id, account_no, debit_amount, credit_amount, tax_code, project_id, cost_center_id)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO line_items (company_id, voucher_id, account_no, debit_amount, credit_amount, tax_code, project_id, cost_center_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 		RETURNING line_id
 	`
 	err := r.db.QueryRow(query,
+		r.companyID,
 		lineItem.VoucherID,
 		lineItem.AccountNo,
 		lineItem.DebitAmount,
@@ -50,10 +67,10 @@ func (r *lineItemRepository) GetLineItemByID(lineID int) (*domain.LineItem, erro
 	query := `
 		SELECT line_id, voucher_id, account_no, debit_amount, credit_amount, tax_code, project_id, cost_center_id
 		FROM line_items
-		WHERE line_id = $1
+		WHERE line_id = $1 AND company_id = $2
 	`
 	lineItem := &domain.LineItem{}
-	err := r.db.QueryRow(query, lineID).Scan(
+	err := r.db.QueryRow(query, lineID, r.companyID).Scan(
 		&lineItem.LineID,
 		&lineItem.VoucherID,
 		&lineItem.AccountNo,
@@ -77,10 +94,10 @@ func (r *lineItemRepository) GetLineItemsByVoucherID(voucherID int) ([]*domain.L
 	query := `
 		SELECT line_id, voucher_id, account_no, debit_amount, credit_amount, tax_code, project_id, cost_center_id
 		FROM line_items
-		WHERE voucher_id = $1
+		WHERE voucher_id = $1 AND company_id = $2
 		ORDER BY line_id
 	`
-	rows, err := r.db.Query(query, voucherID)
+	rows, err := r.db.Query(query, voucherID, r.companyID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get line items by voucher: %w", err)
 	}
@@ -112,10 +129,10 @@ func (r *lineItemRepository) GetLineItemsByAccountNo(accountNo int) ([]*domain.L
 	query := `
 		SELECT line_id, voucher_id, account_no, debit_amount, credit_amount, tax_code, project_id, cost_center_id
 		FROM line_items
-		WHERE account_no = $1
+		WHERE account_no = $1 AND company_id = $2
 		ORDER BY line_id
 	`
-	rows, err := r.db.Query(query, accountNo)
+	rows, err := r.db.Query(query, accountNo, r.companyID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get line items by account: %w", err)
 	}
@@ -143,11 +160,95 @@ func (r *lineItemRepository) GetLineItemsByAccountNo(accountNo int) ([]*domain.L
 	return lineItems, nil
 }
 
+// GetLineItemsByDateRange returns the lines of all booked vouchers dated
+// between fromDate and toDate
+func (r *lineItemRepository) GetLineItemsByDateRange(fromDate, toDate string) ([]*domain.LineItem, error) {
+	query := `
+		SELECT l.line_id, l.voucher_id, l.account_no, l.debit_amount, l.credit_amount, l.tax_code, l.project_id, l.cost_center_id
+		FROM line_items l
+		INNER JOIN vouchers v ON l.voucher_id = v.voucher_id
+		WHERE v.date >= $1 AND v.date <= $2 AND v.company_id = $3 AND v.status = 'booked'
+		ORDER BY l.voucher_id, l.line_id
+	`
+	rows, err := r.db.Query(query, fromDate, toDate, r.companyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get line items by date range: %w", err)
+	}
+	defer rows.Close()
+
+	var lineItems []*domain.LineItem
+	for rows.Next() {
+		lineItem := &domain.LineItem{}
+		err := rows.Scan(
+			&lineItem.LineID,
+			&lineItem.VoucherID,
+			&lineItem.AccountNo,
+			&lineItem.DebitAmount,
+			&lineItem.CreditAmount,
+			&lineItem.TaxCode,
+			&lineItem.ProjectID,
+			&lineItem.CostCenterID,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan line item: %w", err)
+		}
+		lineItems = append(lineItems, lineItem)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating line items: %w", err)
+	}
+
+	return lineItems, nil
+}
+
+// GetChainedLineItems returns the lines of all vouchers in the hash chain,
+// in chain order
+func (r *lineItemRepository) GetChainedLineItems() ([]*domain.LineItem, error) {
+	query := `
+		SELECT l.line_id, l.voucher_id, l.account_no, l.debit_amount, l.credit_amount, l.tax_code, l.project_id, l.cost_center_id
+		FROM line_items l
+		INNER JOIN vouchers v ON l.voucher_id = v.voucher_id
+		WHERE v.company_id = $1 AND v.status = 'booked' AND v.chain_position IS NOT NULL
+		ORDER BY v.chain_position, l.line_id
+	`
+	rows, err := r.db.Query(query, r.companyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chained line items: %w", err)
+	}
+	defer rows.Close()
+
+	var lineItems []*domain.LineItem
+	for rows.Next() {
+		lineItem := &domain.LineItem{}
+		err := rows.Scan(
+			&lineItem.LineID,
+			&lineItem.VoucherID,
+			&lineItem.AccountNo,
+			&lineItem.DebitAmount,
+			&lineItem.CreditAmount,
+			&lineItem.TaxCode,
+			&lineItem.ProjectID,
+			&lineItem.CostCenterID,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan line item: %w", err)
+		}
+		lineItems = append(lineItems, lineItem)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating line items: %w", err)
+	}
+
+	return lineItems, nil
+}
+
 func (r *lineItemRepository) UpdateLineItem(lineItem *domain.LineItem) error {
 	query := `
 		UPDATE line_items
 		SET voucher_id = $1, account_no = $2, debit_amount = $3, credit_amount = $4, tax_code = $5, project_id = $6, cost_center_id = $7
-		WHERE line_id = $8
+		WHERE line_id = $8 AND company_id = $9
 	`
 	_, err := r.db.Exec(query,
 		lineItem.VoucherID,
@@ -158,6 +259,7 @@ func (r *lineItemRepository) UpdateLineItem(lineItem *domain.LineItem) error {
 		lineItem.ProjectID,
 		lineItem.CostCenterID,
 		lineItem.LineID,
+		r.companyID,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to update line item: %w", err)
@@ -167,8 +269,8 @@ func (r *lineItemRepository) UpdateLineItem(lineItem *domain.LineItem) error {
 }
 
 func (r *lineItemRepository) DeleteLineItem(lineID int) error {
-	query := `DELETE FROM line_items WHERE line_id = $1`
-	_, err := r.db.Exec(query, lineID)
+	query := `DELETE FROM line_items WHERE line_id = $1 AND company_id = $2`
+	_, err := r.db.Exec(query, lineID, r.companyID)
 	if err != nil {
 		return fmt.Errorf("failed to delete line item: %w", err)
 	}
@@ -177,8 +279,8 @@ func (r *lineItemRepository) DeleteLineItem(lineID int) error {
 }
 
 func (r *lineItemRepository) DeleteLineItemsByVoucherID(voucherID int) error {
-	query := `DELETE FROM line_items WHERE voucher_id = $1`
-	_, err := r.db.Exec(query, voucherID)
+	query := `DELETE FROM line_items WHERE voucher_id = $1 AND company_id = $2`
+	_, err := r.db.Exec(query, voucherID, r.companyID)
 	if err != nil {
 		return fmt.Errorf("failed to delete line items by voucher: %w", err)
 	}
@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"cmd/api/internal/domain"
+	"database/sql"
+	"fmt"
+)
+
+type PeriodRepository interface {
+	GetPeriod(period string) (*domain.Period, error)
+	GetPeriodForShare(period string) (*domain.Period, error)
+	GetPeriodForUpdate(period string) (*domain.Period, error)
+	GetAllPeriods() ([]*domain.Period, error)
+	SetPeriodStatus(period string, status string, userID int) error
+	WithTx(tx *sql.Tx) PeriodRepository
+	ForCompany(companyID int) PeriodRepository
+}
+
+type periodRepository struct {
+	db        DBTX
+	companyID int
+}
+
+func NewPeriodRepository(db *sql.DB) PeriodRepository {
+	return &periodRepository{db: db}
+}
+
+// WithTx returns a copy of the repository that runs its queries in tx
+func (r *periodRepository) WithTx(tx *sql.Tx) PeriodRepository {
+	return &periodRepository{db: tx, companyID: r.companyID}
+}
+
+// ForCompany returns a copy of the repository that only sees the periods
+// of companyID
+func (r *periodRepository) ForCompany(companyID int) PeriodRepository {
+	return &periodRepository{db: r.db, companyID: companyID}
+}
+
+// GetPeriod returns the status of a period. Periods without a row in the
+// periods table have never been locked and are reported as open.
+func (r *periodRepository) GetPeriod(period string) (*domain.Period, error) {
+	query := `
+		SELECT period, status, changed_by, changed_at
+		FROM periods
+		WHERE period = $1 AND company_id = $2
+	`
+	p := &domain.Period{}
+	err := r.db.QueryRow(query, period, r.companyID).Scan(
+		&p.Period,
+		&p.Status,
+		&p.ChangedBy,
+		&p.ChangedAt,
+	)
+	if err == sql.ErrNoRows {
+		return &domain.Period{Period: period, Status: domain.PeriodOpen}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get period: %w", err)
+	}
+
+	return p, nil
+}
+
+// GetPeriodForShare returns the status of a period like GetPeriod and holds
+// a share lock on its row until the transaction ends. Periods without a row
+// get an open one first, so there is always a row to lock and a concurrent
+// status change waits for the transaction.
+func (r *periodRepository) GetPeriodForShare(period string) (*domain.Period, error) {
+	return r.getPeriodLocked(period, "FOR SHARE")
+}
+
+// GetPeriodForUpdate is GetPeriodForShare with an exclusive lock, for a
+// transaction that changes the status of the period. Bookings holding the
+// share lock finish first and no new ones start until it ends.
+func (r *periodRepository) GetPeriodForUpdate(period string) (*domain.Period, error) {
+	return r.getPeriodLocked(period, "FOR UPDATE")
+}
+
+func (r *periodRepository) getPeriodLocked(period string, lock string) (*domain.Period, error) {
+	_, err := r.db.Exec(`
+		INSERT INTO periods (company_id, period, status)
+		VALUES ($1, $2, 'open')
+		ON CONFLICT (company_id, period) DO NOTHING
+	`, r.companyID, period)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create period: %w", err)
+	}
+
+	query := `
+		SELECT period, status, changed_by, changed_at
+		FROM periods
+		WHERE period = $1 AND company_id = $2
+		` + lock
+	p := &domain.Period{}
+	err = r.db.QueryRow(query, period, r.companyID).Scan(
+		&p.Period,
+		&p.Status,
+		&p.ChangedBy,
+		&p.ChangedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get period: %w", err)
+	}
+
+	return p, nil
+}
+
+// GetAllPeriods returns every period that has a status or a voucher
+func (r *periodRepository) GetAllPeriods() ([]*domain.Period, error) {
+	query := `
+		SELECT all_periods.period, COALESCE(p.status, 'open'), p.changed_by, p.changed_at
+		FROM (
+			SELECT period FROM periods WHERE company_id = $1
+			UNION
+			SELECT DISTINCT period FROM vouchers WHERE company_id = $1 AND period IS NOT NULL AND period != ''
+		) all_periods
+		LEFT JOIN periods p ON p.period = all_periods.period AND p.company_id = $1
+		ORDER BY all_periods.period DESC
+	`
+	rows, err := r.db.Query(query, r.companyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get periods: %w", err)
+	}
+	defer rows.Close()
+
+	periods := make([]*domain.Period, 0)
+	for rows.Next() {
+		p := &domain.Period{}
+		if err := rows.Scan(&p.Period, &p.Status, &p.ChangedBy, &p.ChangedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan period: %w", err)
+		}
+		periods = append(periods, p)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating periods: %w", err)
+	}
+
+	return periods, nil
+}
+
+func (r *periodRepository) SetPeriodStatus(period string, status string, userID int) error {
+	query := `
+		INSERT INTO periods (company_id, period, status, changed_by, changed_at)
+		VALUES ($1, $2, $3, $4, CURRENT_TIMESTAMP)
+		ON CONFLICT (company_id, period) DO UPDATE
+		SET status = EXCLUDED.status, changed_by = EXCLUDED.changed_by, changed_at = EXCLUDED.changed_at
+	`
+	_, err := r.db.Exec(query, r.companyID, period, status, userID)
+	if err != nil {
+		return fmt.Errorf("failed to set period status: %w", err)
+	}
+
+	return nil
+}
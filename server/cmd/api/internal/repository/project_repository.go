@@ -0,0 +1,204 @@
+package repository
+
+import (
+	"cmd/api/internal/domain"
+	"database/sql"
+	"fmt"
+)
+
+type ProjectRepository interface {
+	CreateProject(project *domain.Project) error
+	GetProjectByID(projectID int) (*domain.Project, error)
+	GetProjectByCode(code string) (*domain.Project, error)
+	GetAllProjects() ([]*domain.Project, error)
+	UpdateProject(project *domain.Project) error
+	DeleteProject(projectID int) error
+	WithTx(tx *sql.Tx) ProjectRepository
+	ForCompany(companyID int) ProjectRepository
+}
+
+type projectRepository struct {
+	db        DBTX
+	companyID int
+}
+
+func NewProjectRepository(db *sql.DB) ProjectRepository {
+	return &projectRepository{db: db}
+}
+
+// WithTx returns a copy of the repository that runs its queries in tx
+func (r *projectRepository) WithTx(tx *sql.Tx) ProjectRepository {
+	return &projectRepository{db: tx, companyID: r.companyID}
+}
+
+// ForCompany returns a copy of the repository that only sees the projects
+// of companyID
+func (r *projectRepository) ForCompany(companyID int) ProjectRepository {
+	return &projectRepository{db: r.db, companyID: companyID}
+}
+
+func (r *projectRepository) CreateProject(project *domain.Project) error {
+	query := `
+		INSERT INTO projects (code, name, active, start_date, end_date, company_id)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING project_id
+	`
+	err := r.db.QueryRow(query,
+		project.Code,
+		project.Name,
+		project.Active,
+		nullableDate(project.StartDate),
+		nullableDate(project.EndDate),
+		r.companyID,
+	).Scan(&project.ProjectID)
+	if err != nil {
+		return fmt.Errorf("failed to create project: %w", err)
+	}
+
+	return nil
+}
+
+func (r *projectRepository) GetProjectByID(projectID int) (*domain.Project, error) {
+	query := `
+		SELECT project_id, code, name, active, start_date, end_date
+		FROM projects
+		WHERE project_id = $1 AND company_id = $2
+	`
+	return r.getProject(query, projectID)
+}
+
+func (r *projectRepository) GetProjectByCode(code string) (*domain.Project, error) {
+	query := `
+		SELECT project_id, code, name, active, start_date, end_date
+		FROM projects
+		WHERE code = $1 AND company_id = $2
+	`
+	return r.getProject(query, code)
+}
+
+func (r *projectRepository) getProject(query string, arg interface{}) (*domain.Project, error) {
+	project := &domain.Project{}
+	var startDate, endDate sql.NullTime
+	err := r.db.QueryRow(query, arg, r.companyID).Scan(
+		&project.ProjectID,
+		&project.Code,
+		&project.Name,
+		&project.Active,
+		&startDate,
+		&endDate,
+	)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("project not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project: %w", err)
+	}
+	project.StartDate = dateFromNull(startDate)
+	project.EndDate = dateFromNull(endDate)
+
+	return project, nil
+}
+
+func (r *projectRepository) GetAllProjects() ([]*domain.Project, error) {
+	query := `
+		SELECT project_id, code, name, active, start_date, end_date
+		FROM projects
+		WHERE company_id = $1
+		ORDER BY code
+	`
+	rows, err := r.db.Query(query, r.companyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get projects: %w", err)
+	}
+	defer rows.Close()
+
+	projects := make([]*domain.Project, 0)
+	for rows.Next() {
+		project := &domain.Project{}
+		var startDate, endDate sql.NullTime
+		err := rows.Scan(
+			&project.ProjectID,
+			&project.Code,
+			&project.Name,
+			&project.Active,
+			&startDate,
+			&endDate,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan project: %w", err)
+		}
+		project.StartDate = dateFromNull(startDate)
+		project.EndDate = dateFromNull(endDate)
+		projects = append(projects, project)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating projects: %w", err)
+	}
+
+	return projects, nil
+}
+
+func (r *projectRepository) UpdateProject(project *domain.Project) error {
+	query := `
+		UPDATE projects
+		SET code = $1, name = $2, active = $3, start_date = $4, end_date = $5, updated_at = CURRENT_TIMESTAMP
+		WHERE project_id = $6 AND company_id = $7
+	`
+	result, err := r.db.Exec(query,
+		project.Code,
+		project.Name,
+		project.Active,
+		nullableDate(project.StartDate),
+		nullableDate(project.EndDate),
+		project.ProjectID,
+		r.companyID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update project: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("project not found")
+	}
+
+	return nil
+}
+
+// DeleteProject deletes a project. Projects with booked lines cannot be
+// deleted (the foreign key refuses); deactivate them instead.
+func (r *projectRepository) DeleteProject(projectID int) error {
+	result, err := r.db.Exec(`DELETE FROM projects WHERE project_id = $1 AND company_id = $2`, projectID, r.companyID)
+	if err != nil {
+		return fmt.Errorf("failed to delete project: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("project not found")
+	}
+
+	return nil
+}
+
+// nullableDate passes an optional date as NULL when it is not set
+func nullableDate(date *domain.FlexibleDate) interface{} {
+	if date == nil {
+		return nil
+	}
+	return date.Time
+}
+
+func dateFromNull(date sql.NullTime) *domain.FlexibleDate {
+	if !date.Valid {
+		return nil
+	}
+	return &domain.FlexibleDate{Time: date.Time}
+}
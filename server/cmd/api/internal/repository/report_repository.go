@@ -7,60 +7,130 @@ import (
 )
 
 type ReportRepository interface {
-	GetIncomeStatement(fromDate, toDate string) (*domain.IncomeStatement, error)
+	GetIncomeStatement(fromDate, toDate string, filter domain.DimensionFilter) (*domain.IncomeStatement, error)
+	GetIncomeStatementByDimension(fromDate, toDate string, filter domain.DimensionFilter, dimension string) (map[int]*domain.IncomeStatement, error)
+	GetAccountBalances(fromDate, toDate string, accountType string) ([]domain.AccountBalance, error)
+	GetTrialBalance(fromDate, toDate, fiscalYearStart string) ([]domain.TrialBalanceEntry, error)
+	WithTx(tx *sql.Tx) ReportRepository
+	ForCompany(companyID int) ReportRepository
 }
 
+// Opening balance vouchers (ingående balans) only restate balances that are
+// already in the books, so they are left out of every sum over a date range.
+// Closing vouchers move the year's result to 2099 and are left out of the
+// income statement so it still shows the result.
+const (
+	excludeOpeningVouchers = `NOT EXISTS (SELECT 1 FROM fiscal_years fy WHERE fy.opening_voucher_id = v.voucher_id)`
+	excludeClosingVouchers = `NOT EXISTS (SELECT 1 FROM fiscal_years fy WHERE fy.closing_voucher_id = v.voucher_id)`
+)
+
 type reportRepository struct {
-	db *sql.DB
+	db        DBTX
+	companyID int
 }
 
 func NewReportRepository(db *sql.DB) ReportRepository {
 	return &reportRepository{db: db}
 }
 
-func (r *reportRepository) GetIncomeStatement(fromDate, toDate string) (*domain.IncomeStatement, error) {
+// WithTx returns a copy of the repository that runs its queries in tx
+func (r *reportRepository) WithTx(tx *sql.Tx) ReportRepository {
+	return &reportRepository{db: tx, companyID: r.companyID}
+}
+
+// ForCompany returns a copy of the repository that only reports on the
+// books of companyID
+func (r *reportRepository) ForCompany(companyID int) ReportRepository {
+	return &reportRepository{db: r.db, companyID: companyID}
+}
+
+func (r *reportRepository) GetIncomeStatement(fromDate, toDate string, filter domain.DimensionFilter) (*domain.IncomeStatement, error) {
+	statements, err := r.incomeStatements(fromDate, toDate, filter, "")
+	if err != nil {
+		return nil, err
+	}
+
+	if statement, ok := statements[0]; ok {
+		return statement, nil
+	}
+	return newIncomeStatement(fromDate, toDate), nil
+}
+
+// GetIncomeStatementByDimension returns one income statement per project or
+// cost centre, keyed by its ID. Lines without one are under key 0.
+func (r *reportRepository) GetIncomeStatementByDimension(fromDate, toDate string, filter domain.DimensionFilter, dimension string) (map[int]*domain.IncomeStatement, error) {
+	column, ok := dimensionColumns[dimension]
+	if !ok {
+		return nil, fmt.Errorf("unknown dimension %q", dimension)
+	}
+
+	return r.incomeStatements(fromDate, toDate, filter, column)
+}
+
+// dimensionColumns maps the dimensions reports can be grouped by to their
+// line_items column
+var dimensionColumns = map[string]string{
+	domain.DimensionProject:    "l.project_id",
+	domain.DimensionCostCenter: "l.cost_center_id",
+}
+
+// incomeStatements builds the income statement per value of groupColumn,
+// or a single statement under key 0 when groupColumn is empty
+func (r *reportRepository) incomeStatements(fromDate, toDate string, filter domain.DimensionFilter, groupColumn string) (map[int]*domain.IncomeStatement, error) {
+	groupExpr := "0"
+	if groupColumn != "" {
+		groupExpr = "COALESCE(" + groupColumn + ", 0)"
+	}
+
 	query := `
 		SELECT
+			` + groupExpr + ` as group_id,
 			a.account_no,
 			a.account_name,
 			a.type,
 			SUM(l.debit_amount - l.credit_amount) as balance
 		FROM line_items l
 		INNER JOIN vouchers v ON l.voucher_id = v.voucher_id
-		INNER JOIN accounts a ON l.account_no = a.account_no
+		INNER JOIN accounts a ON a.company_id = l.company_id AND a.account_no = l.account_no
 		WHERE v.date >= $1
 		  AND v.date <= $2
+		  AND ($3::int IS NULL OR l.project_id = $3)
+		  AND ($4::int IS NULL OR l.cost_center_id = $4)
+		  AND v.company_id = $5
 		  AND v.corrected_by_voucher_id IS NULL
+		  AND v.status = 'booked'
 		  AND a.type = 'P&L'
-		GROUP BY a.account_no, a.account_name, a.type
+		  AND ` + excludeClosingVouchers + `
+		GROUP BY group_id, a.account_no, a.account_name, a.type
 		HAVING SUM(l.debit_amount - l.credit_amount) != 0
-		ORDER BY a.account_no
+		ORDER BY group_id, a.account_no
 	`
 
-	rows, err := r.db.Query(query, fromDate, toDate)
+	rows, err := r.db.Query(query, fromDate, toDate, filter.ProjectID, filter.CostCenterID, r.companyID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query income statement: %w", err)
 	}
 	defer rows.Close()
 
-	statement := &domain.IncomeStatement{
-		Income:   make([]domain.IncomeStatementEntry, 0),
-		Expenses: make([]domain.IncomeStatementEntry, 0),
-	}
-	statement.Period.FromDate = fromDate
-	statement.Period.ToDate = toDate
-
+	statements := make(map[int]*domain.IncomeStatement)
 	for rows.Next() {
+		var groupID int
 		var accountNo int
 		var accountName string
 		var accountType string
-		var balance float64
+		var balance domain.Amount
 
-		err := rows.Scan(&accountNo, &accountName, &accountType, &balance)
+		err := rows.Scan(&groupID, &accountNo, &accountName, &accountType, &balance)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan row: %w", err)
 		}
 
+		statement, ok := statements[groupID]
+		if !ok {
+			statement = newIncomeStatement(fromDate, toDate)
+			statements[groupID] = statement
+		}
+
 		entry := domain.IncomeStatementEntry{
 			AccountNo:   accountNo,
 			AccountName: accountName,
@@ -71,10 +141,10 @@ func (r *reportRepository) GetIncomeStatement(fromDate, toDate string) (*domain.
 		// Expense accounts (4000-8999): negative balance means expense
 		if accountNo >= 3000 && accountNo < 4000 {
 			statement.Income = append(statement.Income, entry)
-			statement.TotalIncome += balance
+			statement.TotalIncome = statement.TotalIncome.Add(balance)
 		} else if accountNo >= 4000 && accountNo < 9000 {
 			statement.Expenses = append(statement.Expenses, entry)
-			statement.TotalExpenses += balance
+			statement.TotalExpenses = statement.TotalExpenses.Add(balance)
 		}
 	}
 
@@ -83,7 +153,137 @@ func (r *reportRepository) GetIncomeStatement(fromDate, toDate string) (*domain.
 	}
 
 	// Calculate net result
-	statement.NetResult = statement.TotalIncome + statement.TotalExpenses
+	for _, statement := range statements {
+		statement.NetResult = statement.TotalIncome.Add(statement.TotalExpenses)
+	}
+
+	return statements, nil
+}
+
+func newIncomeStatement(fromDate, toDate string) *domain.IncomeStatement {
+	statement := &domain.IncomeStatement{
+		Income:   make([]domain.IncomeStatementEntry, 0),
+		Expenses: make([]domain.IncomeStatementEntry, 0),
+	}
+	statement.Period.FromDate = fromDate
+	statement.Period.ToDate = toDate
+	return statement
+}
+
+// GetAccountBalances sums debit - credit per account for vouchers dated
+// between fromDate and toDate. An empty fromDate means from the first
+// booking and an empty accountType means both P&L and BS accounts.
+func (r *reportRepository) GetAccountBalances(fromDate, toDate string, accountType string) ([]domain.AccountBalance, error) {
+	query := `
+		SELECT
+			a.account_no,
+			a.account_name,
+			a.type,
+			SUM(l.debit_amount - l.credit_amount) as balance
+		FROM line_items l
+		INNER JOIN vouchers v ON l.voucher_id = v.voucher_id
+		INNER JOIN accounts a ON a.company_id = l.company_id AND a.account_no = l.account_no
+		WHERE ($1::date IS NULL OR v.date >= $1::date)
+		  AND v.date <= $2::date
+		  AND ($3 = '' OR a.type = $3)
+		  AND v.company_id = $4
+		  AND v.corrected_by_voucher_id IS NULL
+		  AND v.status = 'booked'
+		  AND ` + excludeOpeningVouchers + `
+		GROUP BY a.account_no, a.account_name, a.type
+		HAVING SUM(l.debit_amount - l.credit_amount) != 0
+		ORDER BY a.account_no
+	`
+
+	rows, err := r.db.Query(query, nullIfEmpty(fromDate), toDate, accountType, r.companyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query account balances: %w", err)
+	}
+	defer rows.Close()
+
+	balances := make([]domain.AccountBalance, 0)
+	for rows.Next() {
+		var balance domain.AccountBalance
+		err := rows.Scan(&balance.AccountNo, &balance.AccountName, &balance.Type, &balance.Balance)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		balances = append(balances, balance)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return balances, nil
+}
+
+// GetTrialBalance returns opening balance, period debit/credit and closing
+// balance for every account with a balance or activity. Opening balances of
+// P&L accounts only count from fiscalYearStart.
+func (r *reportRepository) GetTrialBalance(fromDate, toDate, fiscalYearStart string) ([]domain.TrialBalanceEntry, error) {
+	query := `
+		SELECT
+			a.account_no,
+			a.account_name,
+			a.type,
+			COALESCE(SUM(CASE WHEN v.date < $1::date AND (a.type = 'BS' OR v.date >= $3::date)
+				THEN l.debit_amount - l.credit_amount END), 0) as opening_balance,
+			COALESCE(SUM(CASE WHEN v.date >= $1::date THEN l.debit_amount END), 0) as period_debit,
+			COALESCE(SUM(CASE WHEN v.date >= $1::date THEN l.credit_amount END), 0) as period_credit
+		FROM line_items l
+		INNER JOIN vouchers v ON l.voucher_id = v.voucher_id
+		INNER JOIN accounts a ON a.company_id = l.company_id AND a.account_no = l.account_no
+		WHERE v.date <= $2::date
+		  AND v.company_id = $4
+		  AND v.corrected_by_voucher_id IS NULL
+		  AND v.status = 'booked'
+		  AND ` + excludeOpeningVouchers + `
+		GROUP BY a.account_no, a.account_name, a.type
+		ORDER BY a.account_no
+	`
+
+	rows, err := r.db.Query(query, fromDate, toDate, fiscalYearStart, r.companyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query trial balance: %w", err)
+	}
+	defer rows.Close()
+
+	entries := make([]domain.TrialBalanceEntry, 0)
+	for rows.Next() {
+		var entry domain.TrialBalanceEntry
+		err := rows.Scan(
+			&entry.AccountNo,
+			&entry.AccountName,
+			&entry.Type,
+			&entry.OpeningBalance,
+			&entry.PeriodDebit,
+			&entry.PeriodCredit,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+
+		// Skip accounts that only had activity in earlier fiscal years
+		if entry.OpeningBalance.IsZero() && entry.PeriodDebit.IsZero() && entry.PeriodCredit.IsZero() {
+			continue
+		}
 
-	return statement, nil
+		entry.ClosingBalance = entry.OpeningBalance.Add(entry.PeriodDebit).Sub(entry.PeriodCredit)
+		entries = append(entries, entry)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return entries, nil
+}
+
+// nullIfEmpty maps an empty optional filter to SQL NULL
+func nullIfEmpty(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
 }
@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"cmd/api/internal/domain"
+	"database/sql"
+	"fmt"
+)
+
+type SearchRepository interface {
+	SearchVouchers(text string, amount *domain.Amount, limit int) ([]*domain.SearchHit, error)
+	ForCompany(companyID int) SearchRepository
+}
+
+type searchRepository struct {
+	db        DBTX
+	companyID int
+}
+
+func NewSearchRepository(db *sql.DB) SearchRepository {
+	return &searchRepository{db: db}
+}
+
+// ForCompany returns a copy of the repository that only searches the books
+// of companyID
+func (r *searchRepository) ForCompany(companyID int) SearchRepository {
+	return &searchRepository{db: r.db, companyID: companyID}
+}
+
+// searchHeadline marks the matching words of a snippet
+const searchHeadline = `'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MinWords=5, MaxWords=20'`
+
+// SearchVouchers finds the vouchers whose description or reference matches
+// text, that have lines on accounts whose name matches it, or, when amount
+// is given, whose total or one of whose lines equals it. Voucher text ranks
+// above account names, and an amount match above both.
+func (r *searchRepository) SearchVouchers(text string, amount *domain.Amount, limit int) ([]*domain.SearchHit, error) {
+	var amountArg interface{}
+	if amount != nil {
+		amountArg = *amount
+	}
+
+	// The candidates are found through the indexes first; only they are
+	// ranked and get snippets
+	query := `
+		WITH search AS (
+			SELECT websearch_to_tsquery('swedish', $2) AS q, $3::numeric AS amount
+		), matched_accounts AS (
+			SELECT a.account_no, ts_rank(a.search_vector, search.q) AS rank,
+				a.account_no || ' ' || ts_headline('swedish', a.account_name, search.q, ` + searchHeadline + `) AS headline
+			FROM accounts a, search
+			WHERE a.company_id = $1 AND a.search_vector @@ search.q
+		), candidates AS (
+			SELECT v.voucher_id FROM vouchers v, search
+			WHERE v.company_id = $1 AND v.search_vector @@ search.q
+			UNION
+			SELECT l.voucher_id FROM line_items l
+			INNER JOIN matched_accounts m ON m.account_no = l.account_no
+			WHERE l.company_id = $1
+			UNION
+			SELECT v.voucher_id FROM vouchers v, search
+			WHERE v.company_id = $1 AND v.total_amount = search.amount
+			UNION
+			SELECT l.voucher_id FROM line_items l, search
+			WHERE l.company_id = $1 AND (l.debit_amount = search.amount OR l.credit_amount = search.amount)
+		), hits AS (
+			SELECT c.voucher_id AS hit_voucher_id,
+				search.q AS hit_query,
+				v.search_vector @@ search.q AS text_match,
+				ts_rank(v.search_vector, search.q) AS text_rank,
+				acc.rank AS account_rank,
+				acc.headlines AS account_headlines,
+				(v.total_amount = search.amount OR EXISTS (
+					SELECT 1 FROM line_items l
+					WHERE l.voucher_id = v.voucher_id AND (l.debit_amount = search.amount OR l.credit_amount = search.amount)
+				)) IS TRUE AS amount_match
+			FROM candidates c
+			INNER JOIN vouchers v ON v.voucher_id = c.voucher_id
+			CROSS JOIN search
+			LEFT JOIN LATERAL (
+				SELECT MAX(m.rank) AS rank, string_agg(DISTINCT m.headline, ', ') AS headlines
+				FROM line_items l
+				INNER JOIN matched_accounts m ON m.account_no = l.account_no
+				WHERE l.voucher_id = c.voucher_id
+			) acc ON true
+		)
+		SELECT ` + voucherColumns + `,
+			text_rank + 0.5 * COALESCE(account_rank, 0) + CASE WHEN amount_match THEN 1 ELSE 0 END AS rank,
+			CASE WHEN text_match THEN ts_headline('swedish', description, hit_query, ` + searchHeadline + `) ELSE '' END,
+			CASE WHEN text_match AND reference <> '' THEN ts_headline('swedish', reference, hit_query, ` + searchHeadline + `) ELSE '' END,
+			COALESCE(account_headlines, ''),
+			amount_match
+		FROM hits
+		INNER JOIN vouchers ON vouchers.voucher_id = hits.hit_voucher_id
+		ORDER BY rank DESC, date DESC, vouchers.voucher_id DESC
+		LIMIT $4
+	`
+	rows, err := r.db.Query(query, r.companyID, text, amountArg, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search vouchers: %w", err)
+	}
+	defer rows.Close()
+
+	hits := make([]*domain.SearchHit, 0)
+	for rows.Next() {
+		hit := &domain.SearchHit{Snippets: map[string]string{}}
+		var description, reference, accounts string
+		var amountMatch bool
+		err := scanVoucher(rows, &hit.Voucher, &hit.Rank, &description, &reference, &accounts, &amountMatch)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan search hit: %w", err)
+		}
+
+		if description != "" {
+			hit.Snippets["description"] = description
+		}
+		if reference != "" {
+			hit.Snippets["reference"] = reference
+		}
+		if accounts != "" {
+			hit.Snippets["accounts"] = accounts
+		}
+		if amountMatch {
+			hit.Snippets["amount"] = "<mark>" + amount.Format() + "</mark>"
+		}
+		hits = append(hits, hit)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating search hits: %w", err)
+	}
+
+	return hits, nil
+}
@@ -12,16 +12,23 @@ type UserRepository interface {
 	GetUserByEmail(email string) (*domain.User, error)
 	UpdateUser(user *domain.User) error
 	DeleteUser(userID int) error
+	SharesCompany(userID, otherUserID int) (bool, error)
+	WithTx(tx *sql.Tx) UserRepository
 }
 
 type userRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
 func NewUserRepository(db *sql.DB) UserRepository {
 	return &userRepository{db: db}
 }
 
+// WithTx returns a copy of the repository that runs its queries in tx
+func (r *userRepository) WithTx(tx *sql.Tx) UserRepository {
+	return &userRepository{db: tx}
+}
+
 func (r *userRepository) CreateUser(user *domain.User) error {
 	query := `
 		INSERT INTO users (name, email, password_hash, role)
@@ -107,3 +114,22 @@ func (r *userRepository) DeleteUser(userID int) error {
 
 	return nil
 }
+
+// SharesCompany reports whether two users are members of at least one
+// common company
+func (r *userRepository) SharesCompany(userID, otherUserID int) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM user_companies a
+			INNER JOIN user_companies b ON b.company_id = a.company_id
+			WHERE a.user_id = $1 AND b.user_id = $2
+		)
+	`
+	var shares bool
+	if err := r.db.QueryRow(query, userID, otherUserID).Scan(&shares); err != nil {
+		return false, fmt.Errorf("failed to check common companies: %w", err)
+	}
+
+	return shares, nil
+}
@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"cmd/api/internal/domain"
+	"database/sql"
+	"fmt"
+)
+
+type VATRepository interface {
+	GetVATTurnover(fromDate, toDate string) ([]*domain.VATAccountSum, error)
+	GetSettlementsInRange(fromDate, toDate string) ([]*domain.VATSettlement, error)
+	LockSettlements() error
+	CreateSettlement(settlement *domain.VATSettlement) error
+	WithTx(tx *sql.Tx) VATRepository
+	ForCompany(companyID int) VATRepository
+}
+
+type vatRepository struct {
+	db        DBTX
+	companyID int
+}
+
+func NewVATRepository(db *sql.DB) VATRepository {
+	return &vatRepository{db: db}
+}
+
+// WithTx returns a copy of the repository that runs its queries in tx
+func (r *vatRepository) WithTx(tx *sql.Tx) VATRepository {
+	return &vatRepository{db: tx, companyID: r.companyID}
+}
+
+// ForCompany returns a copy of the repository that only sees the bookings
+// and settlements of companyID
+func (r *vatRepository) ForCompany(companyID int) VATRepository {
+	return &vatRepository{db: r.db, companyID: companyID}
+}
+
+// GetVATTurnover sums debit and credit per account and tax code for the VAT
+// accounts (26xx) and the sales and purchase accounts (3xxx-4xxx). Corrected
+// vouchers, opening balances and earlier VAT settlements are left out.
+func (r *vatRepository) GetVATTurnover(fromDate, toDate string) ([]*domain.VATAccountSum, error) {
+	query := `
+		SELECT l.account_no, COALESCE(l.tax_code, 0),
+		       COALESCE(SUM(l.debit_amount), 0), COALESCE(SUM(l.credit_amount), 0)
+		FROM line_items l
+		INNER JOIN vouchers v ON l.voucher_id = v.voucher_id
+		WHERE v.date >= $1 AND v.date <= $2
+		  AND v.company_id = $3
+		  AND v.corrected_by_voucher_id IS NULL
+		  AND v.status = 'booked'
+		  AND ` + excludeOpeningVouchers + `
+		  AND NOT EXISTS (SELECT 1 FROM vat_settlements vs WHERE vs.voucher_id = v.voucher_id)
+		  AND (l.account_no BETWEEN 2600 AND 2699 OR l.account_no BETWEEN 3000 AND 4999)
+		GROUP BY l.account_no, COALESCE(l.tax_code, 0)
+		ORDER BY l.account_no
+	`
+
+	rows, err := r.db.Query(query, fromDate, toDate, r.companyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query VAT turnover: %w", err)
+	}
+	defer rows.Close()
+
+	sums := make([]*domain.VATAccountSum, 0)
+	for rows.Next() {
+		sum := &domain.VATAccountSum{}
+		if err := rows.Scan(&sum.AccountNo, &sum.TaxCode, &sum.Debit, &sum.Credit); err != nil {
+			return nil, fmt.Errorf("failed to scan VAT turnover: %w", err)
+		}
+		sums = append(sums, sum)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating VAT turnover: %w", err)
+	}
+
+	return sums, nil
+}
+
+// LockSettlements holds a lock on the settlements of the company until the
+// transaction ends, so two settlements cannot both pass the overlap check
+func (r *vatRepository) LockSettlements() error {
+	_, err := r.db.Exec(`SELECT pg_advisory_xact_lock(hashtext('vat_settlements'), $1)`, r.companyID)
+	if err != nil {
+		return fmt.Errorf("failed to lock VAT settlements: %w", err)
+	}
+
+	return nil
+}
+
+// GetSettlementsInRange returns the settlements whose dates overlap the range
+func (r *vatRepository) GetSettlementsInRange(fromDate, toDate string) ([]*domain.VATSettlement, error) {
+	query := `
+		SELECT vat_settlement_id, period, from_date, to_date, voucher_id, booked_by, booked_at
+		FROM vat_settlements
+		WHERE from_date <= $2 AND to_date >= $1 AND company_id = $3
+		ORDER BY from_date
+	`
+
+	rows, err := r.db.Query(query, fromDate, toDate, r.companyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get VAT settlements: %w", err)
+	}
+	defer rows.Close()
+
+	settlements := make([]*domain.VATSettlement, 0)
+	for rows.Next() {
+		settlement := &domain.VATSettlement{}
+		err := rows.Scan(
+			&settlement.VATSettlementID,
+			&settlement.Period,
+			&settlement.FromDate.Time,
+			&settlement.ToDate.Time,
+			&settlement.VoucherID,
+			&settlement.BookedBy,
+			&settlement.BookedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan VAT settlement: %w", err)
+		}
+		settlements = append(settlements, settlement)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating VAT settlements: %w", err)
+	}
+
+	return settlements, nil
+}
+
+func (r *vatRepository) CreateSettlement(settlement *domain.VATSettlement) error {
+	query := `
+		INSERT INTO vat_settlements (period, from_date, to_date, voucher_id, booked_by, company_id)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING vat_settlement_id, booked_at
+	`
+	err := r.db.QueryRow(query,
+		settlement.Period,
+		settlement.FromDate.Time,
+		settlement.ToDate.Time,
+		settlement.VoucherID,
+		settlement.BookedBy,
+		r.companyID,
+	).Scan(&settlement.VATSettlementID, &settlement.BookedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create VAT settlement: %w", err)
+	}
+
+	return nil
+}
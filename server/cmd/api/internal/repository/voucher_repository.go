@@ -4,92 +4,205 @@ import (
 	"cmd/api/internal/domain"
 	"database/sql"
 	"fmt"
+	"strings"
 )
 
 type VoucherRepository interface {
 	CreateVoucher(voucher *domain.Voucher) error
 	CreateCorrectionVoucher(voucher *domain.Voucher, originalVoucherID int) error
+	CreateDraftVoucher(voucher *domain.Voucher) error
+	BookVoucher(voucher *domain.Voucher) error
 	GetVoucherByID(voucherID int) (*domain.Voucher, error)
-	GetAllVouchers() ([]*domain.Voucher, error)
+	GetVoucherByIDForUpdate(voucherID int) (*domain.Voucher, error)
+	ListVouchers(filter domain.VoucherFilter) ([]*domain.Voucher, int, error)
 	GetVouchersByPeriod(period string) ([]*domain.Voucher, error)
 	GetVouchersByCreatedBy(userID int) ([]*domain.Voucher, error)
+	GetVouchersByDateRange(fromDate, toDate string) ([]*domain.Voucher, error)
 	GetAllPeriods() ([]string, error)
 	UpdateVoucher(voucher *domain.Voucher) error
 	DeleteVoucher(voucherID int) error
 	MarkVoucherAsCorrected(voucherID int, correctedByID int) error
+	LockChainHead() (*domain.ChainHead, error)
+	GetChainHead() (*domain.ChainHead, error)
+	SealVoucher(voucherID, position int, prevHash, hash string) error
+	GetChain() ([]*domain.ChainLink, error)
+	CountUnchainedVouchers() (int, error)
+	WithTx(tx *sql.Tx) VoucherRepository
+	ForCompany(companyID int) VoucherRepository
 }
 
+// nextVoucherNumber takes the next number in series $3 of company $1 for a
+// voucher dated $2. Numbers restart in every fiscal year, or calendar year
+// when no fiscal year covers the date. The counter row stays locked until
+// the transaction ends and a rollback gives the number back, so each series
+// is numbered without gaps.
+const nextVoucherNumber = `
+	WITH numbering AS (
+		SELECT COALESCE(
+			(SELECT start_date FROM fiscal_years
+			 WHERE company_id = $1 AND $2::date BETWEEN start_date AND end_date),
+			date_trunc('year', $2::date)::date
+		) AS fiscal_year_start
+	), counter AS (
+		INSERT INTO voucher_counters (company_id, series, fiscal_year_start, last_number)
+		SELECT $1, $3, fiscal_year_start, 1 FROM numbering
+		ON CONFLICT (company_id, series, fiscal_year_start)
+		DO UPDATE SET last_number = voucher_counters.last_number + 1
+		RETURNING fiscal_year_start, last_number
+	)`
+
 type voucherRepository struct {
-	db *sql.DB
+	db        DBTX
+	companyID int
 }
 
 func NewVoucherRepository(db *sql.DB) VoucherRepository {
 	return &voucherRepository{db: db}
 }
 
+// WithTx returns a copy of the repository that runs its queries in tx
+func (r *voucherRepository) WithTx(tx *sql.Tx) VoucherRepository {
+	return &voucherRepository{db: tx, companyID: r.companyID}
+}
+
+// ForCompany returns a copy of the repository that only sees the vouchers
+// of companyID
+func (r *voucherRepository) ForCompany(companyID int) VoucherRepository {
+	return &voucherRepository{db: r.db, companyID: companyID}
+}
+
 func (r *voucherRepository) CreateVoucher(voucher *domain.Voucher) error {
-	query := `
-		INSERT INTO vouchers (date, description, reference, total_amount, period, created_by)
-		VALUES ($1, $2, $3, $4, $5, $6)
-		RETURNING voucher_id, voucher_number
+	query := nextVoucherNumber + `
+		INSERT INTO vouchers (company_id, date, series, fiscal_year_start, voucher_number, description, reference, total_amount, period, created_by, status, booked_at)
+		SELECT $1, $2, $3, fiscal_year_start, last_number, $4, $5, $6, $7, $8, 'booked', CURRENT_TIMESTAMP FROM counter
+		RETURNING voucher_id, voucher_number, booked_at
 	`
 	err := r.db.QueryRow(query,
+		r.companyID,
 		voucher.Date.Time,
+		voucher.Series,
 		voucher.Description,
 		voucher.Reference,
 		voucher.TotalAmount,
 		voucher.Period,
 		voucher.CreatedBy,
-	).Scan(&voucher.VoucherID, &voucher.VoucherNumber)
+	).Scan(&voucher.VoucherID, &voucher.VoucherNumber, &voucher.BookedAt)
 	if err != nil {
 		return fmt.Errorf("failed to create voucher: %w", err)
 	}
+	voucher.Status = domain.VoucherBooked
 
 	return nil
 }
 
-func (r *voucherRepository) CreateCorrectionVoucher(voucher *domain.Voucher, originalVoucherID int) error {
+// CreateDraftVoucher stores a draft. It gets no number until it is booked.
+func (r *voucherRepository) CreateDraftVoucher(voucher *domain.Voucher) error {
 	query := `
-		INSERT INTO vouchers (date, description, reference, total_amount, period, created_by, corrects_voucher_id)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
-		RETURNING voucher_id, voucher_number
+		INSERT INTO vouchers (company_id, date, series, description, reference, total_amount, period, created_by, status)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, 'draft')
+		RETURNING voucher_id
+	`
+	err := r.db.QueryRow(query,
+		r.companyID,
+		voucher.Date.Time,
+		voucher.Series,
+		voucher.Description,
+		voucher.Reference,
+		voucher.TotalAmount,
+		voucher.Period,
+		voucher.CreatedBy,
+	).Scan(&voucher.VoucherID)
+	if err != nil {
+		return fmt.Errorf("failed to create draft voucher: %w", err)
+	}
+	voucher.VoucherNumber = 0
+	voucher.Status = domain.VoucherDraft
+
+	return nil
+}
+
+// BookVoucher books a draft with its final total: it takes the next number
+// in the voucher's series for its date, in the same way as CreateVoucher
+func (r *voucherRepository) BookVoucher(voucher *domain.Voucher) error {
+	query := nextVoucherNumber + `
+		UPDATE vouchers v
+		SET status = 'booked', voucher_number = counter.last_number, fiscal_year_start = counter.fiscal_year_start,
+			series = $3, total_amount = $5, booked_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+		FROM counter
+		WHERE v.voucher_id = $4 AND v.company_id = $1 AND v.status = 'draft'
+		RETURNING v.voucher_number, v.booked_at
+	`
+	err := r.db.QueryRow(query, r.companyID, voucher.Date.Time, voucher.Series, voucher.VoucherID, voucher.TotalAmount).Scan(&voucher.VoucherNumber, &voucher.BookedAt)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("draft voucher not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to book voucher: %w", err)
+	}
+	voucher.Status = domain.VoucherBooked
+
+	return nil
+}
+
+func (r *voucherRepository) CreateCorrectionVoucher(voucher *domain.Voucher, originalVoucherID int) error {
+	query := nextVoucherNumber + `
+		INSERT INTO vouchers (company_id, date, series, fiscal_year_start, voucher_number, description, reference, total_amount, period, created_by, corrects_voucher_id, status, booked_at)
+		SELECT $1, $2, $3, fiscal_year_start, last_number, $4, $5, $6, $7, $8, $9, 'booked', CURRENT_TIMESTAMP FROM counter
+		RETURNING voucher_id, voucher_number, booked_at
 	`
 	err := r.db.QueryRow(query,
+		r.companyID,
 		voucher.Date.Time,
+		voucher.Series,
 		voucher.Description,
 		voucher.Reference,
 		voucher.TotalAmount,
 		voucher.Period,
 		voucher.CreatedBy,
 		originalVoucherID,
-	).Scan(&voucher.VoucherID, &voucher.VoucherNumber)
+	).Scan(&voucher.VoucherID, &voucher.VoucherNumber, &voucher.BookedAt)
 	if err != nil {
 		return fmt.Errorf("failed to create correction voucher: %w", err)
 	}
 	voucher.CorrectsVoucherID = &originalVoucherID
+	voucher.Status = domain.VoucherBooked
 
 	return nil
 }
 
 func (r *voucherRepository) MarkVoucherAsCorrected(voucherID int, correctedByID int) error {
-	query := `UPDATE vouchers SET corrected_by_voucher_id = $1 WHERE voucher_id = $2`
-	_, err := r.db.Exec(query, correctedByID, voucherID)
+	query := `UPDATE vouchers SET corrected_by_voucher_id = $1 WHERE voucher_id = $2 AND company_id = $3`
+	_, err := r.db.Exec(query, correctedByID, voucherID, r.companyID)
 	if err != nil {
 		return fmt.Errorf("failed to mark voucher as corrected: %w", err)
 	}
 	return nil
 }
 
-func (r *voucherRepository) GetVoucherByID(voucherID int) (*domain.Voucher, error) {
-	query := `
-		SELECT voucher_id, voucher_number, date, description, reference, total_amount, period, created_by, corrects_voucher_id, corrected_by_voucher_id
-		FROM vouchers
-		WHERE voucher_id = $1
-	`
-	voucher := &domain.Voucher{}
-	err := r.db.QueryRow(query, voucherID).Scan(
+// voucherColumns are the columns scanned by scanVoucher
+const voucherColumns = `voucher_id, series, COALESCE(voucher_number, 0), status, date, description, reference, total_amount, period, created_by, corrects_voucher_id, corrected_by_voucher_id, chain_position, hash, booked_at`
+
+// voucherOrders are the ORDER BY clauses of the voucher sort orders; %[1]s
+// is the direction. voucher_id last keeps pages stable.
+var voucherOrders = map[string]string{
+	domain.VoucherSortNumber:    "fiscal_year_start %[1]s, series, voucher_number %[1]s, voucher_id %[1]s",
+	domain.VoucherSortDate:      "date %[1]s, voucher_id %[1]s",
+	domain.VoucherSortAmount:    "total_amount %[1]s, voucher_id %[1]s",
+	domain.VoucherSortCreatedAt: "created_at %[1]s, voucher_id %[1]s",
+}
+
+// rowScanner is a *sql.Row or *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanVoucher scans voucherColumns, followed by extra, into voucher
+func scanVoucher(row rowScanner, voucher *domain.Voucher, extra ...interface{}) error {
+	dest := []interface{}{
 		&voucher.VoucherID,
+		&voucher.Series,
 		&voucher.VoucherNumber,
+		&voucher.Status,
 		&voucher.Date.Time,
 		&voucher.Description,
 		&voucher.Reference,
@@ -98,119 +211,187 @@ func (r *voucherRepository) GetVoucherByID(voucherID int) (*domain.Voucher, erro
 		&voucher.CreatedBy,
 		&voucher.CorrectsVoucherID,
 		&voucher.CorrectedByVoucherID,
-	)
-	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("voucher not found")
-	}
-	if err != nil {
-		return nil, fmt.Errorf("failed to get voucher: %w", err)
+		&voucher.ChainPosition,
+		&voucher.Hash,
+		&voucher.BookedAt,
 	}
+	return row.Scan(append(dest, extra...)...)
+}
 
-	return voucher, nil
+// voucherQuery builds the WHERE clause of a voucher listing. Conditions are
+// written with ? for their arguments, which become numbered placeholders.
+type voucherQuery struct {
+	conditions []string
+	args       []interface{}
 }
 
-func (r *voucherRepository) GetAllVouchers() ([]*domain.Voucher, error) {
-	query := `
-		SELECT voucher_id, voucher_number, date, description, reference, total_amount, period, created_by, corrects_voucher_id, corrected_by_voucher_id
-		FROM vouchers
-		ORDER BY voucher_number DESC
-	`
-	rows, err := r.db.Query(query)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get vouchers: %w", err)
+// newVoucherQuery starts a query over the vouchers of companyID
+func newVoucherQuery(companyID int) *voucherQuery {
+	q := &voucherQuery{}
+	q.where("company_id = ?", companyID)
+	return q
+}
+
+func (q *voucherQuery) where(condition string, args ...interface{}) {
+	for _, arg := range args {
+		q.args = append(q.args, arg)
+		condition = strings.Replace(condition, "?", fmt.Sprintf("$%d", len(q.args)), 1)
 	}
-	defer rows.Close()
+	q.conditions = append(q.conditions, condition)
+}
 
-	vouchers := make([]*domain.Voucher, 0)
-	for rows.Next() {
-		voucher := &domain.Voucher{}
-		err := rows.Scan(
-			&voucher.VoucherID,
-			&voucher.VoucherNumber,
-			&voucher.Date.Time,
-			&voucher.Description,
-			&voucher.Reference,
-			&voucher.TotalAmount,
-			&voucher.Period,
-			&voucher.CreatedBy,
-			&voucher.CorrectsVoucherID,
-			&voucher.CorrectedByVoucherID,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan voucher: %w", err)
+// filter adds the conditions of filter
+func (q *voucherQuery) filter(filter domain.VoucherFilter) {
+	if filter.From != nil {
+		q.where("date >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		q.where("date <= ?", *filter.To)
+	}
+	if filter.Period != "" {
+		q.where("period = ?", filter.Period)
+	}
+	if filter.MinAmount != nil {
+		q.where("total_amount >= ?", *filter.MinAmount)
+	}
+	if filter.MaxAmount != nil {
+		q.where("total_amount <= ?", *filter.MaxAmount)
+	}
+	if filter.AccountNo != nil {
+		q.where("EXISTS (SELECT 1 FROM line_items l WHERE l.voucher_id = vouchers.voucher_id AND l.account_no = ?)", *filter.AccountNo)
+	}
+	if filter.CreatedBy != nil {
+		q.where("created_by = ?", *filter.CreatedBy)
+	}
+	if filter.Corrected != nil {
+		if *filter.Corrected {
+			q.where("corrected_by_voucher_id IS NOT NULL")
+		} else {
+			q.where("corrected_by_voucher_id IS NULL")
 		}
-		vouchers = append(vouchers, voucher)
 	}
+	if filter.Status != "" {
+		q.where("status = ?", filter.Status)
+	}
+	if filter.Text != "" {
+		pattern := "%" + escapeLike(filter.Text) + "%"
+		q.where("(description ILIKE ? OR reference ILIKE ?)", pattern, pattern)
+	}
+}
 
-	return vouchers, nil
+func (q *voucherQuery) whereClause() string {
+	return strings.Join(q.conditions, " AND ")
 }
 
-func (r *voucherRepository) GetVouchersByPeriod(period string) ([]*domain.Voucher, error) {
+// escapeLike escapes the wildcards of a LIKE pattern
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+func (r *voucherRepository) GetVoucherByID(voucherID int) (*domain.Voucher, error) {
+	query := `
+		SELECT ` + voucherColumns + `
+		FROM vouchers
+		WHERE voucher_id = $1 AND company_id = $2
+	`
+	return r.getVoucher(query, voucherID)
+}
+
+// GetVoucherByIDForUpdate reads a voucher and locks its row until the
+// surrounding transaction ends. Only meaningful on a repository from WithTx.
+func (r *voucherRepository) GetVoucherByIDForUpdate(voucherID int) (*domain.Voucher, error) {
 	query := `
-		SELECT voucher_id, voucher_number, date, description, reference, total_amount, period, created_by, corrects_voucher_id, corrected_by_voucher_id
+		SELECT ` + voucherColumns + `
 		FROM vouchers
-		WHERE period = $1
-		ORDER BY voucher_number DESC
+		WHERE voucher_id = $1 AND company_id = $2
+		FOR UPDATE
 	`
-	rows, err := r.db.Query(query, period)
+	return r.getVoucher(query, voucherID)
+}
+
+func (r *voucherRepository) getVoucher(query string, voucherID int) (*domain.Voucher, error) {
+	voucher := &domain.Voucher{}
+	err := scanVoucher(r.db.QueryRow(query, voucherID, r.companyID), voucher)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("voucher not found")
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to get vouchers by period: %w", err)
+		return nil, fmt.Errorf("failed to get voucher: %w", err)
 	}
-	defer rows.Close()
 
-	vouchers := make([]*domain.Voucher, 0)
-	for rows.Next() {
-		voucher := &domain.Voucher{}
-		err := rows.Scan(
-			&voucher.VoucherID,
-			&voucher.VoucherNumber,
-			&voucher.Date.Time,
-			&voucher.Description,
-			&voucher.Reference,
-			&voucher.TotalAmount,
-			&voucher.Period,
-			&voucher.CreatedBy,
-			&voucher.CorrectsVoucherID,
-			&voucher.CorrectedByVoucherID,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan voucher: %w", err)
-		}
-		vouchers = append(vouchers, voucher)
+	return voucher, nil
+}
+
+// ListVouchers returns the page of vouchers selected by filter, sorted as it
+// asks, and the number of vouchers matching it on all pages
+func (r *voucherRepository) ListVouchers(filter domain.VoucherFilter) ([]*domain.Voucher, int, error) {
+	q := newVoucherQuery(r.companyID)
+	q.filter(filter)
+
+	var total int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM vouchers WHERE `+q.whereClause(), q.args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count vouchers: %w", err)
 	}
 
-	return vouchers, nil
+	vouchers, err := r.queryVouchers(q, filter.Sort, filter.Ascending, filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return vouchers, total, nil
+}
+
+func (r *voucherRepository) GetVouchersByPeriod(period string) ([]*domain.Voucher, error) {
+	q := newVoucherQuery(r.companyID)
+	q.filter(domain.VoucherFilter{Period: period})
+	return r.queryVouchers(q, domain.VoucherSortNumber, false, 0, 0)
 }
 
 func (r *voucherRepository) GetVouchersByCreatedBy(userID int) ([]*domain.Voucher, error) {
-	query := `
-		SELECT voucher_id, voucher_number, date, description, reference, total_amount, period, created_by, corrects_voucher_id, corrected_by_voucher_id
-		FROM vouchers
-		WHERE created_by = $1
-		ORDER BY voucher_number DESC
-	`
-	rows, err := r.db.Query(query, userID)
+	q := newVoucherQuery(r.companyID)
+	q.filter(domain.VoucherFilter{CreatedBy: &userID})
+	return r.queryVouchers(q, domain.VoucherSortNumber, false, 0, 0)
+}
+
+// GetVouchersByDateRange returns the booked vouchers dated between fromDate
+// and toDate in booking order
+func (r *voucherRepository) GetVouchersByDateRange(fromDate, toDate string) ([]*domain.Voucher, error) {
+	q := newVoucherQuery(r.companyID)
+	q.where("date >= ? AND date <= ?", fromDate, toDate)
+	q.filter(domain.VoucherFilter{Status: domain.VoucherBooked})
+	return r.queryVouchers(q, domain.VoucherSortNumber, true, 0, 0)
+}
+
+// queryVouchers runs q sorted by sort and returns at most limit vouchers
+// from offset; limit 0 returns all of them
+func (r *voucherRepository) queryVouchers(q *voucherQuery, sort string, ascending bool, limit, offset int) ([]*domain.Voucher, error) {
+	order, ok := voucherOrders[sort]
+	if !ok {
+		return nil, fmt.Errorf("unknown sort order %q", sort)
+	}
+	direction := "DESC"
+	if ascending {
+		direction = "ASC"
+	}
+
+	query := `SELECT ` + voucherColumns + ` FROM vouchers WHERE ` + q.whereClause() +
+		` ORDER BY ` + fmt.Sprintf(order, direction)
+	args := q.args
+	if limit > 0 {
+		args = append(args[:len(args):len(args)], limit, offset)
+		query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+	}
+
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get vouchers by user: %w", err)
+		return nil, fmt.Errorf("failed to get vouchers: %w", err)
 	}
 	defer rows.Close()
 
 	vouchers := make([]*domain.Voucher, 0)
 	for rows.Next() {
 		voucher := &domain.Voucher{}
-		err := rows.Scan(
-			&voucher.VoucherID,
-			&voucher.VoucherNumber,
-			&voucher.Date.Time,
-			&voucher.Description,
-			&voucher.Reference,
-			&voucher.TotalAmount,
-			&voucher.Period,
-			&voucher.CreatedBy,
-			&voucher.CorrectsVoucherID,
-			&voucher.CorrectedByVoucherID,
-		)
-		if err != nil {
+		if err := scanVoucher(rows, voucher); err != nil {
 			return nil, fmt.Errorf("failed to scan voucher: %w", err)
 		}
 		vouchers = append(vouchers, voucher)
@@ -227,10 +408,10 @@ func (r *voucherRepository) GetAllPeriods() ([]string, error) {
 	query := `
 		SELECT DISTINCT period
 		FROM vouchers
-		WHERE period IS NOT NULL AND period != ''
+		WHERE company_id = $1 AND period IS NOT NULL AND period != ''
 		ORDER BY period DESC
 	`
-	rows, err := r.db.Query(query)
+	rows, err := r.db.Query(query, r.companyID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get periods: %w", err)
 	}
@@ -252,34 +433,180 @@ func (r *voucherRepository) GetAllPeriods() ([]string, error) {
 	return periods, nil
 }
 
+// UpdateVoucher changes a draft. Booked vouchers are never updated.
 func (r *voucherRepository) UpdateVoucher(voucher *domain.Voucher) error {
 	query := `
 		UPDATE vouchers
-		SET date = $1, description = $2, reference = $3, total_amount = $4, period = $5, created_by = $6
-		WHERE voucher_id = $7
+		SET date = $1, series = $2, description = $3, reference = $4, total_amount = $5, period = $6, created_by = $7, updated_at = CURRENT_TIMESTAMP
+		WHERE voucher_id = $8 AND company_id = $9 AND status = 'draft'
 	`
-	_, err := r.db.Exec(query,
+	result, err := r.db.Exec(query,
 		voucher.Date.Time,
+		voucher.Series,
 		voucher.Description,
 		voucher.Reference,
 		voucher.TotalAmount,
 		voucher.Period,
 		voucher.CreatedBy,
 		voucher.VoucherID,
+		r.companyID,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to update voucher: %w", err)
 	}
 
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("draft voucher not found")
+	}
+
 	return nil
 }
 
+// DeleteVoucher deletes a draft. Booked vouchers are never deleted.
 func (r *voucherRepository) DeleteVoucher(voucherID int) error {
-	query := `DELETE FROM vouchers WHERE voucher_id = $1`
-	_, err := r.db.Exec(query, voucherID)
+	query := `DELETE FROM vouchers WHERE voucher_id = $1 AND company_id = $2 AND status = 'draft'`
+	result, err := r.db.Exec(query, voucherID, r.companyID)
 	if err != nil {
 		return fmt.Errorf("failed to delete voucher: %w", err)
 	}
 
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("draft voucher not found")
+	}
+
+	return nil
+}
+
+// LockChainHead returns the last link of the company's hash chain and locks
+// it until the transaction ends, so vouchers are chained one at a time. Only
+// meaningful on a repository from WithTx.
+func (r *voucherRepository) LockChainHead() (*domain.ChainHead, error) {
+	_, err := r.db.Exec(`INSERT INTO voucher_chain_heads (company_id) VALUES ($1) ON CONFLICT (company_id) DO NOTHING`, r.companyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create chain head: %w", err)
+	}
+
+	return r.getChainHead(`
+		SELECT last_position, COALESCE(last_hash, '')
+		FROM voucher_chain_heads
+		WHERE company_id = $1
+		FOR UPDATE
+	`)
+}
+
+// GetChainHead returns the last link of the company's hash chain, or
+// position 0 if nothing is chained yet
+func (r *voucherRepository) GetChainHead() (*domain.ChainHead, error) {
+	head, err := r.getChainHead(`
+		SELECT last_position, COALESCE(last_hash, '')
+		FROM voucher_chain_heads
+		WHERE company_id = $1
+	`)
+	if err == sql.ErrNoRows {
+		return &domain.ChainHead{}, nil
+	}
+
+	return head, err
+}
+
+func (r *voucherRepository) getChainHead(query string) (*domain.ChainHead, error) {
+	head := &domain.ChainHead{}
+	err := r.db.QueryRow(query, r.companyID).Scan(&head.Position, &head.Hash)
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chain head: %w", err)
+	}
+
+	return head, nil
+}
+
+// SealVoucher stores a voucher's place in the hash chain and moves the
+// chain head to it
+func (r *voucherRepository) SealVoucher(voucherID, position int, prevHash, hash string) error {
+	query := `
+		UPDATE vouchers
+		SET chain_position = $1, prev_hash = NULLIF($2, ''), hash = $3
+		WHERE voucher_id = $4 AND company_id = $5 AND hash IS NULL
+	`
+	result, err := r.db.Exec(query, position, prevHash, hash, voucherID, r.companyID)
+	if err != nil {
+		return fmt.Errorf("failed to seal voucher: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("voucher not found or already sealed")
+	}
+
+	query = `
+		UPDATE voucher_chain_heads
+		SET last_position = $1, last_hash = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE company_id = $3
+	`
+	if _, err := r.db.Exec(query, position, hash, r.companyID); err != nil {
+		return fmt.Errorf("failed to update chain head: %w", err)
+	}
+
 	return nil
 }
+
+// GetChain returns the vouchers in the hash chain in chain order, without
+// their lines. Only booked vouchers are in the chain; a chained voucher
+// turned back into a draft shows up as removed from it.
+func (r *voucherRepository) GetChain() ([]*domain.ChainLink, error) {
+	query := `
+		SELECT ` + voucherColumns + `, COALESCE(prev_hash, '')
+		FROM vouchers
+		WHERE company_id = $1 AND status = 'booked' AND chain_position IS NOT NULL
+		ORDER BY chain_position
+	`
+	rows, err := r.db.Query(query, r.companyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get voucher chain: %w", err)
+	}
+	defer rows.Close()
+
+	links := make([]*domain.ChainLink, 0)
+	for rows.Next() {
+		link := &domain.ChainLink{}
+		err := scanVoucher(rows, &link.Voucher, &link.PrevHash)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan voucher: %w", err)
+		}
+		links = append(links, link)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating vouchers: %w", err)
+	}
+
+	return links, nil
+}
+
+// CountUnchainedVouchers counts the vouchers booked before the hash chain
+// was introduced. Drafts are not booked and not counted.
+func (r *voucherRepository) CountUnchainedVouchers() (int, error) {
+	var count int
+	err := r.db.QueryRow(
+		`SELECT COUNT(*) FROM vouchers WHERE company_id = $1 AND status = 'booked' AND chain_position IS NULL`,
+		r.companyID,
+	).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count unchained vouchers: %w", err)
+	}
+
+	return count, nil
+}
@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"cmd/api/internal/domain"
+	"database/sql"
+	"fmt"
+)
+
+type VoucherSeriesRepository interface {
+	CreateSeries(series *domain.VoucherSeries) error
+	GetSeries(series string) (*domain.VoucherSeries, error)
+	GetAllSeries() ([]*domain.VoucherSeries, error)
+	UpdateSeries(series *domain.VoucherSeries) error
+	DeleteSeries(series string) error
+	WithTx(tx *sql.Tx) VoucherSeriesRepository
+	ForCompany(companyID int) VoucherSeriesRepository
+}
+
+type voucherSeriesRepository struct {
+	db        DBTX
+	companyID int
+}
+
+func NewVoucherSeriesRepository(db *sql.DB) VoucherSeriesRepository {
+	return &voucherSeriesRepository{db: db}
+}
+
+// WithTx returns a copy of the repository that runs its queries in tx
+func (r *voucherSeriesRepository) WithTx(tx *sql.Tx) VoucherSeriesRepository {
+	return &voucherSeriesRepository{db: tx, companyID: r.companyID}
+}
+
+// ForCompany returns a copy of the repository that only sees the voucher
+// series of companyID
+func (r *voucherSeriesRepository) ForCompany(companyID int) VoucherSeriesRepository {
+	return &voucherSeriesRepository{db: r.db, companyID: companyID}
+}
+
+func (r *voucherSeriesRepository) CreateSeries(series *domain.VoucherSeries) error {
+	query := `
+		INSERT INTO voucher_series (company_id, series, name)
+		VALUES ($1, $2, $3)
+	`
+	_, err := r.db.Exec(query, r.companyID, series.Series, series.Name)
+	if err != nil {
+		return fmt.Errorf("failed to create voucher series: %w", err)
+	}
+
+	return nil
+}
+
+func (r *voucherSeriesRepository) GetSeries(series string) (*domain.VoucherSeries, error) {
+	query := `
+		SELECT series, name
+		FROM voucher_series
+		WHERE series = $1 AND company_id = $2
+	`
+	voucherSeries := &domain.VoucherSeries{}
+	err := r.db.QueryRow(query, series, r.companyID).Scan(&voucherSeries.Series, &voucherSeries.Name)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("voucher series not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get voucher series: %w", err)
+	}
+
+	return voucherSeries, nil
+}
+
+func (r *voucherSeriesRepository) GetAllSeries() ([]*domain.VoucherSeries, error) {
+	query := `
+		SELECT series, name
+		FROM voucher_series
+		WHERE company_id = $1
+		ORDER BY series
+	`
+	rows, err := r.db.Query(query, r.companyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get voucher series: %w", err)
+	}
+	defer rows.Close()
+
+	allSeries := make([]*domain.VoucherSeries, 0)
+	for rows.Next() {
+		series := &domain.VoucherSeries{}
+		if err := rows.Scan(&series.Series, &series.Name); err != nil {
+			return nil, fmt.Errorf("failed to scan voucher series: %w", err)
+		}
+		allSeries = append(allSeries, series)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating voucher series: %w", err)
+	}
+
+	return allSeries, nil
+}
+
+// UpdateSeries renames a series. The code itself cannot change once vouchers
+// are numbered in it.
+func (r *voucherSeriesRepository) UpdateSeries(series *domain.VoucherSeries) error {
+	query := `
+		UPDATE voucher_series
+		SET name = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE series = $2 AND company_id = $3
+	`
+	result, err := r.db.Exec(query, series.Name, series.Series, r.companyID)
+	if err != nil {
+		return fmt.Errorf("failed to update voucher series: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("voucher series not found")
+	}
+
+	return nil
+}
+
+// DeleteSeries deletes a series. Series with vouchers cannot be deleted (the
+// foreign key refuses).
+func (r *voucherSeriesRepository) DeleteSeries(series string) error {
+	result, err := r.db.Exec(`DELETE FROM voucher_series WHERE series = $1 AND company_id = $2`, series, r.companyID)
+	if err != nil {
+		return fmt.Errorf("failed to delete voucher series: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("voucher series not found")
+	}
+
+	return nil
+}
@@ -0,0 +1,279 @@
+package repository
+
+import (
+	"cmd/api/internal/domain"
+	"database/sql"
+	"fmt"
+)
+
+type VoucherTemplateRepository interface {
+	CreateTemplate(template *domain.VoucherTemplate) error
+	GetTemplateByID(templateID int) (*domain.VoucherTemplate, error)
+	GetTemplateByName(name string) (*domain.VoucherTemplate, error)
+	GetAllTemplates() ([]*domain.VoucherTemplate, error)
+	UpdateTemplate(template *domain.VoucherTemplate) error
+	DeleteTemplate(templateID int) error
+	WithTx(tx *sql.Tx) VoucherTemplateRepository
+	ForCompany(companyID int) VoucherTemplateRepository
+}
+
+type voucherTemplateRepository struct {
+	db        DBTX
+	companyID int
+}
+
+func NewVoucherTemplateRepository(db *sql.DB) VoucherTemplateRepository {
+	return &voucherTemplateRepository{db: db}
+}
+
+// WithTx returns a copy of the repository that runs its queries in tx
+func (r *voucherTemplateRepository) WithTx(tx *sql.Tx) VoucherTemplateRepository {
+	return &voucherTemplateRepository{db: tx, companyID: r.companyID}
+}
+
+// ForCompany returns a copy of the repository that only sees the voucher
+// templates of companyID
+func (r *voucherTemplateRepository) ForCompany(companyID int) VoucherTemplateRepository {
+	return &voucherTemplateRepository{db: r.db, companyID: companyID}
+}
+
+// CreateTemplate inserts a template with its lines. Run it in a
+// transaction, so a failing line leaves no template behind.
+func (r *voucherTemplateRepository) CreateTemplate(template *domain.VoucherTemplate) error {
+	query := `
+		INSERT INTO voucher_templates (company_id, name, description, series, active, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING template_id, created_at, updated_at
+	`
+	err := r.db.QueryRow(query,
+		r.companyID,
+		template.Name,
+		template.Description,
+		template.Series,
+		template.Active,
+		template.CreatedBy,
+	).Scan(&template.TemplateID, &template.CreatedAt, &template.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create voucher template: %w", err)
+	}
+
+	return r.createLines(template)
+}
+
+func (r *voucherTemplateRepository) createLines(template *domain.VoucherTemplate) error {
+	query := `
+		INSERT INTO voucher_template_lines (company_id, template_id, line_no, account_no, side, amount, percentage,
+			tax_code, project_id, cost_center_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING line_id
+	`
+	for i := range template.Lines {
+		line := &template.Lines[i]
+		err := r.db.QueryRow(query,
+			r.companyID,
+			template.TemplateID,
+			i+1,
+			line.AccountNo,
+			line.Side,
+			line.Amount,
+			line.Percentage,
+			line.TaxCode,
+			line.ProjectID,
+			line.CostCenterID,
+		).Scan(&line.LineID)
+		if err != nil {
+			return fmt.Errorf("failed to create template line %d: %w", i+1, err)
+		}
+	}
+
+	return nil
+}
+
+func (r *voucherTemplateRepository) GetTemplateByID(templateID int) (*domain.VoucherTemplate, error) {
+	return r.getTemplate(`template_id = $1`, templateID)
+}
+
+func (r *voucherTemplateRepository) GetTemplateByName(name string) (*domain.VoucherTemplate, error) {
+	return r.getTemplate(`name = $1`, name)
+}
+
+func (r *voucherTemplateRepository) getTemplate(condition string, arg interface{}) (*domain.VoucherTemplate, error) {
+	query := `
+		SELECT template_id, name, description, series, active, created_by, created_at, updated_at
+		FROM voucher_templates
+		WHERE ` + condition + ` AND company_id = $2
+	`
+	template := &domain.VoucherTemplate{}
+	err := r.db.QueryRow(query, arg, r.companyID).Scan(
+		&template.TemplateID,
+		&template.Name,
+		&template.Description,
+		&template.Series,
+		&template.Active,
+		&template.CreatedBy,
+		&template.CreatedAt,
+		&template.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("voucher template not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get voucher template: %w", err)
+	}
+
+	lines, err := r.getLines(&template.TemplateID)
+	if err != nil {
+		return nil, err
+	}
+	template.Lines = lines[template.TemplateID]
+
+	return template, nil
+}
+
+// GetAllTemplates returns the templates with their lines, by name
+func (r *voucherTemplateRepository) GetAllTemplates() ([]*domain.VoucherTemplate, error) {
+	query := `
+		SELECT template_id, name, description, series, active, created_by, created_at, updated_at
+		FROM voucher_templates
+		WHERE company_id = $1
+		ORDER BY name
+	`
+	rows, err := r.db.Query(query, r.companyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get voucher templates: %w", err)
+	}
+	defer rows.Close()
+
+	templates := make([]*domain.VoucherTemplate, 0)
+	for rows.Next() {
+		template := &domain.VoucherTemplate{}
+		err := rows.Scan(
+			&template.TemplateID,
+			&template.Name,
+			&template.Description,
+			&template.Series,
+			&template.Active,
+			&template.CreatedBy,
+			&template.CreatedAt,
+			&template.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan voucher template: %w", err)
+		}
+		templates = append(templates, template)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating voucher templates: %w", err)
+	}
+
+	lines, err := r.getLines(nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, template := range templates {
+		template.Lines = lines[template.TemplateID]
+	}
+
+	return templates, nil
+}
+
+// getLines returns the lines of a template, or with templateID nil of every
+// template, per template ID in line order
+func (r *voucherTemplateRepository) getLines(templateID *int) (map[int][]domain.VoucherTemplateLine, error) {
+	query := `
+		SELECT template_id, line_id, account_no, side, amount, percentage, tax_code, project_id, cost_center_id
+		FROM voucher_template_lines
+		WHERE company_id = $1 AND ($2::int IS NULL OR template_id = $2)
+		ORDER BY template_id, line_no
+	`
+	rows, err := r.db.Query(query, r.companyID, templateID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get template lines: %w", err)
+	}
+	defer rows.Close()
+
+	lines := make(map[int][]domain.VoucherTemplateLine)
+	for rows.Next() {
+		var id int
+		var line domain.VoucherTemplateLine
+		err := rows.Scan(
+			&id,
+			&line.LineID,
+			&line.AccountNo,
+			&line.Side,
+			&line.Amount,
+			&line.Percentage,
+			&line.TaxCode,
+			&line.ProjectID,
+			&line.CostCenterID,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan template line: %w", err)
+		}
+		lines[id] = append(lines[id], line)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating template lines: %w", err)
+	}
+
+	return lines, nil
+}
+
+// UpdateTemplate updates a template and replaces its lines. Run it in a
+// transaction.
+func (r *voucherTemplateRepository) UpdateTemplate(template *domain.VoucherTemplate) error {
+	query := `
+		UPDATE voucher_templates
+		SET name = $1, description = $2, series = $3, active = $4, updated_at = CURRENT_TIMESTAMP
+		WHERE template_id = $5 AND company_id = $6
+		RETURNING created_by, created_at, updated_at
+	`
+	err := r.db.QueryRow(query,
+		template.Name,
+		template.Description,
+		template.Series,
+		template.Active,
+		template.TemplateID,
+		r.companyID,
+	).Scan(&template.CreatedBy, &template.CreatedAt, &template.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("voucher template not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update voucher template: %w", err)
+	}
+
+	_, err = r.db.Exec(
+		`DELETE FROM voucher_template_lines WHERE template_id = $1 AND company_id = $2`,
+		template.TemplateID, r.companyID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to delete template lines: %w", err)
+	}
+
+	return r.createLines(template)
+}
+
+// DeleteTemplate deletes a template and its lines. Vouchers created from it
+// are not affected.
+func (r *voucherTemplateRepository) DeleteTemplate(templateID int) error {
+	result, err := r.db.Exec(
+		`DELETE FROM voucher_templates WHERE template_id = $1 AND company_id = $2`,
+		templateID, r.companyID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to delete voucher template: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("voucher template not found")
+	}
+
+	return nil
+}
@@ -16,8 +16,26 @@ func SetupRoutes(
 	authHandler *handlers.AuthHandler,
 	pdfHandler *handlers.PDFHandler,
 	reportHandler *handlers.ReportHandler,
+	periodHandler *handlers.PeriodHandler,
+	fiscalYearHandler *handlers.FiscalYearHandler,
+	exportHandler *handlers.ExportHandler,
+	importHandler *handlers.ImportHandler,
+	vatHandler *handlers.VATHandler,
+	projectHandler *handlers.ProjectHandler,
+	costCenterHandler *handlers.CostCenterHandler,
+	companyHandler *handlers.CompanyHandler,
+	voucherSeriesHandler *handlers.VoucherSeriesHandler,
+	auditHandler *handlers.AuditHandler,
+	attachmentHandler *handlers.AttachmentHandler,
+	searchHandler *handlers.SearchHandler,
+	bankHandler *handlers.BankHandler,
+	bankRuleHandler *handlers.BankRuleHandler,
+	voucherTemplateHandler *handlers.VoucherTemplateHandler,
 	authMiddleware gin.HandlerFunc) {
 
+	// The books of the active company in the token
+	requireCompany := middleware.RequireCompany()
+
 	v1 := router.Group("/api/v1")
 	{
 		auth := v1.Group("/auth")
@@ -27,8 +45,12 @@ func SetupRoutes(
 			auth.POST("/logout", authHandler.Logout)
 			auth.POST("/refresh", authHandler.RefreshToken)
 			auth.GET("/me", authMiddleware, authHandler.GetCurrentUser)
+			auth.POST("/switch-company", authMiddleware, authHandler.SwitchCompany)
 		}
 
+		// Users are not part of a company: callers see themselves and the
+		// members of their companies, and only change themselves, unless
+		// they are an administrator
 		users := v1.Group("/users", authMiddleware)
 		{
 			users.POST("", userHandler.CreateUser)
@@ -38,7 +60,19 @@ func SetupRoutes(
 			users.DELETE("/:id", userHandler.DeleteUser)
 		}
 
-		accounts := v1.Group("/accounts", authMiddleware)
+		companies := v1.Group("/companies", authMiddleware)
+		{
+			companies.GET("", companyHandler.GetMyCompanies)
+			companies.POST("", companyHandler.CreateCompany)
+			companies.GET("/current", requireCompany, companyHandler.GetCurrentCompany)
+			companies.GET("/current/members", requireCompany, companyHandler.GetMembers)
+			// Only Admin can change the company and its members
+			companies.PUT("/current", requireCompany, middleware.RequireRole("Admin"), companyHandler.UpdateCurrentCompany)
+			companies.POST("/current/members", requireCompany, middleware.RequireRole("Admin"), companyHandler.SaveMember)
+			companies.DELETE("/current/members/:userId", requireCompany, middleware.RequireRole("Admin"), companyHandler.RemoveMember)
+		}
+
+		accounts := v1.Group("/accounts", authMiddleware, requireCompany)
 		{
 			accounts.POST("", accountHandler.CreateAccount)
 			accounts.GET("", accountHandler.GetAllAccounts)
@@ -49,7 +83,34 @@ func SetupRoutes(
 			accounts.DELETE("/:accountNo", accountHandler.DeleteAccount)
 		}
 
-		lineItems := v1.Group("/lineitems", authMiddleware)
+		projects := v1.Group("/projects", authMiddleware, requireCompany)
+		{
+			projects.POST("", projectHandler.CreateProject)
+			projects.GET("", projectHandler.GetAllProjects)
+			projects.GET("/:id", projectHandler.GetProjectByID)
+			projects.PUT("/:id", projectHandler.UpdateProject)
+			projects.DELETE("/:id", projectHandler.DeleteProject)
+		}
+
+		costCenters := v1.Group("/cost-centers", authMiddleware, requireCompany)
+		{
+			costCenters.POST("", costCenterHandler.CreateCostCenter)
+			costCenters.GET("", costCenterHandler.GetAllCostCenters)
+			costCenters.GET("/:id", costCenterHandler.GetCostCenterByID)
+			costCenters.PUT("/:id", costCenterHandler.UpdateCostCenter)
+			costCenters.DELETE("/:id", costCenterHandler.DeleteCostCenter)
+		}
+
+		voucherSeries := v1.Group("/voucher-series", authMiddleware, requireCompany)
+		{
+			voucherSeries.GET("", voucherSeriesHandler.GetAllSeries)
+			// Only Admin can change the voucher series
+			voucherSeries.POST("", middleware.RequireRole("Admin"), voucherSeriesHandler.CreateSeries)
+			voucherSeries.PUT("/:series", middleware.RequireRole("Admin"), voucherSeriesHandler.UpdateSeries)
+			voucherSeries.DELETE("/:series", middleware.RequireRole("Admin"), voucherSeriesHandler.DeleteSeries)
+		}
+
+		lineItems := v1.Group("/lineitems", authMiddleware, requireCompany)
 		{
 			lineItems.POST("", lineItemHandler.CreateLineItem)
 			lineItems.GET("/:id", lineItemHandler.GetLineItemByID)
@@ -59,26 +120,112 @@ func SetupRoutes(
 			lineItems.DELETE("/:id", lineItemHandler.DeleteLineItem)
 		}
 
-		vouchers := v1.Group("/vouchers", authMiddleware)
+		vouchers := v1.Group("/vouchers", authMiddleware, requireCompany)
 		{
 			vouchers.POST("", voucherHandler.CreateVoucher)
+			vouchers.POST("/vat-preview", voucherHandler.PreviewVATLines)
+			vouchers.POST("/from-template/:id", voucherTemplateHandler.CreateVoucherFromTemplate)
 			vouchers.GET("", voucherHandler.GetAllVouchers)
 			vouchers.GET("/periods", voucherHandler.GetAllPeriods)
+			vouchers.GET("/verify-chain", voucherHandler.VerifyChain)
 			vouchers.GET("/:id", voucherHandler.GetVoucherByID)
 			vouchers.GET("/period/:period", voucherHandler.GetVouchersByPeriod)
 			vouchers.GET("/user/:userId", voucherHandler.GetVouchersByCreatedBy)
 			vouchers.GET("/:id/validate", voucherHandler.ValidateVoucherBalance)
 			vouchers.POST("/:id/correct", voucherHandler.CreateCorrectionVoucher)
 			vouchers.POST("/:id/correct-with-changes", voucherHandler.CreateCorrectionWithChanges)
+			vouchers.POST("/:id/book", voucherHandler.BookVoucher)
 			vouchers.GET("/:id/pdf", pdfHandler.GenerateVoucherPDF)
-			// Only Admin can update or delete vouchers
-			vouchers.PUT("/:id", middleware.RequireRole("Admin"), voucherHandler.UpdateVoucher)
-			vouchers.DELETE("/:id", middleware.RequireRole("Admin"), voucherHandler.DeleteVoucher)
+			vouchers.POST("/:id/attachments", attachmentHandler.UploadAttachment)
+			vouchers.GET("/:id/attachments", attachmentHandler.GetAttachments)
+			vouchers.GET("/:id/attachments/:attachmentId", attachmentHandler.DownloadAttachment)
+			vouchers.DELETE("/:id/attachments/:attachmentId", attachmentHandler.DeleteAttachment)
+			// Drafts are changed and deleted by their creator, booked
+			// vouchers only through corrections
+			vouchers.PUT("/:id", voucherHandler.UpdateVoucher)
+			vouchers.DELETE("/:id", voucherHandler.DeleteVoucher)
+		}
+
+		periods := v1.Group("/periods", authMiddleware, requireCompany)
+		{
+			periods.GET("", periodHandler.GetAllPeriods)
+			periods.GET("/:period", periodHandler.GetPeriod)
+			// Only Admin can lock, reopen or close periods
+			periods.POST("/:period/lock", middleware.RequireRole("Admin"), periodHandler.LockPeriod)
+			periods.POST("/:period/reopen", middleware.RequireRole("Admin"), periodHandler.ReopenPeriod)
+			periods.POST("/:period/close", middleware.RequireRole("Admin"), periodHandler.ClosePeriod)
+		}
+
+		fiscalYears := v1.Group("/fiscal-years", authMiddleware, requireCompany)
+		{
+			fiscalYears.GET("", fiscalYearHandler.GetAllFiscalYears)
+			fiscalYears.GET("/:id", fiscalYearHandler.GetFiscalYearByID)
+			// Only Admin can create fiscal years and do the year-end closing
+			fiscalYears.POST("", middleware.RequireRole("Admin"), fiscalYearHandler.CreateFiscalYear)
+			fiscalYears.POST("/:id/close", middleware.RequireRole("Admin"), fiscalYearHandler.CloseFiscalYear)
 		}
 
-		reports := v1.Group("/reports", authMiddleware)
+		reports := v1.Group("/reports", authMiddleware, requireCompany)
 		{
 			reports.GET("/income-statement", reportHandler.GetIncomeStatement)
+			reports.GET("/balance-sheet", reportHandler.GetBalanceSheet)
+			reports.GET("/trial-balance", reportHandler.GetTrialBalance)
+			reports.GET("/vat-return", vatHandler.GetVATReturn)
+			reports.GET("/vat-return/eskd", vatHandler.ExportESKD)
+			reports.POST("/vat-return/settlement", middleware.RequireRole("Admin"), vatHandler.BookVATSettlement)
+		}
+
+		export := v1.Group("/export", authMiddleware, requireCompany)
+		{
+			export.GET("/sie", exportHandler.ExportSIE)
+		}
+
+		imports := v1.Group("/import", authMiddleware, requireCompany)
+		{
+			imports.POST("/sie", middleware.RequireRole("Admin"), importHandler.ImportSIE)
+		}
+
+		// The audit trail is read-only and for Admin and Manager
+		audit := v1.Group("/audit", authMiddleware, requireCompany, middleware.RequireRole("Admin", "Manager"))
+		{
+			audit.GET("", auditHandler.GetAuditTrail)
+		}
+
+		search := v1.Group("/search", authMiddleware, requireCompany)
+		{
+			search.GET("", searchHandler.Search)
+		}
+
+		// Bank statements are imported and reconciled by the bookkeepers
+		bank := v1.Group("/bank", authMiddleware, requireCompany)
+		{
+			bank.POST("/import", middleware.RequireRole("Admin", "Bookkeeper"), bankHandler.ImportStatement)
+			bank.POST("/match", middleware.RequireRole("Admin", "Bookkeeper"), bankHandler.MatchAccount)
+			bank.GET("/transactions", bankHandler.GetTransactions)
+			bank.GET("/transactions/:id/suggestions", bankHandler.GetSuggestions)
+			bank.POST("/transactions/:id/match", middleware.RequireRole("Admin", "Bookkeeper"), bankHandler.MatchTransaction)
+			bank.DELETE("/transactions/:id/match", middleware.RequireRole("Admin", "Bookkeeper"), bankHandler.UnmatchTransaction)
+			bank.GET("/reconciliation", bankHandler.GetReconciliation)
+
+			// Booking rules turn recurring bank transactions into drafts
+			bank.GET("/rules", bankRuleHandler.GetAllRules)
+			bank.POST("/rules", middleware.RequireRole("Admin", "Bookkeeper"), bankRuleHandler.CreateRule)
+			bank.POST("/rules/apply", middleware.RequireRole("Admin", "Bookkeeper"), bankRuleHandler.ApplyRules)
+			bank.GET("/rules/:id", bankRuleHandler.GetRuleByID)
+			bank.PUT("/rules/:id", middleware.RequireRole("Admin", "Bookkeeper"), bankRuleHandler.UpdateRule)
+			bank.DELETE("/rules/:id", middleware.RequireRole("Admin", "Bookkeeper"), bankRuleHandler.DeleteRule)
+			bank.GET("/rules/:id/applications", bankRuleHandler.GetApplications)
+		}
+
+		// Konteringsmallar for recurring entries, used through
+		// POST /vouchers/from-template/:id
+		voucherTemplates := v1.Group("/voucher-templates", authMiddleware, requireCompany)
+		{
+			voucherTemplates.GET("", voucherTemplateHandler.GetAllTemplates)
+			voucherTemplates.POST("", middleware.RequireRole("Admin", "Bookkeeper"), voucherTemplateHandler.CreateTemplate)
+			voucherTemplates.GET("/:id", voucherTemplateHandler.GetTemplateByID)
+			voucherTemplates.PUT("/:id", middleware.RequireRole("Admin", "Bookkeeper"), voucherTemplateHandler.UpdateTemplate)
+			voucherTemplates.DELETE("/:id", middleware.RequireRole("Admin", "Bookkeeper"), voucherTemplateHandler.DeleteTemplate)
 		}
 	}
 }
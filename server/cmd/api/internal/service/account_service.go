@@ -3,6 +3,7 @@ package service
 import (
 	"cmd/api/internal/domain"
 	"cmd/api/internal/repository"
+	"database/sql"
 	"errors"
 	"fmt"
 
@@ -10,19 +11,63 @@ import (
 )
 
 type AccountService struct {
-	repository repository.AccountRepository
-	validate   *validator.Validate
+	repository           repository.AccountRepository
+	projectRepository    repository.ProjectRepository
+	costCenterRepository repository.CostCenterRepository
+	auditRepository      repository.AuditRepository
+	txManager            repository.TxManager
+	validate             *validator.Validate
+	actorID              int
 }
 
-func NewAccountService(repo repository.AccountRepository) *AccountService {
+func NewAccountService(
+	repo repository.AccountRepository,
+	projectRepo repository.ProjectRepository,
+	costCenterRepo repository.CostCenterRepository,
+	auditRepo repository.AuditRepository,
+	txManager repository.TxManager,
+) *AccountService {
 	return &AccountService{
-		repository: repo,
-		validate:   validator.New(),
+		repository:           repo,
+		projectRepository:    projectRepo,
+		costCenterRepository: costCenterRepo,
+		auditRepository:      auditRepo,
+		txManager:            txManager,
+		validate:             validator.New(),
 	}
 }
 
+// ForCompany returns a copy of the service that works on the books of companyID
+func (s *AccountService) ForCompany(companyID int) *AccountService {
+	scoped := *s
+	scoped.repository = s.repository.ForCompany(companyID)
+	scoped.projectRepository = s.projectRepository.ForCompany(companyID)
+	scoped.costCenterRepository = s.costCenterRepository.ForCompany(companyID)
+	scoped.auditRepository = s.auditRepository.ForCompany(companyID)
+	return &scoped
+}
+
+// AsUser returns a copy of the service that records its changes in the
+// audit trail as made by userID
+func (s *AccountService) AsUser(userID int) *AccountService {
+	scoped := *s
+	scoped.actorID = userID
+	return &scoped
+}
+
 // CreateAccount creates a new account
 func (s *AccountService) CreateAccount(account *domain.Account) error {
+	if err := s.validateNewAccount(account); err != nil {
+		return err
+	}
+
+	return s.txManager.WithTransaction(func(tx *sql.Tx) error {
+		return s.createAccount(tx, account)
+	})
+}
+
+// validateNewAccount checks an account before it is created
+func (s *AccountService) validateNewAccount(account *domain.Account) error {
 	// Validate input
 	if err := s.validate.Struct(account); err != nil {
 		return fmt.Errorf("validation failed: %w", err)
@@ -49,13 +94,16 @@ func (s *AccountService) CreateAccount(account *domain.Account) error {
 		return errors.New("standard side must be either 'Debit' or 'Credit'")
 	}
 
-	// Create account
-	err = s.repository.CreateAccount(account)
-	if err != nil {
+	return nil
+}
+
+// createAccount creates a validated account in tx
+func (s *AccountService) createAccount(tx *sql.Tx, account *domain.Account) error {
+	if err := s.repository.WithTx(tx).CreateAccount(account); err != nil {
 		return fmt.Errorf("failed to create account: %w", err)
 	}
 
-	return nil
+	return recordAudit(s.auditRepository.WithTx(tx), s.actorID, domain.AuditEntityAccount, account.AccountNo, nil, account)
 }
 
 // GetAccountByNo retrieves an account by account number
@@ -128,12 +176,13 @@ func (s *AccountService) UpdateAccount(account *domain.Account) error {
 	}
 
 	// Update account
-	err = s.repository.UpdateAccount(account)
-	if err != nil {
-		return fmt.Errorf("failed to update account: %w", err)
-	}
+	return s.txManager.WithTransaction(func(tx *sql.Tx) error {
+		if err := s.repository.WithTx(tx).UpdateAccount(account); err != nil {
+			return fmt.Errorf("failed to update account: %w", err)
+		}
 
-	return nil
+		return recordAudit(s.auditRepository.WithTx(tx), s.actorID, domain.AuditEntityAccount, account.AccountNo, existingAccount, account)
+	})
 }
 
 // DeleteAccount deletes an account by account number
@@ -152,16 +201,18 @@ func (s *AccountService) DeleteAccount(accountNo int) error {
 	}
 
 	// Delete account
-	err = s.repository.DeleteAccount(accountNo)
-	if err != nil {
-		return fmt.Errorf("failed to delete account: %w", err)
-	}
+	return s.txManager.WithTransaction(func(tx *sql.Tx) error {
+		if err := s.repository.WithTx(tx).DeleteAccount(accountNo); err != nil {
+			return fmt.Errorf("failed to delete account: %w", err)
+		}
 
-	return nil
+		return recordAudit(s.auditRepository.WithTx(tx), s.actorID, domain.AuditEntityAccount, accountNo, existingAccount, nil)
+	})
 }
 
-// GetLedger retrieves ledger entries for an account
-func (s *AccountService) GetLedger(accountNo int, period string) ([]*domain.LedgerEntry, error) {
+// GetLedger retrieves ledger entries for an account, limited to the
+// project and cost centre in filter if set
+func (s *AccountService) GetLedger(accountNo int, period string, filter domain.DimensionFilter) ([]*domain.LedgerEntry, error) {
 	if accountNo <= 0 {
 		return nil, errors.New("invalid account number")
 	}
@@ -176,10 +227,64 @@ func (s *AccountService) GetLedger(accountNo int, period string) ([]*domain.Ledg
 	}
 
 	// Get ledger entries
-	entries, err := s.repository.GetLedger(accountNo, period)
+	entries, err := s.repository.GetLedger(accountNo, period, filter)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get ledger: %w", err)
 	}
 
 	return entries, nil
 }
+
+// GetLedgerByDimension splits the ledger of an account per project or cost
+// centre, with the running balance starting over in each group. Lines
+// without one are in a last group with a nil ID.
+func (s *AccountService) GetLedgerByDimension(accountNo int, period string, filter domain.DimensionFilter, groupBy string) ([]domain.LedgerGroup, error) {
+	objects, err := dimensionObjects(s.projectRepository, s.costCenterRepository, groupBy)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := s.GetLedger(accountNo, period, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make(map[int]*domain.LedgerGroup)
+	var ids []int
+	for _, entry := range entries {
+		dimID := entry.ProjectID
+		if groupBy == domain.DimensionCostCenter {
+			dimID = entry.CostCenterID
+		}
+		id := 0
+		if dimID != nil {
+			id = *dimID
+		}
+
+		group, ok := groups[id]
+		if !ok {
+			group = &domain.LedgerGroup{
+				ID:      dimensionID(id),
+				Code:    objects[id].code,
+				Name:    objects[id].name,
+				Entries: make([]*domain.LedgerEntry, 0),
+			}
+			groups[id] = group
+			ids = append(ids, id)
+		}
+
+		group.TotalDebit = group.TotalDebit.Add(entry.DebitAmount)
+		group.TotalCredit = group.TotalCredit.Add(entry.CreditAmount)
+		group.Balance = group.Balance.Add(entry.DebitAmount).Sub(entry.CreditAmount)
+		entry.Balance = group.Balance
+		group.Entries = append(group.Entries, entry)
+	}
+
+	sortDimensionIDs(ids, objects)
+	result := make([]domain.LedgerGroup, 0, len(ids))
+	for _, id := range ids {
+		result = append(result, *groups[id])
+	}
+
+	return result, nil
+}
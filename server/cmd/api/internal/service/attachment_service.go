@@ -0,0 +1,238 @@
+package service
+
+import (
+	"cmd/api/internal/domain"
+	"cmd/api/internal/repository"
+	"cmd/api/internal/storage"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+)
+
+// MaxAttachmentSize limits the size of an uploaded attachment
+const MaxAttachmentSize = 20 << 20
+
+// attachmentTypes are the MIME types accepted as attachments, as detected
+// from the file content
+var attachmentTypes = map[string]bool{
+	"application/pdf": true,
+	"image/jpeg":      true,
+	"image/png":       true,
+	"image/gif":       true,
+	"image/webp":      true,
+}
+
+type AttachmentService struct {
+	repository        repository.AttachmentRepository
+	voucherRepository repository.VoucherRepository
+	auditRepository   repository.AuditRepository
+	storage           storage.Storage
+	txManager         repository.TxManager
+	actorID           int
+}
+
+func NewAttachmentService(
+	repo repository.AttachmentRepository,
+	voucherRepo repository.VoucherRepository,
+	auditRepo repository.AuditRepository,
+	store storage.Storage,
+	txManager repository.TxManager,
+) *AttachmentService {
+	return &AttachmentService{
+		repository:        repo,
+		voucherRepository: voucherRepo,
+		auditRepository:   auditRepo,
+		storage:           store,
+		txManager:         txManager,
+	}
+}
+
+// ForCompany returns a copy of the service that works on the books of companyID
+func (s *AttachmentService) ForCompany(companyID int) *AttachmentService {
+	scoped := *s
+	scoped.repository = s.repository.ForCompany(companyID)
+	scoped.voucherRepository = s.voucherRepository.ForCompany(companyID)
+	scoped.auditRepository = s.auditRepository.ForCompany(companyID)
+	return &scoped
+}
+
+// AsUser returns a copy of the service that records its changes in the
+// audit trail as made by userID
+func (s *AttachmentService) AsUser(userID int) *AttachmentService {
+	scoped := *s
+	scoped.actorID = userID
+	return &scoped
+}
+
+// UploadAttachment attaches a PDF or image to a voucher. The type is
+// detected from the content, not the file name. When checksum is given it
+// must be the SHA-256 of data in hex, so a damaged upload is refused.
+func (s *AttachmentService) UploadAttachment(voucherID int, fileName string, data []byte, checksum string) (*domain.Attachment, error) {
+	if voucherID <= 0 {
+		return nil, errors.New("invalid voucher ID")
+	}
+
+	fileName = strings.TrimSpace(filepath.Base(strings.ReplaceAll(fileName, "\\", "/")))
+	if fileName == "" || fileName == "." || fileName == "/" {
+		return nil, errors.New("file name is required")
+	}
+	if len(fileName) > 255 {
+		return nil, errors.New("file name is too long")
+	}
+
+	if len(data) == 0 {
+		return nil, errors.New("file is empty")
+	}
+	if len(data) > MaxAttachmentSize {
+		return nil, fmt.Errorf("file is larger than %d MB", MaxAttachmentSize>>20)
+	}
+
+	contentType := http.DetectContentType(data)
+	if !attachmentTypes[contentType] {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedAttachmentType, contentType)
+	}
+
+	sum := sha256.Sum256(data)
+	digest := hex.EncodeToString(sum[:])
+	if checksum != "" && !strings.EqualFold(strings.TrimSpace(checksum), digest) {
+		return nil, ErrAttachmentChecksum
+	}
+
+	if _, err := s.voucherRepository.GetVoucherByID(voucherID); err != nil {
+		return nil, fmt.Errorf("failed to get voucher: %w", err)
+	}
+
+	existing, err := s.repository.GetAttachmentsByVoucherID(voucherID)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range existing {
+		if a.Checksum == digest {
+			return nil, fmt.Errorf("%w: %s", ErrAttachmentExists, a.FileName)
+		}
+	}
+
+	attachment := &domain.Attachment{
+		VoucherID:   voucherID,
+		FileName:    fileName,
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		Checksum:    digest,
+		StorageKey:  fmt.Sprintf("vouchers/%d/%s", voucherID, digest),
+		UploadedBy:  s.actorID,
+	}
+
+	// The file is stored first and removed again if the row cannot be
+	// written, so no row ever points to a missing file. A concurrent upload
+	// of the same file loses on the unique checksum; the file under its key
+	// then belongs to the other row and is kept.
+	if err := s.storage.Save(attachment.StorageKey, data); err != nil {
+		return nil, fmt.Errorf("failed to store attachment: %w", err)
+	}
+
+	err = s.txManager.WithTransaction(func(tx *sql.Tx) error {
+		created, err := s.repository.WithTx(tx).CreateAttachment(attachment)
+		if err != nil {
+			return err
+		}
+		if !created {
+			return ErrAttachmentExists
+		}
+
+		return recordAudit(s.auditRepository.WithTx(tx), s.actorID, domain.AuditEntityAttachment, attachment.AttachmentID, nil, attachment)
+	})
+	if errors.Is(err, ErrAttachmentExists) {
+		return nil, err
+	}
+	if err != nil {
+		_ = s.storage.Delete(attachment.StorageKey)
+		return nil, fmt.Errorf("failed to create attachment: %w", err)
+	}
+
+	return attachment, nil
+}
+
+// GetAttachments returns the attachments of a voucher
+func (s *AttachmentService) GetAttachments(voucherID int) ([]*domain.Attachment, error) {
+	if voucherID <= 0 {
+		return nil, errors.New("invalid voucher ID")
+	}
+
+	attachments, err := s.repository.GetAttachmentsByVoucherID(voucherID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attachments: %w", err)
+	}
+
+	return attachments, nil
+}
+
+// GetAttachmentFile returns an attachment with its file. The file is checked
+// against the stored checksum, so a file changed in the storage is not
+// handed out as the voucher's underlag.
+func (s *AttachmentService) GetAttachmentFile(voucherID, attachmentID int) (*domain.Attachment, []byte, error) {
+	if voucherID <= 0 || attachmentID <= 0 {
+		return nil, nil, errors.New("invalid attachment ID")
+	}
+
+	attachment, err := s.repository.GetAttachmentByID(voucherID, attachmentID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	data, err := s.storage.Load(attachment.StorageKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read attachment: %w", err)
+	}
+
+	sum := sha256.Sum256(data)
+	if hex.EncodeToString(sum[:]) != attachment.Checksum {
+		return nil, nil, fmt.Errorf("attachment %d does not match its checksum", attachmentID)
+	}
+
+	return attachment, data, nil
+}
+
+// DeleteAttachment removes an attachment from a draft. Only the creator of
+// the draft can remove it; the attachments of booked vouchers are part of
+// the verifikation and are kept.
+func (s *AttachmentService) DeleteAttachment(voucherID, attachmentID int) error {
+	if voucherID <= 0 || attachmentID <= 0 {
+		return errors.New("invalid attachment ID")
+	}
+
+	var attachment *domain.Attachment
+	err := s.txManager.WithTransaction(func(tx *sql.Tx) error {
+		voucher, err := s.voucherRepository.WithTx(tx).GetVoucherByIDForUpdate(voucherID)
+		if err != nil {
+			return err
+		}
+		if err := ensureDraftOf(voucher, s.actorID); err != nil {
+			return err
+		}
+
+		attachmentRepo := s.repository.WithTx(tx)
+		attachment, err = attachmentRepo.GetAttachmentByID(voucherID, attachmentID)
+		if err != nil {
+			return err
+		}
+		if err := attachmentRepo.DeleteAttachment(voucherID, attachmentID); err != nil {
+			return err
+		}
+
+		return recordAudit(s.auditRepository.WithTx(tx), s.actorID, domain.AuditEntityAttachment, attachmentID, attachment, nil)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete attachment: %w", err)
+	}
+
+	if err := s.storage.Delete(attachment.StorageKey); err != nil {
+		return fmt.Errorf("attachment deleted but its file could not be removed: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"bytes"
+	"cmd/api/internal/domain"
+	"cmd/api/internal/repository"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+type AuditService struct {
+	repository repository.AuditRepository
+}
+
+func NewAuditService(repo repository.AuditRepository) *AuditService {
+	return &AuditService{
+		repository: repo,
+	}
+}
+
+// ForCompany returns a copy of the service that reads the audit trail of companyID
+func (s *AuditService) ForCompany(companyID int) *AuditService {
+	scoped := *s
+	scoped.repository = s.repository.ForCompany(companyID)
+	return &scoped
+}
+
+// GetEntries returns the audit trail, newest first
+func (s *AuditService) GetEntries(filter domain.AuditFilter) ([]*domain.AuditEntry, error) {
+	if filter.From != nil && filter.To != nil && filter.To.Before(*filter.From) {
+		return nil, errors.New("to date must not be before from date")
+	}
+
+	entries, err := s.repository.GetEntries(filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get audit trail: %w", err)
+	}
+
+	return entries, nil
+}
+
+// recordAudit writes a change made by userID to the audit trail. Pass the
+// repository of the transaction making the change, so the entry is written
+// or rolled back with it. before is nil for a creation and after is nil for
+// a deletion; everything else is an update.
+func recordAudit(repo repository.AuditRepository, userID int, entity string, entityID interface{}, before, after interface{}) error {
+	beforeJSON, err := auditJSON(before)
+	if err != nil {
+		return err
+	}
+	afterJSON, err := auditJSON(after)
+	if err != nil {
+		return err
+	}
+
+	action := domain.AuditUpdate
+	switch {
+	case beforeJSON == nil:
+		action = domain.AuditCreate
+	case afterJSON == nil:
+		action = domain.AuditDelete
+	}
+
+	return repo.CreateEntry(&domain.AuditEntry{
+		UserID:   userID,
+		Entity:   entity,
+		EntityID: fmt.Sprint(entityID),
+		Action:   action,
+		Before:   beforeJSON,
+		After:    afterJSON,
+	})
+}
+
+// auditJSON encodes a state for the audit trail; nil (also a nil pointer)
+// gives no document
+func auditJSON(state interface{}) (json.RawMessage, error) {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode audit entry: %w", err)
+	}
+	if bytes.Equal(data, []byte("null")) {
+		return nil, nil
+	}
+
+	return data, nil
+}
@@ -0,0 +1,387 @@
+package service
+
+import (
+	"cmd/api/internal/domain"
+	"cmd/api/internal/repository"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+type BankRuleService struct {
+	repository        repository.BankRuleRepository
+	accountRepository repository.AccountRepository
+	voucherService    *VoucherService
+	auditRepository   repository.AuditRepository
+	txManager         repository.TxManager
+	validate          *validator.Validate
+	actorID           int
+}
+
+func NewBankRuleService(
+	repo repository.BankRuleRepository,
+	accountRepo repository.AccountRepository,
+	voucherService *VoucherService,
+	auditRepo repository.AuditRepository,
+	txManager repository.TxManager,
+) *BankRuleService {
+	return &BankRuleService{
+		repository:        repo,
+		accountRepository: accountRepo,
+		voucherService:    voucherService,
+		auditRepository:   auditRepo,
+		txManager:         txManager,
+		validate:          validator.New(),
+	}
+}
+
+// ForCompany returns a copy of the service that works on the books of companyID
+func (s *BankRuleService) ForCompany(companyID int) *BankRuleService {
+	scoped := *s
+	scoped.repository = s.repository.ForCompany(companyID)
+	scoped.accountRepository = s.accountRepository.ForCompany(companyID)
+	scoped.voucherService = s.voucherService.ForCompany(companyID)
+	scoped.auditRepository = s.auditRepository.ForCompany(companyID)
+	return &scoped
+}
+
+// AsUser returns a copy of the service that records its changes in the
+// audit trail as made by userID
+func (s *BankRuleService) AsUser(userID int) *BankRuleService {
+	scoped := *s
+	scoped.voucherService = s.voucherService.AsUser(userID)
+	scoped.actorID = userID
+	return &scoped
+}
+
+// compileRulePattern compiles a rule pattern; patterns ignore case, since
+// the banks write the same counterparty in upper or mixed case
+func compileRulePattern(pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, nil
+	}
+	return regexp.Compile("(?i)" + pattern)
+}
+
+// validateRule checks a rule before it is saved
+func (s *BankRuleService) validateRule(rule *domain.BankRule) error {
+	rule.Name = strings.TrimSpace(rule.Name)
+	rule.Description = strings.TrimSpace(rule.Description)
+	rule.Series = strings.ToUpper(strings.TrimSpace(rule.Series))
+
+	if err := s.validate.Struct(rule); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+
+	if rule.CounterpartyPattern == "" && rule.TextPattern == "" && rule.MinAmount == nil && rule.MaxAmount == nil {
+		return errors.New("a rule needs a counterparty pattern, a text pattern or an amount range")
+	}
+	if _, err := compileRulePattern(rule.CounterpartyPattern); err != nil {
+		return fmt.Errorf("invalid counterparty pattern: %w", err)
+	}
+	if _, err := compileRulePattern(rule.TextPattern); err != nil {
+		return fmt.Errorf("invalid text pattern: %w", err)
+	}
+
+	if (rule.MinAmount != nil && rule.MinAmount.IsNegative()) || (rule.MaxAmount != nil && rule.MaxAmount.IsNegative()) {
+		return errors.New("the amount range is without sign, use direction for money in or out")
+	}
+	if rule.MinAmount != nil && rule.MaxAmount != nil && *rule.MaxAmount < *rule.MinAmount {
+		return errors.New("max_amount must not be less than min_amount")
+	}
+
+	if rule.AccountNo != nil {
+		if err := validateBankAccount(s.accountRepository, *rule.AccountNo); err != nil {
+			return err
+		}
+	}
+	if _, err := s.accountRepository.GetAccountByNo(rule.CounterAccountNo); err != nil {
+		return fmt.Errorf("counter account %d: %w", rule.CounterAccountNo, err)
+	}
+
+	return nil
+}
+
+// CreateRule creates a new booking rule. New rules are always active.
+func (s *BankRuleService) CreateRule(rule *domain.BankRule) error {
+	if err := s.validateRule(rule); err != nil {
+		return err
+	}
+
+	rule.Active = true
+	rule.CreatedBy = s.actorID
+	return s.txManager.WithTransaction(func(tx *sql.Tx) error {
+		if err := s.repository.WithTx(tx).CreateRule(rule); err != nil {
+			return err
+		}
+
+		return recordAudit(s.auditRepository.WithTx(tx), s.actorID, domain.AuditEntityBankRule, rule.RuleID, nil, rule)
+	})
+}
+
+// GetRuleByID retrieves a booking rule by ID
+func (s *BankRuleService) GetRuleByID(ruleID int) (*domain.BankRule, error) {
+	if ruleID <= 0 {
+		return nil, errors.New("invalid bank rule ID")
+	}
+
+	return s.repository.GetRuleByID(ruleID)
+}
+
+// GetAllRules returns the booking rules in the order they are tried
+func (s *BankRuleService) GetAllRules() ([]*domain.BankRule, error) {
+	rules, err := s.repository.GetAllRules()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bank rules: %w", err)
+	}
+
+	return rules, nil
+}
+
+// UpdateRule updates a booking rule. The drafts it has already produced
+// are not changed.
+func (s *BankRuleService) UpdateRule(rule *domain.BankRule) error {
+	existingRule, err := s.repository.GetRuleByID(rule.RuleID)
+	if err != nil {
+		return err
+	}
+	if err := s.validateRule(rule); err != nil {
+		return err
+	}
+
+	return s.txManager.WithTransaction(func(tx *sql.Tx) error {
+		if err := s.repository.WithTx(tx).UpdateRule(rule); err != nil {
+			return err
+		}
+
+		return recordAudit(s.auditRepository.WithTx(tx), s.actorID, domain.AuditEntityBankRule, rule.RuleID, existingRule, rule)
+	})
+}
+
+// DeleteRule deletes a booking rule that has not produced any vouchers;
+// one that has is kept as the record of them and can be deactivated
+func (s *BankRuleService) DeleteRule(ruleID int) error {
+	if ruleID <= 0 {
+		return errors.New("invalid bank rule ID")
+	}
+
+	existingRule, err := s.repository.GetRuleByID(ruleID)
+	if err != nil {
+		return err
+	}
+	applications, err := s.repository.GetApplications(&ruleID)
+	if err != nil {
+		return err
+	}
+	if len(applications) > 0 {
+		return fmt.Errorf("the rule has produced %d vouchers, deactivate it instead", len(applications))
+	}
+
+	return s.txManager.WithTransaction(func(tx *sql.Tx) error {
+		if err := s.repository.WithTx(tx).DeleteRule(ruleID); err != nil {
+			return err
+		}
+
+		return recordAudit(s.auditRepository.WithTx(tx), s.actorID, domain.AuditEntityBankRule, ruleID, existingRule, nil)
+	})
+}
+
+// GetApplications returns which vouchers a rule has produced from which
+// bank transactions
+func (s *BankRuleService) GetApplications(ruleID int) ([]*domain.BankRuleApplication, error) {
+	if ruleID <= 0 {
+		return nil, errors.New("invalid bank rule ID")
+	}
+
+	if _, err := s.repository.GetRuleByID(ruleID); err != nil {
+		return nil, err
+	}
+
+	return s.repository.GetApplications(&ruleID)
+}
+
+// compiledRule is an active rule with its patterns compiled
+type compiledRule struct {
+	rule         *domain.BankRule
+	counterparty *regexp.Regexp
+	text         *regexp.Regexp
+}
+
+// matches reports whether every condition of the rule holds for t
+func (r *compiledRule) matches(t *domain.BankTransaction) bool {
+	rule := r.rule
+	if rule.AccountNo != nil && *rule.AccountNo != t.AccountNo {
+		return false
+	}
+	switch rule.Direction {
+	case domain.BankDirectionIn:
+		if !t.Amount.IsPositive() {
+			return false
+		}
+	case domain.BankDirectionOut:
+		if !t.Amount.IsNegative() {
+			return false
+		}
+	}
+
+	amount := t.Amount.Abs()
+	if rule.MinAmount != nil && amount < *rule.MinAmount {
+		return false
+	}
+	if rule.MaxAmount != nil && amount > *rule.MaxAmount {
+		return false
+	}
+
+	if r.counterparty != nil && !r.counterparty.MatchString(t.Counterparty) {
+		return false
+	}
+	if r.text != nil && !r.text.MatchString(t.Text) {
+		return false
+	}
+	return true
+}
+
+// firstMatch returns the first rule that matches t, or nil. The repository
+// returns the rules in priority order.
+func firstMatch(rules []*compiledRule, t *domain.BankTransaction) *compiledRule {
+	for _, rule := range rules {
+		if rule.matches(t) {
+			return rule
+		}
+	}
+	return nil
+}
+
+// ApplyRules books the transactions of the bank account that are neither
+// matched to the books nor booked before as drafts, using the first active
+// rule that matches each. With dry run it only returns the drafts it would
+// create. Each draft is recorded with the rule and transaction it came
+// from; once booked, the bank matching pairs it with its transaction.
+func (s *BankRuleService) ApplyRules(accountNo int, dryRun bool) (*domain.BankRuleResult, error) {
+	if err := validateBankAccount(s.accountRepository, accountNo); err != nil {
+		return nil, err
+	}
+
+	rules, err := s.repository.GetAllRules()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bank rules: %w", err)
+	}
+	compiled := make([]*compiledRule, 0, len(rules))
+	for _, rule := range rules {
+		if !rule.Active {
+			continue
+		}
+		counterparty, err := compileRulePattern(rule.CounterpartyPattern)
+		if err != nil {
+			return nil, fmt.Errorf("rule %q: invalid counterparty pattern: %w", rule.Name, err)
+		}
+		text, err := compileRulePattern(rule.TextPattern)
+		if err != nil {
+			return nil, fmt.Errorf("rule %q: invalid text pattern: %w", rule.Name, err)
+		}
+		compiled = append(compiled, &compiledRule{rule: rule, counterparty: counterparty, text: text})
+	}
+
+	transactions, err := s.repository.GetUnbookedTransactions(accountNo)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &domain.BankRuleResult{DryRun: dryRun, Proposals: []*domain.BankRuleProposal{}}
+	for _, t := range transactions {
+		match := firstMatch(compiled, t)
+		if match == nil {
+			result.Unmatched++
+			continue
+		}
+
+		proposal := &domain.BankRuleProposal{Transaction: t, RuleID: match.rule.RuleID, RuleName: match.rule.Name}
+		result.Proposals = append(result.Proposals, proposal)
+
+		proposal.Voucher, err = s.draftFromRule(match.rule, t)
+		if err != nil {
+			proposal.Error = err.Error()
+			continue
+		}
+		if dryRun {
+			continue
+		}
+
+		if err := s.createDraft(match.rule, t, proposal.Voucher); err != nil {
+			proposal.Error = err.Error()
+			continue
+		}
+		result.Created++
+	}
+
+	return result, nil
+}
+
+// draftFromRule builds the draft a rule books a bank transaction as: the
+// bank account against the counter account for the full amount, with the
+// VAT split out of the counter line unless the rule's VAT rate is 0
+func (s *BankRuleService) draftFromRule(rule *domain.BankRule, t *domain.BankTransaction) (*domain.Voucher, error) {
+	gross := t.Amount.Abs()
+	bankLine := domain.LineItem{AccountNo: t.AccountNo}
+	counterLine := domain.LineItem{
+		AccountNo:    rule.CounterAccountNo,
+		ProjectID:    rule.ProjectID,
+		CostCenterID: rule.CostCenterID,
+	}
+	if t.Amount.IsPositive() {
+		bankLine.DebitAmount = gross
+		counterLine.CreditAmount = gross
+	} else {
+		bankLine.CreditAmount = gross
+		counterLine.DebitAmount = gross
+	}
+
+	lines := []domain.LineItem{bankLine, counterLine}
+	if rule.TaxCode == nil || *rule.TaxCode > 0 {
+		if rule.TaxCode != nil {
+			lines[1].TaxCode = *rule.TaxCode
+		}
+		expanded, err := s.voucherService.ExpandVATLines(lines, VATModeGross)
+		if err != nil {
+			return nil, err
+		}
+		lines = expanded
+	}
+
+	description := rule.Description
+	for _, text := range []string{t.Text, t.Counterparty, rule.Name} {
+		if description == "" {
+			description = text
+		}
+	}
+
+	return &domain.Voucher{
+		Series:      rule.Series,
+		Status:      domain.VoucherDraft,
+		Date:        domain.FlexibleDate{Time: t.BookingDate},
+		Description: description,
+		Reference:   t.Reference,
+		Period:      t.BookingDate.Format("2006-01"),
+		Lines:       lines,
+	}, nil
+}
+
+// createDraft creates the draft through the VoucherService, so it is
+// validated and audited like any other voucher, and records the rule that
+// produced it in the same transaction. If the record cannot be written,
+// e.g. because another run booked the transaction at the same time, no
+// draft is created.
+func (s *BankRuleService) createDraft(rule *domain.BankRule, t *domain.BankTransaction, voucher *domain.Voucher) error {
+	return s.voucherService.createVoucher(voucher, func(tx *sql.Tx) error {
+		return s.repository.WithTx(tx).CreateApplication(&domain.BankRuleApplication{
+			RuleID:        rule.RuleID,
+			TransactionID: t.TransactionID,
+			VoucherID:     voucher.VoucherID,
+			CreatedBy:     s.actorID,
+		})
+	})
+}
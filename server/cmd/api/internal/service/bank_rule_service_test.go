@@ -0,0 +1,210 @@
+package service
+
+import (
+	"cmd/api/internal/domain"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+func compileRule(t *testing.T, rule *domain.BankRule) *compiledRule {
+	t.Helper()
+	counterparty, err := compileRulePattern(rule.CounterpartyPattern)
+	if err != nil {
+		t.Fatalf("invalid counterparty pattern: %v", err)
+	}
+	text, err := compileRulePattern(rule.TextPattern)
+	if err != nil {
+		t.Fatalf("invalid text pattern: %v", err)
+	}
+	return &compiledRule{rule: rule, counterparty: counterparty, text: text}
+}
+
+func amountPtr(a domain.Amount) *domain.Amount { return &a }
+
+func intPtr(i int) *int { return &i }
+
+func TestCompileRulePattern(t *testing.T) {
+	re, err := compileRulePattern("")
+	if re != nil || err != nil {
+		t.Errorf(`compileRulePattern("") = %v, %v, want nil, nil`, re, err)
+	}
+
+	re, err = compileRulePattern(`^telia\b`)
+	if err != nil {
+		t.Fatalf("compileRulePattern returned error: %v", err)
+	}
+	if !re.MatchString("TELIA SVERIGE AB") || re.MatchString("Bolaget Telia") {
+		t.Errorf("pattern %s does not match case-insensitively from the start", re)
+	}
+
+	if _, err := compileRulePattern("(telia"); err == nil {
+		t.Error("an invalid pattern was accepted")
+	}
+}
+
+func TestRuleMatches(t *testing.T) {
+	rent := domain.BankTransaction{AccountNo: 1930, Amount: -800000, Counterparty: "Fastighets AB Gården", Text: "Hyra januari"}
+	payment := domain.BankTransaction{AccountNo: 1930, Amount: 1250000, Counterparty: "Kund AB", Text: "OCR 4711"}
+
+	tests := []struct {
+		name        string
+		rule        domain.BankRule
+		transaction domain.BankTransaction
+		want        bool
+	}{
+		{name: "counterparty", rule: domain.BankRule{CounterpartyPattern: "gården"}, transaction: rent, want: true},
+		{name: "counterparty differs", rule: domain.BankRule{CounterpartyPattern: "gården"}, transaction: payment, want: false},
+		{name: "text", rule: domain.BankRule{TextPattern: `^hyra\s`}, transaction: rent, want: true},
+		{name: "both patterns must match", rule: domain.BankRule{CounterpartyPattern: "gården", TextPattern: "el"}, transaction: rent, want: false},
+		{name: "bank account", rule: domain.BankRule{AccountNo: intPtr(1930), TextPattern: "hyra"}, transaction: rent, want: true},
+		{name: "other bank account", rule: domain.BankRule{AccountNo: intPtr(1940), TextPattern: "hyra"}, transaction: rent, want: false},
+		{name: "money out", rule: domain.BankRule{Direction: domain.BankDirectionOut, TextPattern: "."}, transaction: rent, want: true},
+		{name: "money out only", rule: domain.BankRule{Direction: domain.BankDirectionOut, TextPattern: "."}, transaction: payment, want: false},
+		{name: "money in", rule: domain.BankRule{Direction: domain.BankDirectionIn, TextPattern: "."}, transaction: payment, want: true},
+		{name: "money in only", rule: domain.BankRule{Direction: domain.BankDirectionIn, TextPattern: "."}, transaction: rent, want: false},
+		{name: "amount range is without sign", rule: domain.BankRule{MinAmount: amountPtr(800000), MaxAmount: amountPtr(800000)}, transaction: rent, want: true},
+		{name: "below min amount", rule: domain.BankRule{MinAmount: amountPtr(800001)}, transaction: rent, want: false},
+		{name: "above max amount", rule: domain.BankRule{MaxAmount: amountPtr(799999)}, transaction: rent, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := tt.rule
+			if got := compileRule(t, &rule).matches(&tt.transaction); got != tt.want {
+				t.Errorf("matches = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFirstMatch(t *testing.T) {
+	// In priority order, as the repository returns them
+	rules := []*compiledRule{
+		compileRule(t, &domain.BankRule{RuleID: 1, Direction: domain.BankDirectionIn, CounterpartyPattern: "kund"}),
+		compileRule(t, &domain.BankRule{RuleID: 2, TextPattern: "hyra"}),
+		compileRule(t, &domain.BankRule{RuleID: 3, Direction: domain.BankDirectionOut, TextPattern: "."}),
+	}
+
+	tests := []struct {
+		transaction domain.BankTransaction
+		want        int
+	}{
+		{transaction: domain.BankTransaction{Amount: -800000, Text: "Hyra januari"}, want: 2},
+		{transaction: domain.BankTransaction{Amount: 800000, Text: "Hyra tillbaka", Counterparty: "Kund AB"}, want: 1},
+		{transaction: domain.BankTransaction{Amount: -1000, Text: "Kortköp"}, want: 3},
+		{transaction: domain.BankTransaction{Amount: 1000, Text: "Ränta"}, want: 0},
+	}
+
+	for _, tt := range tests {
+		got := 0
+		if match := firstMatch(rules, &tt.transaction); match != nil {
+			got = match.rule.RuleID
+		}
+		if got != tt.want {
+			t.Errorf("firstMatch(%q) = rule %d, want rule %d", tt.transaction.Text, got, tt.want)
+		}
+	}
+}
+
+func TestDraftFromRule(t *testing.T) {
+	s := &BankRuleService{voucherService: &VoucherService{accountRepository: testAccounts}}
+	day := time.Date(2025, time.January, 3, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name            string
+		rule            domain.BankRule
+		transaction     domain.BankTransaction
+		wantDescription string
+		wantLines       []domain.LineItem
+	}{
+		{
+			name:            "rent without VAT",
+			rule:            domain.BankRule{Name: "Hyra", Description: "Lokalhyra", CounterAccountNo: 5010, TaxCode: intPtr(0), ProjectID: intPtr(7)},
+			transaction:     domain.BankTransaction{AccountNo: 1930, BookingDate: day, Amount: -800000, Text: "Hyra januari"},
+			wantDescription: "Lokalhyra",
+			wantLines: []domain.LineItem{
+				{AccountNo: 1930, CreditAmount: 800000},
+				{AccountNo: 5010, DebitAmount: 800000, ProjectID: intPtr(7)},
+			},
+		},
+		{
+			name:            "purchase with the account's VAT",
+			rule:            domain.BankRule{Name: "Varuinköp", CounterAccountNo: 4010},
+			transaction:     domain.BankTransaction{AccountNo: 1930, BookingDate: day, Amount: -12500, Counterparty: "Grossisten AB"},
+			wantDescription: "Grossisten AB",
+			wantLines: []domain.LineItem{
+				{AccountNo: 1930, CreditAmount: 12500},
+				{AccountNo: 4010, DebitAmount: 10000, TaxCode: 25},
+				{AccountNo: 2640, DebitAmount: 2500, TaxCode: 25},
+			},
+		},
+		{
+			name:            "sale with the rule's VAT rate",
+			rule:            domain.BankRule{Name: "Försäljning", CounterAccountNo: 3001, TaxCode: intPtr(12)},
+			transaction:     domain.BankTransaction{AccountNo: 1930, BookingDate: day, Amount: 11200, Text: "Swish 123"},
+			wantDescription: "Swish 123",
+			wantLines: []domain.LineItem{
+				{AccountNo: 1930, DebitAmount: 11200},
+				{AccountNo: 3001, CreditAmount: 10000, TaxCode: 12},
+				{AccountNo: 2620, CreditAmount: 1200, TaxCode: 12},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			voucher, err := s.draftFromRule(&tt.rule, &tt.transaction)
+			if err != nil {
+				t.Fatalf("draftFromRule returned error: %v", err)
+			}
+			if voucher.Status != domain.VoucherDraft || voucher.Period != "2025-01" || !voucher.Date.Time.Equal(day) {
+				t.Errorf("voucher = %s %s %v, want a draft on 2025-01-03", voucher.Status, voucher.Period, voucher.Date.Time)
+			}
+			if voucher.Description != tt.wantDescription {
+				t.Errorf("Description = %q, want %q", voucher.Description, tt.wantDescription)
+			}
+			if !reflect.DeepEqual(voucher.Lines, tt.wantLines) {
+				t.Errorf("Lines = %+v, want %+v", voucher.Lines, tt.wantLines)
+			}
+		})
+	}
+}
+
+func TestValidateRule(t *testing.T) {
+	s := &BankRuleService{accountRepository: testAccounts, validate: validator.New()}
+
+	tests := []struct {
+		name    string
+		rule    domain.BankRule
+		wantErr bool
+	}{
+		{name: "valid", rule: domain.BankRule{Name: " Hyra ", TextPattern: "hyra", CounterAccountNo: 5010, Series: " b"}},
+		{name: "amount range only", rule: domain.BankRule{Name: "Stora", MinAmount: amountPtr(100000), CounterAccountNo: 5010}},
+		{name: "no condition", rule: domain.BankRule{Name: "Allt", CounterAccountNo: 5010}, wantErr: true},
+		{name: "invalid pattern", rule: domain.BankRule{Name: "Hyra", TextPattern: "hyra(", CounterAccountNo: 5010}, wantErr: true},
+		{name: "negative amount", rule: domain.BankRule{Name: "Ut", MaxAmount: amountPtr(-100), CounterAccountNo: 5010}, wantErr: true},
+		{name: "max below min", rule: domain.BankRule{Name: "Fel", MinAmount: amountPtr(200), MaxAmount: amountPtr(100), CounterAccountNo: 5010}, wantErr: true},
+		{name: "not a bank account", rule: domain.BankRule{Name: "Hyra", TextPattern: "hyra", AccountNo: intPtr(2440), CounterAccountNo: 5010}, wantErr: true},
+		{name: "unknown counter account", rule: domain.BankRule{Name: "Hyra", TextPattern: "hyra", CounterAccountNo: 9999}, wantErr: true},
+		{name: "unsupported VAT rate", rule: domain.BankRule{Name: "Hyra", TextPattern: "hyra", CounterAccountNo: 5010, TaxCode: intPtr(7)}, wantErr: true},
+		{name: "invalid direction", rule: domain.BankRule{Name: "Hyra", TextPattern: "hyra", CounterAccountNo: 5010, Direction: "both"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := tt.rule
+			err := s.validateRule(&rule)
+			if tt.wantErr != (err != nil) {
+				t.Errorf("validateRule = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	rule := tests[0].rule
+	if err := s.validateRule(&rule); err != nil || rule.Name != "Hyra" || rule.Series != "B" {
+		t.Errorf("validateRule = %v, name %q series %q, want Hyra and B", err, rule.Name, rule.Series)
+	}
+}
@@ -0,0 +1,587 @@
+package service
+
+import (
+	"cmd/api/internal/bank"
+	"cmd/api/internal/domain"
+	"cmd/api/internal/repository"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Bank accounts in the BAS chart of accounts (1900-1999, e.g. 1930 Företagskonto)
+const (
+	bankAccountFirst = 1900
+	bankAccountLast  = 1999
+)
+
+const (
+	// autoMatchDays is how far apart the bank's booking date and the
+	// voucher date may be for the automatic matching
+	autoMatchDays = 3
+	// suggestionDays is how far from the bank's booking date ledger lines
+	// are suggested
+	suggestionDays = 30
+	maxSuggestions = 5
+	// bankFieldLength is the length of the text columns of bank_transactions
+	bankFieldLength = 255
+)
+
+type BankService struct {
+	repository        repository.BankRepository
+	accountRepository repository.AccountRepository
+	auditRepository   repository.AuditRepository
+	txManager         repository.TxManager
+	actorID           int
+}
+
+func NewBankService(
+	repo repository.BankRepository,
+	accountRepo repository.AccountRepository,
+	auditRepo repository.AuditRepository,
+	txManager repository.TxManager,
+) *BankService {
+	return &BankService{
+		repository:        repo,
+		accountRepository: accountRepo,
+		auditRepository:   auditRepo,
+		txManager:         txManager,
+	}
+}
+
+// ForCompany returns a copy of the service that works on the books of companyID
+func (s *BankService) ForCompany(companyID int) *BankService {
+	scoped := *s
+	scoped.repository = s.repository.ForCompany(companyID)
+	scoped.accountRepository = s.accountRepository.ForCompany(companyID)
+	scoped.auditRepository = s.auditRepository.ForCompany(companyID)
+	return &scoped
+}
+
+// AsUser returns a copy of the service that records its changes in the
+// audit trail as made by userID
+func (s *BankService) AsUser(userID int) *BankService {
+	scoped := *s
+	scoped.actorID = userID
+	return &scoped
+}
+
+// validateBankAccount checks that accountNo is a bank account in the chart
+// of accounts
+func validateBankAccount(accountRepo repository.AccountRepository, accountNo int) error {
+	if accountNo < bankAccountFirst || accountNo > bankAccountLast {
+		return fmt.Errorf("account %d is not a bank account (%d-%d)", accountNo, bankAccountFirst, bankAccountLast)
+	}
+	if _, err := accountRepo.GetAccountByNo(accountNo); err != nil {
+		return fmt.Errorf("account %d: %w", accountNo, err)
+	}
+	return nil
+}
+
+// ImportStatement imports a camt.053 or CSV bank statement to the bank
+// account accountNo and matches the new transactions to the books. Rows
+// imported before are skipped, so overlapping statements can be imported.
+// Nothing is imported when the file has errors, or with dry run.
+func (s *BankService) ImportStatement(accountNo int, data []byte, dryRun bool) (*domain.BankImportResult, error) {
+	if err := validateBankAccount(s.accountRepository, accountNo); err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, errors.New("file is empty")
+	}
+
+	statement, parseErrs := bank.Parse(data)
+	result := &domain.BankImportResult{
+		DryRun:       dryRun,
+		Format:       statement.Format,
+		AccountNo:    accountNo,
+		Transactions: []*domain.BankTransaction{},
+		Errors:       []domain.BankImportError{},
+	}
+	for _, e := range parseErrs {
+		result.Errors = append(result.Errors, domain.BankImportError{Line: e.Line, Message: e.Message})
+	}
+	if len(statement.Transactions) == 0 && len(result.Errors) == 0 {
+		result.Errors = append(result.Errors, domain.BankImportError{Message: "the statement has no booked transactions"})
+	}
+
+	// Identical rows, such as two equal card payments on one day, are told
+	// apart by their order in the file
+	seen := make(map[string]int)
+	for _, row := range statement.Transactions {
+		currency := strings.ToUpper(row.Currency)
+		if currency == "" {
+			currency = "SEK"
+		}
+		if currency != "SEK" {
+			result.Errors = append(result.Errors, domain.BankImportError{
+				Line:    row.Line,
+				Message: fmt.Sprintf("transaction in %s cannot be reconciled against the books in SEK", currency),
+			})
+			continue
+		}
+
+		transaction := &domain.BankTransaction{
+			AccountNo:     accountNo,
+			BookingDate:   row.BookingDate,
+			ValueDate:     row.ValueDate,
+			Amount:        row.Amount,
+			Currency:      currency,
+			Text:          truncate(row.Text, bankFieldLength),
+			Reference:     truncate(row.Reference, bankFieldLength),
+			Counterparty:  truncate(row.Counterparty, bankFieldLength),
+			BankReference: truncate(row.BankReference, bankFieldLength),
+			ImportedBy:    s.actorID,
+		}
+		key := fingerprintKey(transaction)
+		seen[key]++
+		sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%d", key, seen[key])))
+		transaction.Fingerprint = hex.EncodeToString(sum[:])
+
+		result.Transactions = append(result.Transactions, transaction)
+	}
+
+	if dryRun || len(result.Errors) > 0 {
+		return result, nil
+	}
+
+	err := s.txManager.WithTransaction(func(tx *sql.Tx) error {
+		repo := s.repository.WithTx(tx)
+		auditRepo := s.auditRepository.WithTx(tx)
+
+		created := make([]*domain.BankTransaction, 0, len(result.Transactions))
+		for _, transaction := range result.Transactions {
+			inserted, err := repo.CreateTransaction(transaction)
+			if err != nil {
+				return err
+			}
+			if !inserted {
+				result.Duplicates++
+				continue
+			}
+			if err := recordAudit(auditRepo, s.actorID, domain.AuditEntityBankTransaction, transaction.TransactionID, nil, transaction); err != nil {
+				return err
+			}
+			created = append(created, transaction)
+		}
+		result.Imported = len(created)
+		result.Transactions = created
+
+		matched, err := s.autoMatch(repo, auditRepo, accountNo, created)
+		result.Matched = matched
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to import bank statement: %w", err)
+	}
+
+	return result, nil
+}
+
+// fingerprintKey is what identifies a bank row across imports
+func fingerprintKey(t *domain.BankTransaction) string {
+	return strings.Join([]string{
+		t.BookingDate.Format("2006-01-02"),
+		t.Amount.String(),
+		t.BankReference,
+		t.Reference,
+		t.Text,
+		t.Counterparty,
+	}, "|")
+}
+
+func truncate(s string, length int) string {
+	s = strings.TrimSpace(s)
+	if utf8.RuneCountInString(s) <= length {
+		return s
+	}
+	return string([]rune(s)[:length])
+}
+
+// MatchAccount runs the automatic matching on every unmatched transaction of
+// the bank account, e.g. after the missing vouchers have been booked. It
+// returns the number of transactions matched.
+func (s *BankService) MatchAccount(accountNo int) (int, error) {
+	if err := validateBankAccount(s.accountRepository, accountNo); err != nil {
+		return 0, err
+	}
+
+	unmatched := false
+	var matched int
+	err := s.txManager.WithTransaction(func(tx *sql.Tx) error {
+		repo := s.repository.WithTx(tx)
+		transactions, err := repo.GetTransactions(domain.BankTransactionFilter{AccountNo: &accountNo, Matched: &unmatched})
+		if err != nil {
+			return err
+		}
+
+		matched, err = s.autoMatch(repo, s.auditRepository.WithTx(tx), accountNo, transactions)
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to match bank transactions: %w", err)
+	}
+
+	return matched, nil
+}
+
+// autoMatch matches each transaction to the unmatched ledger line with the
+// same amount dated at most autoMatchDays from it. When there are several
+// such lines, or several transactions for one line, only a reference found
+// on both sides decides; otherwise the transaction is left for manual
+// matching.
+func (s *BankService) autoMatch(repo repository.BankRepository, auditRepo repository.AuditRepository, accountNo int, transactions []*domain.BankTransaction) (int, error) {
+	if len(transactions) == 0 {
+		return 0, nil
+	}
+
+	from, to := transactions[0].BookingDate, transactions[0].BookingDate
+	for _, t := range transactions {
+		if t.BookingDate.Before(from) {
+			from = t.BookingDate
+		}
+		if t.BookingDate.After(to) {
+			to = t.BookingDate
+		}
+	}
+	lines, err := repo.GetUnmatchedLedgerLines(accountNo, from.AddDate(0, 0, -autoMatchDays), to.AddDate(0, 0, autoMatchDays))
+	if err != nil {
+		return 0, err
+	}
+
+	candidates := make(map[int][]*domain.BankLedgerLine)   // Per transaction ID
+	competitors := make(map[int][]*domain.BankTransaction) // Per line ID
+	for _, t := range transactions {
+		for _, line := range lines {
+			if line.Amount == t.Amount && daysBetween(line.Date, t.BookingDate) <= autoMatchDays {
+				candidates[t.TransactionID] = append(candidates[t.TransactionID], line)
+				competitors[line.LineID] = append(competitors[line.LineID], t)
+			}
+		}
+	}
+
+	used := make(map[int]bool)
+	matched := 0
+	for _, t := range transactions {
+		if t.MatchedLineID != nil {
+			continue
+		}
+
+		var open []*domain.BankLedgerLine
+		for _, line := range candidates[t.TransactionID] {
+			if !used[line.LineID] {
+				open = append(open, line)
+			}
+		}
+		line := pickUnique(open, func(line *domain.BankLedgerLine) bool { return referencesMatch(t, line) })
+		if line == nil {
+			continue
+		}
+
+		var others []*domain.BankTransaction
+		for _, other := range competitors[line.LineID] {
+			if other.MatchedLineID == nil {
+				others = append(others, other)
+			}
+		}
+		if pickUnique(others, func(other *domain.BankTransaction) bool { return referencesMatch(other, line) }) != t {
+			continue
+		}
+
+		before := *t
+		if err := repo.SetMatch(t.TransactionID, &line.LineID, domain.BankMatchAuto, s.actorID); err != nil {
+			return 0, err
+		}
+		lineID := line.LineID
+		t.MatchedLineID = &lineID
+		t.MatchMethod = domain.BankMatchAuto
+		if err := recordAudit(auditRepo, s.actorID, domain.AuditEntityBankTransaction, t.TransactionID, before, t); err != nil {
+			return 0, err
+		}
+
+		used[line.LineID] = true
+		matched++
+	}
+
+	return matched, nil
+}
+
+// pickUnique returns the only item, or else the only item preferred by
+// prefer, or else nil
+func pickUnique[T any](items []*T, prefer func(*T) bool) *T {
+	if len(items) == 1 {
+		return items[0]
+	}
+
+	var pick *T
+	for _, item := range items {
+		if prefer(item) {
+			if pick != nil {
+				return nil
+			}
+			pick = item
+		}
+	}
+	return pick
+}
+
+// referencesMatch reports whether the bank's reference appears on the
+// voucher, or the voucher's reference in the bank's text
+func referencesMatch(t *domain.BankTransaction, line *domain.BankLedgerLine) bool {
+	bankRef := normalizeReference(t.Reference)
+	voucherRef := normalizeReference(line.Reference)
+
+	if len(bankRef) >= 3 && (strings.Contains(voucherRef, bankRef) || strings.Contains(normalizeReference(line.Description), bankRef)) {
+		return true
+	}
+	if len(voucherRef) >= 3 && (strings.Contains(bankRef, voucherRef) || strings.Contains(normalizeReference(t.Text), voucherRef)) {
+		return true
+	}
+	return false
+}
+
+// normalizeReference drops spaces and case, so "OCR 1234 5678" and
+// "ocr12345678" are the same
+func normalizeReference(s string) string {
+	return strings.ToLower(strings.Join(strings.Fields(s), ""))
+}
+
+func daysBetween(a, b time.Time) int {
+	days := int(a.Sub(b).Hours() / 24)
+	if days < 0 {
+		return -days
+	}
+	return days
+}
+
+// GetTransactions returns the imported bank transactions that match filter
+func (s *BankService) GetTransactions(filter domain.BankTransactionFilter) ([]*domain.BankTransaction, error) {
+	if filter.From != nil && filter.To != nil && filter.To.Before(*filter.From) {
+		return nil, errors.New("to_date must not be before from_date")
+	}
+
+	transactions, err := s.repository.GetTransactions(filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bank transactions: %w", err)
+	}
+
+	return transactions, nil
+}
+
+// GetSuggestions returns the ledger lines that may book an unmatched bank
+// transaction, best first. A line is suggested when it has the same amount
+// or shares a reference with the transaction and is dated at most
+// suggestionDays from it; the date, the reference and words of the bank
+// text found in the voucher description raise the score.
+func (s *BankService) GetSuggestions(transactionID int) ([]*domain.BankMatchSuggestion, error) {
+	if transactionID <= 0 {
+		return nil, errors.New("invalid bank transaction ID")
+	}
+
+	t, err := s.repository.GetTransactionByID(transactionID)
+	if err != nil {
+		return nil, err
+	}
+	if t.MatchedLineID != nil {
+		return nil, ErrBankTransactionMatched
+	}
+
+	lines, err := s.repository.GetUnmatchedLedgerLines(t.AccountNo,
+		t.BookingDate.AddDate(0, 0, -suggestionDays), t.BookingDate.AddDate(0, 0, suggestionDays))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ledger lines: %w", err)
+	}
+
+	words := significantWords(t.Text + " " + t.Counterparty)
+	suggestions := make([]*domain.BankMatchSuggestion, 0)
+	for _, line := range lines {
+		suggestion := &domain.BankMatchSuggestion{Line: *line, Reasons: []string{}}
+		if line.Amount == t.Amount {
+			suggestion.Score += 50
+			suggestion.Reasons = append(suggestion.Reasons, "amount")
+		}
+		if referencesMatch(t, line) {
+			suggestion.Score += 30
+			suggestion.Reasons = append(suggestion.Reasons, "reference")
+		}
+		if suggestion.Score == 0 {
+			continue
+		}
+
+		days := daysBetween(line.Date, t.BookingDate)
+		if days <= autoMatchDays {
+			suggestion.Reasons = append(suggestion.Reasons, "date")
+		}
+		suggestion.Score += max(0, 20-days)
+
+		description := strings.ToLower(line.Description)
+		for _, word := range words {
+			if strings.Contains(description, word) {
+				suggestion.Score += 10
+				suggestion.Reasons = append(suggestion.Reasons, "text")
+				break
+			}
+		}
+
+		suggestions = append(suggestions, suggestion)
+	}
+
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return suggestions[i].Score > suggestions[j].Score
+	})
+	if len(suggestions) > maxSuggestions {
+		suggestions = suggestions[:maxSuggestions]
+	}
+
+	return suggestions, nil
+}
+
+// significantWords returns the lower-case words of s long enough to say
+// something, e.g. the supplier's name in "KORTKÖP 240105 CLAS OHLSON"
+func significantWords(s string) []string {
+	words := make([]string, 0)
+	for _, word := range strings.Fields(strings.ToLower(s)) {
+		word = strings.Trim(word, ".,:;-/()*")
+		if utf8.RuneCountInString(word) >= 4 && strings.ContainsFunc(word, func(r rune) bool { return r < '0' || r > '9' }) {
+			words = append(words, word)
+		}
+	}
+	return words
+}
+
+// Match matches a bank transaction by hand to a line on its bank account
+// with the same amount
+func (s *BankService) Match(transactionID, lineID int) (*domain.BankTransaction, error) {
+	if transactionID <= 0 {
+		return nil, errors.New("invalid bank transaction ID")
+	}
+	if lineID <= 0 {
+		return nil, errors.New("invalid line ID")
+	}
+
+	var transaction *domain.BankTransaction
+	err := s.txManager.WithTransaction(func(tx *sql.Tx) error {
+		repo := s.repository.WithTx(tx)
+		t, err := repo.GetTransactionByIDForUpdate(transactionID)
+		if err != nil {
+			return err
+		}
+		if t.MatchedLineID != nil {
+			return ErrBankTransactionMatched
+		}
+
+		line, err := repo.GetLedgerLineByID(t.AccountNo, lineID)
+		if err != nil {
+			return err
+		}
+		if line.Amount != t.Amount {
+			return fmt.Errorf("the line is %s but the bank transaction %s", line.Amount.Format(), t.Amount.Format())
+		}
+		lineMatched, err := repo.IsLineMatched(lineID)
+		if err != nil {
+			return err
+		}
+		if lineMatched {
+			return fmt.Errorf("%w: line %d is matched to another bank transaction", ErrBankTransactionMatched, lineID)
+		}
+
+		if err := repo.SetMatch(transactionID, &lineID, domain.BankMatchManual, s.actorID); err != nil {
+			return err
+		}
+		transaction, err = repo.GetTransactionByID(transactionID)
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(s.auditRepository.WithTx(tx), s.actorID, domain.AuditEntityBankTransaction, transactionID, t, transaction)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to match bank transaction: %w", err)
+	}
+
+	return transaction, nil
+}
+
+// Unmatch removes the match of a bank transaction, automatic or manual
+func (s *BankService) Unmatch(transactionID int) (*domain.BankTransaction, error) {
+	if transactionID <= 0 {
+		return nil, errors.New("invalid bank transaction ID")
+	}
+
+	var transaction *domain.BankTransaction
+	err := s.txManager.WithTransaction(func(tx *sql.Tx) error {
+		repo := s.repository.WithTx(tx)
+		t, err := repo.GetTransactionByIDForUpdate(transactionID)
+		if err != nil {
+			return err
+		}
+		if t.MatchedLineID == nil {
+			return errors.New("bank transaction is not matched")
+		}
+
+		if err := repo.SetMatch(transactionID, nil, "", s.actorID); err != nil {
+			return err
+		}
+		transaction, err = repo.GetTransactionByID(transactionID)
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(s.auditRepository.WithTx(tx), s.actorID, domain.AuditEntityBankTransaction, transactionID, t, transaction)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmatch bank transaction: %w", err)
+	}
+
+	return transaction, nil
+}
+
+// GetReconciliation compares the bank account with the imported statements
+// from from to to: the totals of both sides and the transactions that are
+// only at the bank or only in the books
+func (s *BankService) GetReconciliation(accountNo int, from, to time.Time) (*domain.BankReconciliation, error) {
+	if err := validateBankAccount(s.accountRepository, accountNo); err != nil {
+		return nil, err
+	}
+	if to.Before(from) {
+		return nil, errors.New("to_date must not be before from_date")
+	}
+
+	transactions, err := s.repository.GetTransactions(domain.BankTransactionFilter{AccountNo: &accountNo, From: &from, To: &to})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bank transactions: %w", err)
+	}
+
+	reconciliation := &domain.BankReconciliation{
+		AccountNo:     accountNo,
+		From:          from,
+		To:            to,
+		UnmatchedBank: []*domain.BankTransaction{},
+	}
+	for _, t := range transactions {
+		reconciliation.BankTotal = reconciliation.BankTotal.Add(t.Amount)
+		if t.MatchedLineID != nil {
+			reconciliation.Matched++
+		} else {
+			reconciliation.UnmatchedBank = append(reconciliation.UnmatchedBank, t)
+		}
+	}
+
+	reconciliation.UnmatchedLedger, err = s.repository.GetUnmatchedLedgerLines(accountNo, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ledger lines: %w", err)
+	}
+	reconciliation.LedgerTotal, err = s.repository.GetLedgerTotal(accountNo, from, to)
+	if err != nil {
+		return nil, err
+	}
+	reconciliation.Difference = reconciliation.BankTotal.Sub(reconciliation.LedgerTotal)
+
+	return reconciliation, nil
+}
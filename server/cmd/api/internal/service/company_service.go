@@ -0,0 +1,266 @@
+package service
+
+import (
+	"cmd/api/internal/domain"
+	"cmd/api/internal/repository"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// RoleAdmin is the company role allowed to manage the company and its members
+const RoleAdmin = "Admin"
+
+// ErrNotCompanyMember is returned when a user acts on a company they are not
+// a member of
+var ErrNotCompanyMember = errors.New("user is not a member of the company")
+
+type CompanyService struct {
+	repository        repository.CompanyRepository
+	userRepository    repository.UserRepository
+	accountRepository repository.AccountRepository
+	seriesRepository  repository.VoucherSeriesRepository
+	auditRepository   repository.AuditRepository
+	txManager         repository.TxManager
+	validate          *validator.Validate
+	actorID           int
+}
+
+func NewCompanyService(
+	repo repository.CompanyRepository,
+	userRepo repository.UserRepository,
+	accountRepo repository.AccountRepository,
+	seriesRepo repository.VoucherSeriesRepository,
+	auditRepo repository.AuditRepository,
+	txManager repository.TxManager,
+) *CompanyService {
+	return &CompanyService{
+		repository:        repo,
+		userRepository:    userRepo,
+		accountRepository: accountRepo,
+		seriesRepository:  seriesRepo,
+		auditRepository:   auditRepo,
+		txManager:         txManager,
+		validate:          validator.New(),
+	}
+}
+
+// AsUser returns a copy of the service that records its changes in the
+// audit trail as made by userID
+func (s *CompanyService) AsUser(userID int) *CompanyService {
+	scoped := *s
+	scoped.actorID = userID
+	return &scoped
+}
+
+// CreateCompany creates a company with userID as its first Admin and the
+// default voucher series. If copyAccountsFrom is set, the chart of accounts
+// of that company (which the user must be a member of) is copied to the new
+// one.
+func (s *CompanyService) CreateCompany(company *domain.Company, userID, copyAccountsFrom int) error {
+	if err := s.validate.Struct(company); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+
+	if copyAccountsFrom > 0 {
+		if _, err := s.GetMembership(copyAccountsFrom, userID); err != nil {
+			return err
+		}
+	}
+
+	return s.txManager.WithTransaction(func(tx *sql.Tx) error {
+		repo := s.repository.WithTx(tx)
+
+		if err := repo.CreateCompany(company); err != nil {
+			return err
+		}
+		auditRepo := s.auditRepository.ForCompany(company.CompanyID).WithTx(tx)
+		if err := recordAudit(auditRepo, userID, domain.AuditEntityCompany, company.CompanyID, nil, company); err != nil {
+			return err
+		}
+
+		member := &domain.CompanyMember{CompanyID: company.CompanyID, UserID: userID, Role: RoleAdmin}
+		if err := repo.SaveMember(member); err != nil {
+			return err
+		}
+		if err := recordAudit(auditRepo, userID, domain.AuditEntityCompanyMember, userID, nil, member); err != nil {
+			return err
+		}
+
+		seriesRepo := s.seriesRepository.ForCompany(company.CompanyID).WithTx(tx)
+		for _, series := range domain.DefaultVoucherSeries {
+			if err := seriesRepo.CreateSeries(&series); err != nil {
+				return err
+			}
+			if err := recordAudit(auditRepo, userID, domain.AuditEntityVoucherSeries, series.Series, nil, series); err != nil {
+				return err
+			}
+		}
+
+		if copyAccountsFrom > 0 {
+			if err := repo.CopyAccounts(copyAccountsFrom, company.CompanyID); err != nil {
+				return err
+			}
+
+			accounts, err := s.accountRepository.ForCompany(company.CompanyID).WithTx(tx).GetAllAccounts()
+			if err != nil {
+				return err
+			}
+			for _, account := range accounts {
+				if err := recordAudit(auditRepo, userID, domain.AuditEntityAccount, account.AccountNo, nil, account); err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	})
+}
+
+// GetCompany retrieves a company by ID
+func (s *CompanyService) GetCompany(companyID int) (*domain.Company, error) {
+	if companyID <= 0 {
+		return nil, errors.New("invalid company ID")
+	}
+
+	return s.repository.GetCompanyByID(companyID)
+}
+
+// UpdateCompany changes the name and organisation number of a company
+func (s *CompanyService) UpdateCompany(company *domain.Company) error {
+	if err := s.validate.Struct(company); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+
+	existing, err := s.repository.GetCompanyByID(company.CompanyID)
+	if err != nil {
+		return err
+	}
+
+	return s.txManager.WithTransaction(func(tx *sql.Tx) error {
+		if err := s.repository.WithTx(tx).UpdateCompany(company); err != nil {
+			return err
+		}
+
+		auditRepo := s.auditRepository.ForCompany(company.CompanyID).WithTx(tx)
+		return recordAudit(auditRepo, s.actorID, domain.AuditEntityCompany, company.CompanyID, existing, company)
+	})
+}
+
+// GetCompaniesForUser returns the companies a user can work in
+func (s *CompanyService) GetCompaniesForUser(userID int) ([]*domain.CompanyMembership, error) {
+	if userID <= 0 {
+		return nil, errors.New("invalid user ID")
+	}
+
+	return s.repository.GetCompaniesByUser(userID)
+}
+
+// GetMembership returns the user's membership in a company, or
+// ErrNotCompanyMember
+func (s *CompanyService) GetMembership(companyID, userID int) (*domain.CompanyMember, error) {
+	member, err := s.repository.GetMember(companyID, userID)
+	if err != nil {
+		return nil, ErrNotCompanyMember
+	}
+
+	return member, nil
+}
+
+// GetDefaultMembership returns the membership a user logs in to when no
+// company is asked for: the first company they are a member of. Users that
+// are not a member of any company get nil.
+func (s *CompanyService) GetDefaultMembership(userID int) (*domain.CompanyMember, error) {
+	companies, err := s.GetCompaniesForUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(companies) == 0 {
+		return nil, nil
+	}
+
+	return &domain.CompanyMember{
+		CompanyID: companies[0].CompanyID,
+		UserID:    userID,
+		Role:      companies[0].Role,
+	}, nil
+}
+
+// GetMembers lists the members of a company
+func (s *CompanyService) GetMembers(companyID int) ([]*domain.CompanyMember, error) {
+	return s.repository.GetMembers(companyID)
+}
+
+// SaveMember adds a user to a company or changes their role there
+func (s *CompanyService) SaveMember(member *domain.CompanyMember) error {
+	if err := s.validate.Struct(member); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+
+	if _, err := s.userRepository.GetUserByID(member.UserID); err != nil {
+		return fmt.Errorf("user not found: %w", err)
+	}
+
+	if member.Role != RoleAdmin {
+		if err := s.ensureOtherAdmin(member.CompanyID, member.UserID); err != nil {
+			return err
+		}
+	}
+
+	// A user without a membership yet is added, which is a creation
+	existing, _ := s.repository.GetMember(member.CompanyID, member.UserID)
+
+	return s.txManager.WithTransaction(func(tx *sql.Tx) error {
+		if err := s.repository.WithTx(tx).SaveMember(member); err != nil {
+			return err
+		}
+
+		auditRepo := s.auditRepository.ForCompany(member.CompanyID).WithTx(tx)
+		return recordAudit(auditRepo, s.actorID, domain.AuditEntityCompanyMember, member.UserID, existing, member)
+	})
+}
+
+// RemoveMember removes a user from a company
+func (s *CompanyService) RemoveMember(companyID, userID int) error {
+	if err := s.ensureOtherAdmin(companyID, userID); err != nil {
+		return err
+	}
+
+	existing, err := s.GetMembership(companyID, userID)
+	if err != nil {
+		return err
+	}
+
+	return s.txManager.WithTransaction(func(tx *sql.Tx) error {
+		if err := s.repository.WithTx(tx).RemoveMember(companyID, userID); err != nil {
+			return err
+		}
+
+		auditRepo := s.auditRepository.ForCompany(companyID).WithTx(tx)
+		return recordAudit(auditRepo, s.actorID, domain.AuditEntityCompanyMember, userID, existing, nil)
+	})
+}
+
+// ensureOtherAdmin refuses to leave a company without an Admin when userID
+// loses the role
+func (s *CompanyService) ensureOtherAdmin(companyID, userID int) error {
+	members, err := s.repository.GetMembers(companyID)
+	if err != nil {
+		return err
+	}
+
+	for _, member := range members {
+		if member.Role == RoleAdmin && member.UserID != userID {
+			return nil
+		}
+	}
+	for _, member := range members {
+		if member.UserID == userID && member.Role == RoleAdmin {
+			return errors.New("a company must have at least one Admin")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"cmd/api/internal/domain"
+	"cmd/api/internal/repository"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/go-playground/validator/v10"
+)
+
+type CostCenterService struct {
+	repository      repository.CostCenterRepository
+	auditRepository repository.AuditRepository
+	txManager       repository.TxManager
+	validate        *validator.Validate
+	actorID         int
+}
+
+func NewCostCenterService(
+	repo repository.CostCenterRepository,
+	auditRepo repository.AuditRepository,
+	txManager repository.TxManager,
+) *CostCenterService {
+	return &CostCenterService{
+		repository:      repo,
+		auditRepository: auditRepo,
+		txManager:       txManager,
+		validate:        validator.New(),
+	}
+}
+
+// ForCompany returns a copy of the service that works on the books of companyID
+func (s *CostCenterService) ForCompany(companyID int) *CostCenterService {
+	scoped := *s
+	scoped.repository = s.repository.ForCompany(companyID)
+	scoped.auditRepository = s.auditRepository.ForCompany(companyID)
+	return &scoped
+}
+
+// AsUser returns a copy of the service that records its changes in the
+// audit trail as made by userID
+func (s *CostCenterService) AsUser(userID int) *CostCenterService {
+	scoped := *s
+	scoped.actorID = userID
+	return &scoped
+}
+
+// CreateCostCenter creates a new cost centre. New cost centres are always active.
+func (s *CostCenterService) CreateCostCenter(costCenter *domain.CostCenter) error {
+	if err := s.validateNewCostCenter(costCenter); err != nil {
+		return err
+	}
+
+	return s.txManager.WithTransaction(func(tx *sql.Tx) error {
+		return s.createCostCenter(tx, costCenter)
+	})
+}
+
+// validateNewCostCenter checks a cost centre before it is created
+func (s *CostCenterService) validateNewCostCenter(costCenter *domain.CostCenter) error {
+	if err := s.validate.Struct(costCenter); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+
+	if err := validateDimensionDates(costCenter.StartDate, costCenter.EndDate); err != nil {
+		return err
+	}
+
+	if existing, err := s.repository.GetCostCenterByCode(costCenter.Code); err == nil && existing != nil {
+		return errors.New("cost centre with this code already exists")
+	}
+
+	return nil
+}
+
+// createCostCenter creates a validated cost centre in tx
+func (s *CostCenterService) createCostCenter(tx *sql.Tx, costCenter *domain.CostCenter) error {
+	costCenter.Active = true
+	if err := s.repository.WithTx(tx).CreateCostCenter(costCenter); err != nil {
+		return fmt.Errorf("failed to create cost centre: %w", err)
+	}
+
+	return recordAudit(s.auditRepository.WithTx(tx), s.actorID, domain.AuditEntityCostCenter, costCenter.CostCenterID, nil, costCenter)
+}
+
+// GetCostCenterByID retrieves a cost centre by ID
+func (s *CostCenterService) GetCostCenterByID(costCenterID int) (*domain.CostCenter, error) {
+	if costCenterID <= 0 {
+		return nil, errors.New("invalid cost centre ID")
+	}
+
+	costCenter, err := s.repository.GetCostCenterByID(costCenterID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cost centre: %w", err)
+	}
+
+	return costCenter, nil
+}
+
+// GetAllCostCenters retrieves all cost centres
+func (s *CostCenterService) GetAllCostCenters() ([]*domain.CostCenter, error) {
+	costCenters, err := s.repository.GetAllCostCenters()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cost centres: %w", err)
+	}
+
+	return costCenters, nil
+}
+
+// UpdateCostCenter updates an existing cost centre
+func (s *CostCenterService) UpdateCostCenter(costCenter *domain.CostCenter) error {
+	if err := s.validate.Struct(costCenter); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+
+	existingCostCenter, err := s.repository.GetCostCenterByID(costCenter.CostCenterID)
+	if err != nil {
+		return fmt.Errorf("cost centre not found: %w", err)
+	}
+
+	if err := validateDimensionDates(costCenter.StartDate, costCenter.EndDate); err != nil {
+		return err
+	}
+
+	if existing, err := s.repository.GetCostCenterByCode(costCenter.Code); err == nil && existing.CostCenterID != costCenter.CostCenterID {
+		return errors.New("cost centre with this code already exists")
+	}
+
+	return s.txManager.WithTransaction(func(tx *sql.Tx) error {
+		if err := s.repository.WithTx(tx).UpdateCostCenter(costCenter); err != nil {
+			return fmt.Errorf("failed to update cost centre: %w", err)
+		}
+
+		return recordAudit(s.auditRepository.WithTx(tx), s.actorID, domain.AuditEntityCostCenter, costCenter.CostCenterID, existingCostCenter, costCenter)
+	})
+}
+
+// DeleteCostCenter deletes a cost centre that has no bookings
+func (s *CostCenterService) DeleteCostCenter(costCenterID int) error {
+	if costCenterID <= 0 {
+		return errors.New("invalid cost centre ID")
+	}
+
+	existingCostCenter, err := s.repository.GetCostCenterByID(costCenterID)
+	if err != nil {
+		return fmt.Errorf("cost centre not found: %w", err)
+	}
+
+	return s.txManager.WithTransaction(func(tx *sql.Tx) error {
+		if err := s.repository.WithTx(tx).DeleteCostCenter(costCenterID); err != nil {
+			return fmt.Errorf("failed to delete cost centre: %w", err)
+		}
+
+		return recordAudit(s.auditRepository.WithTx(tx), s.actorID, domain.AuditEntityCostCenter, costCenterID, existingCostCenter, nil)
+	})
+}
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"cmd/api/internal/domain"
+	"cmd/api/internal/repository"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// validateLineDimensions checks that the projects and cost centres on lines
+// exist and accept bookings on date
+func validateLineDimensions(
+	projectRepo repository.ProjectRepository,
+	costCenterRepo repository.CostCenterRepository,
+	lines []domain.LineItem,
+	date time.Time,
+) error {
+	for i, line := range lines {
+		if line.ProjectID != nil {
+			project, err := projectRepo.GetProjectByID(*line.ProjectID)
+			if err != nil {
+				return fmt.Errorf("line %d: %w", i+1, err)
+			}
+			if err := checkBookable("project", project.Code, project.Active, project.StartDate, project.EndDate, date); err != nil {
+				return fmt.Errorf("line %d: %w", i+1, err)
+			}
+		}
+
+		if line.CostCenterID != nil {
+			costCenter, err := costCenterRepo.GetCostCenterByID(*line.CostCenterID)
+			if err != nil {
+				return fmt.Errorf("line %d: %w", i+1, err)
+			}
+			if err := checkBookable("cost centre", costCenter.Code, costCenter.Active, costCenter.StartDate, costCenter.EndDate, date); err != nil {
+				return fmt.Errorf("line %d: %w", i+1, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkBookable refuses bookings on an inactive project or cost centre, or
+// on a date outside its date range
+func checkBookable(kind, code string, active bool, startDate, endDate *domain.FlexibleDate, date time.Time) error {
+	if !active {
+		return fmt.Errorf("%s %s is not active", kind, code)
+	}
+	if startDate != nil && date.Before(startDate.Time) {
+		return fmt.Errorf("%s %s starts %s", kind, code, startDate.Time.Format("2006-01-02"))
+	}
+	if endDate != nil && date.After(endDate.Time) {
+		return fmt.Errorf("%s %s ended %s", kind, code, endDate.Time.Format("2006-01-02"))
+	}
+	return nil
+}
+
+// validateDimensionDates checks the optional date range of a project or cost centre
+func validateDimensionDates(startDate, endDate *domain.FlexibleDate) error {
+	if startDate != nil && endDate != nil && endDate.Time.Before(startDate.Time) {
+		return errors.New("end_date must be on or after start_date")
+	}
+	return nil
+}
+
+// dimensionObject is the code and name of a project or cost centre
+type dimensionObject struct {
+	code string
+	name string
+}
+
+// dimensionObjects returns every project or cost centre by ID, depending
+// on which dimension is asked for
+func dimensionObjects(
+	projectRepo repository.ProjectRepository,
+	costCenterRepo repository.CostCenterRepository,
+	dimension string,
+) (map[int]dimensionObject, error) {
+	objects := make(map[int]dimensionObject)
+
+	switch dimension {
+	case domain.DimensionProject:
+		projects, err := projectRepo.GetAllProjects()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get projects: %w", err)
+		}
+		for _, project := range projects {
+			objects[project.ProjectID] = dimensionObject{code: project.Code, name: project.Name}
+		}
+	case domain.DimensionCostCenter:
+		costCenters, err := costCenterRepo.GetAllCostCenters()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get cost centres: %w", err)
+		}
+		for _, costCenter := range costCenters {
+			objects[costCenter.CostCenterID] = dimensionObject{code: costCenter.Code, name: costCenter.Name}
+		}
+	default:
+		return nil, fmt.Errorf("invalid group_by %q, expected %s or %s", dimension, domain.DimensionProject, domain.DimensionCostCenter)
+	}
+
+	return objects, nil
+}
+
+// sortDimensionIDs orders IDs by the code of their object, with 0 (lines
+// without a project or cost centre) last
+func sortDimensionIDs(ids []int, objects map[int]dimensionObject) {
+	sort.Slice(ids, func(i, j int) bool {
+		if ids[i] == 0 || ids[j] == 0 {
+			return ids[j] == 0 && ids[i] != 0
+		}
+		return objects[ids[i]].code < objects[ids[j]].code
+	})
+}
+
+// dimensionID returns nil for 0, the key used for lines without a project
+// or cost centre
+func dimensionID(id int) *int {
+	if id == 0 {
+		return nil
+	}
+	return &id
+}
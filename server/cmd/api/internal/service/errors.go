@@ -0,0 +1,67 @@
+package service
+
+import (
+	"cmd/api/internal/domain"
+	"errors"
+	"fmt"
+)
+
+// ErrVoucherAlreadyCorrected is returned when a correction is attempted on a
+// voucher that already has one
+var ErrVoucherAlreadyCorrected = errors.New("voucher has already been corrected")
+
+// ErrVoucherBooked is returned when a booked voucher, or one of its lines,
+// is edited, deleted or booked again. Booked vouchers can only be changed
+// with a correction voucher.
+var ErrVoucherBooked = errors.New("voucher is booked and can only be changed with a correction voucher")
+
+// ErrVoucherNotBooked is returned when a draft is corrected; drafts are
+// changed directly instead
+var ErrVoucherNotBooked = errors.New("voucher is a draft, only booked vouchers can be corrected")
+
+// ErrNotVoucherCreator is returned when someone else than the creator of a
+// draft changes or deletes it
+var ErrNotVoucherCreator = errors.New("only the creator of a draft can change or delete it")
+
+// ErrPeriodNotOpen is returned when a booking, edit or delete targets a
+// locked or closed period
+var ErrPeriodNotOpen = errors.New("period is not open for bookings")
+
+// ErrInvalidPeriodTransition is returned when a period status change is not
+// allowed, e.g. reopening a closed period
+var ErrInvalidPeriodTransition = errors.New("invalid period status change")
+
+// BalanceError is returned when a voucher's debit and credit totals differ
+type BalanceError struct {
+	TotalDebit  domain.Amount `json:"total_debit"`
+	TotalCredit domain.Amount `json:"total_credit"`
+	Difference  domain.Amount `json:"difference"`
+}
+
+func (e *BalanceError) Error() string {
+	return fmt.Sprintf("voucher is not balanced: debit %s, credit %s, difference %s",
+		e.TotalDebit, e.TotalCredit, e.Difference)
+}
+
+// ErrFiscalYearClosed is returned when closing a fiscal year that is already closed
+var ErrFiscalYearClosed = errors.New("fiscal year is already closed")
+
+// ErrVATAlreadySettled is returned when booking a VAT settlement for a period
+// that overlaps one that is already settled
+var ErrVATAlreadySettled = errors.New("VAT for the period has already been settled")
+
+// ErrUnsupportedAttachmentType is returned when an uploaded attachment is not
+// a PDF or an image
+var ErrUnsupportedAttachmentType = errors.New("attachment must be a PDF or an image (JPEG, PNG, GIF or WebP)")
+
+// ErrAttachmentChecksum is returned when an uploaded attachment does not
+// match the checksum sent with it
+var ErrAttachmentChecksum = errors.New("attachment does not match its checksum")
+
+// ErrAttachmentExists is returned when the same file is attached to a
+// voucher twice
+var ErrAttachmentExists = errors.New("file is already attached to the voucher")
+
+// ErrBankTransactionMatched is returned when a bank transaction that is
+// already matched, or a ledger line that already has one, is matched again
+var ErrBankTransactionMatched = errors.New("bank transaction is already matched to the books")
@@ -0,0 +1,346 @@
+package service
+
+import (
+	"cmd/api/internal/domain"
+	"cmd/api/internal/repository"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// BAS accounts used by the year-end closing
+const (
+	accountYearResultPL       = 8999 // Årets resultat (resultaträkning)
+	accountYearResultBS       = 2099 // Årets resultat (balansräkning)
+	accountPreviousYearResult = 2098 // Vinst eller förlust från föregående år
+)
+
+type FiscalYearService struct {
+	repository         repository.FiscalYearRepository
+	voucherRepository  repository.VoucherRepository
+	lineItemRepository repository.LineItemRepository
+	periodRepository   repository.PeriodRepository
+	reportRepository   repository.ReportRepository
+	auditRepository    repository.AuditRepository
+	txManager          repository.TxManager
+	actorID            int
+}
+
+func NewFiscalYearService(
+	repo repository.FiscalYearRepository,
+	voucherRepo repository.VoucherRepository,
+	lineItemRepo repository.LineItemRepository,
+	periodRepo repository.PeriodRepository,
+	reportRepo repository.ReportRepository,
+	auditRepo repository.AuditRepository,
+	txManager repository.TxManager,
+) *FiscalYearService {
+	return &FiscalYearService{
+		repository:         repo,
+		voucherRepository:  voucherRepo,
+		lineItemRepository: lineItemRepo,
+		periodRepository:   periodRepo,
+		reportRepository:   reportRepo,
+		auditRepository:    auditRepo,
+		txManager:          txManager,
+	}
+}
+
+// ForCompany returns a copy of the service that works on the books of companyID
+func (s *FiscalYearService) ForCompany(companyID int) *FiscalYearService {
+	scoped := *s
+	scoped.repository = s.repository.ForCompany(companyID)
+	scoped.voucherRepository = s.voucherRepository.ForCompany(companyID)
+	scoped.lineItemRepository = s.lineItemRepository.ForCompany(companyID)
+	scoped.periodRepository = s.periodRepository.ForCompany(companyID)
+	scoped.reportRepository = s.reportRepository.ForCompany(companyID)
+	scoped.auditRepository = s.auditRepository.ForCompany(companyID)
+	return &scoped
+}
+
+// AsUser returns a copy of the service that records the fiscal years it
+// creates in the audit trail as made by userID. Closing a year is recorded
+// as made by the user closing it.
+func (s *FiscalYearService) AsUser(userID int) *FiscalYearService {
+	scoped := *s
+	scoped.actorID = userID
+	return &scoped
+}
+
+// CreateFiscalYear creates a new open fiscal year
+func (s *FiscalYearService) CreateFiscalYear(fiscalYear *domain.FiscalYear) error {
+	start := fiscalYear.StartDate.Time
+	end := fiscalYear.EndDate.Time
+
+	// A fiscal year runs from the first day of a month to the last day of a month
+	if start.Day() != 1 {
+		return errors.New("start_date must be the first day of a month")
+	}
+	if end.AddDate(0, 0, 1).Day() != 1 {
+		return errors.New("end_date must be the last day of a month")
+	}
+	if !end.After(start) {
+		return errors.New("end_date must be after start_date")
+	}
+
+	// Bokföringslagen allows at most 18 months (first or changed fiscal year)
+	if end.After(start.AddDate(0, 18, -1)) {
+		return errors.New("a fiscal year cannot be longer than 18 months")
+	}
+
+	existing, err := s.repository.GetAllFiscalYears()
+	if err != nil {
+		return fmt.Errorf("failed to get fiscal years: %w", err)
+	}
+	for _, other := range existing {
+		if !start.After(other.EndDate.Time) && !end.Before(other.StartDate.Time) {
+			return fmt.Errorf("fiscal year overlaps %s - %s",
+				other.StartDate.Time.Format("2006-01-02"), other.EndDate.Time.Format("2006-01-02"))
+		}
+	}
+
+	fiscalYear.Status = domain.FiscalYearOpen
+	return s.txManager.WithTransaction(func(tx *sql.Tx) error {
+		if err := s.repository.WithTx(tx).CreateFiscalYear(fiscalYear); err != nil {
+			return fmt.Errorf("failed to create fiscal year: %w", err)
+		}
+
+		return recordAudit(s.auditRepository.WithTx(tx), s.actorID, domain.AuditEntityFiscalYear, fiscalYear.FiscalYearID, nil, fiscalYear)
+	})
+}
+
+// GetFiscalYearByID retrieves a fiscal year by ID
+func (s *FiscalYearService) GetFiscalYearByID(fiscalYearID int) (*domain.FiscalYear, error) {
+	if fiscalYearID <= 0 {
+		return nil, errors.New("invalid fiscal year ID")
+	}
+
+	fiscalYear, err := s.repository.GetFiscalYearByID(fiscalYearID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fiscal year: %w", err)
+	}
+
+	return fiscalYear, nil
+}
+
+// GetAllFiscalYears retrieves all fiscal years, latest first
+func (s *FiscalYearService) GetAllFiscalYears() ([]*domain.FiscalYear, error) {
+	fiscalYears, err := s.repository.GetAllFiscalYears()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fiscal years: %w", err)
+	}
+
+	return fiscalYears, nil
+}
+
+// CloseFiscalYear performs the year-end closing (bokslut) of a fiscal year:
+//   - the P&L result is booked from 8999 to 2099 Årets resultat on the last day
+//   - an opening balance voucher (ingående balans) with every BS account's
+//     balance is booked on the first day of the next fiscal year, which is
+//     created if it does not exist yet
+//   - the fiscal year and all its periods are closed
+//
+// Everything happens in one transaction. It returns the closed year and the
+// next year.
+func (s *FiscalYearService) CloseFiscalYear(fiscalYearID int, userID int) (*domain.FiscalYear, *domain.FiscalYear, error) {
+	if fiscalYearID <= 0 {
+		return nil, nil, errors.New("invalid fiscal year ID")
+	}
+	if userID <= 0 {
+		return nil, nil, errors.New("invalid user ID")
+	}
+
+	var closed, next *domain.FiscalYear
+
+	err := s.txManager.WithTransaction(func(tx *sql.Tx) error {
+		fiscalYearRepo := s.repository.WithTx(tx)
+		reportRepo := s.reportRepository.WithTx(tx)
+		auditRepo := s.auditRepository.WithTx(tx)
+
+		fiscalYear, err := fiscalYearRepo.GetFiscalYearByIDForUpdate(fiscalYearID)
+		if err != nil {
+			return err
+		}
+		if fiscalYear.Status == domain.FiscalYearClosed {
+			return ErrFiscalYearClosed
+		}
+
+		start := fiscalYear.StartDate.Time
+		end := fiscalYear.EndDate.Time
+
+		allYears, err := fiscalYearRepo.GetAllFiscalYears()
+		if err != nil {
+			return err
+		}
+		if previous := findFiscalYear(allYears, start.AddDate(0, 0, -1)); previous != nil && previous.Status != domain.FiscalYearClosed {
+			return errors.New("the previous fiscal year must be closed first")
+		}
+
+		// 1. Transfer the result to 2099
+		plBalances, err := reportRepo.GetAccountBalances(start.Format("2006-01-02"), end.Format("2006-01-02"), "P&L")
+		if err != nil {
+			return err
+		}
+		var result domain.Amount
+		for _, balance := range plBalances {
+			result = result.Add(balance.Balance)
+		}
+
+		var closingVoucherID *int
+		if !result.IsZero() {
+			closingVoucher := &domain.Voucher{
+				Series:      domain.ManualVoucherSeries,
+				Date:        domain.FlexibleDate{Time: end},
+				Description: fmt.Sprintf("Bokslut %s - %s: årets resultat", start.Format("2006-01-02"), end.Format("2006-01-02")),
+				Period:      end.Format("2006-01"),
+				CreatedBy:   userID,
+				Lines: []domain.LineItem{
+					// A profit is a credit balance (negative) on the P&L accounts;
+					// the opposite entry on 8999 brings them to zero
+					signedLine(accountYearResultPL, result.Neg()),
+					signedLine(accountYearResultBS, result),
+				},
+			}
+			if err := s.createVoucher(tx, closingVoucher); err != nil {
+				return fmt.Errorf("failed to create closing voucher: %w", err)
+			}
+			closingVoucherID = &closingVoucher.VoucherID
+		}
+
+		// 2. Find or create the next fiscal year
+		nextStart := end.AddDate(0, 0, 1)
+		next = findFiscalYear(allYears, nextStart)
+		if next == nil {
+			next = &domain.FiscalYear{
+				StartDate: domain.FlexibleDate{Time: nextStart},
+				EndDate:   domain.FlexibleDate{Time: nextStart.AddDate(1, 0, -1)},
+				Status:    domain.FiscalYearOpen,
+			}
+			if err := fiscalYearRepo.CreateFiscalYear(next); err != nil {
+				return err
+			}
+			if err := recordAudit(auditRepo, userID, domain.AuditEntityFiscalYear, next.FiscalYearID, nil, next); err != nil {
+				return err
+			}
+		}
+
+		// 3. Carry the BS balances over as ingående balans
+		bsBalances, err := reportRepo.GetAccountBalances("", end.Format("2006-01-02"), "BS")
+		if err != nil {
+			return err
+		}
+
+		var openingVoucherID *int
+		if len(bsBalances) > 0 {
+			openingVoucher := &domain.Voucher{
+				Series:      domain.ManualVoucherSeries,
+				Date:        domain.FlexibleDate{Time: nextStart},
+				Description: fmt.Sprintf("Ingående balans %s", nextStart.Format("2006-01-02")),
+				Period:      nextStart.Format("2006-01"),
+				CreatedBy:   userID,
+			}
+			var sum domain.Amount
+			for _, balance := range bsBalances {
+				openingVoucher.Lines = append(openingVoucher.Lines, signedLine(balance.AccountNo, balance.Balance))
+				sum = sum.Add(balance.Balance)
+			}
+			// Results of years booked before any fiscal year was closed were
+			// never moved to 2099; carry them as previous years' result
+			if !sum.IsZero() {
+				openingVoucher.Lines = append(openingVoucher.Lines, signedLine(accountPreviousYearResult, sum.Neg()))
+			}
+
+			if err := s.createVoucher(tx, openingVoucher); err != nil {
+				return fmt.Errorf("failed to create opening balance voucher: %w", err)
+			}
+			openingVoucherID = &openingVoucher.VoucherID
+		}
+
+		// 4. Close the fiscal year and its periods
+		if err := fiscalYearRepo.MarkFiscalYearAsClosed(fiscalYearID, closingVoucherID, openingVoucherID, userID); err != nil {
+			return err
+		}
+
+		periodRepo := s.periodRepository.WithTx(tx)
+		for month := start; !month.After(end); month = month.AddDate(0, 1, 0) {
+			period := month.Format("2006-01")
+			before, err := periodRepo.GetPeriod(period)
+			if err != nil {
+				return err
+			}
+			if err := periodRepo.SetPeriodStatus(period, domain.PeriodClosed, userID); err != nil {
+				return err
+			}
+			after, err := periodRepo.GetPeriod(period)
+			if err != nil {
+				return err
+			}
+			if err := recordAudit(auditRepo, userID, domain.AuditEntityPeriod, period, before, after); err != nil {
+				return err
+			}
+		}
+
+		closed, err = fiscalYearRepo.GetFiscalYearByID(fiscalYearID)
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(auditRepo, userID, domain.AuditEntityFiscalYear, fiscalYearID, fiscalYear, closed)
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to close fiscal year: %w", err)
+	}
+
+	return closed, next, nil
+}
+
+// createVoucher stores a system generated voucher and its lines in tx, seals
+// it in the hash chain and records it in the audit trail as made by its
+// creator
+func (s *FiscalYearService) createVoucher(tx *sql.Tx, voucher *domain.Voucher) error {
+	for _, line := range voucher.Lines {
+		voucher.TotalAmount = voucher.TotalAmount.Add(line.DebitAmount)
+	}
+
+	voucherRepo := s.voucherRepository.WithTx(tx)
+	if err := voucherRepo.CreateVoucher(voucher); err != nil {
+		return err
+	}
+
+	lineItemRepo := s.lineItemRepository.WithTx(tx)
+	for i := range voucher.Lines {
+		voucher.Lines[i].VoucherID = voucher.VoucherID
+		if err := lineItemRepo.CreateLineItem(&voucher.Lines[i]); err != nil {
+			return err
+		}
+	}
+
+	if err := sealVoucher(voucherRepo, voucher); err != nil {
+		return err
+	}
+
+	return recordAudit(s.auditRepository.WithTx(tx), voucher.CreatedBy, domain.AuditEntityVoucher, voucher.VoucherID, nil, voucher)
+}
+
+// signedLine creates a line with a positive balance as debit and a negative
+// balance as credit
+func signedLine(accountNo int, balance domain.Amount) domain.LineItem {
+	line := domain.LineItem{AccountNo: accountNo}
+	if balance.IsNegative() {
+		line.CreditAmount = balance.Neg()
+	} else {
+		line.DebitAmount = balance
+	}
+	return line
+}
+
+// findFiscalYear returns the fiscal year in years that contains date, or nil
+func findFiscalYear(years []*domain.FiscalYear, date time.Time) *domain.FiscalYear {
+	for _, year := range years {
+		if !date.Before(year.StartDate.Time) && !date.After(year.EndDate.Time) {
+			return year
+		}
+	}
+	return nil
+}
@@ -3,6 +3,7 @@ package service
 import (
 	"cmd/api/internal/domain"
 	"cmd/api/internal/repository"
+	"database/sql"
 	"errors"
 	"fmt"
 
@@ -10,17 +11,54 @@ import (
 )
 
 type LineItemService struct {
-	repository repository.LineItemRepository
-	validate   *validator.Validate
+	repository           repository.LineItemRepository
+	voucherRepository    repository.VoucherRepository
+	projectRepository    repository.ProjectRepository
+	costCenterRepository repository.CostCenterRepository
+	auditRepository      repository.AuditRepository
+	txManager            repository.TxManager
+	validate             *validator.Validate
+	actorID              int
 }
 
-func NewLineItemService(repo repository.LineItemRepository) *LineItemService {
+func NewLineItemService(
+	repo repository.LineItemRepository,
+	voucherRepo repository.VoucherRepository,
+	projectRepo repository.ProjectRepository,
+	costCenterRepo repository.CostCenterRepository,
+	auditRepo repository.AuditRepository,
+	txManager repository.TxManager,
+) *LineItemService {
 	return &LineItemService{
-		repository: repo,
-		validate:   validator.New(),
+		repository:           repo,
+		voucherRepository:    voucherRepo,
+		projectRepository:    projectRepo,
+		costCenterRepository: costCenterRepo,
+		auditRepository:      auditRepo,
+		txManager:            txManager,
+		validate:             validator.New(),
 	}
 }
 
+// ForCompany returns a copy of the service that works on the books of companyID
+func (s *LineItemService) ForCompany(companyID int) *LineItemService {
+	scoped := *s
+	scoped.repository = s.repository.ForCompany(companyID)
+	scoped.voucherRepository = s.voucherRepository.ForCompany(companyID)
+	scoped.projectRepository = s.projectRepository.ForCompany(companyID)
+	scoped.costCenterRepository = s.costCenterRepository.ForCompany(companyID)
+	scoped.auditRepository = s.auditRepository.ForCompany(companyID)
+	return &scoped
+}
+
+// AsUser returns a copy of the service that records its changes in the
+// audit trail as made by userID
+func (s *LineItemService) AsUser(userID int) *LineItemService {
+	scoped := *s
+	scoped.actorID = userID
+	return &scoped
+}
+
 // CreateLineItem creates a new line item
 func (s *LineItemService) CreateLineItem(lineItem *domain.LineItem) error {
 	// Validate input
@@ -28,13 +66,8 @@ func (s *LineItemService) CreateLineItem(lineItem *domain.LineItem) error {
 		return fmt.Errorf("validation failed: %w", err)
 	}
 
-	// Business rule: Either debit or credit must be > 0, but not both
-	if lineItem.DebitAmount > 0 && lineItem.CreditAmount > 0 {
-		return errors.New("a line item cannot have both debit and credit amounts")
-	}
-
-	if lineItem.DebitAmount == 0 && lineItem.CreditAmount == 0 {
-		return errors.New("a line item must have either debit or credit amount")
+	if err := validateLineItemFields(lineItem); err != nil {
+		return err
 	}
 
 	// Validate voucher ID
@@ -42,18 +75,22 @@ func (s *LineItemService) CreateLineItem(lineItem *domain.LineItem) error {
 		return errors.New("invalid voucher ID")
 	}
 
-	// Validate account number
-	if lineItem.AccountNo <= 0 {
-		return errors.New("invalid account number")
-	}
-
 	// Create line item
-	err := s.repository.CreateLineItem(lineItem)
-	if err != nil {
-		return fmt.Errorf("failed to create line item: %w", err)
-	}
-
-	return nil
+	return s.txManager.WithTransaction(func(tx *sql.Tx) error {
+		voucher, err := s.ensureVoucherEditable(tx, lineItem.VoucherID)
+		if err != nil {
+			return err
+		}
+		if err := validateLineDimensions(s.projectRepository, s.costCenterRepository, []domain.LineItem{*lineItem}, voucher.Date.Time); err != nil {
+			return err
+		}
+
+		if err := s.repository.WithTx(tx).CreateLineItem(lineItem); err != nil {
+			return fmt.Errorf("failed to create line item: %w", err)
+		}
+
+		return recordAudit(s.auditRepository.WithTx(tx), s.actorID, domain.AuditEntityLineItem, lineItem.LineID, nil, lineItem)
+	})
 }
 
 // GetLineItemByID retrieves a line item by ID
@@ -114,13 +151,8 @@ func (s *LineItemService) UpdateLineItem(lineItem *domain.LineItem) error {
 		return errors.New("line item not found")
 	}
 
-	// Business rule: Either debit or credit must be > 0, but not both
-	if lineItem.DebitAmount > 0 && lineItem.CreditAmount > 0 {
-		return errors.New("a line item cannot have both debit and credit amounts")
-	}
-
-	if lineItem.DebitAmount == 0 && lineItem.CreditAmount == 0 {
-		return errors.New("a line item must have either debit or credit amount")
+	if err := validateLineItemFields(lineItem); err != nil {
+		return err
 	}
 
 	// Validate voucher ID
@@ -128,18 +160,29 @@ func (s *LineItemService) UpdateLineItem(lineItem *domain.LineItem) error {
 		return errors.New("invalid voucher ID")
 	}
 
-	// Validate account number
-	if lineItem.AccountNo <= 0 {
-		return errors.New("invalid account number")
-	}
-
 	// Update line item
-	err = s.repository.UpdateLineItem(lineItem)
-	if err != nil {
-		return fmt.Errorf("failed to update line item: %w", err)
-	}
-
-	return nil
+	return s.txManager.WithTransaction(func(tx *sql.Tx) error {
+		// Both the current voucher and the one the line moves to must be
+		// editable
+		voucher, err := s.ensureVoucherEditable(tx, existingLineItem.VoucherID)
+		if err != nil {
+			return err
+		}
+		if lineItem.VoucherID != existingLineItem.VoucherID {
+			if voucher, err = s.ensureVoucherEditable(tx, lineItem.VoucherID); err != nil {
+				return err
+			}
+		}
+		if err := validateLineDimensions(s.projectRepository, s.costCenterRepository, []domain.LineItem{*lineItem}, voucher.Date.Time); err != nil {
+			return err
+		}
+
+		if err := s.repository.WithTx(tx).UpdateLineItem(lineItem); err != nil {
+			return fmt.Errorf("failed to update line item: %w", err)
+		}
+
+		return recordAudit(s.auditRepository.WithTx(tx), s.actorID, domain.AuditEntityLineItem, lineItem.LineID, existingLineItem, lineItem)
+	})
 }
 
 // DeleteLineItem deletes a line item by ID
@@ -158,12 +201,17 @@ func (s *LineItemService) DeleteLineItem(lineID int) error {
 	}
 
 	// Delete line item
-	err = s.repository.DeleteLineItem(lineID)
-	if err != nil {
-		return fmt.Errorf("failed to delete line item: %w", err)
-	}
+	return s.txManager.WithTransaction(func(tx *sql.Tx) error {
+		if _, err := s.ensureVoucherEditable(tx, existingLineItem.VoucherID); err != nil {
+			return err
+		}
 
-	return nil
+		if err := s.repository.WithTx(tx).DeleteLineItem(lineID); err != nil {
+			return fmt.Errorf("failed to delete line item: %w", err)
+		}
+
+		return recordAudit(s.auditRepository.WithTx(tx), s.actorID, domain.AuditEntityLineItem, lineID, existingLineItem, nil)
+	})
 }
 
 // DeleteLineItemsByVoucherID deletes all line items for a voucher
@@ -172,9 +220,66 @@ func (s *LineItemService) DeleteLineItemsByVoucherID(voucherID int) error {
 		return errors.New("invalid voucher ID")
 	}
 
-	err := s.repository.DeleteLineItemsByVoucherID(voucherID)
+	return s.txManager.WithTransaction(func(tx *sql.Tx) error {
+		if _, err := s.ensureVoucherEditable(tx, voucherID); err != nil {
+			return err
+		}
+
+		repo := s.repository.WithTx(tx)
+		lineItems, err := repo.GetLineItemsByVoucherID(voucherID)
+		if err != nil {
+			return fmt.Errorf("failed to get line items by voucher: %w", err)
+		}
+
+		if err := repo.DeleteLineItemsByVoucherID(voucherID); err != nil {
+			return fmt.Errorf("failed to delete line items by voucher: %w", err)
+		}
+
+		auditRepo := s.auditRepository.WithTx(tx)
+		for _, lineItem := range lineItems {
+			if err := recordAudit(auditRepo, s.actorID, domain.AuditEntityLineItem, lineItem.LineID, lineItem, nil); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// ensureVoucherEditable locks the voucher until tx ends, so it cannot be
+// booked while its lines change, and refuses changes to lines of booked
+// vouchers and of drafts made by someone else
+func (s *LineItemService) ensureVoucherEditable(tx *sql.Tx, voucherID int) (*domain.Voucher, error) {
+	voucher, err := s.voucherRepository.WithTx(tx).GetVoucherByIDForUpdate(voucherID)
 	if err != nil {
-		return fmt.Errorf("failed to delete line items by voucher: %w", err)
+		return nil, fmt.Errorf("failed to get voucher: %w", err)
+	}
+	if err := ensureDraftOf(voucher, s.actorID); err != nil {
+		return nil, err
+	}
+
+	return voucher, nil
+}
+
+// validateLineItemFields checks the rules every line item must satisfy,
+// independent of which voucher it belongs to
+func validateLineItemFields(lineItem *domain.LineItem) error {
+	// Business rule: Either debit or credit must be > 0, but not both
+	if lineItem.DebitAmount > 0 && lineItem.CreditAmount > 0 {
+		return errors.New("a line item cannot have both debit and credit amounts")
+	}
+
+	if lineItem.DebitAmount == 0 && lineItem.CreditAmount == 0 {
+		return errors.New("a line item must have either debit or credit amount")
+	}
+
+	if lineItem.DebitAmount < 0 || lineItem.CreditAmount < 0 {
+		return errors.New("debit and credit amounts cannot be negative")
+	}
+
+	// Validate account number
+	if lineItem.AccountNo <= 0 {
+		return errors.New("invalid account number")
 	}
 
 	return nil
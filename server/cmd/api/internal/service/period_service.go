@@ -0,0 +1,160 @@
+package service
+
+import (
+	"cmd/api/internal/domain"
+	"cmd/api/internal/repository"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+type PeriodService struct {
+	repository      repository.PeriodRepository
+	auditRepository repository.AuditRepository
+	txManager       repository.TxManager
+}
+
+func NewPeriodService(
+	repo repository.PeriodRepository,
+	auditRepo repository.AuditRepository,
+	txManager repository.TxManager,
+) *PeriodService {
+	return &PeriodService{
+		repository:      repo,
+		auditRepository: auditRepo,
+		txManager:       txManager,
+	}
+}
+
+// ForCompany returns a copy of the service that works on the books of companyID
+func (s *PeriodService) ForCompany(companyID int) *PeriodService {
+	scoped := *s
+	scoped.repository = s.repository.ForCompany(companyID)
+	scoped.auditRepository = s.auditRepository.ForCompany(companyID)
+	return &scoped
+}
+
+// GetAllPeriods retrieves all known periods with their status
+func (s *PeriodService) GetAllPeriods() ([]*domain.Period, error) {
+	periods, err := s.repository.GetAllPeriods()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get periods: %w", err)
+	}
+
+	return periods, nil
+}
+
+// GetPeriod retrieves the status of a single period
+func (s *PeriodService) GetPeriod(period string) (*domain.Period, error) {
+	if err := validatePeriodFormat(period); err != nil {
+		return nil, err
+	}
+
+	p, err := s.repository.GetPeriod(period)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get period: %w", err)
+	}
+
+	return p, nil
+}
+
+// LockPeriod locks an open period so no bookings can be made in it
+func (s *PeriodService) LockPeriod(period string, userID int) (*domain.Period, error) {
+	return s.changeStatus(period, userID, domain.PeriodLocked, domain.PeriodOpen)
+}
+
+// ReopenPeriod reopens a locked period. Closed periods cannot be reopened.
+func (s *PeriodService) ReopenPeriod(period string, userID int) (*domain.Period, error) {
+	return s.changeStatus(period, userID, domain.PeriodOpen, domain.PeriodLocked)
+}
+
+// ClosePeriod closes a period for good
+func (s *PeriodService) ClosePeriod(period string, userID int) (*domain.Period, error) {
+	return s.changeStatus(period, userID, domain.PeriodClosed, domain.PeriodOpen, domain.PeriodLocked)
+}
+
+// changeStatus moves a period to status if its current status is one of from
+func (s *PeriodService) changeStatus(period string, userID int, status string, from ...string) (*domain.Period, error) {
+	if err := validatePeriodFormat(period); err != nil {
+		return nil, err
+	}
+	if userID <= 0 {
+		return nil, errors.New("invalid user ID")
+	}
+
+	var updated *domain.Period
+	err := s.txManager.WithTransaction(func(tx *sql.Tx) error {
+		repo := s.repository.WithTx(tx)
+		current, err := repo.GetPeriodForUpdate(period)
+		if err != nil {
+			return fmt.Errorf("failed to get period: %w", err)
+		}
+
+		allowed := false
+		for _, f := range from {
+			if current.Status == f {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return fmt.Errorf("%w: cannot change period %s from %s to %s", ErrInvalidPeriodTransition, period, current.Status, status)
+		}
+
+		if err := repo.SetPeriodStatus(period, status, userID); err != nil {
+			return fmt.Errorf("failed to update period: %w", err)
+		}
+
+		updated, err = repo.GetPeriod(period)
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(s.auditRepository.WithTx(tx), userID, domain.AuditEntityPeriod, period, current, updated)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+// ensurePeriodOpen returns ErrPeriodNotOpen unless period accepts bookings.
+// It only reads the status; bookings use lockPeriodOpen instead.
+func ensurePeriodOpen(repo repository.PeriodRepository, period string) error {
+	p, err := repo.GetPeriod(period)
+	if err != nil {
+		return fmt.Errorf("failed to get period: %w", err)
+	}
+
+	return checkPeriodOpen(p)
+}
+
+// lockPeriodOpen is ensurePeriodOpen for a booking transaction: repo must
+// run in it. The period row stays share-locked until the transaction ends,
+// so the period cannot be locked or closed between the check and the
+// booking.
+func lockPeriodOpen(repo repository.PeriodRepository, period string) error {
+	p, err := repo.GetPeriodForShare(period)
+	if err != nil {
+		return fmt.Errorf("failed to get period: %w", err)
+	}
+
+	return checkPeriodOpen(p)
+}
+
+func checkPeriodOpen(p *domain.Period) error {
+	if p.Status != domain.PeriodOpen {
+		return fmt.Errorf("%w: period %s is %s", ErrPeriodNotOpen, p.Period, p.Status)
+	}
+
+	return nil
+}
+
+func validatePeriodFormat(period string) error {
+	if _, err := time.Parse("2006-01", period); err != nil {
+		return errors.New("period must be in format YYYY-MM (e.g., '2025-01')")
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"cmd/api/internal/domain"
+	"cmd/api/internal/repository"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/go-playground/validator/v10"
+)
+
+type ProjectService struct {
+	repository      repository.ProjectRepository
+	auditRepository repository.AuditRepository
+	txManager       repository.TxManager
+	validate        *validator.Validate
+	actorID         int
+}
+
+func NewProjectService(
+	repo repository.ProjectRepository,
+	auditRepo repository.AuditRepository,
+	txManager repository.TxManager,
+) *ProjectService {
+	return &ProjectService{
+		repository:      repo,
+		auditRepository: auditRepo,
+		txManager:       txManager,
+		validate:        validator.New(),
+	}
+}
+
+// ForCompany returns a copy of the service that works on the books of companyID
+func (s *ProjectService) ForCompany(companyID int) *ProjectService {
+	scoped := *s
+	scoped.repository = s.repository.ForCompany(companyID)
+	scoped.auditRepository = s.auditRepository.ForCompany(companyID)
+	return &scoped
+}
+
+// AsUser returns a copy of the service that records its changes in the
+// audit trail as made by userID
+func (s *ProjectService) AsUser(userID int) *ProjectService {
+	scoped := *s
+	scoped.actorID = userID
+	return &scoped
+}
+
+// CreateProject creates a new project. New projects are always active.
+func (s *ProjectService) CreateProject(project *domain.Project) error {
+	if err := s.validateNewProject(project); err != nil {
+		return err
+	}
+
+	return s.txManager.WithTransaction(func(tx *sql.Tx) error {
+		return s.createProject(tx, project)
+	})
+}
+
+// validateNewProject checks a project before it is created
+func (s *ProjectService) validateNewProject(project *domain.Project) error {
+	if err := s.validate.Struct(project); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+
+	if err := validateDimensionDates(project.StartDate, project.EndDate); err != nil {
+		return err
+	}
+
+	if existing, err := s.repository.GetProjectByCode(project.Code); err == nil && existing != nil {
+		return errors.New("project with this code already exists")
+	}
+
+	return nil
+}
+
+// createProject creates a validated project in tx
+func (s *ProjectService) createProject(tx *sql.Tx, project *domain.Project) error {
+	project.Active = true
+	if err := s.repository.WithTx(tx).CreateProject(project); err != nil {
+		return fmt.Errorf("failed to create project: %w", err)
+	}
+
+	return recordAudit(s.auditRepository.WithTx(tx), s.actorID, domain.AuditEntityProject, project.ProjectID, nil, project)
+}
+
+// GetProjectByID retrieves a project by ID
+func (s *ProjectService) GetProjectByID(projectID int) (*domain.Project, error) {
+	if projectID <= 0 {
+		return nil, errors.New("invalid project ID")
+	}
+
+	project, err := s.repository.GetProjectByID(projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project: %w", err)
+	}
+
+	return project, nil
+}
+
+// GetAllProjects retrieves all projects
+func (s *ProjectService) GetAllProjects() ([]*domain.Project, error) {
+	projects, err := s.repository.GetAllProjects()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get projects: %w", err)
+	}
+
+	return projects, nil
+}
+
+// UpdateProject updates an existing project
+func (s *ProjectService) UpdateProject(project *domain.Project) error {
+	if err := s.validate.Struct(project); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+
+	existingProject, err := s.repository.GetProjectByID(project.ProjectID)
+	if err != nil {
+		return fmt.Errorf("project not found: %w", err)
+	}
+
+	if err := validateDimensionDates(project.StartDate, project.EndDate); err != nil {
+		return err
+	}
+
+	if existing, err := s.repository.GetProjectByCode(project.Code); err == nil && existing.ProjectID != project.ProjectID {
+		return errors.New("project with this code already exists")
+	}
+
+	return s.txManager.WithTransaction(func(tx *sql.Tx) error {
+		if err := s.repository.WithTx(tx).UpdateProject(project); err != nil {
+			return fmt.Errorf("failed to update project: %w", err)
+		}
+
+		return recordAudit(s.auditRepository.WithTx(tx), s.actorID, domain.AuditEntityProject, project.ProjectID, existingProject, project)
+	})
+}
+
+// DeleteProject deletes a project that has no bookings
+func (s *ProjectService) DeleteProject(projectID int) error {
+	if projectID <= 0 {
+		return errors.New("invalid project ID")
+	}
+
+	existingProject, err := s.repository.GetProjectByID(projectID)
+	if err != nil {
+		return fmt.Errorf("project not found: %w", err)
+	}
+
+	return s.txManager.WithTransaction(func(tx *sql.Tx) error {
+		if err := s.repository.WithTx(tx).DeleteProject(projectID); err != nil {
+			return fmt.Errorf("failed to delete project: %w", err)
+		}
+
+		return recordAudit(s.auditRepository.WithTx(tx), s.actorID, domain.AuditEntityProject, projectID, existingProject, nil)
+	})
+}
@@ -9,44 +9,312 @@ import (
 )
 
 type ReportService struct {
-	repository repository.ReportRepository
+	repository           repository.ReportRepository
+	fiscalYearRepository repository.FiscalYearRepository
+	projectRepository    repository.ProjectRepository
+	costCenterRepository repository.CostCenterRepository
 }
 
-func NewReportService(repo repository.ReportRepository) *ReportService {
+func NewReportService(
+	repo repository.ReportRepository,
+	fiscalYearRepo repository.FiscalYearRepository,
+	projectRepo repository.ProjectRepository,
+	costCenterRepo repository.CostCenterRepository,
+) *ReportService {
 	return &ReportService{
-		repository: repo,
+		repository:           repo,
+		fiscalYearRepository: fiscalYearRepo,
+		projectRepository:    projectRepo,
+		costCenterRepository: costCenterRepo,
 	}
 }
 
-func (s *ReportService) GetIncomeStatement(fromDate, toDate string) (*domain.IncomeStatement, error) {
+// ForCompany returns a copy of the service that works on the books of companyID
+func (s *ReportService) ForCompany(companyID int) *ReportService {
+	scoped := *s
+	scoped.repository = s.repository.ForCompany(companyID)
+	scoped.fiscalYearRepository = s.fiscalYearRepository.ForCompany(companyID)
+	scoped.projectRepository = s.projectRepository.ForCompany(companyID)
+	scoped.costCenterRepository = s.costCenterRepository.ForCompany(companyID)
+	return &scoped
+}
+
+// GetIncomeStatement generates the income statement for a date range,
+// limited to the project and cost centre in filter if set
+func (s *ReportService) GetIncomeStatement(fromDate, toDate string, filter domain.DimensionFilter) (*domain.IncomeStatement, error) {
+	if err := validateIncomeStatementRange(fromDate, toDate); err != nil {
+		return nil, err
+	}
+
+	// Get income statement from repository
+	statement, err := s.repository.GetIncomeStatement(fromDate, toDate, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate income statement: %w", err)
+	}
+
+	return statement, nil
+}
+
+// GetIncomeStatementByDimension generates one income statement per project
+// or cost centre with bookings in the date range. Lines without one are
+// in a last group with a nil ID.
+func (s *ReportService) GetIncomeStatementByDimension(fromDate, toDate string, filter domain.DimensionFilter, groupBy string) ([]domain.IncomeStatementGroup, error) {
+	if err := validateIncomeStatementRange(fromDate, toDate); err != nil {
+		return nil, err
+	}
+
+	objects, err := dimensionObjects(s.projectRepository, s.costCenterRepository, groupBy)
+	if err != nil {
+		return nil, err
+	}
+
+	statements, err := s.repository.GetIncomeStatementByDimension(fromDate, toDate, filter, groupBy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate income statement: %w", err)
+	}
+
+	ids := make([]int, 0, len(statements))
+	for id := range statements {
+		ids = append(ids, id)
+	}
+	sortDimensionIDs(ids, objects)
+
+	groups := make([]domain.IncomeStatementGroup, 0, len(ids))
+	for _, id := range ids {
+		groups = append(groups, domain.IncomeStatementGroup{
+			ID:        dimensionID(id),
+			Code:      objects[id].code,
+			Name:      objects[id].name,
+			Statement: statements[id],
+		})
+	}
+
+	return groups, nil
+}
+
+// FiscalYearDates returns the first and last date of a fiscal year as YYYY-MM-DD
+func (s *ReportService) FiscalYearDates(fiscalYearID int) (string, string, error) {
+	if fiscalYearID <= 0 {
+		return "", "", errors.New("invalid fiscal year ID")
+	}
+
+	fiscalYear, err := s.fiscalYearRepository.GetFiscalYearByID(fiscalYearID)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get fiscal year: %w", err)
+	}
+
+	return fiscalYear.StartDate.Time.Format("2006-01-02"), fiscalYear.EndDate.Time.Format("2006-01-02"), nil
+}
+
+func validateIncomeStatementRange(fromDate, toDate string) error {
 	// Validate dates
 	if fromDate == "" || toDate == "" {
-		return nil, errors.New("from_date and to_date are required")
+		return errors.New("from_date and to_date are required")
 	}
 
 	// Parse and validate date format
-	_, err := time.Parse("2006-01-02", fromDate)
+	from, err := time.Parse("2006-01-02", fromDate)
 	if err != nil {
-		return nil, fmt.Errorf("invalid from_date format, expected YYYY-MM-DD: %w", err)
+		return fmt.Errorf("invalid from_date format, expected YYYY-MM-DD: %w", err)
 	}
 
-	_, err = time.Parse("2006-01-02", toDate)
+	to, err := time.Parse("2006-01-02", toDate)
 	if err != nil {
-		return nil, fmt.Errorf("invalid to_date format, expected YYYY-MM-DD: %w", err)
+		return fmt.Errorf("invalid to_date format, expected YYYY-MM-DD: %w", err)
 	}
 
 	// Validate that from_date is before to_date
-	from, _ := time.Parse("2006-01-02", fromDate)
-	to, _ := time.Parse("2006-01-02", toDate)
+	if from.After(to) {
+		return errors.New("from_date must be before or equal to to_date")
+	}
+
+	return nil
+}
+
+// GetTrialBalance generates the trial balance (saldobalans) for a date range.
+// The P&L accounts of earlier years that were never closed to 2099 are
+// carried as balanserat resultat in the opening and closing totals, as on
+// the balance sheet.
+func (s *ReportService) GetTrialBalance(fromDate, toDate string) (*domain.TrialBalance, error) {
+	if fromDate == "" || toDate == "" {
+		return nil, errors.New("from_date and to_date are required")
+	}
+
+	from, err := time.Parse("2006-01-02", fromDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid from_date format, expected YYYY-MM-DD: %w", err)
+	}
+	to, err := time.Parse("2006-01-02", toDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid to_date format, expected YYYY-MM-DD: %w", err)
+	}
 	if from.After(to) {
 		return nil, errors.New("from_date must be before or equal to to_date")
 	}
 
-	// Get income statement from repository
-	statement, err := s.repository.GetIncomeStatement(fromDate, toDate)
+	fiscalYearStart, err := s.fiscalYearStart(from)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate income statement: %w", err)
+		return nil, err
 	}
 
-	return statement, nil
+	entries, err := s.repository.GetTrialBalance(fromDate, toDate, fiscalYearStart.Format("2006-01-02"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate trial balance: %w", err)
+	}
+	earlierPLBalances, err := s.repository.GetAccountBalances("", fiscalYearStart.AddDate(0, 0, -1).Format("2006-01-02"), "P&L")
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate trial balance: %w", err)
+	}
+
+	trialBalance := &domain.TrialBalance{
+		FiscalYearStart: fiscalYearStart.Format("2006-01-02"),
+		Accounts:        entries,
+	}
+	trialBalance.Period.FromDate = fromDate
+	trialBalance.Period.ToDate = toDate
+
+	for _, balance := range earlierPLBalances {
+		trialBalance.RetainedResult = trialBalance.RetainedResult.Add(balance.Balance)
+	}
+	trialBalance.TotalOpeningBalance = trialBalance.RetainedResult
+	trialBalance.TotalClosingBalance = trialBalance.RetainedResult
+
+	for _, entry := range entries {
+		trialBalance.TotalOpeningBalance = trialBalance.TotalOpeningBalance.Add(entry.OpeningBalance)
+		trialBalance.TotalDebit = trialBalance.TotalDebit.Add(entry.PeriodDebit)
+		trialBalance.TotalCredit = trialBalance.TotalCredit.Add(entry.PeriodCredit)
+		trialBalance.TotalClosingBalance = trialBalance.TotalClosingBalance.Add(entry.ClosingBalance)
+	}
+	trialBalance.Balanced = trialBalance.TotalDebit == trialBalance.TotalCredit &&
+		trialBalance.TotalClosingBalance.IsZero()
+
+	return trialBalance, nil
+}
+
+// basGroupNames are the BAS account groups used on the balance sheet
+var basGroupNames = map[int]string{
+	10: "Immateriella anläggningstillgångar",
+	11: "Byggnader och mark",
+	12: "Maskiner och inventarier",
+	13: "Finansiella anläggningstillgångar",
+	14: "Lager, produkter i arbete och pågående arbeten",
+	15: "Kundfordringar",
+	16: "Övriga kortfristiga fordringar",
+	17: "Förutbetalda kostnader och upplupna intäkter",
+	18: "Kortfristiga placeringar",
+	19: "Kassa och bank",
+	20: "Eget kapital",
+	21: "Obeskattade reserver",
+	22: "Avsättningar",
+	23: "Långfristiga skulder",
+	24: "Kortfristiga skulder till kreditinstitut, kunder och leverantörer",
+	25: "Skatteskulder",
+	26: "Moms och punktskatter",
+	27: "Personalens skatter, avgifter och löneavdrag",
+	28: "Övriga kortfristiga skulder",
+	29: "Upplupna kostnader och förutbetalda intäkter",
+}
+
+// GetBalanceSheet generates the balance sheet (balansräkning) at date.
+// BS accounts are summed from the first booking up to date and the result of
+// the fiscal year containing date is added to equity. So is the result of
+// earlier years that were never closed to 2099, as balanserat resultat;
+// closed years net to zero on the P&L accounts.
+func (s *ReportService) GetBalanceSheet(date string) (*domain.BalanceSheet, error) {
+	if date == "" {
+		return nil, errors.New("date is required")
+	}
+	balanceDate, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date format, expected YYYY-MM-DD: %w", err)
+	}
+
+	fiscalYearStart, err := s.fiscalYearStart(balanceDate)
+	if err != nil {
+		return nil, err
+	}
+
+	bsBalances, err := s.repository.GetAccountBalances("", date, "BS")
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate balance sheet: %w", err)
+	}
+	plBalances, err := s.repository.GetAccountBalances(fiscalYearStart.Format("2006-01-02"), date, "P&L")
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate balance sheet: %w", err)
+	}
+	earlierPLBalances, err := s.repository.GetAccountBalances("", fiscalYearStart.AddDate(0, 0, -1).Format("2006-01-02"), "P&L")
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate balance sheet: %w", err)
+	}
+
+	sheet := &domain.BalanceSheet{
+		Date:                 date,
+		FiscalYearStart:      fiscalYearStart.Format("2006-01-02"),
+		Assets:               make([]domain.BalanceSheetGroup, 0),
+		EquityAndLiabilities: make([]domain.BalanceSheetGroup, 0),
+	}
+
+	// Balances come ordered by account number, so groups are filled in order
+	for _, balance := range bsBalances {
+		group := balance.AccountNo / 100
+		groups := &sheet.EquityAndLiabilities
+		amount := balance.Balance.Neg()
+		if balance.AccountNo < 2000 {
+			groups = &sheet.Assets
+			amount = balance.Balance
+		}
+
+		if len(*groups) == 0 || (*groups)[len(*groups)-1].Group != group {
+			*groups = append(*groups, domain.BalanceSheetGroup{
+				Group:    group,
+				Name:     basGroupNames[group],
+				Accounts: make([]domain.BalanceSheetEntry, 0),
+			})
+		}
+		g := &(*groups)[len(*groups)-1]
+		g.Accounts = append(g.Accounts, domain.BalanceSheetEntry{
+			AccountNo:   balance.AccountNo,
+			AccountName: balance.AccountName,
+			Balance:     amount,
+		})
+		g.Total = g.Total.Add(amount)
+	}
+
+	for _, g := range sheet.Assets {
+		sheet.TotalAssets = sheet.TotalAssets.Add(g.Total)
+	}
+	for _, g := range sheet.EquityAndLiabilities {
+		sheet.TotalEquityAndLiabilities = sheet.TotalEquityAndLiabilities.Add(g.Total)
+	}
+
+	// A profit is a credit balance on the P&L accounts
+	for _, balance := range plBalances {
+		sheet.CurrentYearResult = sheet.CurrentYearResult.Sub(balance.Balance)
+	}
+	for _, balance := range earlierPLBalances {
+		sheet.RetainedResult = sheet.RetainedResult.Sub(balance.Balance)
+	}
+	sheet.TotalEquityAndLiabilities = sheet.TotalEquityAndLiabilities.
+		Add(sheet.RetainedResult).
+		Add(sheet.CurrentYearResult)
+
+	sheet.Difference = sheet.TotalAssets.Sub(sheet.TotalEquityAndLiabilities)
+	sheet.Balanced = sheet.Difference.IsZero()
+
+	return sheet, nil
+}
+
+// fiscalYearStart returns the first day of the fiscal year containing date,
+// falling back to the calendar year when no fiscal year is registered
+func (s *ReportService) fiscalYearStart(date time.Time) (time.Time, error) {
+	fiscalYears, err := s.fiscalYearRepository.GetAllFiscalYears()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get fiscal years: %w", err)
+	}
+
+	if fiscalYear := findFiscalYear(fiscalYears, date); fiscalYear != nil {
+		return fiscalYear.StartDate.Time, nil
+	}
+
+	return time.Date(date.Year(), time.January, 1, 0, 0, 0, 0, time.UTC), nil
 }
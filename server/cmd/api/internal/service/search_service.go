@@ -0,0 +1,64 @@
+package service
+
+import (
+	"cmd/api/internal/domain"
+	"cmd/api/internal/repository"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Number of hits a search returns
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+)
+
+type SearchService struct {
+	repository repository.SearchRepository
+}
+
+func NewSearchService(repo repository.SearchRepository) *SearchService {
+	return &SearchService{
+		repository: repo,
+	}
+}
+
+// ForCompany returns a copy of the service that searches the books of companyID
+func (s *SearchService) ForCompany(companyID int) *SearchService {
+	scoped := *s
+	scoped.repository = s.repository.ForCompany(companyID)
+	return &scoped
+}
+
+// Search finds vouchers by the words in query, the names of the accounts
+// they are booked on, and, when query is an amount such as "1 250,50 kr",
+// their amounts. A limit of 0 returns defaultSearchLimit hits.
+func (s *SearchService) Search(query string, limit int) (*domain.SearchResult, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, errors.New("search query is required")
+	}
+	if len(query) > 200 {
+		return nil, errors.New("search query is too long")
+	}
+
+	if limit == 0 {
+		limit = defaultSearchLimit
+	}
+	if limit < 0 || limit > maxSearchLimit {
+		return nil, fmt.Errorf("limit must be between 1 and %d", maxSearchLimit)
+	}
+
+	var amount *domain.Amount
+	if parsed, err := domain.ParseAmount(strings.TrimSuffix(strings.TrimSpace(strings.TrimSuffix(query, "kr")), ":-")); err == nil {
+		amount = &parsed
+	}
+
+	hits, err := s.repository.SearchVouchers(query, amount, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search: %w", err)
+	}
+
+	return &domain.SearchResult{Query: query, Hits: hits}, nil
+}
@@ -0,0 +1,733 @@
+package service
+
+import (
+	"cmd/api/internal/domain"
+	"cmd/api/internal/repository"
+	"cmd/api/internal/sie"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SIE dimensions used for line item objects
+const (
+	sieDimCostCenter = 1 // Kostnadsställe
+	sieDimProject    = 6 // Projekt
+)
+
+type SIEService struct {
+	accountService       *AccountService
+	projectService       *ProjectService
+	costCenterService    *CostCenterService
+	accountRepository    repository.AccountRepository
+	voucherRepository    repository.VoucherRepository
+	lineItemRepository   repository.LineItemRepository
+	reportRepository     repository.ReportRepository
+	fiscalYearRepository repository.FiscalYearRepository
+	periodRepository     repository.PeriodRepository
+	seriesRepository     repository.VoucherSeriesRepository
+	companyRepository    repository.CompanyRepository
+	auditRepository      repository.AuditRepository
+	txManager            repository.TxManager
+	companyID            int
+}
+
+func NewSIEService(
+	accountService *AccountService,
+	projectService *ProjectService,
+	costCenterService *CostCenterService,
+	accountRepo repository.AccountRepository,
+	voucherRepo repository.VoucherRepository,
+	lineItemRepo repository.LineItemRepository,
+	reportRepo repository.ReportRepository,
+	fiscalYearRepo repository.FiscalYearRepository,
+	periodRepo repository.PeriodRepository,
+	seriesRepo repository.VoucherSeriesRepository,
+	companyRepo repository.CompanyRepository,
+	auditRepo repository.AuditRepository,
+	txManager repository.TxManager,
+) *SIEService {
+	return &SIEService{
+		accountService:       accountService,
+		projectService:       projectService,
+		costCenterService:    costCenterService,
+		accountRepository:    accountRepo,
+		voucherRepository:    voucherRepo,
+		lineItemRepository:   lineItemRepo,
+		reportRepository:     reportRepo,
+		fiscalYearRepository: fiscalYearRepo,
+		periodRepository:     periodRepo,
+		seriesRepository:     seriesRepo,
+		companyRepository:    companyRepo,
+		auditRepository:      auditRepo,
+		txManager:            txManager,
+	}
+}
+
+// ForCompany returns a copy of the service that works on the books of companyID
+func (s *SIEService) ForCompany(companyID int) *SIEService {
+	scoped := *s
+	scoped.accountService = s.accountService.ForCompany(companyID)
+	scoped.projectService = s.projectService.ForCompany(companyID)
+	scoped.costCenterService = s.costCenterService.ForCompany(companyID)
+	scoped.accountRepository = s.accountRepository.ForCompany(companyID)
+	scoped.voucherRepository = s.voucherRepository.ForCompany(companyID)
+	scoped.lineItemRepository = s.lineItemRepository.ForCompany(companyID)
+	scoped.reportRepository = s.reportRepository.ForCompany(companyID)
+	scoped.fiscalYearRepository = s.fiscalYearRepository.ForCompany(companyID)
+	scoped.periodRepository = s.periodRepository.ForCompany(companyID)
+	scoped.seriesRepository = s.seriesRepository.ForCompany(companyID)
+	scoped.auditRepository = s.auditRepository.ForCompany(companyID)
+	scoped.companyID = companyID
+	return &scoped
+}
+
+// ExportFiscalYear produces a SIE type 4 file with the chart of accounts,
+// opening and closing balances, results and every voucher of a fiscal year.
+// It returns a suggested file name and the CP437 encoded file.
+func (s *SIEService) ExportFiscalYear(fiscalYearID int) (string, []byte, error) {
+	if fiscalYearID <= 0 {
+		return "", nil, errors.New("invalid fiscal year ID")
+	}
+
+	fiscalYear, err := s.fiscalYearRepository.GetFiscalYearByID(fiscalYearID)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get fiscal year: %w", err)
+	}
+	allYears, err := s.fiscalYearRepository.GetAllFiscalYears()
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get fiscal years: %w", err)
+	}
+	previous := findFiscalYear(allYears, fiscalYear.StartDate.Time.AddDate(0, 0, -1))
+
+	accounts, err := s.accountRepository.GetAllAccounts()
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get accounts: %w", err)
+	}
+
+	company, err := s.companyRepository.GetCompanyByID(s.companyID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	w := sie.NewWriter()
+
+	// Header
+	w.Line("#FLAGGA", "0")
+	w.Line("#PROGRAM", sie.Quote("Eskio"), sie.Quote("1.0"))
+	w.Line("#FORMAT", "PC8")
+	w.Line("#GEN", sie.Date(time.Now()))
+	w.Line("#SIETYP", "4")
+	w.Line("#FNAMN", sie.Quote(company.Name))
+	if company.OrgNr != "" {
+		w.Line("#ORGNR", company.OrgNr)
+	}
+	w.Line("#RAR", "0", sie.Date(fiscalYear.StartDate.Time), sie.Date(fiscalYear.EndDate.Time))
+	if previous != nil {
+		w.Line("#RAR", "-1", sie.Date(previous.StartDate.Time), sie.Date(previous.EndDate.Time))
+	}
+	w.Line("#KPTYP", "BAS2014")
+
+	// Chart of accounts
+	for _, account := range accounts {
+		w.Line("#KONTO", strconv.Itoa(account.AccountNo), sie.Quote(account.AccountName))
+		w.Line("#KTYP", strconv.Itoa(account.AccountNo), sieAccountType(account))
+	}
+
+	// Balances and results for this and the previous year
+	if err := s.writeBalances(w, "0", fiscalYear); err != nil {
+		return "", nil, err
+	}
+	if previous != nil {
+		if err := s.writeBalances(w, "-1", previous); err != nil {
+			return "", nil, err
+		}
+	}
+
+	// Vouchers
+	if err := s.writeVouchers(w, fiscalYear, allYears); err != nil {
+		return "", nil, err
+	}
+
+	data, err := w.Bytes()
+	if err != nil {
+		return "", nil, err
+	}
+
+	filename := fmt.Sprintf("eskio_%s_%s.se",
+		fiscalYear.StartDate.Time.Format("20060102"), fiscalYear.EndDate.Time.Format("20060102"))
+
+	return filename, data, nil
+}
+
+// writeBalances writes #IB and #UB for BS accounts and #RES for P&L
+// accounts of one fiscal year. yearIndex is 0 for the exported year and -1
+// for the year before.
+func (s *SIEService) writeBalances(w *sie.Writer, yearIndex string, fiscalYear *domain.FiscalYear) error {
+	start := fiscalYear.StartDate.Time
+	end := fiscalYear.EndDate.Time
+
+	opening, err := s.reportRepository.GetAccountBalances("", start.AddDate(0, 0, -1).Format("2006-01-02"), "BS")
+	if err != nil {
+		return fmt.Errorf("failed to get opening balances: %w", err)
+	}
+	closing, err := s.reportRepository.GetAccountBalances("", end.Format("2006-01-02"), "BS")
+	if err != nil {
+		return fmt.Errorf("failed to get closing balances: %w", err)
+	}
+	results, err := s.reportRepository.GetAccountBalances(start.Format("2006-01-02"), end.Format("2006-01-02"), "P&L")
+	if err != nil {
+		return fmt.Errorf("failed to get results: %w", err)
+	}
+
+	for _, balance := range opening {
+		w.Line("#IB", yearIndex, strconv.Itoa(balance.AccountNo), sie.Amount(balance.Balance))
+	}
+	for _, balance := range closing {
+		w.Line("#UB", yearIndex, strconv.Itoa(balance.AccountNo), sie.Amount(balance.Balance))
+	}
+	for _, balance := range results {
+		w.Line("#RES", yearIndex, strconv.Itoa(balance.AccountNo), sie.Amount(balance.Balance))
+	}
+
+	return nil
+}
+
+// writeVouchers writes #DIM, #OBJEKT and every booked #VER of the fiscal
+// year, corrected vouchers included, so the file is the whole bookkeeping
+// with no gaps in the series. Opening balance vouchers are left out; #IB
+// already covers them.
+func (s *SIEService) writeVouchers(w *sie.Writer, fiscalYear *domain.FiscalYear, allYears []*domain.FiscalYear) error {
+	fromDate := fiscalYear.StartDate.Time.Format("2006-01-02")
+	toDate := fiscalYear.EndDate.Time.Format("2006-01-02")
+
+	vouchers, err := s.voucherRepository.GetVouchersByDateRange(fromDate, toDate)
+	if err != nil {
+		return fmt.Errorf("failed to get vouchers: %w", err)
+	}
+	lineItems, err := s.lineItemRepository.GetLineItemsByDateRange(fromDate, toDate)
+	if err != nil {
+		return fmt.Errorf("failed to get line items: %w", err)
+	}
+
+	projects, err := s.projectService.GetAllProjects()
+	if err != nil {
+		return err
+	}
+	costCenters, err := s.costCenterService.GetAllCostCenters()
+	if err != nil {
+		return err
+	}
+	projectByID := make(map[int]*domain.Project, len(projects))
+	for _, project := range projects {
+		projectByID[project.ProjectID] = project
+	}
+	costCenterByID := make(map[int]*domain.CostCenter, len(costCenters))
+	for _, costCenter := range costCenters {
+		costCenterByID[costCenter.CostCenterID] = costCenter
+	}
+
+	linesByVoucher := make(map[int][]*domain.LineItem)
+	usedCostCenters := make(map[int]bool)
+	usedProjects := make(map[int]bool)
+	for _, line := range lineItems {
+		linesByVoucher[line.VoucherID] = append(linesByVoucher[line.VoucherID], line)
+		if line.CostCenterID != nil {
+			usedCostCenters[*line.CostCenterID] = true
+		}
+		if line.ProjectID != nil {
+			usedProjects[*line.ProjectID] = true
+		}
+	}
+
+	openingVouchers := make(map[int]bool)
+	for _, year := range allYears {
+		if year.OpeningVoucherID != nil {
+			openingVouchers[*year.OpeningVoucherID] = true
+		}
+	}
+
+	// Dimensions and objects used by the lines
+	if len(usedCostCenters) > 0 {
+		w.Line("#DIM", strconv.Itoa(sieDimCostCenter), sie.Quote("Kostnadsställe"))
+		for _, id := range sortedKeys(usedCostCenters) {
+			costCenter := costCenterByID[id]
+			w.Line("#OBJEKT", strconv.Itoa(sieDimCostCenter), sie.Quote(costCenter.Code), sie.Quote(costCenter.Name))
+		}
+	}
+	if len(usedProjects) > 0 {
+		w.Line("#DIM", strconv.Itoa(sieDimProject), sie.Quote("Projekt"))
+		for _, id := range sortedKeys(usedProjects) {
+			project := projectByID[id]
+			w.Line("#OBJEKT", strconv.Itoa(sieDimProject), sie.Quote(project.Code), sie.Quote(project.Name))
+		}
+	}
+
+	for _, voucher := range vouchers {
+		if openingVouchers[voucher.VoucherID] {
+			continue
+		}
+
+		w.Line("#VER", voucher.Series, strconv.Itoa(voucher.VoucherNumber),
+			sie.Date(voucher.Date.Time), sie.Quote(voucher.Description))
+		w.BeginBlock()
+		for _, line := range linesByVoucher[voucher.VoucherID] {
+			var objects []string
+			if line.CostCenterID != nil {
+				objects = append(objects, strconv.Itoa(sieDimCostCenter), costCenterByID[*line.CostCenterID].Code)
+			}
+			if line.ProjectID != nil {
+				objects = append(objects, strconv.Itoa(sieDimProject), projectByID[*line.ProjectID].Code)
+			}
+
+			// Debit is positive and credit negative
+			w.Line("#TRANS", strconv.Itoa(line.AccountNo), sie.Object(objects...),
+				sie.Amount(line.DebitAmount.Sub(line.CreditAmount)))
+		}
+		w.EndBlock()
+	}
+
+	return nil
+}
+
+// sieAccountType maps an account to #KTYP: T (tillgång), S (skuld/eget
+// kapital), I (intäkt) or K (kostnad)
+func sieAccountType(account *domain.Account) string {
+	switch {
+	case account.Type == "BS" && account.AccountNo < 2000:
+		return "T"
+	case account.Type == "BS":
+		return "S"
+	case account.StandardSide == "Credit":
+		return "I"
+	default:
+		return "K"
+	}
+}
+
+func sortedKeys(m map[int]bool) []int {
+	keys := make([]int, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	return keys
+}
+
+// ImportFile imports a SIE 4 file: accounts missing from the chart of
+// accounts are created through the AccountService and every #VER becomes a
+// voucher with its #TRANS rows as lines. Objects in dimension 1 and 6 are
+// matched by code to cost centres and projects; those declared by #OBJEKT
+// that do not exist yet are created.
+//
+// The whole file is checked first and nothing is imported if any row has
+// an error. With dryRun the result shows what would be created without
+// writing anything. The vouchers are created in one transaction.
+func (s *SIEService) ImportFile(data []byte, userID int, dryRun bool) (*domain.SIEImportResult, error) {
+	if userID <= 0 {
+		return nil, errors.New("invalid user ID")
+	}
+
+	result := &domain.SIEImportResult{
+		DryRun:             dryRun,
+		AccountsCreated:    []domain.Account{},
+		ProjectsCreated:    []domain.Project{},
+		CostCentersCreated: []domain.CostCenter{},
+		Vouchers:           []domain.Voucher{},
+		Errors:             []domain.SIEImportError{},
+		Warnings:           []domain.SIEImportError{},
+	}
+
+	file, parseErrs := sie.Parse(data)
+	for _, e := range parseErrs {
+		result.Errors = append(result.Errors, domain.SIEImportError{Line: e.Line, Message: e.Message})
+	}
+
+	// Guard against importing a client's file into the wrong company
+	company, err := s.companyRepository.GetCompanyByID(s.companyID)
+	if err != nil {
+		return nil, err
+	}
+	if file.OrgNr != "" && company.OrgNr != "" && !sameOrgNr(file.OrgNr, company.OrgNr) {
+		result.Errors = append(result.Errors, domain.SIEImportError{
+			Message: fmt.Sprintf("the file is for organisation number %s but the company has %s", file.OrgNr, company.OrgNr),
+		})
+	}
+
+	existing, err := s.accountRepository.GetAllAccounts()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get accounts: %w", err)
+	}
+	known := make(map[int]bool, len(existing))
+	for _, account := range existing {
+		known[account.AccountNo] = true
+	}
+
+	s.planAccounts(file, known, result)
+	objects, err := s.planObjects(file, result)
+	if err != nil {
+		return nil, err
+	}
+	series, err := s.seriesRepository.GetAllSeries()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get voucher series: %w", err)
+	}
+	knownSeries := make(map[string]bool, len(series))
+	for _, voucherSeries := range series {
+		knownSeries[voucherSeries.Series] = true
+	}
+
+	pending := s.planVouchers(file, known, knownSeries, objects, userID, result)
+
+	if dryRun || len(result.Errors) > 0 {
+		return result, nil
+	}
+
+	accountService := s.accountService.AsUser(userID)
+	projectService := s.projectService.AsUser(userID)
+	costCenterService := s.costCenterService.AsUser(userID)
+	for i := range result.AccountsCreated {
+		if err := accountService.validateNewAccount(&result.AccountsCreated[i]); err != nil {
+			return nil, fmt.Errorf("account %d: %w", result.AccountsCreated[i].AccountNo, err)
+		}
+	}
+	for i := range result.ProjectsCreated {
+		if err := projectService.validateNewProject(&result.ProjectsCreated[i]); err != nil {
+			return nil, fmt.Errorf("project %s: %w", result.ProjectsCreated[i].Code, err)
+		}
+	}
+	for i := range result.CostCentersCreated {
+		if err := costCenterService.validateNewCostCenter(&result.CostCentersCreated[i]); err != nil {
+			return nil, fmt.Errorf("cost centre %s: %w", result.CostCentersCreated[i].Code, err)
+		}
+	}
+
+	// The accounts and objects are created with the vouchers, so a failed
+	// import leaves nothing behind
+	err = s.txManager.WithTransaction(func(tx *sql.Tx) error {
+		for i := range result.AccountsCreated {
+			if err := accountService.createAccount(tx, &result.AccountsCreated[i]); err != nil {
+				return fmt.Errorf("account %d: %w", result.AccountsCreated[i].AccountNo, err)
+			}
+		}
+		for i := range result.ProjectsCreated {
+			project := &result.ProjectsCreated[i]
+			if err := projectService.createProject(tx, project); err != nil {
+				return fmt.Errorf("project %s: %w", project.Code, err)
+			}
+			objects.projects[project.Code] = project.ProjectID
+		}
+		for i := range result.CostCentersCreated {
+			costCenter := &result.CostCentersCreated[i]
+			if err := costCenterService.createCostCenter(tx, costCenter); err != nil {
+				return fmt.Errorf("cost centre %s: %w", costCenter.Code, err)
+			}
+			objects.costCenters[costCenter.Code] = costCenter.CostCenterID
+		}
+		for _, p := range pending {
+			line := &result.Vouchers[p.voucher].Lines[p.line]
+			if p.dim == sieDimProject {
+				id := objects.projects[p.code]
+				line.ProjectID = &id
+			} else {
+				id := objects.costCenters[p.code]
+				line.CostCenterID = &id
+			}
+		}
+
+		periodRepo := s.periodRepository.WithTx(tx)
+		voucherRepo := s.voucherRepository.WithTx(tx)
+		lineItemRepo := s.lineItemRepository.WithTx(tx)
+		auditRepo := s.auditRepository.WithTx(tx)
+
+		for i := range result.Vouchers {
+			voucher := &result.Vouchers[i]
+			if err := lockPeriodOpen(periodRepo, voucher.Period); err != nil {
+				return fmt.Errorf("voucher %s: %w", voucher.Reference, err)
+			}
+			if err := voucherRepo.CreateVoucher(voucher); err != nil {
+				return fmt.Errorf("voucher %s: %w", voucher.Reference, err)
+			}
+			for j := range voucher.Lines {
+				voucher.Lines[j].VoucherID = voucher.VoucherID
+				if err := lineItemRepo.CreateLineItem(&voucher.Lines[j]); err != nil {
+					return fmt.Errorf("voucher %s line %d: %w", voucher.Reference, j+1, err)
+				}
+			}
+			if err := sealVoucher(voucherRepo, voucher); err != nil {
+				return fmt.Errorf("voucher %s: %w", voucher.Reference, err)
+			}
+			if err := recordAudit(auditRepo, userID, domain.AuditEntityVoucher, voucher.VoucherID, nil, voucher); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to import: %w", err)
+	}
+
+	return result, nil
+}
+
+// planAccounts adds every #KONTO missing from the chart of accounts to the
+// result and marks it as known
+func (s *SIEService) planAccounts(file *sie.File, known map[int]bool, result *domain.SIEImportResult) {
+	for _, konto := range file.Accounts {
+		if known[konto.No] {
+			continue
+		}
+
+		if konto.No < 1000 || konto.No > 8999 {
+			result.Errors = append(result.Errors, domain.SIEImportError{
+				Line:    konto.Line,
+				Message: fmt.Sprintf("account %d is not a four digit BAS account", konto.No),
+			})
+			continue
+		}
+
+		account := domain.Account{
+			AccountNo:    konto.No,
+			AccountName:  konto.Name,
+			AccountGroup: konto.No / 1000,
+		}
+
+		accountType := konto.Type
+		if accountType == "" {
+			accountType = basAccountType(konto.No)
+		}
+		switch accountType {
+		case "T":
+			account.Type, account.StandardSide = "BS", "Debit"
+		case "S":
+			account.Type, account.StandardSide = "BS", "Credit"
+		case "I":
+			account.Type, account.StandardSide = "P&L", "Credit"
+		default:
+			account.Type, account.StandardSide = "P&L", "Debit"
+		}
+
+		result.AccountsCreated = append(result.AccountsCreated, account)
+		known[konto.No] = true
+	}
+}
+
+// sieObjects maps object codes to project and cost centre IDs. Objects
+// that the import creates map to 0 until they exist.
+type sieObjects struct {
+	projects    map[string]int
+	costCenters map[string]int
+}
+
+// siePendingObject is a line whose project or cost centre is created by the
+// import, so its ID is only known once the import runs
+type siePendingObject struct {
+	voucher int
+	line    int
+	dim     int
+	code    string
+}
+
+// planObjects adds every #OBJEKT in dimension 1 and 6 that does not exist
+// to the result
+func (s *SIEService) planObjects(file *sie.File, result *domain.SIEImportResult) (*sieObjects, error) {
+	objects := &sieObjects{
+		projects:    make(map[string]int),
+		costCenters: make(map[string]int),
+	}
+
+	projects, err := s.projectService.GetAllProjects()
+	if err != nil {
+		return nil, err
+	}
+	for _, project := range projects {
+		objects.projects[project.Code] = project.ProjectID
+	}
+	costCenters, err := s.costCenterService.GetAllCostCenters()
+	if err != nil {
+		return nil, err
+	}
+	for _, costCenter := range costCenters {
+		objects.costCenters[costCenter.Code] = costCenter.CostCenterID
+	}
+
+	for _, object := range file.Objects {
+		switch object.Dim {
+		case sieDimProject:
+			if _, exists := objects.projects[object.ID]; !exists {
+				result.ProjectsCreated = append(result.ProjectsCreated, domain.Project{Code: object.ID, Name: object.Name, Active: true})
+				objects.projects[object.ID] = 0
+			}
+		case sieDimCostCenter:
+			if _, exists := objects.costCenters[object.ID]; !exists {
+				result.CostCentersCreated = append(result.CostCentersCreated, domain.CostCenter{Code: object.ID, Name: object.Name, Active: true})
+				objects.costCenters[object.ID] = 0
+			}
+		}
+	}
+
+	return objects, nil
+}
+
+// planVouchers turns every #VER into a voucher in the result, recording
+// rows that cannot be imported as errors. Vouchers keep their series if the
+// company has it and go to the manual series otherwise; they are numbered
+// anew. It returns the lines that need the ID of a project or cost centre
+// the import creates.
+func (s *SIEService) planVouchers(
+	file *sie.File,
+	known map[int]bool,
+	knownSeries map[string]bool,
+	objects *sieObjects,
+	userID int,
+	result *domain.SIEImportResult,
+) []siePendingObject {
+	periods := make(map[string]error)
+	var pending []siePendingObject
+
+	for _, ver := range file.Vouchers {
+		reference := fmt.Sprintf("SIE %s%s", ver.Series, ver.Number)
+		voucher := domain.Voucher{
+			Series:      strings.ToUpper(ver.Series),
+			Date:        domain.FlexibleDate{Time: ver.Date},
+			Description: ver.Text,
+			Reference:   reference,
+			Period:      ver.Date.Format("2006-01"),
+			CreatedBy:   userID,
+		}
+		if voucher.Description == "" {
+			voucher.Description = reference
+		}
+		if !knownSeries[voucher.Series] {
+			if voucher.Series != "" {
+				result.Warnings = append(result.Warnings, domain.SIEImportError{
+					Line:    ver.Line,
+					Message: fmt.Sprintf("voucher series %q does not exist, voucher %s is booked in series %s", ver.Series, reference, domain.ManualVoucherSeries),
+				})
+			}
+			voucher.Series = domain.ManualVoucherSeries
+		}
+
+		var voucherPending []siePendingObject
+		valid := true
+		fail := func(line int, format string, args ...interface{}) {
+			result.Errors = append(result.Errors, domain.SIEImportError{Line: line, Message: fmt.Sprintf(format, args...)})
+			valid = false
+		}
+
+		for _, trans := range ver.Trans {
+			// Rows with a zero amount carry nothing to book
+			if trans.Amount.IsZero() {
+				continue
+			}
+			if !known[trans.AccountNo] {
+				fail(trans.Line, "account %d does not exist and is not defined by #KONTO", trans.AccountNo)
+				continue
+			}
+
+			line := domain.LineItem{AccountNo: trans.AccountNo}
+			if trans.Amount.IsPositive() {
+				line.DebitAmount = trans.Amount
+			} else {
+				line.CreditAmount = trans.Amount.Neg()
+			}
+
+			for _, ref := range trans.Objects {
+				if ref.Dim != sieDimCostCenter && ref.Dim != sieDimProject {
+					result.Warnings = append(result.Warnings, domain.SIEImportError{
+						Line:    trans.Line,
+						Message: fmt.Sprintf("object %q in dimension %d is not imported", ref.ID, ref.Dim),
+					})
+					continue
+				}
+				ids := objects.projects
+				if ref.Dim == sieDimCostCenter {
+					ids = objects.costCenters
+				}
+				id, exists := ids[ref.ID]
+				if !exists {
+					fail(trans.Line, "object %q in dimension %d does not exist and is not defined by #OBJEKT", ref.ID, ref.Dim)
+					continue
+				}
+				if id == 0 {
+					voucherPending = append(voucherPending, siePendingObject{
+						voucher: len(result.Vouchers),
+						line:    len(voucher.Lines),
+						dim:     ref.Dim,
+						code:    ref.ID,
+					})
+					continue
+				}
+				if ref.Dim == sieDimCostCenter {
+					line.CostCenterID = &id
+				} else {
+					line.ProjectID = &id
+				}
+			}
+
+			voucher.Lines = append(voucher.Lines, line)
+		}
+		if !valid {
+			continue
+		}
+
+		total, err := validateVoucherLines(voucher.Lines)
+		if err != nil {
+			fail(ver.Line, "voucher %s: %v", reference, err)
+			continue
+		}
+		voucher.TotalAmount = total
+
+		periodErr, checked := periods[voucher.Period]
+		if !checked {
+			periodErr = ensurePeriodOpen(s.periodRepository, voucher.Period)
+			periods[voucher.Period] = periodErr
+		}
+		if periodErr != nil {
+			fail(ver.Line, "voucher %s: %v", reference, periodErr)
+			continue
+		}
+
+		result.Vouchers = append(result.Vouchers, voucher)
+		pending = append(pending, voucherPending...)
+	}
+
+	return pending
+}
+
+// basAccountType guesses the #KTYP of an account from its BAS account class
+func basAccountType(accountNo int) string {
+	switch accountNo / 1000 {
+	case 1:
+		return "T"
+	case 2:
+		return "S"
+	case 3:
+		return "I"
+	default:
+		return "K"
+	}
+}
+
+// sameOrgNr compares two organisation numbers on their last ten digits, so
+// that "556000-0000" and "165560000000" are the same
+func sameOrgNr(a, b string) bool {
+	digits := func(s string) string {
+		var d strings.Builder
+		for _, r := range s {
+			if r >= '0' && r <= '9' {
+				d.WriteRune(r)
+			}
+		}
+		if d.Len() > 10 {
+			return d.String()[d.Len()-10:]
+		}
+		return d.String()
+	}
+	return digits(a) == digits(b)
+}
@@ -9,6 +9,7 @@ package service
 import (
 	"cmd/api/internal/domain"
 	"cmd/api/internal/repository"
+	"database/sql"
 	"errors"
 	"fmt"
 
@@ -16,19 +17,62 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
+// ErrNotSelfOrAdmin is returned when a user creates, changes or deletes
+// another user without being an administrator
+var ErrNotSelfOrAdmin = errors.New("users can only change themselves unless they are an administrator")
+
 type UserService struct {
-	repository repository.UserRepository
-	validate   *validator.Validate
+	repository      repository.UserRepository
+	auditRepository repository.AuditRepository
+	txManager       repository.TxManager
+	validate        *validator.Validate
+	actorID         int
 }
 
-func NewUserService(repo repository.UserRepository) *UserService {
+func NewUserService(
+	repo repository.UserRepository,
+	auditRepo repository.AuditRepository,
+	txManager repository.TxManager,
+) *UserService {
 	return &UserService{
-		repository: repo,
-		validate:   validator.New(),
+		repository:      repo,
+		auditRepository: auditRepo,
+		txManager:       txManager,
+		validate:        validator.New(),
 	}
 }
 
+// ForCompany returns a copy of the service that writes its audit entries to
+// the audit trail of companyID, the active company of the actor. Users do
+// not belong to a company, so changes made without one, like registering,
+// are in no company's audit trail.
+func (s *UserService) ForCompany(companyID int) *UserService {
+	scoped := *s
+	scoped.auditRepository = s.auditRepository.ForCompany(companyID)
+	return &scoped
+}
+
+// AsUser returns a copy of the service that records its changes in the
+// audit trail as made by userID
+func (s *UserService) AsUser(userID int) *UserService {
+	scoped := *s
+	scoped.actorID = userID
+	return &scoped
+}
+
+// CreateUser creates a user. Without an actor it is someone registering
+// themselves; otherwise only an administrator can create users.
 func (s *UserService) CreateUser(user *domain.User) error {
+	if s.actorID != 0 {
+		admin, err := s.isAdmin()
+		if err != nil {
+			return err
+		}
+		if !admin {
+			return ErrNotSelfOrAdmin
+		}
+	}
+
 	// 1. Validate the input using validator
 	if err := s.validate.Struct(user); err != nil {
 		return fmt.Errorf("validation failed: %w", err)
@@ -52,13 +96,19 @@ func (s *UserService) CreateUser(user *domain.User) error {
 		user.Role = "Bookkeeper"
 	}
 
-	// 5. Save to database via repository
-	err = s.repository.CreateUser(user)
-	if err != nil {
-		return fmt.Errorf("failed to create user: %w", err)
-	}
+	// 5. Save to database via repository, together with the audit entry.
+	// Someone registering themselves is their own actor.
+	return s.txManager.WithTransaction(func(tx *sql.Tx) error {
+		if err := s.repository.WithTx(tx).CreateUser(user); err != nil {
+			return fmt.Errorf("failed to create user: %w", err)
+		}
 
-	return nil
+		actorID := s.actorID
+		if actorID == 0 {
+			actorID = user.UserID
+		}
+		return recordAudit(s.auditRepository.WithTx(tx), actorID, domain.AuditEntityUser, user.UserID, nil, auditUser(user))
+	})
 }
 
 // GetUserByID retrieves a user by ID
@@ -89,7 +139,87 @@ func (s *UserService) GetUserByEmail(email string) (*domain.User, error) {
 	return user, nil
 }
 
+// UpdateUser changes a user. Users can change themselves, except their
+// role; administrators can change anyone.
+// GetVisibleUserByID retrieves a user the actor can see: themselves, a
+// member of one of their companies or, for administrators, anyone. Other
+// users are reported as not found. The password hash is left out.
+func (s *UserService) GetVisibleUserByID(userID int) (*domain.User, error) {
+	user, err := s.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.visibleUser(user)
+}
+
+// GetVisibleUserByEmail retrieves a user by email like GetVisibleUserByID
+func (s *UserService) GetVisibleUserByEmail(email string) (*domain.User, error) {
+	user, err := s.GetUserByEmail(email)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.visibleUser(user)
+}
+
+// visibleUser returns user without its password hash if the actor can see
+// it
+func (s *UserService) visibleUser(user *domain.User) (*domain.User, error) {
+	if user.UserID != s.actorID {
+		admin, err := s.isAdmin()
+		if err != nil {
+			return nil, err
+		}
+		if !admin {
+			shares, err := s.repository.SharesCompany(s.actorID, user.UserID)
+			if err != nil {
+				return nil, err
+			}
+			if !shares {
+				return nil, errors.New("failed to get user: user not found")
+			}
+		}
+	}
+
+	return auditUser(user), nil
+}
+
+// isAdmin reports whether the actor is an administrator, i.e. has the Admin
+// role on their user rather than only in a company
+func (s *UserService) isAdmin() (bool, error) {
+	if s.actorID <= 0 {
+		return false, nil
+	}
+
+	actor, err := s.repository.GetUserByID(s.actorID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get acting user: %w", err)
+	}
+
+	return actor.Role == RoleAdmin, nil
+}
+
+// ensureSelfOrAdmin refuses changes to other users than the actor unless
+// the actor is an administrator, and reports whether they are
+func (s *UserService) ensureSelfOrAdmin(userID int) (bool, error) {
+	admin, err := s.isAdmin()
+	if err != nil {
+		return false, err
+	}
+	if !admin && (s.actorID <= 0 || userID != s.actorID) {
+		return false, ErrNotSelfOrAdmin
+	}
+
+	return admin, nil
+}
+
 func (s *UserService) UpdateUser(user *domain.User) error {
+	admin, err := s.ensureSelfOrAdmin(user.UserID)
+	if err != nil {
+		return err
+	}
+
 	// 1. Validate input using validator
 	if err := s.validate.Struct(user); err != nil {
 		return fmt.Errorf("validation failed: %w", err)
@@ -101,6 +231,10 @@ func (s *UserService) UpdateUser(user *domain.User) error {
 		return fmt.Errorf("user not found: %w", err)
 	}
 
+	if !admin {
+		user.Role = existingUser.Role
+	}
+
 	// 3. If email is being changed, check if new email already exists
 	if existingUser.Email != user.Email {
 		userWithEmail, err := s.repository.GetUserByEmail(user.Email)
@@ -122,23 +256,43 @@ func (s *UserService) UpdateUser(user *domain.User) error {
 	}
 
 	// 5. Update in database
-	err = s.repository.UpdateUser(user)
-	if err != nil {
-		return fmt.Errorf("failed to update user: %w", err)
-	}
+	return s.txManager.WithTransaction(func(tx *sql.Tx) error {
+		if err := s.repository.WithTx(tx).UpdateUser(user); err != nil {
+			return fmt.Errorf("failed to update user: %w", err)
+		}
 
-	return nil
+		return recordAudit(s.auditRepository.WithTx(tx), s.actorID, domain.AuditEntityUser, user.UserID, auditUser(existingUser), auditUser(user))
+	})
 }
 
+// DeleteUser deletes a user. Users can delete themselves; administrators
+// can delete anyone.
 func (s *UserService) DeleteUser(userID int) error {
 	if userID <= 0 {
 		return errors.New("invalid user ID")
 	}
+	if _, err := s.ensureSelfOrAdmin(userID); err != nil {
+		return err
+	}
 
-	err := s.repository.DeleteUser(userID)
+	existingUser, err := s.repository.GetUserByID(userID)
 	if err != nil {
-		return fmt.Errorf("failed to delete user: %w", err)
+		return fmt.Errorf("user not found: %w", err)
 	}
 
-	return nil
+	return s.txManager.WithTransaction(func(tx *sql.Tx) error {
+		if err := s.repository.WithTx(tx).DeleteUser(userID); err != nil {
+			return fmt.Errorf("failed to delete user: %w", err)
+		}
+
+		return recordAudit(s.auditRepository.WithTx(tx), s.actorID, domain.AuditEntityUser, userID, auditUser(existingUser), nil)
+	})
+}
+
+// auditUser is the state of a user as written to the audit trail, without
+// the password hash
+func auditUser(user *domain.User) *domain.User {
+	redacted := *user
+	redacted.PasswordHash = ""
+	return &redacted
 }
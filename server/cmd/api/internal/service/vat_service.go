@@ -0,0 +1,412 @@
+package service
+
+import (
+	"cmd/api/internal/domain"
+	"cmd/api/internal/eskd"
+	"cmd/api/internal/repository"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Accounts used by the VAT settlement
+const (
+	vatSettlementAccount = 2650 // Redovisningskonto moms
+	roundingAccount      = 3740 // Öresutjämning
+)
+
+// vatBox describes how one box of the momsdeklaration is computed. Sales
+// and output VAT boxes sum credit - debit, purchase and input VAT boxes sum
+// debit - credit. Boxes without a match have no accounts in the chart of
+// accounts and are always zero.
+type vatBox struct {
+	box    string
+	label  string
+	credit bool
+	match  func(accountNo, taxCode int) bool
+}
+
+// vatBoxes lists the boxes in the order of the form. Sales boxes 05 and 42
+// use the line tax code to tell taxed sales from exempt ones. Box 22 and 50
+// have no accounts since 4531 and 4545 are customs and freight here.
+var vatBoxes = []vatBox{
+	{"05", "Momspliktig försäljning som inte ingår i ruta 06, 07 eller 08", true, func(a, t int) bool {
+		return a >= 3000 && a <= 3799 && t > 0 && !isVATExemptSalesAccount(a)
+	}},
+	{"06", "Momspliktiga uttag", true, nil},
+	{"07", "Beskattningsunderlag vid vinstmarginalbeskattning", true, nil},
+	{"08", "Hyresinkomster vid frivillig skattskyldighet", true, func(a, t int) bool {
+		return a >= 3910 && a <= 3919 && t > 0
+	}},
+	{"10", "Utgående moms 25 %", true, vatAccounts(2610, 2611, 2612, 2613, 2616, 2617, 2618, 2619)},
+	{"11", "Utgående moms 12 %", true, vatAccounts(2620, 2621, 2622, 2623, 2626, 2627, 2628, 2629)},
+	{"12", "Utgående moms 6 %", true, vatAccounts(2630, 2631, 2632, 2633, 2636, 2637, 2638, 2639)},
+	{"20", "Inköp av varor från ett annat EU-land", false, vatAccounts(4515, 4516, 4517)},
+	{"21", "Inköp av tjänster från ett annat EU-land enligt huvudregeln", false, vatAccounts(4535, 4536, 4537)},
+	{"22", "Inköp av tjänster från ett land utanför EU", false, nil},
+	{"23", "Inköp av varor i Sverige som köparen är skattskyldig för", false, vatAccounts(4415, 4416, 4417)},
+	{"24", "Övriga inköp av tjänster", false, vatAccounts(4425, 4426, 4427)},
+	{"30", "Utgående moms 25 %", true, vatAccounts(2614)},
+	{"31", "Utgående moms 12 %", true, vatAccounts(2624)},
+	{"32", "Utgående moms 6 %", true, vatAccounts(2634)},
+	{"50", "Beskattningsunderlag vid import", false, nil},
+	{"60", "Utgående moms 25 %", true, vatAccounts(2615)},
+	{"61", "Utgående moms 12 %", true, vatAccounts(2625)},
+	{"62", "Utgående moms 6 %", true, vatAccounts(2635)},
+	{"35", "Försäljning av varor till ett annat EU-land", true, vatAccounts(3300)},
+	{"36", "Försäljning av varor utanför EU", true, vatAccounts(3400)},
+	{"37", "Mellanmans inköp av varor vid trepartshandel", false, nil},
+	{"38", "Mellanmans försäljning av varor vid trepartshandel", true, nil},
+	{"39", "Försäljning av tjänster till en näringsidkare i ett annat EU-land enligt huvudregeln", true, vatAccounts(3305)},
+	{"40", "Övrig försäljning av tjänster omsatta utanför Sverige", true, nil},
+	{"41", "Försäljning när köparen är skattskyldig i Sverige", true, nil},
+	{"42", "Övrig försäljning m.m.", true, func(a, t int) bool {
+		return a >= 3000 && a <= 3699 && t == 0 && !isVATExemptSalesAccount(a)
+	}},
+	{"48", "Ingående moms att dra av", false, func(a, t int) bool {
+		return a >= 2640 && a <= 2649
+	}},
+}
+
+// vatToPayBoxes are added up, less box 48, into box 49. As on the form,
+// each box counts in whole kronor with the öre dropped.
+var vatToPayBoxes = []string{"10", "11", "12", "30", "31", "32", "60", "61", "62"}
+
+func vatAccounts(accountNos ...int) func(accountNo, taxCode int) bool {
+	return func(accountNo, taxCode int) bool {
+		for _, no := range accountNos {
+			if accountNo == no {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// isVATExemptSalesAccount reports the EU and export sales accounts, which
+// have their own boxes
+func isVATExemptSalesAccount(accountNo int) bool {
+	return accountNo == 3300 || accountNo == 3305 || accountNo == 3400
+}
+
+// isVATAccount reports the accounts cleared by the VAT settlement
+func isVATAccount(accountNo int) bool {
+	return accountNo >= 2610 && accountNo <= 2649
+}
+
+type VATService struct {
+	repository         repository.VATRepository
+	voucherRepository  repository.VoucherRepository
+	lineItemRepository repository.LineItemRepository
+	periodRepository   repository.PeriodRepository
+	companyRepository  repository.CompanyRepository
+	auditRepository    repository.AuditRepository
+	txManager          repository.TxManager
+	companyID          int
+}
+
+func NewVATService(
+	repo repository.VATRepository,
+	voucherRepo repository.VoucherRepository,
+	lineItemRepo repository.LineItemRepository,
+	periodRepo repository.PeriodRepository,
+	companyRepo repository.CompanyRepository,
+	auditRepo repository.AuditRepository,
+	txManager repository.TxManager,
+) *VATService {
+	return &VATService{
+		repository:         repo,
+		voucherRepository:  voucherRepo,
+		lineItemRepository: lineItemRepo,
+		periodRepository:   periodRepo,
+		companyRepository:  companyRepo,
+		auditRepository:    auditRepo,
+		txManager:          txManager,
+	}
+}
+
+// ForCompany returns a copy of the service that works on the books of companyID
+func (s *VATService) ForCompany(companyID int) *VATService {
+	scoped := *s
+	scoped.repository = s.repository.ForCompany(companyID)
+	scoped.voucherRepository = s.voucherRepository.ForCompany(companyID)
+	scoped.lineItemRepository = s.lineItemRepository.ForCompany(companyID)
+	scoped.periodRepository = s.periodRepository.ForCompany(companyID)
+	scoped.auditRepository = s.auditRepository.ForCompany(companyID)
+	scoped.companyID = companyID
+	return &scoped
+}
+
+// GetVATReturn builds the momsdeklaration for a month ("2025-01"), a quarter
+// ("2025-Q1") or a year ("2025")
+func (s *VATService) GetVATReturn(period string) (*domain.VATReturn, error) {
+	from, to, err := vatPeriodRange(period)
+	if err != nil {
+		return nil, err
+	}
+
+	vatReturn, _, err := s.buildVATReturn(s.repository, period, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return vatReturn, nil
+}
+
+// ExportESKD returns the VAT return for a period as an eSKD file for upload
+// to Skatteverket, with a suggested file name
+func (s *VATService) ExportESKD(period string) (string, []byte, error) {
+	company, err := s.companyRepository.GetCompanyByID(s.companyID)
+	if err != nil {
+		return "", nil, err
+	}
+	if company.OrgNr == "" {
+		return "", nil, errors.New("the company has no organisation number")
+	}
+
+	vatReturn, err := s.GetVATReturn(period)
+	if err != nil {
+		return "", nil, err
+	}
+
+	upload, err := eskd.NewUpload(company.OrgNr, vatReturn)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to build eSKD file: %w", err)
+	}
+
+	data, err := upload.Marshal()
+	if err != nil {
+		return "", nil, err
+	}
+
+	return fmt.Sprintf("moms_%s.xml", period), data, nil
+}
+
+// BookVATSettlement books the momsavräkning for a period: the period's
+// output and input VAT is moved to 2650 Redovisningskonto moms, which gets
+// the amount to pay in whole kronor, with the öre difference on 3740. The
+// voucher is dated the last day of the period.
+func (s *VATService) BookVATSettlement(period string, userID int) (*domain.VATReturn, *domain.Voucher, error) {
+	if userID <= 0 {
+		return nil, nil, errors.New("invalid user ID")
+	}
+
+	from, to, err := vatPeriodRange(period)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var vatReturn *domain.VATReturn
+	var voucher *domain.Voucher
+
+	err = s.txManager.WithTransaction(func(tx *sql.Tx) error {
+		vatRepo := s.repository.WithTx(tx)
+		if err := vatRepo.LockSettlements(); err != nil {
+			return err
+		}
+
+		settlements, err := vatRepo.GetSettlementsInRange(from.Format("2006-01-02"), to.Format("2006-01-02"))
+		if err != nil {
+			return err
+		}
+		if len(settlements) > 0 {
+			return fmt.Errorf("%w: %s overlaps %s", ErrVATAlreadySettled, period, settlements[0].Period)
+		}
+
+		var turnover []*domain.VATAccountSum
+		vatReturn, turnover, err = s.buildVATReturn(vatRepo, period, from, to)
+		if err != nil {
+			return err
+		}
+
+		lines := vatSettlementLines(turnover, vatReturn.VATToPay)
+		if len(lines) == 0 {
+			return errors.New("there is no VAT to settle for the period")
+		}
+
+		voucher = &domain.Voucher{
+			Series:      domain.ManualVoucherSeries,
+			Date:        domain.FlexibleDate{Time: to},
+			Description: fmt.Sprintf("Momsredovisning %s", period),
+			Reference:   "Momsdeklaration " + period,
+			Period:      to.Format("2006-01"),
+			CreatedBy:   userID,
+			Lines:       lines,
+		}
+		total, err := validateVoucherLines(voucher.Lines)
+		if err != nil {
+			return err
+		}
+		voucher.TotalAmount = total
+
+		if err := lockPeriodOpen(s.periodRepository.WithTx(tx), voucher.Period); err != nil {
+			return err
+		}
+		voucherRepo := s.voucherRepository.WithTx(tx)
+		if err := voucherRepo.CreateVoucher(voucher); err != nil {
+			return err
+		}
+		lineItemRepo := s.lineItemRepository.WithTx(tx)
+		for i := range voucher.Lines {
+			voucher.Lines[i].VoucherID = voucher.VoucherID
+			if err := lineItemRepo.CreateLineItem(&voucher.Lines[i]); err != nil {
+				return fmt.Errorf("line %d: %w", i+1, err)
+			}
+		}
+		if err := sealVoucher(voucherRepo, voucher); err != nil {
+			return err
+		}
+
+		settlement := &domain.VATSettlement{
+			Period:    period,
+			FromDate:  domain.FlexibleDate{Time: from},
+			ToDate:    domain.FlexibleDate{Time: to},
+			VoucherID: voucher.VoucherID,
+			BookedBy:  &userID,
+		}
+		if err := vatRepo.CreateSettlement(settlement); err != nil {
+			return err
+		}
+		vatReturn.SettlementVoucherID = &voucher.VoucherID
+
+		auditRepo := s.auditRepository.WithTx(tx)
+		if err := recordAudit(auditRepo, userID, domain.AuditEntityVoucher, voucher.VoucherID, nil, voucher); err != nil {
+			return err
+		}
+		return recordAudit(auditRepo, userID, domain.AuditEntityVATSettlement, settlement.VATSettlementID, nil, settlement)
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to book VAT settlement: %w", err)
+	}
+
+	return vatReturn, voucher, nil
+}
+
+// buildVATReturn computes the boxes from the period's turnover and returns
+// the turnover as well for the settlement
+func (s *VATService) buildVATReturn(
+	vatRepo repository.VATRepository,
+	period string,
+	from, to time.Time,
+) (*domain.VATReturn, []*domain.VATAccountSum, error) {
+	fromDate := from.Format("2006-01-02")
+	toDate := to.Format("2006-01-02")
+
+	turnover, err := vatRepo.GetVATTurnover(fromDate, toDate)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get VAT turnover: %w", err)
+	}
+
+	vatReturn := &domain.VATReturn{
+		Period:   period,
+		FromDate: fromDate,
+		ToDate:   toDate,
+		Boxes:    make([]domain.VATBox, 0, len(vatBoxes)+1),
+	}
+
+	amounts := make(map[string]domain.Amount)
+	for _, box := range vatBoxes {
+		var amount domain.Amount
+		if box.match != nil {
+			for _, sum := range turnover {
+				if !box.match(sum.AccountNo, sum.TaxCode) {
+					continue
+				}
+				if box.credit {
+					amount = amount.Add(sum.Credit.Sub(sum.Debit))
+				} else {
+					amount = amount.Add(sum.Debit.Sub(sum.Credit))
+				}
+			}
+		}
+		amounts[box.box] = amount
+		vatReturn.Boxes = append(vatReturn.Boxes, domain.VATBox{Box: box.box, Label: box.label, Amount: amount})
+	}
+
+	for _, box := range vatToPayBoxes {
+		vatReturn.VATToPay = vatReturn.VATToPay.Add(domain.NewAmount(amounts[box].Kronor()))
+	}
+	vatReturn.VATToPay = vatReturn.VATToPay.Sub(domain.NewAmount(amounts["48"].Kronor()))
+	vatReturn.Boxes = append(vatReturn.Boxes, domain.VATBox{
+		Box:    "49",
+		Label:  "Moms att betala eller få tillbaka",
+		Amount: vatReturn.VATToPay,
+	})
+
+	settlements, err := vatRepo.GetSettlementsInRange(fromDate, toDate)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get VAT settlements: %w", err)
+	}
+	for _, settlement := range settlements {
+		if settlement.Period == period {
+			vatReturn.SettlementVoucherID = &settlement.VoucherID
+		}
+	}
+
+	return vatReturn, turnover, nil
+}
+
+// vatSettlementLines clears every VAT account and books the net on 2650 in
+// whole kronor, with the öre on 3740
+func vatSettlementLines(turnover []*domain.VATAccountSum, vatToPay domain.Amount) []domain.LineItem {
+	balances := make(map[int]domain.Amount)
+	var order []int
+	for _, sum := range turnover {
+		if !isVATAccount(sum.AccountNo) {
+			continue
+		}
+		if _, seen := balances[sum.AccountNo]; !seen {
+			order = append(order, sum.AccountNo)
+		}
+		balances[sum.AccountNo] = balances[sum.AccountNo].Add(sum.Debit.Sub(sum.Credit))
+	}
+
+	lines := make([]domain.LineItem, 0, len(order)+2)
+	var net domain.Amount
+	for _, accountNo := range order {
+		balance := balances[accountNo]
+		if balance.IsZero() {
+			continue
+		}
+		lines = append(lines, signedLine(accountNo, balance.Neg()))
+		net = net.Add(balance.Neg())
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+
+	if !vatToPay.IsZero() {
+		lines = append(lines, signedLine(vatSettlementAccount, vatToPay.Neg()))
+	}
+	if rounding := net.Neg().Add(vatToPay); !rounding.IsZero() {
+		lines = append(lines, signedLine(roundingAccount, rounding))
+	}
+
+	return lines
+}
+
+// vatPeriodRange parses a VAT period: a month "2025-01", a quarter
+// "2025-Q1" or a year "2025"
+func vatPeriodRange(period string) (time.Time, time.Time, error) {
+	if t, err := time.Parse("2006-01", period); err == nil {
+		return t, t.AddDate(0, 1, -1), nil
+	}
+
+	if year, quarter, ok := strings.Cut(period, "-Q"); ok {
+		y, errYear := strconv.Atoi(year)
+		q, errQuarter := strconv.Atoi(quarter)
+		if errYear == nil && errQuarter == nil && len(year) == 4 && q >= 1 && q <= 4 {
+			from := time.Date(y, time.Month(3*(q-1)+1), 1, 0, 0, 0, 0, time.UTC)
+			return from, from.AddDate(0, 3, -1), nil
+		}
+	}
+
+	if t, err := time.Parse("2006", period); err == nil {
+		return t, t.AddDate(1, 0, -1), nil
+	}
+
+	return time.Time{}, time.Time{}, errors.New("period must be a month (2025-01), a quarter (2025-Q1) or a year (2025)")
+}
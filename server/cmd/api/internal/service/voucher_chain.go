@@ -0,0 +1,173 @@
+package service
+
+import (
+	"cmd/api/internal/domain"
+	"cmd/api/internal/repository"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// voucherHashVersion is the first line of the hashed text. Change it if the
+// format below ever changes, since every stored hash depends on it.
+const voucherHashVersion = "eskio-voucher-v1"
+
+// sealVoucher adds a booked voucher, stored with its lines in the same
+// transaction, to the end of its company's hash chain. voucherRepo must come
+// from WithTx: the chain head stays locked until the transaction ends.
+func sealVoucher(voucherRepo repository.VoucherRepository, voucher *domain.Voucher) error {
+	head, err := voucherRepo.LockChainHead()
+	if err != nil {
+		return err
+	}
+
+	position := head.Position + 1
+	hash := voucherHash(head.Hash, position, voucher, voucher.Lines)
+	if err := voucherRepo.SealVoucher(voucher.VoucherID, position, head.Hash, hash); err != nil {
+		return err
+	}
+
+	voucher.ChainPosition = &position
+	voucher.Hash = &hash
+	return nil
+}
+
+// voucherHash is the SHA-256 of the previous hash, the position in the chain
+// and everything booked on the voucher. Corrected-by is left out as it is set
+// on the original when a correction is booked later.
+func voucherHash(prevHash string, position int, voucher *domain.Voucher, lines []domain.LineItem) string {
+	var b strings.Builder
+	field := func(name, value string) {
+		b.WriteString(name)
+		b.WriteByte('=')
+		b.WriteString(value)
+		b.WriteByte('\n')
+	}
+	optional := func(id *int) string {
+		if id == nil {
+			return "-"
+		}
+		return strconv.Itoa(*id)
+	}
+
+	b.WriteString(voucherHashVersion + "\n")
+	field("prev", prevHash)
+	field("position", strconv.Itoa(position))
+	field("series", strconv.Quote(voucher.Series))
+	field("number", strconv.Itoa(voucher.VoucherNumber))
+	field("date", voucher.Date.Time.Format("2006-01-02"))
+	field("period", strconv.Quote(voucher.Period))
+	field("description", strconv.Quote(voucher.Description))
+	field("reference", strconv.Quote(voucher.Reference))
+	field("total", strconv.FormatInt(int64(voucher.TotalAmount), 10))
+	field("created_by", strconv.Itoa(voucher.CreatedBy))
+	field("corrects", optional(voucher.CorrectsVoucherID))
+
+	sorted := make([]domain.LineItem, len(lines))
+	copy(sorted, lines)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].LineID < sorted[j].LineID })
+	for _, line := range sorted {
+		field("line", fmt.Sprintf("%d|%d|%d|%d|%s|%s",
+			line.AccountNo,
+			int64(line.DebitAmount),
+			int64(line.CreditAmount),
+			line.TaxCode,
+			optional(line.ProjectID),
+			optional(line.CostCenterID),
+		))
+	}
+
+	sum := sha256.Sum256([]byte(b.String()))
+	return hex.EncodeToString(sum[:])
+}
+
+// VerifyChain recomputes the company's hash chain from the stored vouchers
+// and reports the first link that does not match: a voucher or line changed
+// after booking, a voucher removed from the chain or vouchers removed from
+// its end.
+func (s *VoucherService) VerifyChain() (*domain.ChainVerification, error) {
+	links, err := s.repository.GetChain()
+	if err != nil {
+		return nil, err
+	}
+	lineItems, err := s.lineItemRepository.GetChainedLineItems()
+	if err != nil {
+		return nil, err
+	}
+	head, err := s.repository.GetChainHead()
+	if err != nil {
+		return nil, err
+	}
+	unchained, err := s.repository.CountUnchainedVouchers()
+	if err != nil {
+		return nil, err
+	}
+
+	lines := make(map[int][]domain.LineItem)
+	for _, item := range lineItems {
+		lines[item.VoucherID] = append(lines[item.VoucherID], *item)
+	}
+
+	result := &domain.ChainVerification{
+		Valid:        true,
+		Unchained:    unchained,
+		HeadPosition: head.Position,
+		HeadHash:     head.Hash,
+	}
+
+	prevHash := ""
+	for i, link := range links {
+		voucher := &link.Voucher
+		position := i + 1
+		expected := voucherHash(prevHash, position, voucher, lines[voucher.VoucherID])
+		stored := ""
+		if voucher.Hash != nil {
+			stored = *voucher.Hash
+		}
+
+		var reason string
+		switch {
+		case *voucher.ChainPosition != position:
+			reason = fmt.Sprintf("expected position %d, the voucher before it is missing", position)
+		case link.PrevHash != prevHash:
+			reason = "previous hash does not match the voucher before it"
+		case stored != expected:
+			reason = "voucher or its lines changed after booking"
+		}
+		if reason != "" {
+			result.Valid = false
+			result.BrokenLink = &domain.ChainBreak{
+				Position:      *voucher.ChainPosition,
+				VoucherID:     voucher.VoucherID,
+				Series:        voucher.Series,
+				VoucherNumber: voucher.VoucherNumber,
+				Reason:        reason,
+				ExpectedHash:  expected,
+				StoredHash:    stored,
+			}
+			return result, nil
+		}
+
+		result.VouchersChecked++
+		prevHash = stored
+	}
+
+	if head.Position != len(links) || head.Hash != prevHash {
+		reason := "chain head does not match the last voucher"
+		if head.Position > len(links) {
+			reason = fmt.Sprintf("chain head is at position %d but the chain ends at %d, vouchers at the end are missing", head.Position, len(links))
+		}
+		result.Valid = false
+		result.BrokenLink = &domain.ChainBreak{
+			Position:     head.Position,
+			Reason:       reason,
+			ExpectedHash: prevHash,
+			StoredHash:   head.Hash,
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"cmd/api/internal/domain"
+	"cmd/api/internal/repository"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+type VoucherSeriesService struct {
+	repository      repository.VoucherSeriesRepository
+	auditRepository repository.AuditRepository
+	txManager       repository.TxManager
+	validate        *validator.Validate
+	actorID         int
+}
+
+func NewVoucherSeriesService(
+	repo repository.VoucherSeriesRepository,
+	auditRepo repository.AuditRepository,
+	txManager repository.TxManager,
+) *VoucherSeriesService {
+	return &VoucherSeriesService{
+		repository:      repo,
+		auditRepository: auditRepo,
+		txManager:       txManager,
+		validate:        validator.New(),
+	}
+}
+
+// ForCompany returns a copy of the service that works on the books of companyID
+func (s *VoucherSeriesService) ForCompany(companyID int) *VoucherSeriesService {
+	scoped := *s
+	scoped.repository = s.repository.ForCompany(companyID)
+	scoped.auditRepository = s.auditRepository.ForCompany(companyID)
+	return &scoped
+}
+
+// AsUser returns a copy of the service that records its changes in the
+// audit trail as made by userID
+func (s *VoucherSeriesService) AsUser(userID int) *VoucherSeriesService {
+	scoped := *s
+	scoped.actorID = userID
+	return &scoped
+}
+
+// CreateSeries creates a new voucher series. Codes are stored in upper case.
+func (s *VoucherSeriesService) CreateSeries(series *domain.VoucherSeries) error {
+	series.Series = strings.ToUpper(strings.TrimSpace(series.Series))
+	if err := s.validate.Struct(series); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+
+	if existing, err := s.repository.GetSeries(series.Series); err == nil && existing != nil {
+		return errors.New("voucher series with this code already exists")
+	}
+
+	return s.txManager.WithTransaction(func(tx *sql.Tx) error {
+		if err := s.repository.WithTx(tx).CreateSeries(series); err != nil {
+			return fmt.Errorf("failed to create voucher series: %w", err)
+		}
+
+		return recordAudit(s.auditRepository.WithTx(tx), s.actorID, domain.AuditEntityVoucherSeries, series.Series, nil, series)
+	})
+}
+
+// GetAllSeries retrieves all voucher series
+func (s *VoucherSeriesService) GetAllSeries() ([]*domain.VoucherSeries, error) {
+	allSeries, err := s.repository.GetAllSeries()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get voucher series: %w", err)
+	}
+
+	return allSeries, nil
+}
+
+// UpdateSeries changes the name of a voucher series
+func (s *VoucherSeriesService) UpdateSeries(series *domain.VoucherSeries) error {
+	series.Series = strings.ToUpper(strings.TrimSpace(series.Series))
+	if err := s.validate.Struct(series); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+
+	existing, err := s.repository.GetSeries(series.Series)
+	if err != nil {
+		return err
+	}
+
+	return s.txManager.WithTransaction(func(tx *sql.Tx) error {
+		if err := s.repository.WithTx(tx).UpdateSeries(series); err != nil {
+			return fmt.Errorf("failed to update voucher series: %w", err)
+		}
+
+		return recordAudit(s.auditRepository.WithTx(tx), s.actorID, domain.AuditEntityVoucherSeries, series.Series, existing, series)
+	})
+}
+
+// DeleteSeries deletes a voucher series that has no vouchers. The manual
+// series is always kept since vouchers without a series are booked there.
+func (s *VoucherSeriesService) DeleteSeries(series string) error {
+	series = strings.ToUpper(strings.TrimSpace(series))
+	if series == domain.ManualVoucherSeries {
+		return errors.New("the manual voucher series cannot be deleted")
+	}
+
+	existing, err := s.repository.GetSeries(series)
+	if err != nil {
+		return err
+	}
+
+	return s.txManager.WithTransaction(func(tx *sql.Tx) error {
+		if err := s.repository.WithTx(tx).DeleteSeries(series); err != nil {
+			return fmt.Errorf("failed to delete voucher series: %w", err)
+		}
+
+		return recordAudit(s.auditRepository.WithTx(tx), s.actorID, domain.AuditEntityVoucherSeries, series, existing, nil)
+	})
+}
@@ -3,29 +3,102 @@ package service
 import (
 	"cmd/api/internal/domain"
 	"cmd/api/internal/repository"
+	"database/sql"
 	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-playground/validator/v10"
 )
 
+// VAT modes for ExpandVATLines: the amount entered on a line is either
+// gross (including VAT) or net (excluding VAT)
+const (
+	VATModeGross = "gross"
+	VATModeNet   = "net"
+)
+
+// Accounts the generated VAT lines are booked on
+var (
+	outputVATAccounts = map[int]int{25: 2610, 12: 2620, 6: 2630} // Utgående moms per rate
+	inputVATAccount   = 2640                                     // Ingående moms
+)
+
 type VoucherService struct {
-	repository         repository.VoucherRepository
-	lineItemRepository repository.LineItemRepository
-	validate           *validator.Validate
+	repository           repository.VoucherRepository
+	lineItemRepository   repository.LineItemRepository
+	accountRepository    repository.AccountRepository
+	periodRepository     repository.PeriodRepository
+	projectRepository    repository.ProjectRepository
+	costCenterRepository repository.CostCenterRepository
+	seriesRepository     repository.VoucherSeriesRepository
+	auditRepository      repository.AuditRepository
+	txManager            repository.TxManager
+	validate             *validator.Validate
+	actorID              int
 }
 
-func NewVoucherService(repo repository.VoucherRepository, lineItemRepo repository.LineItemRepository) *VoucherService {
+func NewVoucherService(
+	repo repository.VoucherRepository,
+	lineItemRepo repository.LineItemRepository,
+	accountRepo repository.AccountRepository,
+	periodRepo repository.PeriodRepository,
+	projectRepo repository.ProjectRepository,
+	costCenterRepo repository.CostCenterRepository,
+	seriesRepo repository.VoucherSeriesRepository,
+	auditRepo repository.AuditRepository,
+	txManager repository.TxManager,
+) *VoucherService {
 	return &VoucherService{
-		repository:         repo,
-		lineItemRepository: lineItemRepo,
-		validate:           validator.New(),
+		repository:           repo,
+		lineItemRepository:   lineItemRepo,
+		accountRepository:    accountRepo,
+		periodRepository:     periodRepo,
+		projectRepository:    projectRepo,
+		costCenterRepository: costCenterRepo,
+		seriesRepository:     seriesRepo,
+		auditRepository:      auditRepo,
+		txManager:            txManager,
+		validate:             validator.New(),
 	}
 }
 
-// CreateVoucher creates a new voucher
+// ForCompany returns a copy of the service that works on the books of companyID
+func (s *VoucherService) ForCompany(companyID int) *VoucherService {
+	scoped := *s
+	scoped.repository = s.repository.ForCompany(companyID)
+	scoped.lineItemRepository = s.lineItemRepository.ForCompany(companyID)
+	scoped.accountRepository = s.accountRepository.ForCompany(companyID)
+	scoped.periodRepository = s.periodRepository.ForCompany(companyID)
+	scoped.projectRepository = s.projectRepository.ForCompany(companyID)
+	scoped.costCenterRepository = s.costCenterRepository.ForCompany(companyID)
+	scoped.seriesRepository = s.seriesRepository.ForCompany(companyID)
+	scoped.auditRepository = s.auditRepository.ForCompany(companyID)
+	return &scoped
+}
+
+// AsUser returns a copy of the service that records its changes in the
+// audit trail as made by userID
+func (s *VoucherService) AsUser(userID int) *VoucherService {
+	scoped := *s
+	scoped.actorID = userID
+	return &scoped
+}
+
+// CreateVoucher creates a new voucher together with its line items.
+// The header and all lines are written in a single transaction, so a
+// failure on any line leaves nothing behind. The voucher is a draft unless
+// its Status is booked, in which case it is booked straight away.
 func (s *VoucherService) CreateVoucher(voucher *domain.Voucher) error {
+	return s.createVoucher(voucher, nil)
+}
+
+// createVoucher is CreateVoucher with a hook that runs in the transaction
+// once the voucher exists, for callers that write rows referring to it.
+// If the hook fails, the voucher is not created either.
+func (s *VoucherService) createVoucher(voucher *domain.Voucher, inTx func(tx *sql.Tx) error) error {
 	// Validate input
 	if err := s.validate.Struct(voucher); err != nil {
 		return fmt.Errorf("validation failed: %w", err)
@@ -36,18 +109,84 @@ func (s *VoucherService) CreateVoucher(voucher *domain.Voucher) error {
 		return errors.New("description is required")
 	}
 
-	// Validate period format (e.g., "2025-01")
-	if len(voucher.Period) != 7 {
-		return errors.New("period must be in format YYYY-MM (e.g., '2025-01')")
+	if err := resolveVoucherPeriod(voucher); err != nil {
+		return err
 	}
 
-	// Validate created by
+	// The creator is the user making the request
+	if s.actorID > 0 {
+		voucher.CreatedBy = s.actorID
+	}
 	if voucher.CreatedBy <= 0 {
 		return errors.New("invalid user ID")
 	}
 
-	// Create voucher
-	err := s.repository.CreateVoucher(voucher)
+	if voucher.Status == "" {
+		voucher.Status = domain.VoucherDraft
+	}
+	book := voucher.Status == domain.VoucherBooked
+	if !book && voucher.Status != domain.VoucherDraft {
+		return fmt.Errorf("status must be '%s' or '%s'", domain.VoucherDraft, domain.VoucherBooked)
+	}
+
+	// Validate line items and compute the total from them. Only booked
+	// vouchers have to balance.
+	validateLines := validateDraftLines
+	if book {
+		validateLines = validateVoucherLines
+	}
+	total, err := validateLines(voucher.Lines)
+	if err != nil {
+		return err
+	}
+	voucher.TotalAmount = total
+
+	if err := validateLineDimensions(s.projectRepository, s.costCenterRepository, voucher.Lines, voucher.Date.Time); err != nil {
+		return err
+	}
+
+	if err := resolveVoucherSeries(s.seriesRepository, voucher); err != nil {
+		return err
+	}
+
+	// Create voucher and lines in one transaction; a booked voucher is
+	// numbered and sealed in the hash chain in the same transaction
+	err = s.txManager.WithTransaction(func(tx *sql.Tx) error {
+		voucherRepo := s.repository.WithTx(tx)
+		if book {
+			if err := lockPeriodOpen(s.periodRepository.WithTx(tx), voucher.Period); err != nil {
+				return err
+			}
+			if err := voucherRepo.CreateVoucher(voucher); err != nil {
+				return err
+			}
+		} else if err := voucherRepo.CreateDraftVoucher(voucher); err != nil {
+			return err
+		}
+
+		lineItemRepo := s.lineItemRepository.WithTx(tx)
+		for i := range voucher.Lines {
+			voucher.Lines[i].VoucherID = voucher.VoucherID
+			if err := lineItemRepo.CreateLineItem(&voucher.Lines[i]); err != nil {
+				return fmt.Errorf("line %d: %w", i+1, err)
+			}
+		}
+
+		if book {
+			if err := sealVoucher(voucherRepo, voucher); err != nil {
+				return err
+			}
+		}
+
+		if err := recordAudit(s.auditRepository.WithTx(tx), s.actorID, domain.AuditEntityVoucher, voucher.VoucherID, nil, voucher); err != nil {
+			return err
+		}
+
+		if inTx != nil {
+			return inTx(tx)
+		}
+		return nil
+	})
 	if err != nil {
 		return fmt.Errorf("failed to create voucher: %w", err)
 	}
@@ -55,6 +194,43 @@ func (s *VoucherService) CreateVoucher(voucher *domain.Voucher) error {
 	return nil
 }
 
+// resolveVoucherSeries puts vouchers without a series in the manual series
+// and checks that the series exists
+func resolveVoucherSeries(seriesRepo repository.VoucherSeriesRepository, voucher *domain.Voucher) error {
+	voucher.Series = strings.ToUpper(strings.TrimSpace(voucher.Series))
+	if voucher.Series == "" {
+		voucher.Series = domain.ManualVoucherSeries
+	}
+
+	if _, err := seriesRepo.GetSeries(voucher.Series); err != nil {
+		return fmt.Errorf("voucher series %s: %w", voucher.Series, err)
+	}
+
+	return nil
+}
+
+// resolveVoucherPeriod sets the period of a voucher to the month of its
+// date, the only period it can be booked in. A period sent along must be
+// that month, so a voucher cannot be booked past a locked period.
+// The date is first cut to the calendar day it was given in, the day the
+// date column stores, so the period and the hash agree with what is read
+// back whatever offset a timestamp was sent with.
+func resolveVoucherPeriod(voucher *domain.Voucher) error {
+	if voucher.Date.IsZero() {
+		return errors.New("date is required")
+	}
+	year, month, day := voucher.Date.Date()
+	voucher.Date.Time = time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+
+	period := voucher.Date.Format("2006-01")
+	if voucher.Period != "" && voucher.Period != period {
+		return fmt.Errorf("period %s does not match the voucher date %s", voucher.Period, voucher.Date.Format("2006-01-02"))
+	}
+	voucher.Period = period
+
+	return nil
+}
+
 // GetVoucherByID retrieves a voucher by ID, including its line items
 func (s *VoucherService) GetVoucherByID(voucherID int) (*domain.Voucher, error) {
 	if voucherID <= 0 {
@@ -81,14 +257,58 @@ func (s *VoucherService) GetVoucherByID(voucherID int) (*domain.Voucher, error)
 	return voucher, nil
 }
 
-// GetAllVouchers retrieves all vouchers
-func (s *VoucherService) GetAllVouchers() ([]*domain.Voucher, error) {
-	vouchers, err := s.repository.GetAllVouchers()
+// Page sizes of voucher listings
+const (
+	defaultVoucherPageSize = 50
+	maxVoucherPageSize     = 500
+)
+
+// ListVouchers returns one page of the vouchers selected by filter. Without
+// a limit the first defaultVoucherPageSize vouchers are returned.
+func (s *VoucherService) ListVouchers(filter domain.VoucherFilter) (*domain.VoucherPage, error) {
+	if filter.Limit == 0 {
+		filter.Limit = defaultVoucherPageSize
+	}
+	if filter.Limit < 0 || filter.Limit > maxVoucherPageSize {
+		return nil, fmt.Errorf("limit must be between 1 and %d", maxVoucherPageSize)
+	}
+	if filter.Offset < 0 {
+		return nil, errors.New("offset cannot be negative")
+	}
+
+	switch filter.Sort {
+	case "":
+		filter.Sort = domain.VoucherSortNumber
+	case domain.VoucherSortNumber, domain.VoucherSortDate, domain.VoucherSortAmount, domain.VoucherSortCreatedAt:
+	default:
+		return nil, fmt.Errorf("sort must be one of '%s', '%s', '%s' or '%s'",
+			domain.VoucherSortNumber, domain.VoucherSortDate, domain.VoucherSortAmount, domain.VoucherSortCreatedAt)
+	}
+
+	if filter.Status != "" && filter.Status != domain.VoucherDraft && filter.Status != domain.VoucherBooked {
+		return nil, fmt.Errorf("status must be '%s' or '%s'", domain.VoucherDraft, domain.VoucherBooked)
+	}
+	if filter.Period != "" && len(filter.Period) != 7 {
+		return nil, errors.New("period must be in format YYYY-MM (e.g., '2025-01')")
+	}
+	if filter.From != nil && filter.To != nil && filter.To.Before(*filter.From) {
+		return nil, errors.New("to_date cannot be before from_date")
+	}
+	if filter.MinAmount != nil && filter.MaxAmount != nil && *filter.MaxAmount < *filter.MinAmount {
+		return nil, errors.New("max_amount cannot be less than min_amount")
+	}
+
+	vouchers, total, err := s.repository.ListVouchers(filter)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get vouchers: %w", err)
 	}
 
-	return vouchers, nil
+	return &domain.VoucherPage{
+		Vouchers: vouchers,
+		Total:    total,
+		Limit:    filter.Limit,
+		Offset:   filter.Offset,
+	}, nil
 }
 
 // GetVouchersByPeriod retrieves vouchers by period
@@ -129,39 +349,86 @@ func (s *VoucherService) GetAllPeriods() ([]string, error) {
 	return periods, nil
 }
 
-// UpdateVoucher updates an existing voucher
+// UpdateVoucher changes a draft. Only its creator can change it, and booked
+// vouchers are changed with a correction voucher instead.
 func (s *VoucherService) UpdateVoucher(voucher *domain.Voucher) error {
 	// Validate input
 	if err := s.validate.Struct(voucher); err != nil {
 		return fmt.Errorf("validation failed: %w", err)
 	}
 
-	// Check if voucher exists
-	existingVoucher, err := s.repository.GetVoucherByID(voucher.VoucherID)
-	if err != nil {
-		return fmt.Errorf("voucher not found: %w", err)
-	}
-	if existingVoucher == nil {
-		return errors.New("voucher not found")
-	}
-
 	// Validate description
 	if voucher.Description == "" {
 		return errors.New("description is required")
 	}
 
-	// Validate period format
-	if len(voucher.Period) != 7 {
-		return errors.New("period must be in format YYYY-MM (e.g., '2025-01')")
+	if err := resolveVoucherPeriod(voucher); err != nil {
+		return err
 	}
 
-	// Validate created by
-	if voucher.CreatedBy <= 0 {
-		return errors.New("invalid user ID")
+	if err := resolveVoucherSeries(s.seriesRepository, voucher); err != nil {
+		return err
 	}
 
-	// Update voucher
-	err = s.repository.UpdateVoucher(voucher)
+	// Update voucher (and its lines) in one transaction
+	err := s.txManager.WithTransaction(func(tx *sql.Tx) error {
+		voucherRepo := s.repository.WithTx(tx)
+		lineItemRepo := s.lineItemRepository.WithTx(tx)
+
+		// Lock the draft so it cannot be booked while it is changed
+		existingVoucher, err := voucherRepo.GetVoucherByIDForUpdate(voucher.VoucherID)
+		if err != nil {
+			return fmt.Errorf("voucher not found: %w", err)
+		}
+		if err := ensureDraftOf(existingVoucher, s.actorID); err != nil {
+			return err
+		}
+
+		before, err := voucherWithLines(lineItemRepo, existingVoucher)
+		if err != nil {
+			return err
+		}
+
+		// Lines in the request replace the existing ones; without lines the
+		// stored lines are kept
+		replaceLines := len(voucher.Lines) > 0
+		if !replaceLines {
+			voucher.Lines = append([]domain.LineItem(nil), before.Lines...)
+		}
+
+		total, err := validateDraftLines(voucher.Lines)
+		if err != nil {
+			return err
+		}
+		voucher.TotalAmount = total
+
+		if err := validateLineDimensions(s.projectRepository, s.costCenterRepository, voucher.Lines, voucher.Date.Time); err != nil {
+			return err
+		}
+
+		// It stays an unnumbered draft of the same creator
+		voucher.Status = domain.VoucherDraft
+		voucher.VoucherNumber = 0
+		voucher.CreatedBy = existingVoucher.CreatedBy
+
+		if err := voucherRepo.UpdateVoucher(voucher); err != nil {
+			return err
+		}
+
+		if replaceLines {
+			if err := lineItemRepo.DeleteLineItemsByVoucherID(voucher.VoucherID); err != nil {
+				return err
+			}
+			for i := range voucher.Lines {
+				voucher.Lines[i].VoucherID = voucher.VoucherID
+				if err := lineItemRepo.CreateLineItem(&voucher.Lines[i]); err != nil {
+					return fmt.Errorf("line %d: %w", i+1, err)
+				}
+			}
+		}
+
+		return recordAudit(s.auditRepository.WithTx(tx), s.actorID, domain.AuditEntityVoucher, voucher.VoucherID, before, voucher)
+	})
 	if err != nil {
 		return fmt.Errorf("failed to update voucher: %w", err)
 	}
@@ -169,34 +436,138 @@ func (s *VoucherService) UpdateVoucher(voucher *domain.Voucher) error {
 	return nil
 }
 
-// DeleteVoucher deletes a voucher by ID (also deletes associated line items)
+// DeleteVoucher deletes a draft by ID (also deletes associated line items).
+// Only its creator can delete it.
 func (s *VoucherService) DeleteVoucher(voucherID int) error {
 	if voucherID <= 0 {
 		return errors.New("invalid voucher ID")
 	}
 
-	// Check if voucher exists
-	existingVoucher, err := s.repository.GetVoucherByID(voucherID)
+	return s.txManager.WithTransaction(func(tx *sql.Tx) error {
+		voucherRepo := s.repository.WithTx(tx)
+		lineItemRepo := s.lineItemRepository.WithTx(tx)
+
+		// Lock the draft so it cannot be booked while it is deleted
+		existingVoucher, err := voucherRepo.GetVoucherByIDForUpdate(voucherID)
+		if err != nil {
+			return fmt.Errorf("voucher not found: %w", err)
+		}
+		if err := ensureDraftOf(existingVoucher, s.actorID); err != nil {
+			return err
+		}
+
+		before, err := voucherWithLines(lineItemRepo, existingVoucher)
+		if err != nil {
+			return err
+		}
+
+		// Delete associated line items first
+		if err := lineItemRepo.DeleteLineItemsByVoucherID(voucherID); err != nil {
+			return fmt.Errorf("failed to delete line items: %w", err)
+		}
+
+		if err := voucherRepo.DeleteVoucher(voucherID); err != nil {
+			return fmt.Errorf("failed to delete voucher: %w", err)
+		}
+
+		return recordAudit(s.auditRepository.WithTx(tx), s.actorID, domain.AuditEntityVoucher, voucherID, before, nil)
+	})
+}
+
+// BookVoucher books a draft: its lines must balance and its period must be
+// open. It gets the next number in its series and is sealed in the hash
+// chain; from then on it can only be changed with a correction voucher.
+func (s *VoucherService) BookVoucher(voucherID int) (*domain.Voucher, error) {
+	if voucherID <= 0 {
+		return nil, errors.New("invalid voucher ID")
+	}
+
+	var voucher *domain.Voucher
+	err := s.txManager.WithTransaction(func(tx *sql.Tx) error {
+		voucherRepo := s.repository.WithTx(tx)
+		lineItemRepo := s.lineItemRepository.WithTx(tx)
+
+		// Lock the draft so it is booked only once
+		draft, err := voucherRepo.GetVoucherByIDForUpdate(voucherID)
+		if err != nil {
+			return err
+		}
+		if draft.Status == domain.VoucherBooked {
+			return ErrVoucherBooked
+		}
+
+		before, err := voucherWithLines(lineItemRepo, draft)
+		if err != nil {
+			return err
+		}
+
+		booked := *before
+		booked.Lines = append([]domain.LineItem(nil), before.Lines...)
+		voucher = &booked
+
+		total, err := validateVoucherLines(voucher.Lines)
+		if err != nil {
+			return err
+		}
+		voucher.TotalAmount = total
+
+		if err := validateLineDimensions(s.projectRepository, s.costCenterRepository, voucher.Lines, voucher.Date.Time); err != nil {
+			return err
+		}
+		if err := resolveVoucherSeries(s.seriesRepository, voucher); err != nil {
+			return err
+		}
+		if err := resolveVoucherPeriod(voucher); err != nil {
+			return err
+		}
+		if err := lockPeriodOpen(s.periodRepository.WithTx(tx), voucher.Period); err != nil {
+			return err
+		}
+
+		if err := voucherRepo.BookVoucher(voucher); err != nil {
+			return err
+		}
+		if err := sealVoucher(voucherRepo, voucher); err != nil {
+			return err
+		}
+
+		return recordAudit(s.auditRepository.WithTx(tx), s.actorID, domain.AuditEntityVoucher, voucherID, before, voucher)
+	})
 	if err != nil {
-		return fmt.Errorf("voucher not found: %w", err)
+		return nil, fmt.Errorf("failed to book voucher: %w", err)
 	}
-	if existingVoucher == nil {
-		return errors.New("voucher not found")
+
+	return voucher, nil
+}
+
+// ensureDraftOf refuses changes to booked vouchers and to drafts of other
+// users than userID
+func ensureDraftOf(voucher *domain.Voucher, userID int) error {
+	if voucher.Status == domain.VoucherBooked {
+		return ErrVoucherBooked
+	}
+	if voucher.CreatedBy != userID {
+		return ErrNotVoucherCreator
 	}
 
-	// Delete associated line items first
-	err = s.lineItemRepository.DeleteLineItemsByVoucherID(voucherID)
+	return nil
+}
+
+// voucherWithLines returns a copy of voucher with its stored lines, as the
+// audit trail records it
+func voucherWithLines(lineItemRepo repository.LineItemRepository, voucher *domain.Voucher) (*domain.Voucher, error) {
+	lineItems, err := lineItemRepo.GetLineItemsByVoucherID(voucher.VoucherID)
 	if err != nil {
-		return fmt.Errorf("failed to delete line items: %w", err)
+		return nil, fmt.Errorf("failed to get line items: %w", err)
 	}
 
-	// Delete voucher
-	err = s.repository.DeleteVoucher(voucherID)
-	if err != nil {
-		return fmt.Errorf("failed to delete voucher: %w", err)
+	withLines := *voucher
+	withLines.Lines = make([]domain.LineItem, len(lineItems))
+	for i, item := range lineItems {
+		withLines.Lines[i] = *item
 	}
 
-	return nil
+	return &withLines, nil
 }
 
 // ValidateVoucherBalance validates that debit and credit balance for a voucher
@@ -206,88 +577,64 @@ func (s *VoucherService) ValidateVoucherBalance(voucherID int) (bool, error) {
 		return false, fmt.Errorf("failed to get line items: %w", err)
 	}
 
-	var totalDebit, totalCredit float64
-	for _, item := range lineItems {
-		totalDebit += item.DebitAmount
-		totalCredit += item.CreditAmount
+	lines := make([]domain.LineItem, len(lineItems))
+	for i, item := range lineItems {
+		lines[i] = *item
 	}
 
-	// Check if debit equals credit (with small tolerance for floating point)
-	balanced := (totalDebit - totalCredit) < 0.01 && (totalDebit - totalCredit) > -0.01
-
-	return balanced, nil
+	return checkBalance(lines) == nil, nil
 }
 
-// CreateCorrectionVoucher creates a correction voucher that reverses the original voucher
-func (s *VoucherService) CreateCorrectionVoucher(originalVoucherID int, userID int) (*domain.Voucher, error) {
+// CreateCorrectionVoucher creates a correction voucher that reverses the
+// original voucher. It is dated date, or today when date is empty, and
+// booked in the period of that date, so vouchers in a locked period are
+// reversed in an open one. The correction is made by the acting user.
+func (s *VoucherService) CreateCorrectionVoucher(originalVoucherID int, date string) (*domain.Voucher, error) {
 	if originalVoucherID <= 0 {
 		return nil, errors.New("invalid voucher ID")
 	}
-	if userID <= 0 {
+	if s.actorID <= 0 {
 		return nil, errors.New("invalid user ID")
 	}
 
-	// Get the original voucher
-	originalVoucher, err := s.repository.GetVoucherByID(originalVoucherID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get original voucher: %w", err)
-	}
-
-	// Check if voucher is already corrected
-	if originalVoucher.CorrectedByVoucherID != nil {
-		return nil, errors.New("voucher has already been corrected")
-	}
-
-	// Get original line items
-	originalLineItems, err := s.lineItemRepository.GetLineItemsByVoucherID(originalVoucherID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get original line items: %w", err)
-	}
-
-	// Create correction voucher with reversed amounts
-	correctionVoucher := &domain.Voucher{
-		Date:        domain.FlexibleDate{Time: originalVoucher.Date.Time},
-		Description: fmt.Sprintf("Rättelse av verifikat #%d: %s", originalVoucher.VoucherNumber, originalVoucher.Description),
-		Reference:   originalVoucher.Reference,
-		TotalAmount: originalVoucher.TotalAmount,
-		Period:      originalVoucher.Period,
-		CreatedBy:   userID,
-	}
-
-	// Create the correction voucher in database
-	err = s.repository.CreateCorrectionVoucher(correctionVoucher, originalVoucherID)
+	parsedDate, err := correctionDate(date)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create correction voucher: %w", err)
-	}
-
-	// Create reversed line items (swap debit and credit)
-	for _, item := range originalLineItems {
-		reversedItem := &domain.LineItem{
-			VoucherID:    correctionVoucher.VoucherID,
-			AccountNo:    item.AccountNo,
-			DebitAmount:  item.CreditAmount,  // Swap: original credit becomes debit
-			CreditAmount: item.DebitAmount,   // Swap: original debit becomes credit
-			TaxCode:      item.TaxCode,
-		}
-		err = s.lineItemRepository.CreateLineItem(reversedItem)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create correction line item: %w", err)
+		return nil, err
+	}
+
+	return s.createCorrection(originalVoucherID, func(original *domain.Voucher, originalLines []*domain.LineItem) (*domain.Voucher, error) {
+		// Create correction voucher with reversed amounts
+		correctionVoucher := &domain.Voucher{
+			Series:      original.Series,
+			Date:        domain.FlexibleDate{Time: parsedDate},
+			Description: fmt.Sprintf("Rättelse av verifikat %s%d: %s", original.Series, original.VoucherNumber, original.Description),
+			Reference:   original.Reference,
+			TotalAmount: original.TotalAmount,
+			Period:      parsedDate.Format("2006-01"),
+			CreatedBy:   s.actorID,
 		}
-	}
 
-	// Mark the original voucher as corrected
-	err = s.repository.MarkVoucherAsCorrected(originalVoucherID, correctionVoucher.VoucherID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to mark original voucher as corrected: %w", err)
-	}
+		// Reverse the line items (swap debit and credit)
+		for _, item := range originalLines {
+			correctionVoucher.Lines = append(correctionVoucher.Lines, domain.LineItem{
+				AccountNo:    item.AccountNo,
+				DebitAmount:  item.CreditAmount, // Swap: original credit becomes debit
+				CreditAmount: item.DebitAmount,  // Swap: original debit becomes credit
+				TaxCode:      item.TaxCode,
+				ProjectID:    item.ProjectID,
+				CostCenterID: item.CostCenterID,
+			})
+		}
 
-	return correctionVoucher, nil
+		return correctionVoucher, nil
+	})
 }
 
-// CreateCorrectionWithChanges creates ONE new corrected voucher and marks the original as corrected
+// CreateCorrectionWithChanges creates ONE new corrected voucher and marks the original as corrected.
+// Like CreateCorrectionVoucher it is dated newDate or today and booked in
+// the period of that date; newPeriod, when given, must be that period.
 func (s *VoucherService) CreateCorrectionWithChanges(
 	originalVoucherID int,
-	userID int,
 	newDate string,
 	newDescription string,
 	newReference string,
@@ -297,72 +644,296 @@ func (s *VoucherService) CreateCorrectionWithChanges(
 	if originalVoucherID <= 0 {
 		return nil, errors.New("invalid voucher ID")
 	}
-	if userID <= 0 {
+	if s.actorID <= 0 {
 		return nil, errors.New("invalid user ID")
 	}
 
-	// Get the original voucher
-	originalVoucher, err := s.repository.GetVoucherByID(originalVoucherID)
+	// Validate the new line items and calculate the total from them
+	newTotal, err := validateVoucherLines(newLineItems)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get original voucher: %w", err)
+		return nil, err
 	}
 
-	// Check if voucher is already corrected
-	if originalVoucher.CorrectedByVoucherID != nil {
-		return nil, errors.New("voucher has already been corrected")
+	parsedDate, err := correctionDate(newDate)
+	if err != nil {
+		return nil, err
 	}
+	if newPeriod != "" && newPeriod != parsedDate.Format("2006-01") {
+		return nil, fmt.Errorf("period %s does not match the date %s", newPeriod, parsedDate.Format("2006-01-02"))
+	}
+
+	if err := validateLineDimensions(s.projectRepository, s.costCenterRepository, newLineItems, parsedDate); err != nil {
+		return nil, err
+	}
+
+	return s.createCorrection(originalVoucherID, func(original *domain.Voucher, originalLines []*domain.LineItem) (*domain.Voucher, error) {
+		// Create NEW CORRECTED voucher (with updated values)
+		newVoucher := &domain.Voucher{
+			Series:      original.Series,
+			Date:        domain.FlexibleDate{Time: parsedDate},
+			Description: newDescription,
+			Reference:   newReference,
+			TotalAmount: newTotal,
+			Period:      parsedDate.Format("2006-01"),
+			CreatedBy:   s.actorID,
+		}
 
-	// Calculate total from new line items
-	var newTotal float64
-	for _, item := range newLineItems {
-		if item.DebitAmount > 0 {
-			newTotal += item.DebitAmount
+		// The new line items hold the corrected values
+		for _, item := range newLineItems {
+			newVoucher.Lines = append(newVoucher.Lines, domain.LineItem{
+				AccountNo:    item.AccountNo,
+				DebitAmount:  item.DebitAmount,
+				CreditAmount: item.CreditAmount,
+				TaxCode:      item.TaxCode,
+				ProjectID:    item.ProjectID,
+				CostCenterID: item.CostCenterID,
+			})
 		}
+
+		return newVoucher, nil
+	})
+}
+
+// correctionDate parses the date of a correction voucher, which is today
+// when none is given
+func correctionDate(date string) (time.Time, error) {
+	if date == "" {
+		now := time.Now()
+		return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC), nil
 	}
 
-	// Parse new date
-	parsedDate, err := time.Parse("2006-01-02", newDate)
+	parsed, err := time.Parse("2006-01-02", date)
 	if err != nil {
-		return nil, fmt.Errorf("invalid date format: %w", err)
+		return time.Time{}, fmt.Errorf("invalid date format: %w", err)
 	}
 
-	// Create NEW CORRECTED voucher (with updated values)
-	newVoucher := &domain.Voucher{
-		Date:        domain.FlexibleDate{Time: parsedDate},
-		Description: newDescription,
-		Reference:   newReference,
-		TotalAmount: newTotal,
-		Period:      newPeriod,
-		CreatedBy:   userID,
-	}
+	return parsed, nil
+}
+
+// createCorrection runs a whole correction in one transaction: it locks the
+// original voucher row, refuses with ErrVoucherAlreadyCorrected if another
+// correction got there first, stores the voucher returned by build together
+// with its lines and links the original to it.
+func (s *VoucherService) createCorrection(
+	originalVoucherID int,
+	build func(original *domain.Voucher, originalLines []*domain.LineItem) (*domain.Voucher, error),
+) (*domain.Voucher, error) {
+	var correction *domain.Voucher
 
-	// Create the new voucher in database using CreateCorrectionVoucher to link it
-	err = s.repository.CreateCorrectionVoucher(newVoucher, originalVoucherID)
+	err := s.txManager.WithTransaction(func(tx *sql.Tx) error {
+		voucherRepo := s.repository.WithTx(tx)
+		lineItemRepo := s.lineItemRepository.WithTx(tx)
+
+		// Lock the original so concurrent corrections are serialized
+		original, err := voucherRepo.GetVoucherByIDForUpdate(originalVoucherID)
+		if err != nil {
+			return fmt.Errorf("failed to get original voucher: %w", err)
+		}
+		if original.Status != domain.VoucherBooked {
+			return ErrVoucherNotBooked
+		}
+		if original.CorrectedByVoucherID != nil {
+			return ErrVoucherAlreadyCorrected
+		}
+
+		originalLines, err := lineItemRepo.GetLineItemsByVoucherID(originalVoucherID)
+		if err != nil {
+			return fmt.Errorf("failed to get original line items: %w", err)
+		}
+
+		correction, err = build(original, originalLines)
+		if err != nil {
+			return err
+		}
+
+		// The original may sit in a locked period, but the correction
+		// itself must be dated in an open one
+		if err := lockPeriodOpen(s.periodRepository.WithTx(tx), correction.Period); err != nil {
+			return err
+		}
+
+		if err := voucherRepo.CreateCorrectionVoucher(correction, originalVoucherID); err != nil {
+			return err
+		}
+
+		for i := range correction.Lines {
+			correction.Lines[i].VoucherID = correction.VoucherID
+			if err := lineItemRepo.CreateLineItem(&correction.Lines[i]); err != nil {
+				return fmt.Errorf("failed to create correction line item: %w", err)
+			}
+		}
+
+		if err := sealVoucher(voucherRepo, correction); err != nil {
+			return err
+		}
+
+		if err := voucherRepo.MarkVoucherAsCorrected(originalVoucherID, correction.VoucherID); err != nil {
+			return fmt.Errorf("failed to mark original voucher as corrected: %w", err)
+		}
+
+		auditRepo := s.auditRepository.WithTx(tx)
+		if err := recordAudit(auditRepo, s.actorID, domain.AuditEntityVoucher, correction.VoucherID, nil, correction); err != nil {
+			return err
+		}
+		corrected := *original
+		corrected.CorrectedByVoucherID = &correction.VoucherID
+		return recordAudit(auditRepo, s.actorID, domain.AuditEntityVoucher, originalVoucherID, original, &corrected)
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to create new corrected voucher: %w", err)
+		return nil, err
 	}
 
-	// Create new line items (the corrected values)
-	for _, item := range newLineItems {
-		newItem := &domain.LineItem{
-			VoucherID:    newVoucher.VoucherID,
-			AccountNo:    item.AccountNo,
-			DebitAmount:  item.DebitAmount,
-			CreditAmount: item.CreditAmount,
-			TaxCode:      item.TaxCode,
+	return correction, nil
+}
+
+// ExpandVATLines adds a VAT line after every income or expense line that
+// has VAT. The rate is the line's TaxCode, or the account's TaxStandard when
+// the line has none. Lines on sales accounts (class 3) get output VAT on
+// 2610/2620/2630 and purchases and expenses input VAT on 2640; the VAT line
+// is on the same side as the line it belongs to, so discounts and credit
+// notes reduce the VAT.
+//
+// With VATModeGross the line amount includes VAT and is reduced to the net
+// amount. With VATModeNet the VAT is added on top and the counter line
+// (bank, supplier, customer) must already hold the gross amount.
+func (s *VoucherService) ExpandVATLines(lines []domain.LineItem, mode string) ([]domain.LineItem, error) {
+	if mode != VATModeGross && mode != VATModeNet {
+		return nil, fmt.Errorf("VAT mode must be '%s' or '%s'", VATModeGross, VATModeNet)
+	}
+
+	expanded := make([]domain.LineItem, 0, len(lines)*2)
+	for i, line := range lines {
+		if err := validateLineItemFields(&line); err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
 		}
-		err = s.lineItemRepository.CreateLineItem(newItem)
+
+		account, err := s.accountRepository.GetAccountByNo(line.AccountNo)
 		if err != nil {
-			return nil, fmt.Errorf("failed to create new line item: %w", err)
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+
+		rate := line.TaxCode
+		if rate == 0 {
+			rate = parseTaxStandard(account.TaxStandard)
+		}
+		// Balance sheet lines (bank, customers, suppliers, VAT) carry no VAT
+		if account.Type != "P&L" || rate == 0 {
+			expanded = append(expanded, line)
+			continue
+		}
+
+		outputAccount, ok := outputVATAccounts[rate]
+		if !ok {
+			return nil, fmt.Errorf("line %d: unsupported VAT rate %d%%", i+1, rate)
+		}
+		vatAccount := inputVATAccount
+		if account.AccountGroup == 3 {
+			vatAccount = outputAccount
+		}
+
+		base := line.DebitAmount
+		if base.IsZero() {
+			base = line.CreditAmount
+		}
+		net, vat := splitVAT(base, rate, mode)
+
+		line.TaxCode = rate
+		vatLine := domain.LineItem{AccountNo: vatAccount, TaxCode: rate}
+		if line.DebitAmount.IsPositive() {
+			line.DebitAmount = net
+			vatLine.DebitAmount = vat
+		} else {
+			line.CreditAmount = net
+			vatLine.CreditAmount = vat
+		}
+
+		expanded = append(expanded, line)
+		if vat.IsPositive() {
+			expanded = append(expanded, vatLine)
 		}
 	}
 
-	// Mark the original voucher as corrected (link to new voucher)
-	err = s.repository.MarkVoucherAsCorrected(originalVoucherID, newVoucher.VoucherID)
+	return expanded, nil
+}
+
+// splitVAT returns the net amount and the VAT for an amount entered in mode,
+// rounded to whole öre
+func splitVAT(amount domain.Amount, rate int, mode string) (domain.Amount, domain.Amount) {
+	ore := amount.Ore()
+	if mode == VATModeGross {
+		divisor := int64(100 + rate)
+		net := domain.Amount((ore*100*2 + divisor) / (divisor * 2))
+		return net, amount.Sub(net)
+	}
+
+	vat := domain.Amount((ore*int64(rate)*2 + 100) / 200)
+	return amount, vat
+}
+
+// parseTaxStandard reads an account's tax standard such as "25%"; anything
+// else counts as no VAT
+func parseTaxStandard(taxStandard string) int {
+	rate, err := strconv.Atoi(strings.TrimSuffix(strings.TrimSpace(taxStandard), "%"))
 	if err != nil {
-		return nil, fmt.Errorf("failed to mark original voucher as corrected: %w", err)
+		return 0
 	}
+	return rate
+}
 
-	// Return the new corrected voucher (the user will be redirected here)
-	return newVoucher, nil
+// validateVoucherLines checks every line and that the lines balance.
+// It returns the voucher total (sum of debits) computed from the lines.
+func validateVoucherLines(lines []domain.LineItem) (domain.Amount, error) {
+	if len(lines) < 2 {
+		return 0, errors.New("a voucher must have at least two line items")
+	}
+
+	for i := range lines {
+		if err := validateLineItemFields(&lines[i]); err != nil {
+			return 0, fmt.Errorf("line %d: %w", i+1, err)
+		}
+	}
+
+	if err := checkBalance(lines); err != nil {
+		return 0, err
+	}
+
+	var total domain.Amount
+	for _, line := range lines {
+		total = total.Add(line.DebitAmount)
+	}
+
+	return total, nil
+}
+
+// validateDraftLines checks the lines of a draft and returns the total. A
+// draft does not have to balance or be complete until it is booked.
+func validateDraftLines(lines []domain.LineItem) (domain.Amount, error) {
+	var total domain.Amount
+	for i := range lines {
+		if err := validateLineItemFields(&lines[i]); err != nil {
+			return 0, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		total = total.Add(lines[i].DebitAmount)
+	}
+
+	return total, nil
+}
+
+// checkBalance returns a *BalanceError unless debit equals credit
+func checkBalance(lines []domain.LineItem) error {
+	var totalDebit, totalCredit domain.Amount
+	for _, line := range lines {
+		totalDebit = totalDebit.Add(line.DebitAmount)
+		totalCredit = totalCredit.Add(line.CreditAmount)
+	}
+
+	if totalDebit != totalCredit {
+		return &BalanceError{
+			TotalDebit:  totalDebit,
+			TotalCredit: totalCredit,
+			Difference:  totalDebit.Sub(totalCredit),
+		}
+	}
+
+	return nil
 }
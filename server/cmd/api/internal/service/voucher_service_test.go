@@ -0,0 +1,261 @@
+package service
+
+import (
+	"cmd/api/internal/domain"
+	"cmd/api/internal/repository"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// fakeAccounts serves GetAccountByNo from a map; any other method panics
+type fakeAccounts struct {
+	repository.AccountRepository
+	accounts map[int]*domain.Account
+}
+
+func (f fakeAccounts) GetAccountByNo(accountNo int) (*domain.Account, error) {
+	account, ok := f.accounts[accountNo]
+	if !ok {
+		return nil, fmt.Errorf("account not found")
+	}
+	return account, nil
+}
+
+var testAccounts = fakeAccounts{accounts: map[int]*domain.Account{
+	1930: {AccountNo: 1930, AccountGroup: 1, Type: "BS"},
+	2440: {AccountNo: 2440, AccountGroup: 2, Type: "BS"},
+	3001: {AccountNo: 3001, AccountGroup: 3, Type: "P&L", TaxStandard: "25%"},
+	3002: {AccountNo: 3002, AccountGroup: 3, Type: "P&L", TaxStandard: "12%"},
+	4010: {AccountNo: 4010, AccountGroup: 4, Type: "P&L", TaxStandard: "25%"},
+	5010: {AccountNo: 5010, AccountGroup: 5, Type: "P&L"},
+}}
+
+func TestSplitVAT(t *testing.T) {
+	tests := []struct {
+		amount  domain.Amount
+		rate    int
+		mode    string
+		wantNet domain.Amount
+		wantVAT domain.Amount
+	}{
+		{amount: 12500, rate: 25, mode: VATModeGross, wantNet: 10000, wantVAT: 2500},
+		{amount: 10000, rate: 25, mode: VATModeGross, wantNet: 8000, wantVAT: 2000},
+		{amount: 9999, rate: 12, mode: VATModeGross, wantNet: 8928, wantVAT: 1071},
+		{amount: 1000, rate: 6, mode: VATModeGross, wantNet: 943, wantVAT: 57},
+		{amount: 1, rate: 25, mode: VATModeGross, wantNet: 1, wantVAT: 0},
+		{amount: 10000, rate: 25, mode: VATModeNet, wantNet: 10000, wantVAT: 2500},
+		{amount: 999, rate: 12, mode: VATModeNet, wantNet: 999, wantVAT: 120},
+		{amount: 2, rate: 25, mode: VATModeNet, wantNet: 2, wantVAT: 1},
+		{amount: 1, rate: 25, mode: VATModeNet, wantNet: 1, wantVAT: 0},
+	}
+
+	for _, tt := range tests {
+		net, vat := splitVAT(tt.amount, tt.rate, tt.mode)
+		if net != tt.wantNet || vat != tt.wantVAT {
+			t.Errorf("splitVAT(%d, %d, %s) = %d, %d, want %d, %d",
+				tt.amount, tt.rate, tt.mode, net, vat, tt.wantNet, tt.wantVAT)
+		}
+	}
+}
+
+func TestParseTaxStandard(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int
+	}{
+		{in: "25%", want: 25},
+		{in: " 12% ", want: 12},
+		{in: "0%", want: 0},
+		{in: "6", want: 6},
+		{in: "", want: 0},
+		{in: "momsfri", want: 0},
+	}
+
+	for _, tt := range tests {
+		if got := parseTaxStandard(tt.in); got != tt.want {
+			t.Errorf("parseTaxStandard(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestExpandVATLines(t *testing.T) {
+	s := &VoucherService{accountRepository: testAccounts}
+
+	tests := []struct {
+		name  string
+		mode  string
+		lines []domain.LineItem
+		want  []domain.LineItem
+	}{
+		{
+			name: "sale entered gross",
+			mode: VATModeGross,
+			lines: []domain.LineItem{
+				{AccountNo: 1930, DebitAmount: 12500},
+				{AccountNo: 3001, CreditAmount: 12500},
+			},
+			want: []domain.LineItem{
+				{AccountNo: 1930, DebitAmount: 12500},
+				{AccountNo: 3001, CreditAmount: 10000, TaxCode: 25},
+				{AccountNo: 2610, CreditAmount: 2500, TaxCode: 25},
+			},
+		},
+		{
+			name: "credit note on sales account",
+			mode: VATModeGross,
+			lines: []domain.LineItem{
+				{AccountNo: 3001, DebitAmount: 12500},
+				{AccountNo: 1930, CreditAmount: 12500},
+			},
+			want: []domain.LineItem{
+				{AccountNo: 3001, DebitAmount: 10000, TaxCode: 25},
+				{AccountNo: 2610, DebitAmount: 2500, TaxCode: 25},
+				{AccountNo: 1930, CreditAmount: 12500},
+			},
+		},
+		{
+			name: "account tax standard picks the output VAT account",
+			mode: VATModeGross,
+			lines: []domain.LineItem{
+				{AccountNo: 1930, DebitAmount: 11200},
+				{AccountNo: 3002, CreditAmount: 11200},
+			},
+			want: []domain.LineItem{
+				{AccountNo: 1930, DebitAmount: 11200},
+				{AccountNo: 3002, CreditAmount: 10000, TaxCode: 12},
+				{AccountNo: 2620, CreditAmount: 1200, TaxCode: 12},
+			},
+		},
+		{
+			name: "tax code on the line overrides the account",
+			mode: VATModeGross,
+			lines: []domain.LineItem{
+				{AccountNo: 1930, DebitAmount: 10600},
+				{AccountNo: 3001, CreditAmount: 10600, TaxCode: 6},
+			},
+			want: []domain.LineItem{
+				{AccountNo: 1930, DebitAmount: 10600},
+				{AccountNo: 3001, CreditAmount: 10000, TaxCode: 6},
+				{AccountNo: 2630, CreditAmount: 600, TaxCode: 6},
+			},
+		},
+		{
+			name: "purchase entered net",
+			mode: VATModeNet,
+			lines: []domain.LineItem{
+				{AccountNo: 4010, DebitAmount: 10000},
+				{AccountNo: 2440, CreditAmount: 12500},
+			},
+			want: []domain.LineItem{
+				{AccountNo: 4010, DebitAmount: 10000, TaxCode: 25},
+				{AccountNo: 2640, DebitAmount: 2500, TaxCode: 25},
+				{AccountNo: 2440, CreditAmount: 12500},
+			},
+		},
+		{
+			name: "no VAT on accounts without a tax standard",
+			mode: VATModeGross,
+			lines: []domain.LineItem{
+				{AccountNo: 5010, DebitAmount: 800000},
+				{AccountNo: 1930, CreditAmount: 800000},
+			},
+			want: []domain.LineItem{
+				{AccountNo: 5010, DebitAmount: 800000},
+				{AccountNo: 1930, CreditAmount: 800000},
+			},
+		},
+		{
+			name: "VAT that rounds to zero adds no line",
+			mode: VATModeGross,
+			lines: []domain.LineItem{
+				{AccountNo: 1930, DebitAmount: 1},
+				{AccountNo: 3001, CreditAmount: 1},
+			},
+			want: []domain.LineItem{
+				{AccountNo: 1930, DebitAmount: 1},
+				{AccountNo: 3001, CreditAmount: 1, TaxCode: 25},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.ExpandVATLines(tt.lines, tt.mode)
+			if err != nil {
+				t.Fatalf("ExpandVATLines returned error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ExpandVATLines = %+v, want %+v", got, tt.want)
+			}
+			if err := checkBalance(got); err != nil {
+				t.Errorf("expanded lines do not balance: %v", err)
+			}
+		})
+	}
+}
+
+func TestExpandVATLinesErrors(t *testing.T) {
+	s := &VoucherService{accountRepository: testAccounts}
+
+	tests := []struct {
+		name  string
+		mode  string
+		lines []domain.LineItem
+	}{
+		{name: "unknown mode", mode: "inkl", lines: []domain.LineItem{{AccountNo: 3001, CreditAmount: 100}}},
+		{name: "unsupported rate", mode: VATModeGross, lines: []domain.LineItem{{AccountNo: 3001, CreditAmount: 100, TaxCode: 7}}},
+		{name: "unknown account", mode: VATModeGross, lines: []domain.LineItem{{AccountNo: 9999, CreditAmount: 100}}},
+		{name: "empty line", mode: VATModeGross, lines: []domain.LineItem{{AccountNo: 3001}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := s.ExpandVATLines(tt.lines, tt.mode); err == nil {
+				t.Errorf("ExpandVATLines = %+v, want an error", got)
+			}
+		})
+	}
+}
+
+func TestResolveVoucherPeriod(t *testing.T) {
+	stockholm := time.FixedZone("CET", 3600)
+
+	tests := []struct {
+		name       string
+		date       time.Time
+		period     string
+		wantPeriod string
+		wantErr    bool
+	}{
+		{name: "date only", date: time.Date(2025, time.March, 7, 0, 0, 0, 0, time.UTC), wantPeriod: "2025-03"},
+		{name: "matching period", date: time.Date(2025, time.March, 7, 0, 0, 0, 0, time.UTC), period: "2025-03", wantPeriod: "2025-03"},
+		{name: "first of the month east of UTC", date: time.Date(2025, time.April, 1, 0, 30, 0, 0, stockholm), wantPeriod: "2025-04"},
+		{name: "other period", date: time.Date(2025, time.March, 7, 0, 0, 0, 0, time.UTC), period: "2025-04", wantErr: true},
+		{name: "no date", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			voucher := &domain.Voucher{Date: domain.FlexibleDate{Time: tt.date}, Period: tt.period}
+			err := resolveVoucherPeriod(voucher)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("resolveVoucherPeriod = period %s, want an error", voucher.Period)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("resolveVoucherPeriod returned error: %v", err)
+			}
+			if voucher.Period != tt.wantPeriod {
+				t.Errorf("Period = %s, want %s", voucher.Period, tt.wantPeriod)
+			}
+			year, month, day := tt.date.Date()
+			if want := time.Date(year, month, day, 0, 0, 0, 0, time.UTC); !voucher.Date.Time.Equal(want) || voucher.Date.Location() != time.UTC {
+				t.Errorf("Date = %v, want %v", voucher.Date.Time, want)
+			}
+		})
+	}
+}
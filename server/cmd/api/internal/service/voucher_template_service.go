@@ -0,0 +1,343 @@
+package service
+
+import (
+	"cmd/api/internal/domain"
+	"cmd/api/internal/repository"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+type VoucherTemplateService struct {
+	repository        repository.VoucherTemplateRepository
+	accountRepository repository.AccountRepository
+	voucherService    *VoucherService
+	auditRepository   repository.AuditRepository
+	txManager         repository.TxManager
+	validate          *validator.Validate
+	actorID           int
+}
+
+func NewVoucherTemplateService(
+	repo repository.VoucherTemplateRepository,
+	accountRepo repository.AccountRepository,
+	voucherService *VoucherService,
+	auditRepo repository.AuditRepository,
+	txManager repository.TxManager,
+) *VoucherTemplateService {
+	return &VoucherTemplateService{
+		repository:        repo,
+		accountRepository: accountRepo,
+		voucherService:    voucherService,
+		auditRepository:   auditRepo,
+		txManager:         txManager,
+		validate:          validator.New(),
+	}
+}
+
+// ForCompany returns a copy of the service that works on the books of companyID
+func (s *VoucherTemplateService) ForCompany(companyID int) *VoucherTemplateService {
+	scoped := *s
+	scoped.repository = s.repository.ForCompany(companyID)
+	scoped.accountRepository = s.accountRepository.ForCompany(companyID)
+	scoped.voucherService = s.voucherService.ForCompany(companyID)
+	scoped.auditRepository = s.auditRepository.ForCompany(companyID)
+	return &scoped
+}
+
+// AsUser returns a copy of the service that records its changes in the
+// audit trail as made by userID
+func (s *VoucherTemplateService) AsUser(userID int) *VoucherTemplateService {
+	scoped := *s
+	scoped.voucherService = s.voucherService.AsUser(userID)
+	scoped.actorID = userID
+	return &scoped
+}
+
+// percentageHundredths returns a line percentage in hundredths of a
+// percent, so 12.5 % is 1250 and the shares can be added up exactly
+func percentageHundredths(percentage float64) (int64, error) {
+	hundredths := math.Round(percentage * 100)
+	if math.Abs(percentage*100-hundredths) > 1e-6 {
+		return 0, fmt.Errorf("percentage %v has more than two decimals", percentage)
+	}
+	return int64(hundredths), nil
+}
+
+// validateTemplate checks a template before it is saved. The fixed amounts
+// and the percentages must each balance between debit and credit, so every
+// voucher created from the template balances whatever its total.
+func (s *VoucherTemplateService) validateTemplate(template *domain.VoucherTemplate) error {
+	template.Name = strings.TrimSpace(template.Name)
+	template.Description = strings.TrimSpace(template.Description)
+	template.Series = strings.ToUpper(strings.TrimSpace(template.Series))
+
+	if err := s.validate.Struct(template); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+
+	var fixedDebit, fixedCredit domain.Amount
+	var percentDebit, percentCredit int64
+	for i, line := range template.Lines {
+		if (line.Amount == nil) == (line.Percentage == nil) {
+			return fmt.Errorf("line %d: give either an amount or a percentage", i+1)
+		}
+		if _, err := s.accountRepository.GetAccountByNo(line.AccountNo); err != nil {
+			return fmt.Errorf("line %d: account %d: %w", i+1, line.AccountNo, err)
+		}
+
+		if line.Amount != nil {
+			if !line.Amount.IsPositive() {
+				return fmt.Errorf("line %d: amount must be positive", i+1)
+			}
+			if line.Side == domain.TemplateSideDebit {
+				fixedDebit = fixedDebit.Add(*line.Amount)
+			} else {
+				fixedCredit = fixedCredit.Add(*line.Amount)
+			}
+			continue
+		}
+
+		hundredths, err := percentageHundredths(*line.Percentage)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", i+1, err)
+		}
+		if line.Side == domain.TemplateSideDebit {
+			percentDebit += hundredths
+		} else {
+			percentCredit += hundredths
+		}
+	}
+
+	if fixedDebit != fixedCredit {
+		return fmt.Errorf("the fixed amounts do not balance: debit %s, credit %s", fixedDebit, fixedCredit)
+	}
+	if percentDebit != percentCredit {
+		return fmt.Errorf("the percentages do not balance: debit %.2f %%, credit %.2f %%",
+			float64(percentDebit)/100, float64(percentCredit)/100)
+	}
+
+	return nil
+}
+
+// CreateTemplate creates a new voucher template. New templates are always
+// active.
+func (s *VoucherTemplateService) CreateTemplate(template *domain.VoucherTemplate) error {
+	if err := s.validateTemplate(template); err != nil {
+		return err
+	}
+
+	if existing, err := s.repository.GetTemplateByName(template.Name); err == nil && existing != nil {
+		return errors.New("voucher template with this name already exists")
+	}
+
+	template.Active = true
+	template.CreatedBy = s.actorID
+	return s.txManager.WithTransaction(func(tx *sql.Tx) error {
+		if err := s.repository.WithTx(tx).CreateTemplate(template); err != nil {
+			return err
+		}
+
+		return recordAudit(s.auditRepository.WithTx(tx), s.actorID, domain.AuditEntityVoucherTemplate, template.TemplateID, nil, template)
+	})
+}
+
+// GetTemplateByID retrieves a voucher template with its lines
+func (s *VoucherTemplateService) GetTemplateByID(templateID int) (*domain.VoucherTemplate, error) {
+	if templateID <= 0 {
+		return nil, errors.New("invalid voucher template ID")
+	}
+
+	return s.repository.GetTemplateByID(templateID)
+}
+
+// GetAllTemplates returns the voucher templates with their lines
+func (s *VoucherTemplateService) GetAllTemplates() ([]*domain.VoucherTemplate, error) {
+	templates, err := s.repository.GetAllTemplates()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get voucher templates: %w", err)
+	}
+
+	return templates, nil
+}
+
+// UpdateTemplate updates a voucher template and replaces its lines.
+// Vouchers already created from it are not changed.
+func (s *VoucherTemplateService) UpdateTemplate(template *domain.VoucherTemplate) error {
+	existingTemplate, err := s.repository.GetTemplateByID(template.TemplateID)
+	if err != nil {
+		return err
+	}
+	if err := s.validateTemplate(template); err != nil {
+		return err
+	}
+
+	if existing, err := s.repository.GetTemplateByName(template.Name); err == nil && existing.TemplateID != template.TemplateID {
+		return errors.New("voucher template with this name already exists")
+	}
+
+	return s.txManager.WithTransaction(func(tx *sql.Tx) error {
+		if err := s.repository.WithTx(tx).UpdateTemplate(template); err != nil {
+			return err
+		}
+
+		return recordAudit(s.auditRepository.WithTx(tx), s.actorID, domain.AuditEntityVoucherTemplate, template.TemplateID, existingTemplate, template)
+	})
+}
+
+// DeleteTemplate deletes a voucher template
+func (s *VoucherTemplateService) DeleteTemplate(templateID int) error {
+	if templateID <= 0 {
+		return errors.New("invalid voucher template ID")
+	}
+
+	existingTemplate, err := s.repository.GetTemplateByID(templateID)
+	if err != nil {
+		return err
+	}
+
+	return s.txManager.WithTransaction(func(tx *sql.Tx) error {
+		if err := s.repository.WithTx(tx).DeleteTemplate(templateID); err != nil {
+			return err
+		}
+
+		return recordAudit(s.auditRepository.WithTx(tx), s.actorID, domain.AuditEntityVoucherTemplate, templateID, existingTemplate, nil)
+	})
+}
+
+// CreateVoucherFromTemplate creates a voucher from a template, a total and a
+// date. It goes through VoucherService.CreateVoucher, so the voucher is
+// validated, numbered and audited like one entered by hand; it is a draft
+// unless the request asks for it to be booked.
+func (s *VoucherTemplateService) CreateVoucherFromTemplate(templateID int, req *domain.TemplateVoucherRequest) (*domain.Voucher, error) {
+	template, err := s.GetTemplateByID(templateID)
+	if err != nil {
+		return nil, err
+	}
+	if !template.Active {
+		return nil, fmt.Errorf("voucher template %q is inactive", template.Name)
+	}
+	if req.Date.IsZero() {
+		return nil, errors.New("date is required")
+	}
+
+	lines, err := templateLines(template, req.TotalAmount)
+	if err != nil {
+		return nil, err
+	}
+
+	description := strings.TrimSpace(req.Description)
+	for _, text := range []string{template.Description, template.Name} {
+		if description == "" {
+			description = text
+		}
+	}
+
+	voucher := &domain.Voucher{
+		Series:      template.Series,
+		Status:      req.Status,
+		Date:        req.Date,
+		Description: description,
+		Reference:   strings.TrimSpace(req.Reference),
+		Period:      req.Date.Format("2006-01"),
+		Lines:       lines,
+	}
+	if err := s.voucherService.CreateVoucher(voucher); err != nil {
+		return nil, err
+	}
+
+	return voucher, nil
+}
+
+// templateLines computes the voucher lines of a template for total. The
+// percentage lines of each side share that side's part of the total; the
+// öre lost to rounding go to the lines with the largest remainders, so the
+// sides balance exactly.
+func templateLines(template *domain.VoucherTemplate, total domain.Amount) ([]domain.LineItem, error) {
+	hasPercentages := false
+	for _, line := range template.Lines {
+		if line.Percentage != nil {
+			hasPercentages = true
+		}
+	}
+	if hasPercentages && !total.IsPositive() {
+		return nil, errors.New("total_amount must be positive, the template has percentage lines")
+	}
+	if total.Ore() > math.MaxInt64/10000 {
+		return nil, errors.New("total_amount is too large")
+	}
+
+	lines := make([]domain.LineItem, len(template.Lines))
+	type share struct {
+		index     int
+		remainder int64
+	}
+	shares := map[string][]share{}
+	targets := map[string]int64{}
+	allocated := map[string]int64{}
+
+	for i, templateLine := range template.Lines {
+		lines[i] = domain.LineItem{
+			AccountNo:    templateLine.AccountNo,
+			TaxCode:      templateLine.TaxCode,
+			ProjectID:    templateLine.ProjectID,
+			CostCenterID: templateLine.CostCenterID,
+		}
+
+		var amount domain.Amount
+		if templateLine.Amount != nil {
+			amount = *templateLine.Amount
+		} else {
+			hundredths, err := percentageHundredths(*templateLine.Percentage)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", i+1, err)
+			}
+			exact := total.Ore() * hundredths
+			amount = domain.Amount(exact / 10000)
+
+			side := templateLine.Side
+			shares[side] = append(shares[side], share{index: i, remainder: exact % 10000})
+			targets[side] += hundredths
+			allocated[side] += amount.Ore()
+		}
+
+		if templateLine.Side == domain.TemplateSideDebit {
+			lines[i].DebitAmount = amount
+		} else {
+			lines[i].CreditAmount = amount
+		}
+	}
+
+	for side, sideShares := range shares {
+		// The side's part of the total, rounded half up
+		target := (total.Ore()*targets[side] + 5000) / 10000
+		missing := target - allocated[side]
+
+		sort.SliceStable(sideShares, func(i, j int) bool {
+			return sideShares[i].remainder > sideShares[j].remainder
+		})
+		for k := int64(0); k < missing && k < int64(len(sideShares)); k++ {
+			line := &lines[sideShares[k].index]
+			if side == domain.TemplateSideDebit {
+				line.DebitAmount++
+			} else {
+				line.CreditAmount++
+			}
+		}
+	}
+
+	// A share too small to get an öre of a small total leaves an empty line
+	nonEmpty := lines[:0]
+	for _, line := range lines {
+		if !line.DebitAmount.IsZero() || !line.CreditAmount.IsZero() {
+			nonEmpty = append(nonEmpty, line)
+		}
+	}
+
+	return nonEmpty, nil
+}
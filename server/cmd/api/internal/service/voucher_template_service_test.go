@@ -0,0 +1,194 @@
+package service
+
+import (
+	"cmd/api/internal/domain"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func percentLine(accountNo int, side string, percentage float64) domain.VoucherTemplateLine {
+	return domain.VoucherTemplateLine{AccountNo: accountNo, Side: side, Percentage: &percentage}
+}
+
+func amountLine(accountNo int, side string, amount domain.Amount) domain.VoucherTemplateLine {
+	return domain.VoucherTemplateLine{AccountNo: accountNo, Side: side, Amount: &amount}
+}
+
+func TestPercentageHundredths(t *testing.T) {
+	tests := []struct {
+		percentage float64
+		want       int64
+		wantErr    bool
+	}{
+		{percentage: 25, want: 2500},
+		{percentage: 12.5, want: 1250},
+		{percentage: 33.33, want: 3333},
+		{percentage: 0.01, want: 1},
+		{percentage: 100, want: 10000},
+		{percentage: 0.1 + 0.2, want: 30},
+		{percentage: 33.333, wantErr: true},
+		{percentage: 0.005, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := percentageHundredths(tt.percentage)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("percentageHundredths(%v) = %d, want an error", tt.percentage, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("percentageHundredths(%v) = %d, %v, want %d", tt.percentage, got, err, tt.want)
+		}
+	}
+}
+
+func TestTemplateLines(t *testing.T) {
+	thirds := &domain.VoucherTemplate{Lines: []domain.VoucherTemplateLine{
+		percentLine(1930, domain.TemplateSideDebit, 100),
+		percentLine(6110, domain.TemplateSideCredit, 33.33),
+		percentLine(6120, domain.TemplateSideCredit, 33.33),
+		percentLine(6130, domain.TemplateSideCredit, 33.34),
+	}}
+	halves := &domain.VoucherTemplate{Lines: []domain.VoucherTemplateLine{
+		percentLine(1930, domain.TemplateSideDebit, 100),
+		percentLine(6110, domain.TemplateSideCredit, 50),
+		percentLine(6120, domain.TemplateSideCredit, 50),
+	}}
+	withFee := &domain.VoucherTemplate{Lines: []domain.VoucherTemplateLine{
+		amountLine(6570, domain.TemplateSideDebit, 2500),
+		amountLine(1930, domain.TemplateSideCredit, 2500),
+		percentLine(5010, domain.TemplateSideDebit, 87.5),
+		percentLine(5020, domain.TemplateSideDebit, 12.5),
+		percentLine(1930, domain.TemplateSideCredit, 100),
+	}}
+
+	tests := []struct {
+		name     string
+		template *domain.VoucherTemplate
+		total    domain.Amount
+		want     []domain.LineItem
+	}{
+		{
+			name:     "thirds of an even total",
+			template: thirds,
+			total:    999900,
+			want: []domain.LineItem{
+				{AccountNo: 1930, DebitAmount: 999900},
+				{AccountNo: 6110, CreditAmount: 333267},
+				{AccountNo: 6120, CreditAmount: 333267},
+				{AccountNo: 6130, CreditAmount: 333366},
+			},
+		},
+		{
+			name:     "the lost öre goes to the largest remainder",
+			template: thirds,
+			total:    100,
+			want: []domain.LineItem{
+				{AccountNo: 1930, DebitAmount: 100},
+				{AccountNo: 6110, CreditAmount: 33},
+				{AccountNo: 6120, CreditAmount: 33},
+				{AccountNo: 6130, CreditAmount: 34},
+			},
+		},
+		{
+			name:     "equal remainders go to the first line",
+			template: halves,
+			total:    101,
+			want: []domain.LineItem{
+				{AccountNo: 1930, DebitAmount: 101},
+				{AccountNo: 6110, CreditAmount: 51},
+				{AccountNo: 6120, CreditAmount: 50},
+			},
+		},
+		{
+			name:     "a line that gets no öre is dropped",
+			template: halves,
+			total:    1,
+			want: []domain.LineItem{
+				{AccountNo: 1930, DebitAmount: 1},
+				{AccountNo: 6110, CreditAmount: 1},
+			},
+		},
+		{
+			name:     "fixed amounts are kept as they are",
+			template: withFee,
+			total:    100001,
+			want: []domain.LineItem{
+				{AccountNo: 6570, DebitAmount: 2500},
+				{AccountNo: 1930, CreditAmount: 2500},
+				{AccountNo: 5010, DebitAmount: 87501},
+				{AccountNo: 5020, DebitAmount: 12500},
+				{AccountNo: 1930, CreditAmount: 100001},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := templateLines(tt.template, tt.total)
+			if err != nil {
+				t.Fatalf("templateLines returned error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("templateLines = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTemplateLinesBalance(t *testing.T) {
+	template := &domain.VoucherTemplate{Lines: []domain.VoucherTemplateLine{
+		percentLine(5010, domain.TemplateSideDebit, 33.33),
+		percentLine(5020, domain.TemplateSideDebit, 33.33),
+		percentLine(5030, domain.TemplateSideDebit, 33.34),
+		percentLine(2440, domain.TemplateSideCredit, 87.5),
+		percentLine(2640, domain.TemplateSideCredit, 12.5),
+	}}
+
+	totals := []domain.Amount{999999, 1000001, 123456789}
+	for total := domain.Amount(1); total <= 1000; total++ {
+		totals = append(totals, total)
+	}
+
+	for _, total := range totals {
+		lines, err := templateLines(template, total)
+		if err != nil {
+			t.Fatalf("templateLines(%d) returned error: %v", total, err)
+		}
+		if err := checkBalance(lines); err != nil {
+			t.Fatalf("templateLines(%d) does not balance: %v", total, err)
+		}
+		var debit domain.Amount
+		for _, line := range lines {
+			debit = debit.Add(line.DebitAmount)
+		}
+		if debit != total {
+			t.Fatalf("templateLines(%d) has debit %d, want the total", total, debit)
+		}
+	}
+}
+
+func TestTemplateLinesErrors(t *testing.T) {
+	percentages := &domain.VoucherTemplate{Lines: []domain.VoucherTemplateLine{
+		percentLine(1930, domain.TemplateSideDebit, 100),
+		percentLine(3001, domain.TemplateSideCredit, 100),
+	}}
+	fixed := &domain.VoucherTemplate{Lines: []domain.VoucherTemplateLine{
+		amountLine(6570, domain.TemplateSideDebit, 2500),
+		amountLine(1930, domain.TemplateSideCredit, 2500),
+	}}
+
+	for _, total := range []domain.Amount{0, -100, math.MaxInt64/10000 + 1} {
+		if _, err := templateLines(percentages, total); err == nil {
+			t.Errorf("templateLines with total %d did not return an error", total)
+		}
+	}
+
+	// A template of fixed amounts needs no total
+	if lines, err := templateLines(fixed, 0); err != nil || len(lines) != 2 {
+		t.Errorf("templateLines = %+v, %v, want the two fixed lines", lines, err)
+	}
+}
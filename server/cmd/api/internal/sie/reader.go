@@ -0,0 +1,395 @@
+package sie
+
+import (
+	"bufio"
+	"bytes"
+	"cmd/api/internal/domain"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// File holds the parts of a SIE file that are imported
+type File struct {
+	CompanyName string
+	OrgNr       string
+	Accounts    []Account
+	Dimensions  []Dimension
+	Objects     []ObjectDef
+	Vouchers    []Voucher
+}
+
+// Account is a #KONTO row, with the type from a matching #KTYP if present
+type Account struct {
+	No   int
+	Name string
+	Type string // T, S, I, K or empty
+	Line int
+}
+
+// Dimension is a #DIM row
+type Dimension struct {
+	No   int
+	Name string
+	Line int
+}
+
+// ObjectDef is an #OBJEKT row
+type ObjectDef struct {
+	Dim  int
+	ID   string
+	Name string
+	Line int
+}
+
+// ObjectRef points at an object from a #TRANS object list
+type ObjectRef struct {
+	Dim int
+	ID  string
+}
+
+// Voucher is a #VER row with its #TRANS rows
+type Voucher struct {
+	Series string
+	Number string
+	Date   time.Time
+	Text   string
+	Line   int
+	Trans  []Trans
+}
+
+// Trans is a #TRANS row. Debit amounts are positive and credit negative.
+type Trans struct {
+	AccountNo int
+	Objects   []ObjectRef
+	Amount    domain.Amount
+	Text      string
+	Line      int
+}
+
+// ParseError is a problem on a given line of the file (1-based)
+type ParseError struct {
+	Line    int
+	Message string
+}
+
+func (e ParseError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Message)
+}
+
+// Parse reads a SIE file. It keeps going after bad rows so that every
+// problem is reported; the file is only usable when no errors are returned.
+// Labels that are not imported (#IB, #UB, #RES, #PSALDO, ...) are skipped.
+func Parse(data []byte) (*File, []ParseError) {
+	file := &File{}
+	var errs []ParseError
+	fail := func(line int, format string, args ...interface{}) {
+		errs = append(errs, ParseError{Line: line, Message: fmt.Sprintf(format, args...)})
+	}
+
+	accountTypes := make(map[int]string)
+	var current *Voucher
+	inBlock := false
+
+	scanner := bufio.NewScanner(bytes.NewReader(decode(data)))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		switch text {
+		case "{":
+			if current == nil || inBlock {
+				fail(lineNo, "unexpected {")
+				continue
+			}
+			inBlock = true
+			continue
+		case "}":
+			if !inBlock {
+				fail(lineNo, "unexpected }")
+				continue
+			}
+			file.Vouchers = append(file.Vouchers, *current)
+			current = nil
+			inBlock = false
+			continue
+		}
+
+		fields, err := splitFields(text)
+		if err != nil {
+			fail(lineNo, "%v", err)
+			continue
+		}
+		label := strings.ToUpper(fields[0])
+		args := fields[1:]
+
+		if current != nil && !inBlock {
+			fail(current.Line, "#VER without a { } block")
+			current = nil
+		}
+		if inBlock && label != "#TRANS" && label != "#RTRANS" && label != "#BTRANS" {
+			fail(lineNo, "%s inside a voucher block", label)
+			continue
+		}
+
+		switch label {
+		case "#FNAMN":
+			if len(args) > 0 {
+				file.CompanyName = args[0]
+			}
+
+		case "#ORGNR":
+			if len(args) > 0 {
+				file.OrgNr = args[0]
+			}
+
+		case "#KONTO":
+			if len(args) < 2 {
+				fail(lineNo, "#KONTO needs an account number and a name")
+				continue
+			}
+			accountNo, err := strconv.Atoi(args[0])
+			if err != nil {
+				fail(lineNo, "invalid account number %q", args[0])
+				continue
+			}
+			file.Accounts = append(file.Accounts, Account{No: accountNo, Name: args[1], Line: lineNo})
+
+		case "#KTYP":
+			if len(args) < 2 {
+				fail(lineNo, "#KTYP needs an account number and a type")
+				continue
+			}
+			accountNo, err := strconv.Atoi(args[0])
+			if err != nil {
+				fail(lineNo, "invalid account number %q", args[0])
+				continue
+			}
+			accountType := strings.ToUpper(args[1])
+			if !strings.Contains("TSIK", accountType) || len(accountType) != 1 {
+				fail(lineNo, "invalid account type %q", args[1])
+				continue
+			}
+			accountTypes[accountNo] = accountType
+
+		case "#DIM":
+			if len(args) < 2 {
+				fail(lineNo, "#DIM needs a dimension number and a name")
+				continue
+			}
+			dim, err := strconv.Atoi(args[0])
+			if err != nil {
+				fail(lineNo, "invalid dimension %q", args[0])
+				continue
+			}
+			file.Dimensions = append(file.Dimensions, Dimension{No: dim, Name: args[1], Line: lineNo})
+
+		case "#OBJEKT":
+			if len(args) < 3 {
+				fail(lineNo, "#OBJEKT needs a dimension, an object ID and a name")
+				continue
+			}
+			dim, err := strconv.Atoi(args[0])
+			if err != nil {
+				fail(lineNo, "invalid dimension %q", args[0])
+				continue
+			}
+			file.Objects = append(file.Objects, ObjectDef{Dim: dim, ID: args[1], Name: args[2], Line: lineNo})
+
+		case "#VER":
+			if len(args) < 3 {
+				fail(lineNo, "#VER needs a series, a number and a date")
+				continue
+			}
+			date, err := parseDate(args[2])
+			if err != nil {
+				fail(lineNo, "%v", err)
+				continue
+			}
+			current = &Voucher{Series: args[0], Number: args[1], Date: date, Line: lineNo}
+			if len(args) > 3 {
+				current.Text = args[3]
+			}
+
+		case "#TRANS":
+			if !inBlock {
+				fail(lineNo, "#TRANS outside a voucher block")
+				continue
+			}
+			trans, err := parseTrans(args)
+			if err != nil {
+				fail(lineNo, "%v", err)
+				continue
+			}
+			trans.Line = lineNo
+			current.Trans = append(current.Trans, trans)
+
+		case "#RTRANS", "#BTRANS":
+			// Added and removed rows are history only; an #RTRANS is always
+			// followed by the #TRANS that books it
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		fail(lineNo, "failed to read file: %v", err)
+	}
+	if current != nil {
+		if inBlock {
+			fail(current.Line, "voucher block is not closed")
+		} else {
+			fail(current.Line, "#VER without a { } block")
+		}
+	}
+
+	for i := range file.Accounts {
+		file.Accounts[i].Type = accountTypes[file.Accounts[i].No]
+	}
+
+	return file, errs
+}
+
+// decode converts the file to UTF-8. SIE files are codepage 437, but some
+// programs write UTF-8 anyway; a file that is valid UTF-8 is kept as is.
+func decode(data []byte) []byte {
+	if utf8.Valid(data) {
+		return data
+	}
+	out, err := charmap.CodePage437.NewDecoder().Bytes(data)
+	if err != nil {
+		return data
+	}
+	return out
+}
+
+// parseTrans parses the fields of #TRANS account {objects} amount [date] [text] ...
+func parseTrans(args []string) (Trans, error) {
+	if len(args) < 3 {
+		return Trans{}, fmt.Errorf("#TRANS needs an account, an object list and an amount")
+	}
+
+	accountNo, err := strconv.Atoi(args[0])
+	if err != nil {
+		return Trans{}, fmt.Errorf("invalid account number %q", args[0])
+	}
+
+	objects, err := parseObjectList(args[1])
+	if err != nil {
+		return Trans{}, err
+	}
+
+	amount, err := domain.ParseAmount(args[2])
+	if err != nil {
+		return Trans{}, fmt.Errorf("invalid amount %q", args[2])
+	}
+
+	trans := Trans{AccountNo: accountNo, Objects: objects, Amount: amount}
+	if len(args) > 4 {
+		trans.Text = args[4]
+	}
+
+	return trans, nil
+}
+
+// parseObjectList parses {dim "id" dim "id" ...}
+func parseObjectList(s string) ([]ObjectRef, error) {
+	if !strings.HasPrefix(s, "{") || !strings.HasSuffix(s, "}") {
+		return nil, fmt.Errorf("invalid object list %q", s)
+	}
+
+	inner := strings.TrimSpace(s[1 : len(s)-1])
+	if inner == "" {
+		return nil, nil
+	}
+
+	fields, err := splitFields(inner)
+	if err != nil {
+		return nil, err
+	}
+	if len(fields)%2 != 0 {
+		return nil, fmt.Errorf("invalid object list %q", s)
+	}
+
+	refs := make([]ObjectRef, 0, len(fields)/2)
+	for i := 0; i < len(fields); i += 2 {
+		dim, err := strconv.Atoi(fields[i])
+		if err != nil {
+			return nil, fmt.Errorf("invalid dimension %q in object list", fields[i])
+		}
+		refs = append(refs, ObjectRef{Dim: dim, ID: fields[i+1]})
+	}
+
+	return refs, nil
+}
+
+func parseDate(s string) (time.Time, error) {
+	t, err := time.Parse("20060102", s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q, expected YYYYMMDD", s)
+	}
+	return t, nil
+}
+
+// splitFields splits a row into fields. Quoted strings have their quotes
+// and escapes removed and an object list {...} is kept as one field.
+func splitFields(line string) ([]string, error) {
+	var fields []string
+	i := 0
+	for i < len(line) {
+		switch c := line[i]; {
+		case c == ' ' || c == '\t':
+			i++
+
+		case c == '"':
+			var b strings.Builder
+			i++
+			closed := false
+			for i < len(line) {
+				if line[i] == '\\' && i+1 < len(line) {
+					b.WriteByte(line[i+1])
+					i += 2
+					continue
+				}
+				if line[i] == '"' {
+					closed = true
+					i++
+					break
+				}
+				b.WriteByte(line[i])
+				i++
+			}
+			if !closed {
+				return nil, fmt.Errorf("unterminated string")
+			}
+			fields = append(fields, b.String())
+
+		case c == '{':
+			end := strings.IndexByte(line[i:], '}')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated object list")
+			}
+			fields = append(fields, line[i:i+end+1])
+			i += end + 1
+
+		default:
+			start := i
+			for i < len(line) && line[i] != ' ' && line[i] != '\t' {
+				i++
+			}
+			fields = append(fields, line[start:i])
+		}
+	}
+
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("empty row")
+	}
+
+	return fields, nil
+}
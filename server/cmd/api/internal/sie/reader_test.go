@@ -0,0 +1,219 @@
+package sie
+
+import (
+	"cmd/api/internal/domain"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const sampleFile = `#FLAGGA 0
+#FORMAT PC8
+#SIETYP 4
+#FNAMN "Exempel AB"
+#ORGNR 556000-0000
+#KONTO 1930 "Företagskonto"
+#KONTO 3001 "Försäljning 25 %"
+#KTYP 1930 T
+#KTYP 3001 I
+#DIM 1 "Kostnadsställe"
+#OBJEKT 1 "100" "Försäljning"
+#IB 0 1930 1000.00
+#VER "A" "1" 20250115 "Faktura \"17\""
+{
+#TRANS 1930 {} 1250.00
+#RTRANS 3001 {1 "100"} -1000.00
+#TRANS 3001 {1 "100"} -1000,00 20250115 "Konsult"
+#TRANS 2611 {} -250.00
+}
+`
+
+func TestParse(t *testing.T) {
+	file, errs := Parse([]byte(strings.ReplaceAll(sampleFile, "\n", "\r\n")))
+	if len(errs) > 0 {
+		t.Fatalf("Parse returned errors: %v", errs)
+	}
+
+	if file.CompanyName != "Exempel AB" || file.OrgNr != "556000-0000" {
+		t.Errorf("company = %q %q, want Exempel AB 556000-0000", file.CompanyName, file.OrgNr)
+	}
+
+	wantAccounts := []Account{
+		{No: 1930, Name: "Företagskonto", Type: "T", Line: 6},
+		{No: 3001, Name: "Försäljning 25 %", Type: "I", Line: 7},
+	}
+	if !reflect.DeepEqual(file.Accounts, wantAccounts) {
+		t.Errorf("Accounts = %+v, want %+v", file.Accounts, wantAccounts)
+	}
+
+	wantObjects := []ObjectDef{{Dim: 1, ID: "100", Name: "Försäljning", Line: 11}}
+	if !reflect.DeepEqual(file.Objects, wantObjects) {
+		t.Errorf("Objects = %+v, want %+v", file.Objects, wantObjects)
+	}
+
+	wantVouchers := []Voucher{{
+		Series: "A",
+		Number: "1",
+		Date:   time.Date(2025, time.January, 15, 0, 0, 0, 0, time.UTC),
+		Text:   `Faktura "17"`,
+		Line:   13,
+		Trans: []Trans{
+			{AccountNo: 1930, Amount: 125000, Line: 15},
+			{AccountNo: 3001, Objects: []ObjectRef{{Dim: 1, ID: "100"}}, Amount: -100000, Text: "Konsult", Line: 17},
+			{AccountNo: 2611, Amount: -25000, Line: 18},
+		},
+	}}
+	if !reflect.DeepEqual(file.Vouchers, wantVouchers) {
+		t.Errorf("Vouchers = %+v, want %+v", file.Vouchers, wantVouchers)
+	}
+}
+
+func TestParseCodepage437(t *testing.T) {
+	// "Företagskonto" with ö as 0x94, as #FORMAT PC8 files have it
+	file, errs := Parse([]byte("#KONTO 1930 \"F\x94retagskonto\"\r\n"))
+	if len(errs) > 0 {
+		t.Fatalf("Parse returned errors: %v", errs)
+	}
+	if len(file.Accounts) != 1 || file.Accounts[0].Name != "Företagskonto" {
+		t.Errorf("Accounts = %+v, want Företagskonto", file.Accounts)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		file  string
+		lines []int
+	}{
+		{name: "bad account", file: "#KONTO abc \"Kassa\"", lines: []int{1}},
+		{name: "account without name", file: "#KONTO 1910", lines: []int{1}},
+		{name: "bad account type", file: "#KTYP 1910 X", lines: []int{1}},
+		{name: "bad date", file: "#VER \"A\" \"1\" 2025-01-01\n{\n}", lines: []int{1, 2, 3}},
+		{name: "bad amount", file: "#VER \"A\" \"1\" 20250101\n{\n#TRANS 1910 {} 12x\n}", lines: []int{3}},
+		{name: "odd object list", file: "#VER \"A\" \"1\" 20250101\n{\n#TRANS 1910 {1} 10\n}", lines: []int{3}},
+		{name: "unterminated string", file: "#FNAMN \"Exempel", lines: []int{1}},
+		{name: "transaction outside block", file: "#TRANS 1910 {} 10", lines: []int{1}},
+		{name: "voucher without block", file: "#VER \"A\" \"1\" 20250101\n#KONTO 1910 \"Kassa\"", lines: []int{1}},
+		{name: "unclosed block", file: "#VER \"A\" \"1\" 20250101\n{\n#TRANS 1910 {} 10", lines: []int{1}},
+		{name: "label inside block", file: "#VER \"A\" \"1\" 20250101\n{\n#KONTO 1910 \"Kassa\"\n}", lines: []int{3}},
+		{name: "stray brace", file: "}", lines: []int{1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, errs := Parse([]byte(tt.file))
+			lines := make([]int, len(errs))
+			for i, err := range errs {
+				lines[i] = err.Line
+			}
+			if !reflect.DeepEqual(lines, tt.lines) {
+				t.Errorf("errors on lines %v (%v), want %v", lines, errs, tt.lines)
+			}
+		})
+	}
+}
+
+func TestWriteThenParse(t *testing.T) {
+	w := NewWriter()
+	w.Line("#FNAMN", Quote(`Åre "Fjäll" AB`))
+	w.Line("#KONTO", "1910", Quote("Kassa"))
+	w.Line("#VER", Quote("B"), Quote("42"), Date(time.Date(2025, time.June, 30, 0, 0, 0, 0, time.UTC)), Quote("Växel"))
+	w.BeginBlock()
+	w.Line("#TRANS", "1910", Object("6", "P 1"), Amount(domain.Amount(-1)))
+	w.Line("#TRANS", "1930", Object(), Amount(domain.Amount(1)))
+	w.EndBlock()
+
+	data, err := w.Bytes()
+	if err != nil {
+		t.Fatalf("Bytes returned error: %v", err)
+	}
+
+	file, errs := Parse(data)
+	if len(errs) > 0 {
+		t.Fatalf("Parse returned errors: %v", errs)
+	}
+	if file.CompanyName != `Åre "Fjäll" AB` {
+		t.Errorf("CompanyName = %q", file.CompanyName)
+	}
+	if len(file.Vouchers) != 1 {
+		t.Fatalf("got %d vouchers, want 1", len(file.Vouchers))
+	}
+	v := file.Vouchers[0]
+	if v.Series != "B" || v.Number != "42" || v.Text != "Växel" {
+		t.Errorf("voucher = %+v", v)
+	}
+	wantTrans := []Trans{
+		{AccountNo: 1910, Objects: []ObjectRef{{Dim: 6, ID: "P 1"}}, Amount: -1, Line: 5},
+		{AccountNo: 1930, Amount: 1, Line: 6},
+	}
+	if !reflect.DeepEqual(v.Trans, wantTrans) {
+		t.Errorf("Trans = %+v, want %+v", v.Trans, wantTrans)
+	}
+}
+
+func TestSplitFields(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    []string
+		wantErr bool
+	}{
+		{in: `#KONTO 1910 Kassa`, want: []string{"#KONTO", "1910", "Kassa"}},
+		{in: "#KONTO\t1910  \"Kassa och bank\"", want: []string{"#KONTO", "1910", "Kassa och bank"}},
+		{in: `#VER "" "" 20250101`, want: []string{"#VER", "", "", "20250101"}},
+		{in: `#FNAMN "A \"B\" \\ C"`, want: []string{"#FNAMN", `A "B" \ C`}},
+		{in: `#TRANS 1910 {1 "100" 6 "P1"} 10.00`, want: []string{"#TRANS", "1910", `{1 "100" 6 "P1"}`, "10.00"}},
+		{in: `#FNAMN "Exempel`, wantErr: true},
+		{in: `#TRANS 1910 {1 "100" 10.00`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := splitFields(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("splitFields(%q) = %q, want an error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("splitFields(%q) returned error: %v", tt.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitFields(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseObjectList(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    []ObjectRef
+		wantErr bool
+	}{
+		{in: "{}", want: nil},
+		{in: "{ }", want: nil},
+		{in: `{1 "100"}`, want: []ObjectRef{{Dim: 1, ID: "100"}}},
+		{in: `{1 100 6 "P 1"}`, want: []ObjectRef{{Dim: 1, ID: "100"}, {Dim: 6, ID: "P 1"}}},
+		{in: `{1}`, wantErr: true},
+		{in: `{x "100"}`, wantErr: true},
+		{in: `1 "100"`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseObjectList(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseObjectList(%q) = %+v, want an error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseObjectList(%q) returned error: %v", tt.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseObjectList(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
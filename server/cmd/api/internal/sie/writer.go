@@ -0,0 +1,86 @@
+// Package sie reads and writes SIE files, the Swedish standard format for
+// moving bookkeeping data between programs (https://sie.se). Files are
+// encoded in IBM PC 8-bit extended ASCII (codepage 437) as #FORMAT PC8
+// requires.
+package sie
+
+import (
+	"bytes"
+	"cmd/api/internal/domain"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+)
+
+// Writer builds a SIE file line by line
+type Writer struct {
+	buf bytes.Buffer
+}
+
+func NewWriter() *Writer {
+	return &Writer{}
+}
+
+// Line writes a label (e.g. "#KONTO") followed by its fields. Fields are
+// written as given; use Quote for strings and Object for object lists.
+func (w *Writer) Line(label string, fields ...string) {
+	w.buf.WriteString(label)
+	for _, field := range fields {
+		w.buf.WriteByte(' ')
+		w.buf.WriteString(field)
+	}
+	w.buf.WriteString("\r\n")
+}
+
+// BeginBlock and EndBlock wrap the #TRANS rows of a #VER
+func (w *Writer) BeginBlock() {
+	w.buf.WriteString("{\r\n")
+}
+
+func (w *Writer) EndBlock() {
+	w.buf.WriteString("}\r\n")
+}
+
+// Bytes returns the file encoded in codepage 437. Characters that do not
+// exist in the codepage are replaced.
+func (w *Writer) Bytes() ([]byte, error) {
+	encoder := encoding.ReplaceUnsupported(charmap.CodePage437.NewEncoder())
+	out, err := encoder.Bytes(w.buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode SIE file: %w", err)
+	}
+	return out, nil
+}
+
+// Quote returns s as a quoted SIE string
+func Quote(s string) string {
+	s = strings.ReplaceAll(s, "\\", "\\\\")
+	s = strings.ReplaceAll(s, "\"", "\\\"")
+	s = strings.NewReplacer("\r", " ", "\n", " ", "\t", " ").Replace(s)
+	return "\"" + s + "\""
+}
+
+// Object returns an object list such as {1 "100" 6 "P1"}; no pairs gives {}
+func Object(pairs ...string) string {
+	quoted := make([]string, len(pairs))
+	for i, p := range pairs {
+		if i%2 == 1 {
+			p = Quote(p)
+		}
+		quoted[i] = p
+	}
+	return "{" + strings.Join(quoted, " ") + "}"
+}
+
+// Date formats a date as YYYYMMDD
+func Date(t time.Time) string {
+	return t.Format("20060102")
+}
+
+// Amount formats an amount with a decimal point and two decimals
+func Amount(a domain.Amount) string {
+	return a.String()
+}
@@ -0,0 +1,104 @@
+package sie
+
+import (
+	"bytes"
+	"cmd/api/internal/domain"
+	"testing"
+	"time"
+)
+
+func TestQuote(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "", want: `""`},
+		{in: "Kassa", want: `"Kassa"`},
+		{in: `Faktura "12"`, want: `"Faktura \"12\""`},
+		{in: `C:\temp`, want: `"C:\\temp"`},
+		{in: "rad ett\r\nrad två\tslut", want: `"rad ett  rad två slut"`},
+	}
+
+	for _, tt := range tests {
+		if got := Quote(tt.in); got != tt.want {
+			t.Errorf("Quote(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestObject(t *testing.T) {
+	tests := []struct {
+		pairs []string
+		want  string
+	}{
+		{pairs: nil, want: "{}"},
+		{pairs: []string{"1", "100"}, want: `{1 "100"}`},
+		{pairs: []string{"1", "100", "6", "P1"}, want: `{1 "100" 6 "P1"}`},
+	}
+
+	for _, tt := range tests {
+		if got := Object(tt.pairs...); got != tt.want {
+			t.Errorf("Object(%q) = %s, want %s", tt.pairs, got, tt.want)
+		}
+	}
+}
+
+func TestDateAndAmount(t *testing.T) {
+	if got := Date(time.Date(2025, time.March, 7, 0, 0, 0, 0, time.UTC)); got != "20250307" {
+		t.Errorf("Date = %s, want 20250307", got)
+	}
+
+	tests := []struct {
+		amount domain.Amount
+		want   string
+	}{
+		{amount: 0, want: "0.00"},
+		{amount: 123450, want: "1234.50"},
+		{amount: -99, want: "-0.99"},
+	}
+	for _, tt := range tests {
+		if got := Amount(tt.amount); got != tt.want {
+			t.Errorf("Amount(%d) = %s, want %s", tt.amount, got, tt.want)
+		}
+	}
+}
+
+func TestWriterBytes(t *testing.T) {
+	w := NewWriter()
+	w.Line("#FLAGGA", "0")
+	w.Line("#FNAMN", Quote("Åkeri Öst"))
+	w.Line("#VER", Quote("A"), Quote("1"), "20250101", Quote("Hyra"))
+	w.BeginBlock()
+	w.Line("#TRANS", "5010", Object(), Amount(domain.NewAmount(100)))
+	w.EndBlock()
+
+	got, err := w.Bytes()
+	if err != nil {
+		t.Fatalf("Bytes returned error: %v", err)
+	}
+
+	// Å is 0x8F and Ö 0x99 in codepage 437, and rows end in CRLF
+	want := []byte("#FLAGGA 0\r\n" +
+		"#FNAMN \"\x8Fkeri \x99st\"\r\n" +
+		"#VER \"A\" \"1\" 20250101 \"Hyra\"\r\n" +
+		"{\r\n" +
+		"#TRANS 5010 {} 100.00\r\n" +
+		"}\r\n")
+	if !bytes.Equal(got, want) {
+		t.Errorf("Bytes = %q, want %q", got, want)
+	}
+}
+
+func TestWriterBytesReplacesUnsupported(t *testing.T) {
+	w := NewWriter()
+	w.Line("#FNAMN", Quote("Café €"))
+
+	got, err := w.Bytes()
+	if err != nil {
+		t.Fatalf("Bytes returned error: %v", err)
+	}
+	// é exists in codepage 437 (0x82), € does not
+	if want := []byte("#FNAMN \"Caf\x82 \x1a\"\r\n"); !bytes.Equal(got, want) {
+		t.Errorf("Bytes = %q, want %q", got, want)
+	}
+}
@@ -9,12 +9,19 @@ import (
 	"cmd/api/internal/repository"
 	"cmd/api/internal/routes"
 	"cmd/api/internal/service"
+	"cmd/api/internal/storage"
 	"log"
+	"os"
 
 	"github.com/gin-gonic/gin"
 )
 
 func main() {
+	// `api verify-chain` checks the voucher hash chains instead of serving
+	if len(os.Args) > 1 && os.Args[1] == "verify-chain" {
+		os.Exit(runVerifyChain(os.Args[2:]))
+	}
+
 	cfg := config.LoadConfig()
 
 	db, err := database.NewConnection(cfg.DatabaseURL)
@@ -30,20 +37,68 @@ func main() {
 	lineItemRepo := repository.NewLineItemRepository(db)
 	voucherRepo := repository.NewVoucherRepository(db)
 	reportRepo := repository.NewReportRepository(db)
+	periodRepo := repository.NewPeriodRepository(db)
+	fiscalYearRepo := repository.NewFiscalYearRepository(db)
+	vatRepo := repository.NewVATRepository(db)
+	projectRepo := repository.NewProjectRepository(db)
+	costCenterRepo := repository.NewCostCenterRepository(db)
+	companyRepo := repository.NewCompanyRepository(db)
+	voucherSeriesRepo := repository.NewVoucherSeriesRepository(db)
+	auditRepo := repository.NewAuditRepository(db)
+	attachmentRepo := repository.NewAttachmentRepository(db)
+	searchRepo := repository.NewSearchRepository(db)
+	bankRepo := repository.NewBankRepository(db)
+	bankRuleRepo := repository.NewBankRuleRepository(db)
+	voucherTemplateRepo := repository.NewVoucherTemplateRepository(db)
+	txManager := repository.NewTxManager(db)
+
+	attachmentStorage, err := storage.NewLocalStorage(cfg.AttachmentDir)
+	if err != nil {
+		log.Fatal("Failed to set up attachment storage:", err)
+	}
 
-	userService := service.NewUserService(userRepo)
-	accountService := service.NewAccountService(accountRepo)
-	lineItemService := service.NewLineItemService(lineItemRepo)
-	voucherService := service.NewVoucherService(voucherRepo, lineItemRepo)
-	reportService := service.NewReportService(reportRepo)
+	userService := service.NewUserService(userRepo, auditRepo, txManager)
+	companyService := service.NewCompanyService(companyRepo, userRepo, accountRepo, voucherSeriesRepo, auditRepo, txManager)
+	accountService := service.NewAccountService(accountRepo, projectRepo, costCenterRepo, auditRepo, txManager)
+	lineItemService := service.NewLineItemService(lineItemRepo, voucherRepo, projectRepo, costCenterRepo, auditRepo, txManager)
+	voucherService := service.NewVoucherService(voucherRepo, lineItemRepo, accountRepo, periodRepo, projectRepo, costCenterRepo, voucherSeriesRepo, auditRepo, txManager)
+	reportService := service.NewReportService(reportRepo, fiscalYearRepo, projectRepo, costCenterRepo)
+	periodService := service.NewPeriodService(periodRepo, auditRepo, txManager)
+	fiscalYearService := service.NewFiscalYearService(fiscalYearRepo, voucherRepo, lineItemRepo, periodRepo, reportRepo, auditRepo, txManager)
+	vatService := service.NewVATService(vatRepo, voucherRepo, lineItemRepo, periodRepo, companyRepo, auditRepo, txManager)
+	projectService := service.NewProjectService(projectRepo, auditRepo, txManager)
+	costCenterService := service.NewCostCenterService(costCenterRepo, auditRepo, txManager)
+	voucherSeriesService := service.NewVoucherSeriesService(voucherSeriesRepo, auditRepo, txManager)
+	auditService := service.NewAuditService(auditRepo)
+	searchService := service.NewSearchService(searchRepo)
+	attachmentService := service.NewAttachmentService(attachmentRepo, voucherRepo, auditRepo, attachmentStorage, txManager)
+	bankService := service.NewBankService(bankRepo, accountRepo, auditRepo, txManager)
+	bankRuleService := service.NewBankRuleService(bankRuleRepo, accountRepo, voucherService, auditRepo, txManager)
+	voucherTemplateService := service.NewVoucherTemplateService(voucherTemplateRepo, accountRepo, voucherService, auditRepo, txManager)
+	sieService := service.NewSIEService(accountService, projectService, costCenterService, accountRepo, voucherRepo, lineItemRepo, reportRepo, fiscalYearRepo, periodRepo, voucherSeriesRepo, companyRepo, auditRepo, txManager)
 
 	userHandler := handlers.NewUserHandler(userService)
 	accountHandler := handlers.NewAccountHandler(accountService)
 	lineItemHandler := handlers.NewLineItemHandler(lineItemService)
 	voucherHandler := handlers.NewVoucherHandler(voucherService)
-	authHandler := handlers.NewAuthHandler(userService, jwtManager)
-	pdfHandler := handlers.NewPDFHandler(voucherService, accountService)
+	authHandler := handlers.NewAuthHandler(userService, companyService, jwtManager)
+	pdfHandler := handlers.NewPDFHandler(voucherService, accountService, attachmentService)
 	reportHandler := handlers.NewReportHandler(reportService)
+	periodHandler := handlers.NewPeriodHandler(periodService)
+	fiscalYearHandler := handlers.NewFiscalYearHandler(fiscalYearService)
+	exportHandler := handlers.NewExportHandler(sieService)
+	importHandler := handlers.NewImportHandler(sieService)
+	vatHandler := handlers.NewVATHandler(vatService)
+	projectHandler := handlers.NewProjectHandler(projectService)
+	costCenterHandler := handlers.NewCostCenterHandler(costCenterService)
+	companyHandler := handlers.NewCompanyHandler(companyService)
+	voucherSeriesHandler := handlers.NewVoucherSeriesHandler(voucherSeriesService)
+	auditHandler := handlers.NewAuditHandler(auditService)
+	attachmentHandler := handlers.NewAttachmentHandler(attachmentService)
+	searchHandler := handlers.NewSearchHandler(searchService)
+	bankHandler := handlers.NewBankHandler(bankService)
+	bankRuleHandler := handlers.NewBankRuleHandler(bankRuleService)
+	voucherTemplateHandler := handlers.NewVoucherTemplateHandler(voucherTemplateService)
 
 	authMiddleware := middleware.AuthMiddleware(jwtManager)
 
@@ -52,10 +107,10 @@ func main() {
 	// Add CORS middleware
 	router.Use(middleware.CORSMiddleware())
 
-	routes.SetupRoutes(router, userHandler, accountHandler, lineItemHandler, voucherHandler, authHandler, pdfHandler, reportHandler, authMiddleware)
+	routes.SetupRoutes(router, userHandler, accountHandler, lineItemHandler, voucherHandler, authHandler, pdfHandler, reportHandler, periodHandler, fiscalYearHandler, exportHandler, importHandler, vatHandler, projectHandler, costCenterHandler, companyHandler, voucherSeriesHandler, auditHandler, attachmentHandler, searchHandler, bankHandler, bankRuleHandler, voucherTemplateHandler, authMiddleware)
 
 	log.Println("Starting server on", cfg.ServerPort)
 	if err := router.Run(cfg.ServerPort); err != nil {
 		log.Fatal("Failed to start server:", err)
 	}
-}
\ No newline at end of file
+}
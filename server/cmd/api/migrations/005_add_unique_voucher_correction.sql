@@ -0,0 +1,5 @@
+-- A voucher can only be corrected once; this backs up the row lock taken
+-- by the correction flow
+CREATE UNIQUE INDEX IF NOT EXISTS idx_vouchers_corrects_unique
+    ON vouchers(corrects_voucher_id)
+    WHERE corrects_voucher_id IS NOT NULL;
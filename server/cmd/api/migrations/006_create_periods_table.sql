@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS periods (
+    period VARCHAR(7) PRIMARY KEY,
+    status VARCHAR(10) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'locked', 'closed')),
+    changed_by INT,
+    changed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (changed_by) REFERENCES users(user_id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_periods_status ON periods(status);
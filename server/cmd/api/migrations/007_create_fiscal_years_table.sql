@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS fiscal_years (
+    fiscal_year_id SERIAL PRIMARY KEY,
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    status VARCHAR(10) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'closed')),
+    closing_voucher_id INT NULL,
+    opening_voucher_id INT NULL,
+    closed_by INT NULL,
+    closed_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (closing_voucher_id) REFERENCES vouchers(voucher_id) ON DELETE RESTRICT,
+    FOREIGN KEY (opening_voucher_id) REFERENCES vouchers(voucher_id) ON DELETE RESTRICT,
+    FOREIGN KEY (closed_by) REFERENCES users(user_id) ON DELETE SET NULL,
+    CHECK (end_date > start_date)
+);
+
+CREATE UNIQUE INDEX idx_fiscal_years_start ON fiscal_years(start_date);
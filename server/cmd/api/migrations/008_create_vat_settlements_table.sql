@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS vat_settlements (
+    vat_settlement_id SERIAL PRIMARY KEY,
+    period VARCHAR(7) NOT NULL UNIQUE,
+    from_date DATE NOT NULL,
+    to_date DATE NOT NULL,
+    voucher_id INT NOT NULL UNIQUE,
+    booked_by INT NULL,
+    booked_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (voucher_id) REFERENCES vouchers(voucher_id) ON DELETE RESTRICT,
+    FOREIGN KEY (booked_by) REFERENCES users(user_id) ON DELETE SET NULL,
+    CHECK (to_date >= from_date)
+);
@@ -0,0 +1,47 @@
+CREATE TABLE IF NOT EXISTS projects (
+    project_id SERIAL PRIMARY KEY,
+    code VARCHAR(20) NOT NULL UNIQUE,
+    name VARCHAR(100) NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    start_date DATE NULL,
+    end_date DATE NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK (start_date IS NULL OR end_date IS NULL OR end_date >= start_date)
+);
+
+CREATE TABLE IF NOT EXISTS cost_centers (
+    cost_center_id SERIAL PRIMARY KEY,
+    code VARCHAR(20) NOT NULL UNIQUE,
+    name VARCHAR(100) NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    start_date DATE NULL,
+    end_date DATE NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK (start_date IS NULL OR end_date IS NULL OR end_date >= start_date)
+);
+
+-- Lines used 0 for "no project/cost centre"; from now on that is NULL
+UPDATE line_items SET project_id = NULL WHERE project_id = 0;
+UPDATE line_items SET cost_center_id = NULL WHERE cost_center_id = 0;
+
+-- Keep IDs that are already booked by creating a placeholder for each
+INSERT INTO projects (project_id, code, name)
+SELECT DISTINCT project_id, project_id::text, 'Projekt ' || project_id
+FROM line_items WHERE project_id IS NOT NULL
+ON CONFLICT DO NOTHING;
+SELECT setval(pg_get_serial_sequence('projects', 'project_id'), COALESCE(MAX(project_id), 0) + 1, false) FROM projects;
+
+INSERT INTO cost_centers (cost_center_id, code, name)
+SELECT DISTINCT cost_center_id, cost_center_id::text, 'Kostnadsställe ' || cost_center_id
+FROM line_items WHERE cost_center_id IS NOT NULL
+ON CONFLICT DO NOTHING;
+SELECT setval(pg_get_serial_sequence('cost_centers', 'cost_center_id'), COALESCE(MAX(cost_center_id), 0) + 1, false) FROM cost_centers;
+
+ALTER TABLE line_items
+    ADD CONSTRAINT fk_line_items_project FOREIGN KEY (project_id) REFERENCES projects(project_id) ON DELETE RESTRICT,
+    ADD CONSTRAINT fk_line_items_cost_center FOREIGN KEY (cost_center_id) REFERENCES cost_centers(cost_center_id) ON DELETE RESTRICT;
+
+CREATE INDEX idx_line_items_project ON line_items(project_id);
+CREATE INDEX idx_line_items_cost_center ON line_items(cost_center_id);
@@ -0,0 +1,113 @@
+-- Companies: every table of the books gets a company_id, and users become
+-- members of one or more companies with a role per company
+CREATE TABLE IF NOT EXISTS companies (
+    company_id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    org_nr VARCHAR(20) NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS user_companies (
+    user_id INT NOT NULL,
+    company_id INT NOT NULL,
+    role VARCHAR(50) NOT NULL DEFAULT 'Bookkeeper',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, company_id),
+    FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE,
+    FOREIGN KEY (company_id) REFERENCES companies(company_id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_user_companies_company ON user_companies(company_id);
+
+-- Voucher numbers run per company; the row is locked while a number is taken
+CREATE TABLE IF NOT EXISTS voucher_counters (
+    company_id INT PRIMARY KEY,
+    last_number INT NOT NULL DEFAULT 0,
+    FOREIGN KEY (company_id) REFERENCES companies(company_id) ON DELETE CASCADE
+);
+
+-- The existing books become company 1 and every user a member of it with
+-- the role they have today
+INSERT INTO companies (company_id, name) VALUES (1, 'Mitt företag')
+ON CONFLICT DO NOTHING;
+SELECT setval(pg_get_serial_sequence('companies', 'company_id'), COALESCE(MAX(company_id), 0) + 1, false) FROM companies;
+
+INSERT INTO user_companies (user_id, company_id, role)
+SELECT user_id, 1, role FROM users
+ON CONFLICT DO NOTHING;
+
+-- The default only fills in existing rows; new rows must name their company
+ALTER TABLE accounts ADD COLUMN company_id INT NOT NULL DEFAULT 1 REFERENCES companies(company_id) ON DELETE RESTRICT;
+ALTER TABLE vouchers ADD COLUMN company_id INT NOT NULL DEFAULT 1 REFERENCES companies(company_id) ON DELETE RESTRICT;
+ALTER TABLE line_items ADD COLUMN company_id INT NOT NULL DEFAULT 1 REFERENCES companies(company_id) ON DELETE RESTRICT;
+ALTER TABLE periods ADD COLUMN company_id INT NOT NULL DEFAULT 1 REFERENCES companies(company_id) ON DELETE RESTRICT;
+ALTER TABLE fiscal_years ADD COLUMN company_id INT NOT NULL DEFAULT 1 REFERENCES companies(company_id) ON DELETE RESTRICT;
+ALTER TABLE vat_settlements ADD COLUMN company_id INT NOT NULL DEFAULT 1 REFERENCES companies(company_id) ON DELETE RESTRICT;
+ALTER TABLE projects ADD COLUMN company_id INT NOT NULL DEFAULT 1 REFERENCES companies(company_id) ON DELETE RESTRICT;
+ALTER TABLE cost_centers ADD COLUMN company_id INT NOT NULL DEFAULT 1 REFERENCES companies(company_id) ON DELETE RESTRICT;
+
+ALTER TABLE accounts ALTER COLUMN company_id DROP DEFAULT;
+ALTER TABLE vouchers ALTER COLUMN company_id DROP DEFAULT;
+ALTER TABLE line_items ALTER COLUMN company_id DROP DEFAULT;
+ALTER TABLE periods ALTER COLUMN company_id DROP DEFAULT;
+ALTER TABLE fiscal_years ALTER COLUMN company_id DROP DEFAULT;
+ALTER TABLE vat_settlements ALTER COLUMN company_id DROP DEFAULT;
+ALTER TABLE projects ALTER COLUMN company_id DROP DEFAULT;
+ALTER TABLE cost_centers ALTER COLUMN company_id DROP DEFAULT;
+
+-- Keys that were global are now per company
+ALTER TABLE line_items
+    DROP CONSTRAINT line_items_voucher_id_fkey,
+    DROP CONSTRAINT line_items_account_no_fkey,
+    DROP CONSTRAINT fk_line_items_project,
+    DROP CONSTRAINT fk_line_items_cost_center;
+
+ALTER TABLE accounts
+    DROP CONSTRAINT accounts_pkey,
+    ADD PRIMARY KEY (company_id, account_no);
+
+INSERT INTO voucher_counters (company_id, last_number)
+SELECT company_id, MAX(voucher_number) FROM vouchers GROUP BY company_id;
+
+ALTER TABLE vouchers
+    DROP CONSTRAINT vouchers_voucher_number_key,
+    ALTER COLUMN voucher_number DROP DEFAULT,
+    ADD CONSTRAINT uq_vouchers_company_number UNIQUE (company_id, voucher_number),
+    ADD CONSTRAINT uq_vouchers_company_id UNIQUE (company_id, voucher_id);
+DROP SEQUENCE IF EXISTS vouchers_number_seq;
+
+ALTER TABLE periods
+    DROP CONSTRAINT periods_pkey,
+    ADD PRIMARY KEY (company_id, period);
+
+DROP INDEX IF EXISTS idx_fiscal_years_start;
+CREATE UNIQUE INDEX idx_fiscal_years_start ON fiscal_years(company_id, start_date);
+
+ALTER TABLE vat_settlements
+    DROP CONSTRAINT vat_settlements_period_key,
+    ADD CONSTRAINT uq_vat_settlements_company_period UNIQUE (company_id, period);
+
+ALTER TABLE projects
+    DROP CONSTRAINT projects_code_key,
+    ADD CONSTRAINT uq_projects_company_code UNIQUE (company_id, code),
+    ADD CONSTRAINT uq_projects_company_id UNIQUE (company_id, project_id);
+
+ALTER TABLE cost_centers
+    DROP CONSTRAINT cost_centers_code_key,
+    ADD CONSTRAINT uq_cost_centers_company_code UNIQUE (company_id, code),
+    ADD CONSTRAINT uq_cost_centers_company_id UNIQUE (company_id, cost_center_id);
+
+-- A line can only point at a voucher, account, project and cost centre of
+-- its own company
+ALTER TABLE line_items
+    ADD CONSTRAINT fk_line_items_voucher FOREIGN KEY (company_id, voucher_id)
+        REFERENCES vouchers(company_id, voucher_id) ON DELETE CASCADE,
+    ADD CONSTRAINT fk_line_items_account FOREIGN KEY (company_id, account_no)
+        REFERENCES accounts(company_id, account_no) ON DELETE RESTRICT,
+    ADD CONSTRAINT fk_line_items_project FOREIGN KEY (company_id, project_id)
+        REFERENCES projects(company_id, project_id) ON DELETE RESTRICT,
+    ADD CONSTRAINT fk_line_items_cost_center FOREIGN KEY (company_id, cost_center_id)
+        REFERENCES cost_centers(company_id, cost_center_id) ON DELETE RESTRICT;
+
+CREATE INDEX idx_line_items_company_account ON line_items(company_id, account_no);
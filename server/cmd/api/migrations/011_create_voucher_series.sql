@@ -0,0 +1,61 @@
+-- Voucher series (verifikationsserier). Every voucher belongs to a series
+-- and is numbered without gaps within its series and fiscal year.
+CREATE TABLE IF NOT EXISTS voucher_series (
+    company_id INT NOT NULL,
+    series VARCHAR(10) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (company_id, series),
+    FOREIGN KEY (company_id) REFERENCES companies(company_id) ON DELETE CASCADE
+);
+
+INSERT INTO voucher_series (company_id, series, name)
+SELECT c.company_id, s.series, s.name
+FROM companies c
+CROSS JOIN (VALUES
+    ('A', 'Manuella verifikationer'),
+    ('B', 'Kundfakturor'),
+    ('C', 'Leverantörsfakturor')
+) AS s(series, name)
+ON CONFLICT DO NOTHING;
+
+-- fiscal_year_start is the first day of the fiscal year the voucher is
+-- numbered in, or of the calendar year when no fiscal year covers its date.
+-- Existing vouchers keep their numbers and end up in series A.
+ALTER TABLE vouchers
+    ADD COLUMN series VARCHAR(10) NOT NULL DEFAULT 'A',
+    ADD COLUMN fiscal_year_start DATE;
+
+UPDATE vouchers v
+SET fiscal_year_start = COALESCE(
+    (SELECT fy.start_date FROM fiscal_years fy
+     WHERE fy.company_id = v.company_id AND v.date BETWEEN fy.start_date AND fy.end_date),
+    date_trunc('year', v.date)::date
+);
+
+ALTER TABLE vouchers
+    ALTER COLUMN series DROP DEFAULT,
+    ALTER COLUMN fiscal_year_start SET NOT NULL,
+    DROP CONSTRAINT uq_vouchers_company_number,
+    ADD CONSTRAINT uq_vouchers_series_number UNIQUE (company_id, series, fiscal_year_start, voucher_number),
+    ADD CONSTRAINT fk_vouchers_series FOREIGN KEY (company_id, series)
+        REFERENCES voucher_series(company_id, series) ON DELETE RESTRICT;
+
+-- Counters now run per series and fiscal year. A number is taken in the same
+-- transaction as the voucher insert, so a rollback gives it back.
+DROP TABLE IF EXISTS voucher_counters;
+
+CREATE TABLE voucher_counters (
+    company_id INT NOT NULL,
+    series VARCHAR(10) NOT NULL,
+    fiscal_year_start DATE NOT NULL,
+    last_number INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (company_id, series, fiscal_year_start),
+    FOREIGN KEY (company_id, series) REFERENCES voucher_series(company_id, series) ON DELETE CASCADE
+);
+
+INSERT INTO voucher_counters (company_id, series, fiscal_year_start, last_number)
+SELECT company_id, series, fiscal_year_start, MAX(voucher_number)
+FROM vouchers
+GROUP BY company_id, series, fiscal_year_start;
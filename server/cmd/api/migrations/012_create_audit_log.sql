@@ -0,0 +1,32 @@
+-- Audit trail of every change to the books, users and companies. Rows are
+-- written in the same transaction as the change; the triggers make the
+-- table append-only.
+CREATE TABLE IF NOT EXISTS audit_log (
+    audit_id BIGSERIAL PRIMARY KEY,
+    company_id INT NULL REFERENCES companies(company_id) ON DELETE RESTRICT, -- NULL for changes outside any company, such as registering
+    user_id INT NOT NULL, -- No foreign key: the history outlives deleted users
+    entity VARCHAR(50) NOT NULL,
+    entity_id VARCHAR(100) NOT NULL,
+    action VARCHAR(20) NOT NULL CHECK (action IN ('create', 'update', 'delete')),
+    before_data JSONB NULL,
+    after_data JSONB NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_audit_log_company_created ON audit_log(company_id, created_at);
+CREATE INDEX idx_audit_log_entity ON audit_log(company_id, entity, entity_id);
+CREATE INDEX idx_audit_log_user ON audit_log(user_id);
+
+CREATE OR REPLACE FUNCTION audit_log_append_only() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_audit_log_no_update
+    BEFORE UPDATE OR DELETE ON audit_log
+    FOR EACH ROW EXECUTE FUNCTION audit_log_append_only();
+
+CREATE TRIGGER trg_audit_log_no_truncate
+    BEFORE TRUNCATE ON audit_log
+    FOR EACH STATEMENT EXECUTE FUNCTION audit_log_append_only();
@@ -0,0 +1,21 @@
+-- Tamper-evident hash chain over booked vouchers. Each voucher booked takes
+-- the next position in its company's chain and stores a SHA-256 hash over
+-- its header, its lines and the hash of the voucher before it. Vouchers
+-- booked before this migration are not in the chain.
+ALTER TABLE vouchers
+    ADD COLUMN IF NOT EXISTS chain_position INT NULL,
+    ADD COLUMN IF NOT EXISTS prev_hash CHAR(64) NULL, -- NULL for the first voucher in the chain
+    ADD COLUMN IF NOT EXISTS hash CHAR(64) NULL;
+
+ALTER TABLE vouchers
+    ADD CONSTRAINT vouchers_chain_position_unique UNIQUE (company_id, chain_position);
+
+-- The last link of every company's chain. Booking locks the row, so vouchers
+-- are chained one at a time, and verification can tell when vouchers at the
+-- end of the chain have been removed.
+CREATE TABLE IF NOT EXISTS voucher_chain_heads (
+    company_id INT PRIMARY KEY REFERENCES companies(company_id) ON DELETE CASCADE,
+    last_position INT NOT NULL DEFAULT 0,
+    last_hash CHAR(64) NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
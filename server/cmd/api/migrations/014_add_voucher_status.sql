@@ -0,0 +1,18 @@
+-- Draft and booked vouchers. A draft has no number yet and can be changed
+-- and deleted by its creator; booking gives it the next number in its
+-- series, seals it in the hash chain and from then on it can only be
+-- changed with a correction voucher. Existing vouchers are booked.
+ALTER TABLE vouchers
+    ADD COLUMN IF NOT EXISTS status VARCHAR(10) NOT NULL DEFAULT 'booked' CHECK (status IN ('draft', 'booked')),
+    ADD COLUMN IF NOT EXISTS booked_at TIMESTAMP NULL;
+
+UPDATE vouchers SET booked_at = created_at WHERE booked_at IS NULL;
+
+ALTER TABLE vouchers
+    ALTER COLUMN status SET DEFAULT 'draft',
+    ALTER COLUMN voucher_number DROP NOT NULL,
+    ALTER COLUMN fiscal_year_start DROP NOT NULL,
+    ADD CONSTRAINT chk_vouchers_booked_numbered
+        CHECK (status = 'draft' OR (voucher_number IS NOT NULL AND fiscal_year_start IS NOT NULL));
+
+CREATE INDEX IF NOT EXISTS idx_vouchers_status ON vouchers(company_id, status);
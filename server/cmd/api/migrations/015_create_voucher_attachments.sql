@@ -0,0 +1,19 @@
+-- Receipts and invoices attached to vouchers (underlag). The file itself is
+-- kept in the attachment storage under storage_key; the row holds its name,
+-- MIME type, size and SHA-256 checksum. Deleting a draft deletes its rows,
+-- the stored files are kept.
+CREATE TABLE IF NOT EXISTS voucher_attachments (
+    attachment_id SERIAL PRIMARY KEY,
+    company_id INT NOT NULL REFERENCES companies(company_id) ON DELETE RESTRICT,
+    voucher_id INT NOT NULL REFERENCES vouchers(voucher_id) ON DELETE CASCADE,
+    file_name VARCHAR(255) NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    size_bytes BIGINT NOT NULL CHECK (size_bytes > 0),
+    checksum CHAR(64) NOT NULL, -- SHA-256, hex
+    storage_key VARCHAR(255) NOT NULL UNIQUE,
+    uploaded_by INT NOT NULL REFERENCES users(user_id) ON DELETE RESTRICT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT voucher_attachments_checksum_unique UNIQUE (voucher_id, checksum)
+);
+
+CREATE INDEX idx_voucher_attachments_voucher ON voucher_attachments(company_id, voucher_id);
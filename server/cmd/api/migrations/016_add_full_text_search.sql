@@ -0,0 +1,20 @@
+-- Full-text search over vouchers and accounts with the Swedish dictionary.
+-- The vectors are generated columns, so they follow every change to the
+-- text they are built from. Description weighs more than reference.
+ALTER TABLE vouchers
+    ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+        setweight(to_tsvector('swedish', COALESCE(description, '')), 'A') ||
+        setweight(to_tsvector('swedish', COALESCE(reference, '')), 'B')
+    ) STORED;
+
+ALTER TABLE accounts
+    ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+        to_tsvector('swedish', account_name)
+    ) STORED;
+
+CREATE INDEX idx_vouchers_search ON vouchers USING GIN (search_vector);
+CREATE INDEX idx_accounts_search ON accounts USING GIN (search_vector);
+
+-- Amount searches look up line amounts
+CREATE INDEX idx_line_items_debit ON line_items(company_id, debit_amount);
+CREATE INDEX idx_line_items_credit ON line_items(company_id, credit_amount);
@@ -0,0 +1,29 @@
+-- Booked rows of imported bank statements. A row is recognised on a later
+-- import by its fingerprint, so overlapping statements can be imported.
+-- Reconciliation matches a row to the line on the bank account (19xx) that
+-- books the same payment; a line is matched to at most one row.
+CREATE TABLE IF NOT EXISTS bank_transactions (
+    transaction_id SERIAL PRIMARY KEY,
+    company_id INT NOT NULL REFERENCES companies(company_id) ON DELETE RESTRICT,
+    account_no INT NOT NULL CHECK (account_no BETWEEN 1900 AND 1999),
+    booking_date DATE NOT NULL,
+    value_date DATE,
+    amount DECIMAL(15, 2) NOT NULL,
+    currency CHAR(3) NOT NULL DEFAULT 'SEK',
+    text VARCHAR(255) NOT NULL DEFAULT '',
+    reference VARCHAR(255) NOT NULL DEFAULT '',
+    counterparty VARCHAR(255) NOT NULL DEFAULT '',
+    bank_reference VARCHAR(255) NOT NULL DEFAULT '',
+    fingerprint CHAR(64) NOT NULL, -- SHA-256, hex
+    matched_line_id INT REFERENCES line_items(line_id) ON DELETE SET NULL,
+    match_method VARCHAR(10) CHECK (match_method IN ('auto', 'manual')),
+    matched_by INT REFERENCES users(user_id) ON DELETE RESTRICT,
+    matched_at TIMESTAMP,
+    imported_by INT NOT NULL REFERENCES users(user_id) ON DELETE RESTRICT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (company_id, account_no) REFERENCES accounts(company_id, account_no) ON DELETE RESTRICT,
+    CONSTRAINT bank_transactions_fingerprint_unique UNIQUE (company_id, account_no, fingerprint)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_bank_transactions_line ON bank_transactions(matched_line_id) WHERE matched_line_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_bank_transactions_date ON bank_transactions(company_id, account_no, booking_date);
@@ -0,0 +1,50 @@
+-- Booking rules for imported bank transactions. A rule matches on the
+-- counterparty, the bank text (regular expressions, case-insensitive), the
+-- direction and an amount range, and books the transaction as a draft
+-- against counter_account_no with the rule's VAT code and dimensions. The
+-- rules are tried in priority order and the first that matches is used.
+CREATE TABLE IF NOT EXISTS bank_rules (
+    rule_id SERIAL PRIMARY KEY,
+    company_id INT NOT NULL REFERENCES companies(company_id) ON DELETE RESTRICT,
+    name VARCHAR(100) NOT NULL,
+    priority INT NOT NULL DEFAULT 100,
+    account_no INT, -- Bank account the rule applies to, NULL for all
+    counterparty_pattern VARCHAR(255) NOT NULL DEFAULT '',
+    text_pattern VARCHAR(255) NOT NULL DEFAULT '',
+    direction VARCHAR(3) NOT NULL DEFAULT '' CHECK (direction IN ('', 'in', 'out')),
+    min_amount DECIMAL(15, 2) CHECK (min_amount >= 0),
+    max_amount DECIMAL(15, 2) CHECK (max_amount >= 0),
+    description VARCHAR(255) NOT NULL DEFAULT '', -- Voucher text, the bank text if empty
+    counter_account_no INT NOT NULL,
+    tax_code INT CHECK (tax_code IN (0, 6, 12, 25)), -- NULL uses the account's VAT rate
+    project_id INT,
+    cost_center_id INT,
+    series VARCHAR(10) NOT NULL DEFAULT '',
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_by INT NOT NULL REFERENCES users(user_id) ON DELETE RESTRICT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (company_id, account_no) REFERENCES accounts(company_id, account_no) ON DELETE RESTRICT,
+    FOREIGN KEY (company_id, counter_account_no) REFERENCES accounts(company_id, account_no) ON DELETE RESTRICT,
+    FOREIGN KEY (company_id, project_id) REFERENCES projects(company_id, project_id) ON DELETE RESTRICT,
+    FOREIGN KEY (company_id, cost_center_id) REFERENCES cost_centers(company_id, cost_center_id) ON DELETE RESTRICT,
+    CHECK (min_amount IS NULL OR max_amount IS NULL OR min_amount <= max_amount)
+);
+
+CREATE INDEX IF NOT EXISTS idx_bank_rules_priority ON bank_rules(company_id, priority, rule_id);
+
+-- Which rule produced which draft voucher from which bank transaction.
+-- Deleting the draft deletes the row, so the transaction can be booked
+-- again; a rule that has produced vouchers cannot be deleted, only
+-- deactivated.
+CREATE TABLE IF NOT EXISTS bank_rule_applications (
+    application_id SERIAL PRIMARY KEY,
+    company_id INT NOT NULL REFERENCES companies(company_id) ON DELETE RESTRICT,
+    rule_id INT NOT NULL REFERENCES bank_rules(rule_id) ON DELETE RESTRICT,
+    transaction_id INT NOT NULL UNIQUE REFERENCES bank_transactions(transaction_id) ON DELETE CASCADE,
+    voucher_id INT NOT NULL UNIQUE REFERENCES vouchers(voucher_id) ON DELETE CASCADE,
+    created_by INT NOT NULL REFERENCES users(user_id) ON DELETE RESTRICT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_bank_rule_applications_rule ON bank_rule_applications(company_id, rule_id);
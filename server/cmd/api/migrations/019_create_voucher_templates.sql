@@ -0,0 +1,38 @@
+-- Voucher templates (konteringsmallar) for recurring entries such as
+-- payroll, rent and the VAT settlement. Each line has a fixed amount or a
+-- percentage of the total the voucher is created with; the fixed amounts
+-- and the percentages each balance between debit and credit, so every
+-- voucher created from a template balances.
+CREATE TABLE IF NOT EXISTS voucher_templates (
+    template_id SERIAL PRIMARY KEY,
+    company_id INT NOT NULL REFERENCES companies(company_id) ON DELETE RESTRICT,
+    name VARCHAR(100) NOT NULL,
+    description VARCHAR(255) NOT NULL DEFAULT '', -- Voucher text
+    series VARCHAR(10) NOT NULL DEFAULT '',
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_by INT NOT NULL REFERENCES users(user_id) ON DELETE RESTRICT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT uq_voucher_templates_company_name UNIQUE (company_id, name),
+    CONSTRAINT uq_voucher_templates_company_id UNIQUE (company_id, template_id)
+);
+
+CREATE TABLE IF NOT EXISTS voucher_template_lines (
+    line_id SERIAL PRIMARY KEY,
+    company_id INT NOT NULL,
+    template_id INT NOT NULL,
+    line_no INT NOT NULL,
+    account_no INT NOT NULL,
+    side VARCHAR(6) NOT NULL CHECK (side IN ('debit', 'credit')),
+    amount DECIMAL(15, 2) CHECK (amount > 0),
+    percentage DECIMAL(5, 2) CHECK (percentage > 0 AND percentage <= 100),
+    tax_code INT NOT NULL DEFAULT 0 CHECK (tax_code IN (0, 6, 12, 25)),
+    project_id INT,
+    cost_center_id INT,
+    FOREIGN KEY (company_id, template_id) REFERENCES voucher_templates(company_id, template_id) ON DELETE CASCADE,
+    FOREIGN KEY (company_id, account_no) REFERENCES accounts(company_id, account_no) ON DELETE RESTRICT,
+    FOREIGN KEY (company_id, project_id) REFERENCES projects(company_id, project_id) ON DELETE RESTRICT,
+    FOREIGN KEY (company_id, cost_center_id) REFERENCES cost_centers(company_id, cost_center_id) ON DELETE RESTRICT,
+    CONSTRAINT uq_voucher_template_lines_no UNIQUE (template_id, line_no),
+    CHECK ((amount IS NULL) <> (percentage IS NULL))
+);
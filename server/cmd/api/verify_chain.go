@@ -0,0 +1,87 @@
+package main
+
+import (
+	"cmd/api/internal/config"
+	"cmd/api/internal/database"
+	"cmd/api/internal/domain"
+	"cmd/api/internal/repository"
+	"cmd/api/internal/service"
+	"flag"
+	"fmt"
+	"os"
+)
+
+// runVerifyChain implements `api verify-chain [-company id]`. It recomputes
+// the voucher hash chain of one company, or of all of them, and prints the
+// first broken link. The exit code is 0 when every chain is intact, 1 when
+// one is broken and 2 when the check could not be run.
+func runVerifyChain(args []string) int {
+	flags := flag.NewFlagSet("verify-chain", flag.ExitOnError)
+	companyID := flags.Int("company", 0, "only verify the company with this ID")
+	flags.Parse(args)
+
+	cfg := config.LoadConfig()
+
+	db, err := database.NewConnection(cfg.DatabaseURL)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Failed to connect to database:", err)
+		return 2
+	}
+	defer db.Close()
+
+	companyRepo := repository.NewCompanyRepository(db)
+	voucherService := service.NewVoucherService(
+		repository.NewVoucherRepository(db),
+		repository.NewLineItemRepository(db),
+		repository.NewAccountRepository(db),
+		repository.NewPeriodRepository(db),
+		repository.NewProjectRepository(db),
+		repository.NewCostCenterRepository(db),
+		repository.NewVoucherSeriesRepository(db),
+		repository.NewAuditRepository(db),
+		repository.NewTxManager(db),
+	)
+
+	var companies []*domain.Company
+	if *companyID > 0 {
+		company, err := companyRepo.GetCompanyByID(*companyID)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+		companies = append(companies, company)
+	} else {
+		companies, err = companyRepo.GetAllCompanies()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+	}
+
+	exitCode := 0
+	for _, company := range companies {
+		verification, err := voucherService.ForCompany(company.CompanyID).VerifyChain()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Company %d %s: %v\n", company.CompanyID, company.Name, err)
+			return 2
+		}
+
+		if verification.Valid {
+			fmt.Printf("Company %d %s: OK, %d vouchers, head %d %s\n",
+				company.CompanyID, company.Name, verification.VouchersChecked, verification.HeadPosition, verification.HeadHash)
+		} else {
+			broken := verification.BrokenLink
+			fmt.Printf("Company %d %s: BROKEN at position %d", company.CompanyID, company.Name, broken.Position)
+			if broken.VoucherID > 0 {
+				fmt.Printf(" (voucher %s%d, ID %d)", broken.Series, broken.VoucherNumber, broken.VoucherID)
+			}
+			fmt.Printf(": %s\n  expected hash %s\n  stored hash   %s\n", broken.Reason, broken.ExpectedHash, broken.StoredHash)
+			exitCode = 1
+		}
+		if verification.Unchained > 0 {
+			fmt.Printf("  %d vouchers booked before the hash chain are not covered\n", verification.Unchained)
+		}
+	}
+
+	return exitCode
+}
@@ -78,14 +78,538 @@ CREATE TABLE IF NOT EXISTS line_items (
 CREATE INDEX idx_line_items_voucher ON line_items(voucher_id);
 CREATE INDEX idx_line_items_account ON line_items(account_no);
 
+-- Migration 005: A voucher can only be corrected once
+CREATE UNIQUE INDEX IF NOT EXISTS idx_vouchers_corrects_unique
+    ON vouchers(corrects_voucher_id)
+    WHERE corrects_voucher_id IS NOT NULL;
+
+-- Migration 006: Create periods table
+CREATE TABLE IF NOT EXISTS periods (
+    period VARCHAR(7) PRIMARY KEY,
+    status VARCHAR(10) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'locked', 'closed')),
+    changed_by INT,
+    changed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (changed_by) REFERENCES users(user_id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_periods_status ON periods(status);
+
+-- Migration 007: Create fiscal_years table
+CREATE TABLE IF NOT EXISTS fiscal_years (
+    fiscal_year_id SERIAL PRIMARY KEY,
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    status VARCHAR(10) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'closed')),
+    closing_voucher_id INT NULL,
+    opening_voucher_id INT NULL,
+    closed_by INT NULL,
+    closed_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (closing_voucher_id) REFERENCES vouchers(voucher_id) ON DELETE RESTRICT,
+    FOREIGN KEY (opening_voucher_id) REFERENCES vouchers(voucher_id) ON DELETE RESTRICT,
+    FOREIGN KEY (closed_by) REFERENCES users(user_id) ON DELETE SET NULL,
+    CHECK (end_date > start_date)
+);
+
+CREATE UNIQUE INDEX idx_fiscal_years_start ON fiscal_years(start_date);
+
+-- Migration 008: Create vat_settlements table
+CREATE TABLE IF NOT EXISTS vat_settlements (
+    vat_settlement_id SERIAL PRIMARY KEY,
+    period VARCHAR(7) NOT NULL UNIQUE,
+    from_date DATE NOT NULL,
+    to_date DATE NOT NULL,
+    voucher_id INT NOT NULL UNIQUE,
+    booked_by INT NULL,
+    booked_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (voucher_id) REFERENCES vouchers(voucher_id) ON DELETE RESTRICT,
+    FOREIGN KEY (booked_by) REFERENCES users(user_id) ON DELETE SET NULL,
+    CHECK (to_date >= from_date)
+);
+
+-- Migration 009: Create projects and cost_centers tables
+CREATE TABLE IF NOT EXISTS projects (
+    project_id SERIAL PRIMARY KEY,
+    code VARCHAR(20) NOT NULL UNIQUE,
+    name VARCHAR(100) NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    start_date DATE NULL,
+    end_date DATE NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK (start_date IS NULL OR end_date IS NULL OR end_date >= start_date)
+);
+
+CREATE TABLE IF NOT EXISTS cost_centers (
+    cost_center_id SERIAL PRIMARY KEY,
+    code VARCHAR(20) NOT NULL UNIQUE,
+    name VARCHAR(100) NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    start_date DATE NULL,
+    end_date DATE NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK (start_date IS NULL OR end_date IS NULL OR end_date >= start_date)
+);
+
+-- Lines used 0 for "no project/cost centre"; from now on that is NULL
+UPDATE line_items SET project_id = NULL WHERE project_id = 0;
+UPDATE line_items SET cost_center_id = NULL WHERE cost_center_id = 0;
+
+-- Keep IDs that are already booked by creating a placeholder for each
+INSERT INTO projects (project_id, code, name)
+SELECT DISTINCT project_id, project_id::text, 'Projekt ' || project_id
+FROM line_items WHERE project_id IS NOT NULL
+ON CONFLICT DO NOTHING;
+SELECT setval(pg_get_serial_sequence('projects', 'project_id'), COALESCE(MAX(project_id), 0) + 1, false) FROM projects;
+
+INSERT INTO cost_centers (cost_center_id, code, name)
+SELECT DISTINCT cost_center_id, cost_center_id::text, 'Kostnadsställe ' || cost_center_id
+FROM line_items WHERE cost_center_id IS NOT NULL
+ON CONFLICT DO NOTHING;
+SELECT setval(pg_get_serial_sequence('cost_centers', 'cost_center_id'), COALESCE(MAX(cost_center_id), 0) + 1, false) FROM cost_centers;
+
+ALTER TABLE line_items
+    ADD CONSTRAINT fk_line_items_project FOREIGN KEY (project_id) REFERENCES projects(project_id) ON DELETE RESTRICT,
+    ADD CONSTRAINT fk_line_items_cost_center FOREIGN KEY (cost_center_id) REFERENCES cost_centers(cost_center_id) ON DELETE RESTRICT;
+
+CREATE INDEX idx_line_items_project ON line_items(project_id);
+CREATE INDEX idx_line_items_cost_center ON line_items(cost_center_id);
+
+-- Migration 010: Create companies
+-- Every table of the books gets a company_id, and users become
+-- members of one or more companies with a role per company
+CREATE TABLE IF NOT EXISTS companies (
+    company_id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    org_nr VARCHAR(20) NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS user_companies (
+    user_id INT NOT NULL,
+    company_id INT NOT NULL,
+    role VARCHAR(50) NOT NULL DEFAULT 'Bookkeeper',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, company_id),
+    FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE,
+    FOREIGN KEY (company_id) REFERENCES companies(company_id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_user_companies_company ON user_companies(company_id);
+
+-- Voucher numbers run per company; the row is locked while a number is taken
+CREATE TABLE IF NOT EXISTS voucher_counters (
+    company_id INT PRIMARY KEY,
+    last_number INT NOT NULL DEFAULT 0,
+    FOREIGN KEY (company_id) REFERENCES companies(company_id) ON DELETE CASCADE
+);
+
+-- The existing books become company 1 and every user a member of it with
+-- the role they have today
+INSERT INTO companies (company_id, name) VALUES (1, 'Mitt företag')
+ON CONFLICT DO NOTHING;
+SELECT setval(pg_get_serial_sequence('companies', 'company_id'), COALESCE(MAX(company_id), 0) + 1, false) FROM companies;
+
+INSERT INTO user_companies (user_id, company_id, role)
+SELECT user_id, 1, role FROM users
+ON CONFLICT DO NOTHING;
+
+-- The default only fills in existing rows; new rows must name their company
+ALTER TABLE accounts ADD COLUMN company_id INT NOT NULL DEFAULT 1 REFERENCES companies(company_id) ON DELETE RESTRICT;
+ALTER TABLE vouchers ADD COLUMN company_id INT NOT NULL DEFAULT 1 REFERENCES companies(company_id) ON DELETE RESTRICT;
+ALTER TABLE line_items ADD COLUMN company_id INT NOT NULL DEFAULT 1 REFERENCES companies(company_id) ON DELETE RESTRICT;
+ALTER TABLE periods ADD COLUMN company_id INT NOT NULL DEFAULT 1 REFERENCES companies(company_id) ON DELETE RESTRICT;
+ALTER TABLE fiscal_years ADD COLUMN company_id INT NOT NULL DEFAULT 1 REFERENCES companies(company_id) ON DELETE RESTRICT;
+ALTER TABLE vat_settlements ADD COLUMN company_id INT NOT NULL DEFAULT 1 REFERENCES companies(company_id) ON DELETE RESTRICT;
+ALTER TABLE projects ADD COLUMN company_id INT NOT NULL DEFAULT 1 REFERENCES companies(company_id) ON DELETE RESTRICT;
+ALTER TABLE cost_centers ADD COLUMN company_id INT NOT NULL DEFAULT 1 REFERENCES companies(company_id) ON DELETE RESTRICT;
+
+ALTER TABLE accounts ALTER COLUMN company_id DROP DEFAULT;
+ALTER TABLE vouchers ALTER COLUMN company_id DROP DEFAULT;
+ALTER TABLE line_items ALTER COLUMN company_id DROP DEFAULT;
+ALTER TABLE periods ALTER COLUMN company_id DROP DEFAULT;
+ALTER TABLE fiscal_years ALTER COLUMN company_id DROP DEFAULT;
+ALTER TABLE vat_settlements ALTER COLUMN company_id DROP DEFAULT;
+ALTER TABLE projects ALTER COLUMN company_id DROP DEFAULT;
+ALTER TABLE cost_centers ALTER COLUMN company_id DROP DEFAULT;
+
+-- Keys that were global are now per company
+ALTER TABLE line_items
+    DROP CONSTRAINT line_items_voucher_id_fkey,
+    DROP CONSTRAINT line_items_account_no_fkey,
+    DROP CONSTRAINT fk_line_items_project,
+    DROP CONSTRAINT fk_line_items_cost_center;
+
+ALTER TABLE accounts
+    DROP CONSTRAINT accounts_pkey,
+    ADD PRIMARY KEY (company_id, account_no);
+
+INSERT INTO voucher_counters (company_id, last_number)
+SELECT company_id, MAX(voucher_number) FROM vouchers GROUP BY company_id;
+
+ALTER TABLE vouchers
+    DROP CONSTRAINT vouchers_voucher_number_key,
+    ALTER COLUMN voucher_number DROP DEFAULT,
+    ADD CONSTRAINT uq_vouchers_company_number UNIQUE (company_id, voucher_number),
+    ADD CONSTRAINT uq_vouchers_company_id UNIQUE (company_id, voucher_id);
+DROP SEQUENCE IF EXISTS vouchers_number_seq;
+
+ALTER TABLE periods
+    DROP CONSTRAINT periods_pkey,
+    ADD PRIMARY KEY (company_id, period);
+
+DROP INDEX IF EXISTS idx_fiscal_years_start;
+CREATE UNIQUE INDEX idx_fiscal_years_start ON fiscal_years(company_id, start_date);
+
+ALTER TABLE vat_settlements
+    DROP CONSTRAINT vat_settlements_period_key,
+    ADD CONSTRAINT uq_vat_settlements_company_period UNIQUE (company_id, period);
+
+ALTER TABLE projects
+    DROP CONSTRAINT projects_code_key,
+    ADD CONSTRAINT uq_projects_company_code UNIQUE (company_id, code),
+    ADD CONSTRAINT uq_projects_company_id UNIQUE (company_id, project_id);
+
+ALTER TABLE cost_centers
+    DROP CONSTRAINT cost_centers_code_key,
+    ADD CONSTRAINT uq_cost_centers_company_code UNIQUE (company_id, code),
+    ADD CONSTRAINT uq_cost_centers_company_id UNIQUE (company_id, cost_center_id);
+
+-- A line can only point at a voucher, account, project and cost centre of
+-- its own company
+ALTER TABLE line_items
+    ADD CONSTRAINT fk_line_items_voucher FOREIGN KEY (company_id, voucher_id)
+        REFERENCES vouchers(company_id, voucher_id) ON DELETE CASCADE,
+    ADD CONSTRAINT fk_line_items_account FOREIGN KEY (company_id, account_no)
+        REFERENCES accounts(company_id, account_no) ON DELETE RESTRICT,
+    ADD CONSTRAINT fk_line_items_project FOREIGN KEY (company_id, project_id)
+        REFERENCES projects(company_id, project_id) ON DELETE RESTRICT,
+    ADD CONSTRAINT fk_line_items_cost_center FOREIGN KEY (company_id, cost_center_id)
+        REFERENCES cost_centers(company_id, cost_center_id) ON DELETE RESTRICT;
+
+CREATE INDEX idx_line_items_company_account ON line_items(company_id, account_no);
+
+-- Migration 011: Create voucher series
+-- Voucher series (verifikationsserier). Every voucher belongs to a series
+-- and is numbered without gaps within its series and fiscal year.
+CREATE TABLE IF NOT EXISTS voucher_series (
+    company_id INT NOT NULL,
+    series VARCHAR(10) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (company_id, series),
+    FOREIGN KEY (company_id) REFERENCES companies(company_id) ON DELETE CASCADE
+);
+
+INSERT INTO voucher_series (company_id, series, name)
+SELECT c.company_id, s.series, s.name
+FROM companies c
+CROSS JOIN (VALUES
+    ('A', 'Manuella verifikationer'),
+    ('B', 'Kundfakturor'),
+    ('C', 'Leverantörsfakturor')
+) AS s(series, name)
+ON CONFLICT DO NOTHING;
+
+-- fiscal_year_start is the first day of the fiscal year the voucher is
+-- numbered in, or of the calendar year when no fiscal year covers its date.
+-- Existing vouchers keep their numbers and end up in series A.
+ALTER TABLE vouchers
+    ADD COLUMN series VARCHAR(10) NOT NULL DEFAULT 'A',
+    ADD COLUMN fiscal_year_start DATE;
+
+UPDATE vouchers v
+SET fiscal_year_start = COALESCE(
+    (SELECT fy.start_date FROM fiscal_years fy
+     WHERE fy.company_id = v.company_id AND v.date BETWEEN fy.start_date AND fy.end_date),
+    date_trunc('year', v.date)::date
+);
+
+ALTER TABLE vouchers
+    ALTER COLUMN series DROP DEFAULT,
+    ALTER COLUMN fiscal_year_start SET NOT NULL,
+    DROP CONSTRAINT uq_vouchers_company_number,
+    ADD CONSTRAINT uq_vouchers_series_number UNIQUE (company_id, series, fiscal_year_start, voucher_number),
+    ADD CONSTRAINT fk_vouchers_series FOREIGN KEY (company_id, series)
+        REFERENCES voucher_series(company_id, series) ON DELETE RESTRICT;
+
+-- Counters now run per series and fiscal year. A number is taken in the same
+-- transaction as the voucher insert, so a rollback gives it back.
+DROP TABLE IF EXISTS voucher_counters;
+
+CREATE TABLE voucher_counters (
+    company_id INT NOT NULL,
+    series VARCHAR(10) NOT NULL,
+    fiscal_year_start DATE NOT NULL,
+    last_number INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (company_id, series, fiscal_year_start),
+    FOREIGN KEY (company_id, series) REFERENCES voucher_series(company_id, series) ON DELETE CASCADE
+);
+
+INSERT INTO voucher_counters (company_id, series, fiscal_year_start, last_number)
+SELECT company_id, series, fiscal_year_start, MAX(voucher_number)
+FROM vouchers
+GROUP BY company_id, series, fiscal_year_start;
+
+-- Migration 012: Create audit log
+-- Audit trail of every change to the books, users and companies. Rows are
+-- written in the same transaction as the change; the triggers make the
+-- table append-only.
+CREATE TABLE IF NOT EXISTS audit_log (
+    audit_id BIGSERIAL PRIMARY KEY,
+    company_id INT NULL REFERENCES companies(company_id) ON DELETE RESTRICT, -- NULL for changes outside any company, such as registering
+    user_id INT NOT NULL, -- No foreign key: the history outlives deleted users
+    entity VARCHAR(50) NOT NULL,
+    entity_id VARCHAR(100) NOT NULL,
+    action VARCHAR(20) NOT NULL CHECK (action IN ('create', 'update', 'delete')),
+    before_data JSONB NULL,
+    after_data JSONB NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_audit_log_company_created ON audit_log(company_id, created_at);
+CREATE INDEX idx_audit_log_entity ON audit_log(company_id, entity, entity_id);
+CREATE INDEX idx_audit_log_user ON audit_log(user_id);
+
+CREATE OR REPLACE FUNCTION audit_log_append_only() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_audit_log_no_update
+    BEFORE UPDATE OR DELETE ON audit_log
+    FOR EACH ROW EXECUTE FUNCTION audit_log_append_only();
+
+CREATE TRIGGER trg_audit_log_no_truncate
+    BEFORE TRUNCATE ON audit_log
+    FOR EACH STATEMENT EXECUTE FUNCTION audit_log_append_only();
+
+-- Migration 013: Add voucher hash chain
+-- Tamper-evident hash chain over booked vouchers. Each voucher booked takes
+-- the next position in its company's chain and stores a SHA-256 hash over
+-- its header, its lines and the hash of the voucher before it. Vouchers
+-- booked before this migration are not in the chain.
+ALTER TABLE vouchers
+    ADD COLUMN IF NOT EXISTS chain_position INT NULL,
+    ADD COLUMN IF NOT EXISTS prev_hash CHAR(64) NULL, -- NULL for the first voucher in the chain
+    ADD COLUMN IF NOT EXISTS hash CHAR(64) NULL;
+
+ALTER TABLE vouchers
+    ADD CONSTRAINT vouchers_chain_position_unique UNIQUE (company_id, chain_position);
+
+-- The last link of every company's chain. Booking locks the row, so vouchers
+-- are chained one at a time, and verification can tell when vouchers at the
+-- end of the chain have been removed.
+CREATE TABLE IF NOT EXISTS voucher_chain_heads (
+    company_id INT PRIMARY KEY REFERENCES companies(company_id) ON DELETE CASCADE,
+    last_position INT NOT NULL DEFAULT 0,
+    last_hash CHAR(64) NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Migration 014: Add voucher status
+-- Draft and booked vouchers. A draft has no number yet and can be changed
+-- and deleted by its creator; booking gives it the next number in its
+-- series, seals it in the hash chain and from then on it can only be
+-- changed with a correction voucher. Existing vouchers are booked.
+ALTER TABLE vouchers
+    ADD COLUMN IF NOT EXISTS status VARCHAR(10) NOT NULL DEFAULT 'booked' CHECK (status IN ('draft', 'booked')),
+    ADD COLUMN IF NOT EXISTS booked_at TIMESTAMP NULL;
+
+UPDATE vouchers SET booked_at = created_at WHERE booked_at IS NULL;
+
+ALTER TABLE vouchers
+    ALTER COLUMN status SET DEFAULT 'draft',
+    ALTER COLUMN voucher_number DROP NOT NULL,
+    ALTER COLUMN fiscal_year_start DROP NOT NULL,
+    ADD CONSTRAINT chk_vouchers_booked_numbered
+        CHECK (status = 'draft' OR (voucher_number IS NOT NULL AND fiscal_year_start IS NOT NULL));
+
+CREATE INDEX IF NOT EXISTS idx_vouchers_status ON vouchers(company_id, status);
+
+-- Migration 015: Create voucher attachments
+-- Receipts and invoices attached to vouchers (underlag). The file itself is
+-- kept in the attachment storage under storage_key; the row holds its name,
+-- MIME type, size and SHA-256 checksum. Deleting a draft deletes its rows,
+-- the stored files are kept.
+CREATE TABLE IF NOT EXISTS voucher_attachments (
+    attachment_id SERIAL PRIMARY KEY,
+    company_id INT NOT NULL REFERENCES companies(company_id) ON DELETE RESTRICT,
+    voucher_id INT NOT NULL REFERENCES vouchers(voucher_id) ON DELETE CASCADE,
+    file_name VARCHAR(255) NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    size_bytes BIGINT NOT NULL CHECK (size_bytes > 0),
+    checksum CHAR(64) NOT NULL, -- SHA-256, hex
+    storage_key VARCHAR(255) NOT NULL UNIQUE,
+    uploaded_by INT NOT NULL REFERENCES users(user_id) ON DELETE RESTRICT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT voucher_attachments_checksum_unique UNIQUE (voucher_id, checksum)
+);
+
+CREATE INDEX IF NOT EXISTS idx_voucher_attachments_voucher ON voucher_attachments(company_id, voucher_id);
+
+-- Migration 016: Add full-text search
+-- Full-text search over vouchers and accounts with the Swedish dictionary.
+-- The vectors are generated columns, so they follow every change to the
+-- text they are built from. Description weighs more than reference.
+ALTER TABLE vouchers
+    ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+        setweight(to_tsvector('swedish', COALESCE(description, '')), 'A') ||
+        setweight(to_tsvector('swedish', COALESCE(reference, '')), 'B')
+    ) STORED;
+
+ALTER TABLE accounts
+    ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+        to_tsvector('swedish', account_name)
+    ) STORED;
+
+CREATE INDEX idx_vouchers_search ON vouchers USING GIN (search_vector);
+CREATE INDEX idx_accounts_search ON accounts USING GIN (search_vector);
+
+-- Amount searches look up line amounts
+CREATE INDEX idx_line_items_debit ON line_items(company_id, debit_amount);
+CREATE INDEX idx_line_items_credit ON line_items(company_id, credit_amount);
+
+-- Migration 017: Create bank transactions
+-- Booked rows of imported bank statements. A row is recognised on a later
+-- import by its fingerprint, so overlapping statements can be imported.
+-- Reconciliation matches a row to the line on the bank account (19xx) that
+-- books the same payment; a line is matched to at most one row.
+CREATE TABLE IF NOT EXISTS bank_transactions (
+    transaction_id SERIAL PRIMARY KEY,
+    company_id INT NOT NULL REFERENCES companies(company_id) ON DELETE RESTRICT,
+    account_no INT NOT NULL CHECK (account_no BETWEEN 1900 AND 1999),
+    booking_date DATE NOT NULL,
+    value_date DATE,
+    amount DECIMAL(15, 2) NOT NULL,
+    currency CHAR(3) NOT NULL DEFAULT 'SEK',
+    text VARCHAR(255) NOT NULL DEFAULT '',
+    reference VARCHAR(255) NOT NULL DEFAULT '',
+    counterparty VARCHAR(255) NOT NULL DEFAULT '',
+    bank_reference VARCHAR(255) NOT NULL DEFAULT '',
+    fingerprint CHAR(64) NOT NULL, -- SHA-256, hex
+    matched_line_id INT REFERENCES line_items(line_id) ON DELETE SET NULL,
+    match_method VARCHAR(10) CHECK (match_method IN ('auto', 'manual')),
+    matched_by INT REFERENCES users(user_id) ON DELETE RESTRICT,
+    matched_at TIMESTAMP,
+    imported_by INT NOT NULL REFERENCES users(user_id) ON DELETE RESTRICT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (company_id, account_no) REFERENCES accounts(company_id, account_no) ON DELETE RESTRICT,
+    CONSTRAINT bank_transactions_fingerprint_unique UNIQUE (company_id, account_no, fingerprint)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_bank_transactions_line ON bank_transactions(matched_line_id) WHERE matched_line_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_bank_transactions_date ON bank_transactions(company_id, account_no, booking_date);
+
+-- Migration 018: Create bank rules
+-- Booking rules for imported bank transactions. A rule matches on the
+-- counterparty, the bank text (regular expressions, case-insensitive), the
+-- direction and an amount range, and books the transaction as a draft
+-- against counter_account_no with the rule's VAT code and dimensions. The
+-- rules are tried in priority order and the first that matches is used.
+CREATE TABLE IF NOT EXISTS bank_rules (
+    rule_id SERIAL PRIMARY KEY,
+    company_id INT NOT NULL REFERENCES companies(company_id) ON DELETE RESTRICT,
+    name VARCHAR(100) NOT NULL,
+    priority INT NOT NULL DEFAULT 100,
+    account_no INT, -- Bank account the rule applies to, NULL for all
+    counterparty_pattern VARCHAR(255) NOT NULL DEFAULT '',
+    text_pattern VARCHAR(255) NOT NULL DEFAULT '',
+    direction VARCHAR(3) NOT NULL DEFAULT '' CHECK (direction IN ('', 'in', 'out')),
+    min_amount DECIMAL(15, 2) CHECK (min_amount >= 0),
+    max_amount DECIMAL(15, 2) CHECK (max_amount >= 0),
+    description VARCHAR(255) NOT NULL DEFAULT '', -- Voucher text, the bank text if empty
+    counter_account_no INT NOT NULL,
+    tax_code INT CHECK (tax_code IN (0, 6, 12, 25)), -- NULL uses the account's VAT rate
+    project_id INT,
+    cost_center_id INT,
+    series VARCHAR(10) NOT NULL DEFAULT '',
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_by INT NOT NULL REFERENCES users(user_id) ON DELETE RESTRICT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (company_id, account_no) REFERENCES accounts(company_id, account_no) ON DELETE RESTRICT,
+    FOREIGN KEY (company_id, counter_account_no) REFERENCES accounts(company_id, account_no) ON DELETE RESTRICT,
+    FOREIGN KEY (company_id, project_id) REFERENCES projects(company_id, project_id) ON DELETE RESTRICT,
+    FOREIGN KEY (company_id, cost_center_id) REFERENCES cost_centers(company_id, cost_center_id) ON DELETE RESTRICT,
+    CHECK (min_amount IS NULL OR max_amount IS NULL OR min_amount <= max_amount)
+);
+
+CREATE INDEX IF NOT EXISTS idx_bank_rules_priority ON bank_rules(company_id, priority, rule_id);
+
+-- Which rule produced which draft voucher from which bank transaction.
+-- Deleting the draft deletes the row, so the transaction can be booked
+-- again; a rule that has produced vouchers cannot be deleted, only
+-- deactivated.
+CREATE TABLE IF NOT EXISTS bank_rule_applications (
+    application_id SERIAL PRIMARY KEY,
+    company_id INT NOT NULL REFERENCES companies(company_id) ON DELETE RESTRICT,
+    rule_id INT NOT NULL REFERENCES bank_rules(rule_id) ON DELETE RESTRICT,
+    transaction_id INT NOT NULL UNIQUE REFERENCES bank_transactions(transaction_id) ON DELETE CASCADE,
+    voucher_id INT NOT NULL UNIQUE REFERENCES vouchers(voucher_id) ON DELETE CASCADE,
+    created_by INT NOT NULL REFERENCES users(user_id) ON DELETE RESTRICT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_bank_rule_applications_rule ON bank_rule_applications(company_id, rule_id);
+
+-- Migration 019: Create voucher templates
+-- Voucher templates (konteringsmallar) for recurring entries such as
+-- payroll, rent and the VAT settlement. Each line has a fixed amount or a
+-- percentage of the total the voucher is created with; the fixed amounts
+-- and the percentages each balance between debit and credit, so every
+-- voucher created from a template balances.
+CREATE TABLE IF NOT EXISTS voucher_templates (
+    template_id SERIAL PRIMARY KEY,
+    company_id INT NOT NULL REFERENCES companies(company_id) ON DELETE RESTRICT,
+    name VARCHAR(100) NOT NULL,
+    description VARCHAR(255) NOT NULL DEFAULT '', -- Voucher text
+    series VARCHAR(10) NOT NULL DEFAULT '',
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_by INT NOT NULL REFERENCES users(user_id) ON DELETE RESTRICT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT uq_voucher_templates_company_name UNIQUE (company_id, name),
+    CONSTRAINT uq_voucher_templates_company_id UNIQUE (company_id, template_id)
+);
+
+CREATE TABLE IF NOT EXISTS voucher_template_lines (
+    line_id SERIAL PRIMARY KEY,
+    company_id INT NOT NULL,
+    template_id INT NOT NULL,
+    line_no INT NOT NULL,
+    account_no INT NOT NULL,
+    side VARCHAR(6) NOT NULL CHECK (side IN ('debit', 'credit')),
+    amount DECIMAL(15, 2) CHECK (amount > 0),
+    percentage DECIMAL(5, 2) CHECK (percentage > 0 AND percentage <= 100),
+    tax_code INT NOT NULL DEFAULT 0 CHECK (tax_code IN (0, 6, 12, 25)),
+    project_id INT,
+    cost_center_id INT,
+    FOREIGN KEY (company_id, template_id) REFERENCES voucher_templates(company_id, template_id) ON DELETE CASCADE,
+    FOREIGN KEY (company_id, account_no) REFERENCES accounts(company_id, account_no) ON DELETE RESTRICT,
+    FOREIGN KEY (company_id, project_id) REFERENCES projects(company_id, project_id) ON DELETE RESTRICT,
+    FOREIGN KEY (company_id, cost_center_id) REFERENCES cost_centers(company_id, cost_center_id) ON DELETE RESTRICT,
+    CONSTRAINT uq_voucher_template_lines_no UNIQUE (template_id, line_no),
+    CHECK ((amount IS NULL) <> (percentage IS NULL))
+);
+
 -- Insert default users
 -- Password for both users is: Password123
 INSERT INTO users (name, email, password_hash, role) VALUES
 ('Admin', 'admin@eskio.se', '$2a$10$Cs8h5LLIFMsFN3rWiR0zp.CCFrkzc1.3Kn6IY5QLNVqjx6sx6Srn6', 'Admin'),
 ('Test User', 'test@gmail.com', '$2a$10$Cs8h5LLIFMsFN3rWiR0zp.CCFrkzc1.3Kn6IY5QLNVqjx6sx6Srn6', 'Bookkeeper');
 
+INSERT INTO user_companies (user_id, company_id, role)
+SELECT user_id, 1, role FROM users;
+
 -- Insert standard Swedish BAS accounts
-INSERT INTO accounts (account_no, account_name, account_group, tax_standard, type, standard_side) VALUES
+INSERT INTO accounts (company_id, account_no, account_name, account_group, tax_standard, type, standard_side)
+SELECT 1, bas.* FROM (VALUES
 -- KLASS 1: TILLGÅNGAR (Assets)
 -- 10 Immateriella anläggningstillgångar
 (1010, 'Utvecklingsutgifter', 1, '0%', 'BS', 'Debit'),
@@ -477,4 +1001,5 @@ INSERT INTO accounts (account_no, account_name, account_group, tax_standard, typ
 (8930, 'Latent skatt', 8, '0%', 'P&L', 'Debit'),
 (8990, 'Övriga skatter', 8, '0%', 'P&L', 'Debit'),
 (8999, 'Årets resultat', 8, '0%', 'P&L', 'Credit')
-ON CONFLICT (account_no) DO NOTHING;
+) AS bas(account_no, account_name, account_group, tax_standard, type, standard_side)
+ON CONFLICT (company_id, account_no) DO NOTHING;